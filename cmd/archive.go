@@ -0,0 +1,24 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+// archiveCmd shares the root command's scan flags; they are attached in
+// root.go's init once defined
+var archiveCmd = &cobra.Command{
+	Use:   "archive <file>",
+	Short: "Scan dependency manifests inside a .zip, .tar or .tar.gz archive",
+	Long: `archive reads supported dependency manifests directly from an archive in
+memory and scans them, without unpacking to disk. Findings reference files as
+<archive>!/<path>. All scan flags of the root command apply.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		flagArchive = args[0]
+		return runCheck(cmd, nil)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(archiveCmd)
+}
@@ -0,0 +1,311 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/ethanolivertroy/kev-check-demo/internal/batch"
+	"github.com/ethanolivertroy/kev-check-demo/internal/clients"
+	"github.com/ethanolivertroy/kev-check-demo/internal/git"
+	"github.com/ethanolivertroy/kev-check-demo/internal/history"
+	"github.com/ethanolivertroy/kev-check-demo/internal/models"
+	"github.com/spf13/cobra"
+)
+
+var (
+	flagBatchCheckpoint string
+	flagBatchPace       time.Duration
+)
+
+// batchCmd shares the root command's scan flags; they are attached in
+// root.go's init once defined
+var batchCmd = &cobra.Command{
+	Use:   "batch <targets.yaml>",
+	Short: "Scan every target listed in a YAML targets file into one report",
+	Long: `batch scans each target in a targets file and writes a single merged
+report. A target is a local path (optionally at a git ref), a list of paths,
+a git repository URL (shallow-cloned at its ref or default branch), an
+archive, or an inventory file. Each target may override the exclusions,
+ignored CVEs and packages, ecosystems, owners, deny list, KEV overlay,
+sources, EPSS and CVSS thresholds, skipped directories, requirements file
+patterns, and whether (fail) and when (fail_on) its KEVs fail the run;
+"defaults" applies overrides to every target.
+
+A path or git target's own .kev-checker.yaml, holding the same override
+fields, is applied before the targets file's overrides for it, so teams can
+set local policy that the central file can still override. Set
+repo_config: false on a target or in defaults to ignore it.
+
+Findings are grouped under the target's name (see --per-path-reports). Files
+in cloned repositories are reported as <git url>!/<path>. All scan flags of
+the root command apply except --ref, --inventory and --changed-files.
+
+With --checkpoint, each finished target's results are saved to a file, and
+a rerun after an interruption only scans the targets not finished yet (or
+whose settings changed). The file is removed once every target was scanned
+without data source failures. --pace waits between targets; after a target
+whose data sources failed, as when they rate limit, the wait doubles up to
+10 minutes until a target scans cleanly.
+
+Example targets file:
+
+  defaults:
+    exclusions: kev-exclusions.yaml
+  targets:
+    - name: api
+      git: https://github.com/example/api.git
+      ref: main
+    - name: web
+      path: ../web
+      owners: ../web/CODEOWNERS
+    - name: legacy
+      inventory: legacy-inventory.json
+      fail: false`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		flagBatch = args[0]
+		return runCheck(cmd, nil)
+	},
+}
+
+func init() {
+	batchCmd.Flags().StringVar(&flagBatchCheckpoint, "checkpoint", "", "File recording finished targets, to resume an interrupted batch")
+	batchCmd.Flags().DurationVar(&flagBatchPace, "pace", 0, "Wait between targets, backing off while data sources fail")
+	rootCmd.AddCommand(batchCmd)
+}
+
+// checkpointResult is the part of a target's scan result kept in a batch
+// checkpoint
+type checkpointResult struct {
+	Findings    []models.Finding       `json:"findings"`
+	Failing     []models.Finding       `json:"failing"`
+	Violations  []models.Violation     `json:"violations"`
+	Deps        []models.Dependency    `json:"deps"`
+	Warnings    []models.Warning       `json:"warnings"`
+	Catalog     clients.KEVCatalogInfo `json:"catalog"`
+	SourceNames []string               `json:"source_names"`
+	ParseErrors []models.ParseError    `json:"parse_errors"`
+	OverBudget  []models.Dependency    `json:"over_budget"`
+	Lifecycle   map[history.State]int  `json:"lifecycle"`
+}
+
+func newCheckpointResult(res *scanResult) checkpointResult {
+	return checkpointResult{
+		Findings:    res.findings,
+		Failing:     res.failing,
+		Violations:  res.violations,
+		Deps:        res.deps,
+		Warnings:    res.warnings,
+		Catalog:     res.catalog,
+		SourceNames: res.sourceNames,
+		ParseErrors: res.parseErrors,
+		OverBudget:  res.overBudget,
+		Lifecycle:   res.lifecycle,
+	}
+}
+
+func (c checkpointResult) scanResult() *scanResult {
+	return &scanResult{
+		findings:    c.Findings,
+		failing:     c.Failing,
+		violations:  c.Violations,
+		deps:        c.Deps,
+		warnings:    c.Warnings,
+		catalog:     c.Catalog,
+		sourceNames: c.SourceNames,
+		parseErrors: c.ParseErrors,
+		overBudget:  c.OverBudget,
+		lifecycle:   c.Lifecycle,
+	}
+}
+
+// scanBatch scans each target with its overrides applied to config and merges
+// the results. Data source failures don't stop the batch; other errors do.
+// Targets finished in an interrupted run are taken from the checkpoint.
+func scanBatch(config *models.Config, file *batch.File) (*scanResult, error) {
+	merged := &scanResult{}
+	failed := make(map[string]bool)
+	warned := make(map[string]bool)
+
+	var checkpoint *batch.Checkpoint
+	if flagBatchCheckpoint != "" {
+		var err error
+		if checkpoint, err = batch.OpenCheckpoint(flagBatchCheckpoint); err != nil {
+			return nil, err
+		}
+	}
+	pace := newPacer(flagBatchPace)
+	scanned := 0
+
+	for _, t := range file.Targets {
+		var key string
+		if checkpoint != nil {
+			var err error
+			if key, err = file.TargetKey(*config, t); err != nil {
+				return merged, fmt.Errorf("target %s: %w", t.Name, err)
+			}
+		}
+
+		var res *scanResult
+		var err error
+		if raw, ok := checkpoint.Lookup(t.Name, key); ok {
+			var stored checkpointResult
+			if err := json.Unmarshal(raw, &stored); err != nil {
+				return merged, fmt.Errorf("target %s: invalid checkpoint entry: %w", t.Name, err)
+			}
+			fmt.Fprintf(os.Stderr, "Target %s already scanned; results taken from %s\n", t.Name, flagBatchCheckpoint)
+			res = stored.scanResult()
+		} else {
+			if scanned > 0 {
+				pace.wait(context.Background())
+			}
+			scanned++
+			fmt.Fprintf(os.Stderr, "Scanning target %s\n", t.Name)
+			res, err = scanTarget(config, file, t)
+			if res != nil {
+				pace.observe(len(res.failures) > 0)
+			}
+			// Targets with failed sources are scanned again on resume
+			if err == nil && checkpoint != nil && len(res.failures) == 0 {
+				if err := checkpoint.Record(t.Name, key, newCheckpointResult(res)); err != nil {
+					return merged, err
+				}
+			}
+		}
+		if res != nil {
+			merged.deps = append(merged.deps, res.deps...)
+			merged.parseErrors = append(merged.parseErrors, res.parseErrors...)
+			merged.overBudget = append(merged.overBudget, res.overBudget...)
+			merged.catalog = res.catalog
+			merged.sourceNames = res.sourceNames
+			for _, f := range res.failures {
+				if !failed[f.Source] {
+					failed[f.Source] = true
+					merged.failures = append(merged.failures, f)
+				}
+			}
+		}
+		if err != nil {
+			return merged, fmt.Errorf("target %s: %w", t.Name, err)
+		}
+
+		merged.findings = append(merged.findings, res.findings...)
+		merged.failing = append(merged.failing, res.failing...)
+		merged.violations = append(merged.violations, res.violations...)
+		// Catalog and source warnings repeat for every target
+		for _, w := range res.warnings {
+			if !warned[w.String()] {
+				warned[w.String()] = true
+				merged.warnings = append(merged.warnings, w)
+			}
+		}
+		for state, n := range res.lifecycle {
+			if merged.lifecycle == nil {
+				merged.lifecycle = make(map[history.State]int)
+			}
+			merged.lifecycle[state] += n
+		}
+		if res.history != nil {
+			merged.history = res.history
+		}
+	}
+
+	if checkpoint != nil && len(merged.failures) == 0 {
+		if err := checkpoint.Remove(); err != nil {
+			return merged, err
+		}
+	}
+	return merged, nil
+}
+
+// scanTarget scans one batch target, attributing its results to the target
+func scanTarget(config *models.Config, file *batch.File, t batch.Target) (*scanResult, error) {
+	cfg := file.Defaults.Apply(*config)
+	cfg.GitRef = t.Ref
+
+	var cloneDir string
+	switch {
+	case t.Path != "":
+		cfg.Paths = []string{t.Path}
+	case len(t.Paths) > 0:
+		cfg.Paths = t.Paths
+	case t.Git != "":
+		dir, err := os.MkdirTemp("", "kev-checker-batch-")
+		if err != nil {
+			return nil, fmt.Errorf("failed to create clone directory: %w", err)
+		}
+		defer os.RemoveAll(dir)
+		if err := git.Clone(t.Git, t.Ref, dir); err != nil {
+			return nil, fmt.Errorf("failed to clone %s: %w", t.Git, err)
+		}
+		cloneDir = dir
+		cfg.Paths = []string{dir}
+		cfg.CloneOf = t.Git
+		cfg.GitRef = "" // Already checked out by the clone
+	case t.Archive != "":
+		cfg.Archive = t.Archive
+	case t.Inventory != "":
+		cfg.Inventory = t.Inventory
+	}
+
+	// The team's own policy applies first; the targets file has the last word
+	if file.UsesRepoConfig(t) {
+		repo, err := batch.LoadRepoConfig(cfg.Paths[0], cfg.GitRef)
+		if err != nil {
+			return nil, err
+		}
+		if repo != nil {
+			fmt.Fprintf(os.Stderr, "Applying %s from target %s\n", batch.RepoConfigFile, t.Name)
+			cfg = repo.Apply(cfg)
+		}
+	}
+	cfg = t.Overrides.Apply(cfg)
+
+	res, err := scanOnce(&cfg)
+	if res == nil {
+		return nil, err
+	}
+
+	// Files in a clone are named after the repository, not the temp dir
+	relabel := func(file string) string {
+		if cloneDir != "" {
+			if rel, err := filepath.Rel(cloneDir, file); err == nil && !strings.HasPrefix(rel, "..") {
+				return t.Git + "!/" + filepath.ToSlash(rel)
+			}
+		}
+		return file
+	}
+	attribute := func(dep *models.Dependency) {
+		dep.ScanPath = t.Name
+		relocate(dep, relabel)
+	}
+	for i := range res.deps {
+		attribute(&res.deps[i])
+	}
+	for i := range res.findings {
+		attribute(&res.findings[i].Dependency)
+	}
+	for i := range res.violations {
+		attribute(&res.violations[i].Dependency)
+	}
+	for i := range res.parseErrors {
+		res.parseErrors[i].File = relabel(res.parseErrors[i].File)
+	}
+	for i := range res.warnings {
+		if res.warnings[i].Dependency.Name != "" {
+			attribute(&res.warnings[i].Dependency)
+		}
+	}
+
+	// The target's findings are reported either way but only fail the run
+	// when the target allows it; scanOnce applied the target's fail_on
+	if !cfg.FailOnKEV {
+		res.failing = nil
+	}
+	return res, err
+}
@@ -0,0 +1,113 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ethanolivertroy/kev-check-demo/internal/cache"
+	"github.com/ethanolivertroy/kev-check-demo/internal/clients"
+	"github.com/ethanolivertroy/kev-check-demo/internal/models"
+	"github.com/ethanolivertroy/kev-check-demo/internal/osvmirror"
+	"github.com/spf13/cobra"
+)
+
+var flagCacheEcosystems []string
+
+var cacheCmd = &cobra.Command{
+	Use:   "cache",
+	Short: "Manage the local data cache",
+}
+
+var cacheWarmCmd = &cobra.Command{
+	Use:   "warm",
+	Short: "Pre-fetch KEV, EPSS and OSV data into the cache",
+	Long: `warm downloads the KEV catalog, EPSS scores for every KEV CVE, and the OSV
+records behind KEV CVEs for each ecosystem, so later scans work from the
+cache. Run it in a container build stage to ship images with the data baked
+in; set $KEV_CHECKER_CACHE_DIR to place the cache at a fixed path.
+
+The cache layout is deterministic: files are named by source and ecosystem,
+and their contents depend only on the upstream data. Scans prefer fresh
+data, and fall back to the cache when the KEV catalog or OSV API is
+unreachable.
+
+Example Dockerfile stage:
+  ENV KEV_CHECKER_CACHE_DIR=/opt/kev-checker/cache
+  RUN kev-checker cache warm --ecosystems npm,PyPI`,
+	Args: cobra.NoArgs,
+	RunE: runCacheWarm,
+}
+
+func init() {
+	cacheWarmCmd.Flags().StringSliceVar(&flagCacheEcosystems, "ecosystems", nil, "Ecosystems to mirror OSV records for (default: all supported)")
+	cacheCmd.AddCommand(cacheWarmCmd)
+	rootCmd.AddCommand(cacheCmd)
+}
+
+func runCacheWarm(cmd *cobra.Command, args []string) error {
+	var ecos []models.Ecosystem
+	for _, eco := range models.Ecosystems() {
+		if !eco.Info().NotInOSV {
+			ecos = append(ecos, eco)
+		}
+	}
+	if len(flagCacheEcosystems) > 0 {
+		ecos = nil
+		for _, name := range flagCacheEcosystems {
+			eco, ok := models.ParseEcosystem(strings.TrimSpace(name))
+			if !ok {
+				return fmt.Errorf("unknown ecosystem %q (supported: %s)", name, ecosystemNames())
+			}
+			if eco.Info().NotInOSV {
+				return fmt.Errorf("OSV has no records for %s to cache", eco)
+			}
+			ecos = append(ecos, eco)
+		}
+	}
+
+	c, err := cache.New("kev-checker", 24*time.Hour)
+	if err != nil {
+		return fmt.Errorf("failed to open cache: %w", err)
+	}
+
+	kev := clients.NewKEVClient(c)
+	catalog, err := kev.RefreshKEVCatalog()
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "Cached KEV catalog %s (%d entries)\n", kev.Info().Version, len(catalog))
+
+	ids := make([]string, 0, len(catalog))
+	for id := range catalog {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	scores, err := clients.NewEPSSClient(c).RefreshScores(ids)
+	if err != nil {
+		return fmt.Errorf("failed to cache EPSS scores: %w", err)
+	}
+	fmt.Fprintf(os.Stderr, "Cached EPSS scores for %d CVEs\n", scores)
+
+	for _, eco := range ecos {
+		n, err := osvmirror.Warm(c, eco, catalog)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(os.Stderr, "Cached %d OSV records for %s\n", n, eco)
+	}
+
+	fmt.Fprintf(os.Stderr, "Cache written to %s\n", c.Dir)
+	return nil
+}
+
+// ecosystemNames lists the supported ecosystems for error messages
+func ecosystemNames() string {
+	var names []string
+	for _, eco := range models.Ecosystems() {
+		names = append(names, string(eco))
+	}
+	return strings.Join(names, ", ")
+}
@@ -0,0 +1,244 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/ethanolivertroy/kev-check-demo/internal/cache"
+	"github.com/ethanolivertroy/kev-check-demo/internal/catalog"
+	"github.com/ethanolivertroy/kev-check-demo/internal/clients"
+	"github.com/ethanolivertroy/kev-check-demo/internal/models"
+	"github.com/spf13/cobra"
+)
+
+var (
+	flagCatalogSince  string
+	flagCatalogFrom   string
+	flagCatalogTo     string
+	flagCatalogFormat string
+)
+
+var catalogCmd = &cobra.Command{
+	Use:   "catalog",
+	Short: "Inspect the KEV catalog",
+}
+
+var catalogDiffCmd = &cobra.Command{
+	Use:   "diff",
+	Short: "List KEV catalog changes since a date or between cached releases",
+	Long: `diff lists KEV entries added to the catalog, due-date changes and new
+ransomware designations. Every catalog release kev-checker downloads is kept
+in the cache, so releases can be compared by version.
+
+With --since, entries added on or after the date are listed; due-date and
+ransomware changes are included when a release from before the date is cached.
+
+Examples:
+  kev-checker catalog diff --since 2024-01-01
+  kev-checker catalog diff --from 2024.05.01 --to 2024.05.08 --format json`,
+	Args: cobra.NoArgs,
+	RunE: runCatalogDiff,
+}
+
+func init() {
+	catalogDiffCmd.Flags().StringVar(&flagCatalogSince, "since", "", "List changes since this date (YYYY-MM-DD)")
+	catalogDiffCmd.Flags().StringVar(&flagCatalogFrom, "from", "", "Cached catalog version to compare from")
+	catalogDiffCmd.Flags().StringVar(&flagCatalogTo, "to", "", "Cached catalog version to compare to (default: latest catalog)")
+	catalogDiffCmd.Flags().StringVarP(&flagCatalogFormat, "format", "f", "terminal", "Output format: terminal, json")
+	catalogCmd.AddCommand(catalogDiffCmd)
+	rootCmd.AddCommand(catalogCmd)
+}
+
+func runCatalogDiff(cmd *cobra.Command, args []string) error {
+	if (flagCatalogSince == "") == (flagCatalogFrom == "") {
+		return fmt.Errorf("exactly one of --since or --from is required")
+	}
+	if flagCatalogFormat != "terminal" && flagCatalogFormat != "json" {
+		return fmt.Errorf("unsupported format: %s", flagCatalogFormat)
+	}
+
+	c, err := cache.New("kev-checker", 24*time.Hour)
+	if err != nil {
+		return fmt.Errorf("failed to open cache: %w", err)
+	}
+
+	// Release to compare to
+	var current map[string]models.KEVInfo
+	var to clients.KEVCatalogInfo
+	if flagCatalogTo != "" {
+		current, to, err = clients.LoadKEVSnapshot(c, flagCatalogTo)
+	} else {
+		kev := clients.NewKEVClient(c)
+		current, err = kev.FetchKEVCatalog()
+		to = kev.Info()
+	}
+	if err != nil {
+		return err
+	}
+
+	// Release or date to compare from
+	var diff catalog.Diff
+	from := flagCatalogFrom
+	if flagCatalogFrom != "" {
+		previous, _, err := clients.LoadKEVSnapshot(c, flagCatalogFrom)
+		if err != nil {
+			return withSnapshotHint(c, err)
+		}
+		diff = catalog.Compare(previous, current)
+	} else {
+		since, err := time.Parse("2006-01-02", flagCatalogSince)
+		if err != nil {
+			return fmt.Errorf("invalid --since %q: expected YYYY-MM-DD", flagCatalogSince)
+		}
+		baseline, err := baselineBefore(c, since, to.Version)
+		if err != nil {
+			return err
+		}
+		if baseline != nil {
+			previous, _, err := clients.LoadKEVSnapshot(c, baseline.Version)
+			if err != nil {
+				return err
+			}
+			diff = catalog.Compare(previous, current)
+			diff.Added = catalog.AddedSince(kevsByID(diff.Added), since).Added
+			from = baseline.Version
+		} else {
+			diff = catalog.AddedSince(current, since)
+			fmt.Fprintf(os.Stderr, "Note: no cached catalog from before %s; due-date and ransomware changes are not shown\n", flagCatalogSince)
+		}
+	}
+
+	if flagCatalogFormat == "json" {
+		out, err := json.MarshalIndent(newCatalogDiffJSON(diff, from, to.Version, flagCatalogSince), "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(out))
+		return nil
+	}
+	printCatalogDiff(diff, from, to.Version)
+	return nil
+}
+
+// baselineBefore returns the latest cached release published before since,
+// other than the release being compared to
+func baselineBefore(c *cache.Cache, since time.Time, exclude string) (*clients.KEVCatalogInfo, error) {
+	snapshots, err := clients.KEVSnapshots(c)
+	if err != nil {
+		return nil, err
+	}
+	var baseline *clients.KEVCatalogInfo
+	for i, s := range snapshots {
+		if s.Version != exclude && !s.Released().IsZero() && s.Released().Before(since) {
+			baseline = &snapshots[i]
+		}
+	}
+	return baseline, nil
+}
+
+// withSnapshotHint lists the cached versions when a requested one is missing
+func withSnapshotHint(c *cache.Cache, err error) error {
+	snapshots, _ := clients.KEVSnapshots(c)
+	if len(snapshots) == 0 {
+		return fmt.Errorf("%w; no catalog versions are cached yet", err)
+	}
+	versions := make([]string, len(snapshots))
+	for i, s := range snapshots {
+		versions[i] = s.Version
+	}
+	return fmt.Errorf("%w; cached versions: %v", err, versions)
+}
+
+func kevsByID(kevs []models.KEVInfo) map[string]models.KEVInfo {
+	m := make(map[string]models.KEVInfo, len(kevs))
+	for _, k := range kevs {
+		m[k.CVEID] = k
+	}
+	return m
+}
+
+func printCatalogDiff(d catalog.Diff, from, to string) {
+	if from == "" {
+		from = "--since " + flagCatalogSince
+	}
+	fmt.Printf("KEV catalog changes: %s → %s\n", from, to)
+
+	fmt.Printf("\nAdded (%d)\n", len(d.Added))
+	for _, k := range d.Added {
+		ransomware := ""
+		if k.RansomwareUse {
+			ransomware = " [ransomware]"
+		}
+		fmt.Printf("  %s  %-16s %s %s: %s%s\n", k.DateAdded.Format("2006-01-02"), k.CVEID, k.VendorProject, k.Product, k.VulnerabilityName, ransomware)
+	}
+
+	fmt.Printf("\nDue date changes (%d)\n", len(d.DueDateChanges))
+	for _, c := range d.DueDateChanges {
+		fmt.Printf("  %-16s %s %s: %s → %s\n", c.KEV.CVEID, c.KEV.VendorProject, c.KEV.Product,
+			c.OldDue.Format("2006-01-02"), c.KEV.DueDate.Format("2006-01-02"))
+	}
+
+	fmt.Printf("\nNewly ransomware-associated (%d)\n", len(d.NewRansomware))
+	for _, k := range d.NewRansomware {
+		fmt.Printf("  %-16s %s %s: %s\n", k.CVEID, k.VendorProject, k.Product, k.VulnerabilityName)
+	}
+}
+
+type catalogDiffJSON struct {
+	From           string              `json:"from,omitempty"`
+	To             string              `json:"to"`
+	Since          string              `json:"since,omitempty"`
+	Added          []catalogEntryJSON  `json:"added"`
+	DueDateChanges []catalogChangeJSON `json:"due_date_changes"`
+	NewRansomware  []catalogEntryJSON  `json:"new_ransomware"`
+}
+
+type catalogEntryJSON struct {
+	CVEID             string `json:"cve_id"`
+	VendorProject     string `json:"vendor_project"`
+	Product           string `json:"product"`
+	VulnerabilityName string `json:"vulnerability_name"`
+	DateAdded         string `json:"date_added"`
+	DueDate           string `json:"due_date"`
+	RansomwareUse     bool   `json:"ransomware_use"`
+}
+
+type catalogChangeJSON struct {
+	catalogEntryJSON
+	OldDueDate string `json:"old_due_date"`
+}
+
+func newCatalogDiffJSON(d catalog.Diff, from, to, since string) catalogDiffJSON {
+	entry := func(k models.KEVInfo) catalogEntryJSON {
+		return catalogEntryJSON{
+			CVEID:             k.CVEID,
+			VendorProject:     k.VendorProject,
+			Product:           k.Product,
+			VulnerabilityName: k.VulnerabilityName,
+			DateAdded:         k.DateAdded.Format("2006-01-02"),
+			DueDate:           k.DueDate.Format("2006-01-02"),
+			RansomwareUse:     k.RansomwareUse,
+		}
+	}
+
+	out := catalogDiffJSON{
+		From:           from,
+		To:             to,
+		Since:          since,
+		Added:          []catalogEntryJSON{},
+		DueDateChanges: []catalogChangeJSON{},
+		NewRansomware:  []catalogEntryJSON{},
+	}
+	for _, k := range d.Added {
+		out.Added = append(out.Added, entry(k))
+	}
+	for _, c := range d.DueDateChanges {
+		out.DueDateChanges = append(out.DueDateChanges, catalogChangeJSON{entry(c.KEV), c.OldDue.Format("2006-01-02")})
+	}
+	for _, k := range d.NewRansomware {
+		out.NewRansomware = append(out.NewRansomware, entry(k))
+	}
+	return out
+}
@@ -0,0 +1,151 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/ethanolivertroy/kev-check-demo/internal/models"
+	"github.com/ethanolivertroy/kev-check-demo/internal/scanner"
+	"github.com/spf13/cobra"
+)
+
+var flagCompareFormat string
+
+var compareRefsCmd = &cobra.Command{
+	Use:   "compare-refs <base-ref> <target-ref> [paths...]",
+	Short: "Compare KEV exposure between two git refs",
+	Long: `compare-refs scans dependency manifests at two git refs, read directly from
+the git object store, and lists KEVs introduced, resolved and still present
+in the target ref relative to the base ref.`,
+	Args: cobra.MinimumNArgs(2),
+	RunE: runCompareRefs,
+}
+
+func init() {
+	compareRefsCmd.Flags().StringVarP(&flagCompareFormat, "format", "f", "terminal", "Output format: terminal, json")
+	rootCmd.AddCommand(compareRefsCmd)
+}
+
+// refExposure is a single KEV exposure identified independently of version
+type refExposure struct {
+	Package    string `json:"package"`
+	Ecosystem  string `json:"ecosystem"`
+	Version    string `json:"version"`
+	CVEID      string `json:"cve_id"`
+	SourceFile string `json:"source_file"`
+}
+
+func (e refExposure) key() string {
+	return strings.Join([]string{e.Ecosystem, e.Package, e.CVEID, e.SourceFile}, "|")
+}
+
+type compareResult struct {
+	BaseRef    string        `json:"base_ref"`
+	TargetRef  string        `json:"target_ref"`
+	Introduced []refExposure `json:"introduced"`
+	Resolved   []refExposure `json:"resolved"`
+	Unchanged  []refExposure `json:"unchanged"`
+}
+
+func runCompareRefs(cmd *cobra.Command, args []string) error {
+	baseRef, targetRef := args[0], args[1]
+	paths := args[2:]
+	if len(paths) == 0 {
+		paths = []string{"."}
+	}
+
+	base, err := scanRef(baseRef, paths)
+	if err != nil {
+		return err
+	}
+	target, err := scanRef(targetRef, paths)
+	if err != nil {
+		return err
+	}
+
+	result := compareResult{
+		BaseRef:    baseRef,
+		TargetRef:  targetRef,
+		Introduced: []refExposure{},
+		Resolved:   []refExposure{},
+		Unchanged:  []refExposure{},
+	}
+	for k, e := range target {
+		if _, ok := base[k]; ok {
+			result.Unchanged = append(result.Unchanged, e)
+		} else {
+			result.Introduced = append(result.Introduced, e)
+		}
+	}
+	for k, e := range base {
+		if _, ok := target[k]; !ok {
+			result.Resolved = append(result.Resolved, e)
+		}
+	}
+	for _, list := range [][]refExposure{result.Introduced, result.Resolved, result.Unchanged} {
+		sort.Slice(list, func(i, j int) bool { return list[i].key() < list[j].key() })
+	}
+
+	if flagCompareFormat == "json" {
+		out, err := json.MarshalIndent(result, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(out))
+		return nil
+	}
+
+	fmt.Printf("KEV exposure: %s -> %s\n", baseRef, targetRef)
+	fmt.Println(strings.Repeat("=", 60))
+	printExposures("Introduced", "+", result.Introduced)
+	printExposures("Resolved", "-", result.Resolved)
+	printExposures("Unchanged", " ", result.Unchanged)
+	return nil
+}
+
+func printExposures(title, marker string, list []refExposure) {
+	fmt.Printf("\n%s (%d)\n", title, len(list))
+	for _, e := range list {
+		fmt.Printf("  %s %s@%s %s (%s)\n", marker, e.Package, e.Version, e.CVEID, e.SourceFile)
+	}
+}
+
+// scanRef scans paths at a git ref and returns KEV exposures keyed by identity
+func scanRef(ref string, paths []string) (map[string]refExposure, error) {
+	config := models.DefaultConfig()
+	config.Paths = paths
+	config.GitRef = ref
+	config.NoHistory = true
+
+	s, err := scanner.New(config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize scanner: %w", err)
+	}
+
+	findings, err := s.Scan(context.Background())
+	if err != nil {
+		return nil, fmt.Errorf("scan of %s failed: %w", ref, err)
+	}
+	for _, w := range s.Warnings() {
+		fmt.Fprintf(os.Stderr, "Warning: %s\n", w)
+	}
+
+	exposures := make(map[string]refExposure)
+	for _, f := range findings {
+		for _, kev := range f.KEVs {
+			e := refExposure{
+				Package:    f.Dependency.Name,
+				Ecosystem:  string(f.Dependency.Ecosystem),
+				Version:    f.Dependency.Version,
+				CVEID:      kev.CVEID,
+				SourceFile: f.Dependency.SourceFile,
+			}
+			exposures[e.key()] = e
+		}
+	}
+	return exposures, nil
+}
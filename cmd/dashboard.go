@@ -0,0 +1,85 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/ethanolivertroy/kev-check-demo/internal/cache"
+	"github.com/ethanolivertroy/kev-check-demo/internal/clients"
+	"github.com/ethanolivertroy/kev-check-demo/internal/dashboard"
+	"github.com/ethanolivertroy/kev-check-demo/internal/history"
+	"github.com/ethanolivertroy/kev-check-demo/internal/models"
+	"github.com/spf13/cobra"
+)
+
+var (
+	flagDashboardOut         string
+	flagDashboardHistoryFile string
+	flagDashboardWeeks       int
+	flagDashboardKEVOverlay  string
+)
+
+var dashboardCmd = &cobra.Command{
+	Use:   "dashboard --out dir/",
+	Short: "Render a static KEV exposure dashboard from the history store",
+	Long: `dashboard reads the finding lifecycle recorded in the history store by
+scans, recheck and queue workers and writes a static dashboard to --out:
+open KEV exposure by week, mean and median time to remediate, and the
+vendors and CWEs behind open findings. The directory holds index.html and
+dashboard.json and can be published to any static site; no server is needed.`,
+	Args: cobra.NoArgs,
+	RunE: runDashboard,
+}
+
+func init() {
+	dashboardCmd.Flags().StringVarP(&flagDashboardOut, "out", "o", "", "Directory to write the dashboard to (required)")
+	dashboardCmd.Flags().StringVar(&flagDashboardHistoryFile, "history-file", "", "History store path or postgres:// URL (default: ~/.cache/kev-checker/history/history.json)")
+	dashboardCmd.Flags().IntVar(&flagDashboardWeeks, "weeks", 26, "Number of weeks of exposure to chart")
+	dashboardCmd.Flags().StringVar(&flagDashboardKEVOverlay, "kev-overlay", "", "Organizational KEV catalog (CISA JSON format) merged with the CISA catalog")
+	dashboardCmd.MarkFlagRequired("out")
+	rootCmd.AddCommand(dashboardCmd)
+}
+
+func runDashboard(cmd *cobra.Command, args []string) error {
+	if flagDashboardWeeks < 1 {
+		return fmt.Errorf("--weeks must be at least 1")
+	}
+	historyPath := flagDashboardHistoryFile
+	if historyPath == "" {
+		var err error
+		if historyPath, err = history.DefaultPath("kev-checker"); err != nil {
+			return err
+		}
+	}
+	store, err := history.Open(historyPath)
+	if err != nil {
+		return fmt.Errorf("failed to open history store: %w", err)
+	}
+
+	c, err := cache.New("kev-checker", 24*time.Hour)
+	if err != nil {
+		// Non-fatal: fetch the catalog without caching
+		c = nil
+	}
+	catalog, err := clients.NewKEVClient(c).FetchKEVCatalog()
+	if err != nil {
+		// Vendors and CWEs come from the catalog; the rest doesn't need it
+		fmt.Fprintf(os.Stderr, "Warning: KEV catalog unavailable, vendors and CWEs will be unknown: %v\n", err)
+		catalog = make(map[string]models.KEVInfo)
+	}
+	if flagDashboardKEVOverlay != "" {
+		overlay, err := clients.LoadKEVOverlay(flagDashboardKEVOverlay)
+		if err != nil {
+			return err
+		}
+		clients.MergeKEVOverlay(catalog, overlay)
+	}
+
+	d := dashboard.Build(store.Findings(), catalog, flagDashboardWeeks, time.Now())
+	if err := d.Write(flagDashboardOut); err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "Dashboard written to %s (%d open, %d resolved)\n", flagDashboardOut, d.Open, d.Resolved)
+	return nil
+}
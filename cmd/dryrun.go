@@ -0,0 +1,95 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/ethanolivertroy/kev-check-demo/internal/models"
+	"github.com/ethanolivertroy/kev-check-demo/internal/scanner"
+	"github.com/ethanolivertroy/kev-check-demo/internal/upload"
+)
+
+// runDryRun discovers dependencies and prints the requests the scan would
+// send, without sending any or writing reports, history or alerts
+func runDryRun(config *models.Config) error {
+	if config.OutputFormat != "terminal" && config.OutputFormat != "json" {
+		return fmt.Errorf("--dry-run supports --format terminal or json")
+	}
+	config.DryRun = true
+	s, err := scanner.New(config)
+	if err != nil {
+		return fmt.Errorf("failed to initialize scanner: %w", err)
+	}
+	plan, err := s.Plan()
+	if err != nil {
+		return fmt.Errorf("dry run failed: %w", err)
+	}
+	plan.Notes = append(plan.Notes, skippedOutputs(config)...)
+	if plan.Requests == nil {
+		plan.Requests = []scanner.PlannedRequest{}
+	}
+
+	switch config.OutputFormat {
+	case "json":
+		out, err := json.MarshalIndent(plan, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(out))
+	case "terminal":
+		fmt.Printf("Dependencies discovered: %d (%d local, %d reused from the history store", plan.Dependencies, plan.Local, plan.Reused)
+		if plan.OverBudget > 0 {
+			fmt.Printf(", %d over the query budget", plan.OverBudget)
+		}
+		fmt.Println(")")
+
+		fmt.Printf("\nRequests (%d):\n", len(plan.Requests))
+		for _, req := range plan.Requests {
+			fmt.Printf("  %-4s %s  [%s", req.Method, req.URL, req.Purpose)
+			if req.Items > 0 {
+				fmt.Printf(", %d items", req.Items)
+			}
+			fmt.Println("]")
+		}
+
+		fmt.Println("\nPer KEV match:")
+		for _, call := range plan.Enrichment {
+			fmt.Printf("  %-15s %-4s %s  per %s", call.Enricher, call.Method, call.URL, call.Per)
+			if call.BatchSize > 0 {
+				fmt.Printf(" (up to %d)", call.BatchSize)
+			}
+			fmt.Println()
+		}
+
+		if len(plan.Notes) > 0 {
+			fmt.Println("\nNotes:")
+			for _, note := range plan.Notes {
+				fmt.Printf("  %s\n", note)
+			}
+		}
+		fmt.Println("\nNo requests were sent. Use --format json for request bodies.")
+	}
+	return nil
+}
+
+// skippedOutputs describes the reports, pushes and alerts the scan would
+// send that a dry run leaves out
+func skippedOutputs(config *models.Config) []string {
+	var notes []string
+	if upload.IsRemote(config.OutputFile) {
+		notes = append(notes, fmt.Sprintf("report upload to %s not sent", config.OutputFile))
+	}
+	if flagSign == "keyless" {
+		notes = append(notes, "Sigstore keyless signing not run")
+	}
+	if config.Push != "" {
+		notes = append(notes, fmt.Sprintf("push to %s not sent", config.Push))
+	}
+	if config.PagerDutyRoutingKey != "" {
+		notes = append(notes, "PagerDuty incidents not opened")
+	}
+	if config.OpsgenieAPIKey != "" {
+		notes = append(notes, "Opsgenie alerts not opened")
+	}
+	return notes
+}
@@ -0,0 +1,74 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/ethanolivertroy/kev-check-demo/internal/exposure"
+	"github.com/ethanolivertroy/kev-check-demo/internal/models"
+	"github.com/ethanolivertroy/kev-check-demo/internal/scanner"
+	"github.com/spf13/cobra"
+)
+
+var flagExposureFormat string
+
+var exposureCmd = &cobra.Command{
+	Use:   "exposure [paths...]",
+	Short: "List KEV vendors/products that appear in the dependency inventory",
+	Long: `exposure scans the given paths and lists the KEV catalog vendors and products
+whose names appear among the dependencies, whether or not the versions in use
+have a KEV. It gives a heads-up list of technologies with a history of
+exploitation. No vulnerability sources are queried.`,
+	RunE: runExposure,
+}
+
+func init() {
+	exposureCmd.Flags().StringVarP(&flagExposureFormat, "format", "f", "terminal", "Output format: terminal, json")
+	rootCmd.AddCommand(exposureCmd)
+}
+
+func runExposure(cmd *cobra.Command, args []string) error {
+	config := models.DefaultConfig()
+	if len(args) > 0 {
+		config.Paths = args
+	}
+	config.NoHistory = true
+
+	s, err := scanner.New(config)
+	if err != nil {
+		return fmt.Errorf("failed to initialize scanner: %w", err)
+	}
+	products, err := s.Exposure()
+	if err != nil {
+		return fmt.Errorf("scan failed: %w", err)
+	}
+
+	switch flagExposureFormat {
+	case "json":
+		report := exposure.Report{Dependencies: len(s.Dependencies()), Products: products}
+		if report.Products == nil {
+			report.Products = []exposure.Product{}
+		}
+		out, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(out))
+	case "terminal":
+		fmt.Printf("Dependencies scanned: %d\n", len(s.Dependencies()))
+		fmt.Printf("KEV products in use:  %d\n", len(products))
+		for _, p := range products {
+			fmt.Printf("\n%s %s\n", p.Vendor, p.Product)
+			fmt.Printf("  %d KEVs", p.KEVs)
+			if p.RansomwareRelated > 0 {
+				fmt.Printf(", %d ransomware-related", p.RansomwareRelated)
+			}
+			fmt.Printf(", latest added %s\n", p.LatestAdded.Format("2006-01-02"))
+			fmt.Printf("  Packages: %s\n", strings.Join(p.Packages, ", "))
+		}
+	default:
+		return fmt.Errorf("unsupported format: %s", flagExposureFormat)
+	}
+	return nil
+}
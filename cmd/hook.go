@@ -0,0 +1,97 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/ethanolivertroy/kev-check-demo/internal/git"
+	"github.com/ethanolivertroy/kev-check-demo/internal/parsers"
+	"github.com/spf13/cobra"
+)
+
+const hookMarker = "# installed by kev-checker"
+
+const preCommitHook = `#!/bin/sh
+` + hookMarker + `
+# Scan dependency manifests touched in the staged diff for CISA KEVs.
+exec kev-checker --changed-files "$@"
+`
+
+var flagHookForce bool
+
+var hookCmd = &cobra.Command{
+	Use:   "hook",
+	Short: "Manage git hook integration",
+}
+
+var hookInstallCmd = &cobra.Command{
+	Use:   "install",
+	Short: "Install a pre-commit hook that scans staged dependency manifests",
+	Args:  cobra.NoArgs,
+	RunE:  runHookInstall,
+}
+
+func init() {
+	hookInstallCmd.Flags().BoolVar(&flagHookForce, "force", false, "Overwrite an existing pre-commit hook")
+	hookCmd.AddCommand(hookInstallCmd)
+	rootCmd.AddCommand(hookCmd)
+}
+
+func runHookInstall(cmd *cobra.Command, args []string) error {
+	hooksDir, err := git.HooksDir(".")
+	if err != nil {
+		return fmt.Errorf("not a git repository: %w", err)
+	}
+
+	path := filepath.Join(hooksDir, "pre-commit")
+	if existing, err := os.ReadFile(path); err == nil {
+		if !strings.Contains(string(existing), hookMarker) && !flagHookForce {
+			return fmt.Errorf("%s already exists; use --force to overwrite", path)
+		}
+	}
+
+	if err := os.MkdirAll(hooksDir, 0755); err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, []byte(preCommitHook), 0755); err != nil {
+		return fmt.Errorf("failed to write hook: %w", err)
+	}
+
+	fmt.Fprintf(os.Stderr, "Pre-commit hook installed at %s\n", path)
+	return nil
+}
+
+// stagedManifests returns staged files that a parser can handle, as paths
+// relative to the working directory. The scan reads their staged content
+// from the index, not the working tree, which may hold unstaged edits.
+func stagedManifests() ([]string, error) {
+	top, err := git.TopLevel(".")
+	if err != nil {
+		return nil, err
+	}
+	files, err := git.StagedFiles(top)
+	if err != nil {
+		return nil, err
+	}
+
+	wd, err := os.Getwd()
+	if err != nil {
+		return nil, err
+	}
+
+	all := parsers.NewParsers(flagReqFiles)
+	var manifests []string
+	for _, f := range files {
+		if parsers.Find(all, f) == nil {
+			continue
+		}
+		path := filepath.Join(top, f)
+		if rel, err := filepath.Rel(wd, path); err == nil {
+			path = rel
+		}
+		manifests = append(manifests, path)
+	}
+	return manifests, nil
+}
@@ -0,0 +1,76 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/ethanolivertroy/kev-check-demo/internal/inventory"
+	"github.com/ethanolivertroy/kev-check-demo/internal/models"
+	"github.com/ethanolivertroy/kev-check-demo/internal/scanner"
+	"github.com/spf13/cobra"
+)
+
+var flagInventoryOutput string
+
+var inventoryCmd = &cobra.Command{
+	Use:   "inventory",
+	Short: "Export dependency inventories",
+}
+
+var inventoryExportCmd = &cobra.Command{
+	Use:   "export [paths...]",
+	Short: "Write the dependencies found under the given paths to an inventory file",
+	Long: `export parses the dependency manifests under the given paths and writes a
+versioned inventory of package URLs and the manifest locations that declare
+them. No vulnerability sources are queried.
+
+Scan an inventory later with --inventory, without access to the original
+repositories:
+  kev-checker inventory export ./services -o inventory.json
+  kev-checker --inventory inventory.json`,
+	RunE: runInventoryExport,
+}
+
+func init() {
+	inventoryExportCmd.Flags().StringVarP(&flagInventoryOutput, "output", "o", "", "Output file path (default: stdout)")
+	inventoryCmd.AddCommand(inventoryExportCmd)
+	rootCmd.AddCommand(inventoryCmd)
+}
+
+func runInventoryExport(cmd *cobra.Command, args []string) error {
+	config := models.DefaultConfig()
+	if len(args) > 0 {
+		config.Paths = args
+	}
+	config.NoHistory = true
+
+	s, err := scanner.New(config)
+	if err != nil {
+		return fmt.Errorf("failed to initialize scanner: %w", err)
+	}
+	deps, err := s.Discover()
+	if err != nil {
+		return err
+	}
+	for _, e := range s.ParseErrors() {
+		fmt.Fprintf(os.Stderr, "Warning: %s\n", parseErrorMessage(e))
+	}
+
+	inv := inventory.Export(deps)
+	out, err := json.MarshalIndent(inv, "", "  ")
+	if err != nil {
+		return err
+	}
+	out = append(out, '\n')
+
+	if flagInventoryOutput == "" {
+		fmt.Print(string(out))
+		return nil
+	}
+	if err := os.WriteFile(flagInventoryOutput, out, 0644); err != nil {
+		return fmt.Errorf("failed to write inventory: %w", err)
+	}
+	fmt.Fprintf(os.Stderr, "Inventory of %d packages written to %s\n", len(inv.Packages), flagInventoryOutput)
+	return nil
+}
@@ -0,0 +1,53 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+)
+
+// Bounds of the backoff after scans whose data sources failed
+const (
+	paceBackoff  = 30 * time.Second
+	maxPaceDelay = 10 * time.Minute
+)
+
+// pacer spaces out the scans of batch and queue runs so many repositories
+// don't exhaust the data sources' rate limits. After a scan whose sources
+// failed, as when they throttle us, the delay doubles up to maxPaceDelay; a
+// clean scan returns it to the base delay. A zero base delay disables pacing.
+type pacer struct {
+	base  time.Duration
+	delay time.Duration
+}
+
+func newPacer(base time.Duration) *pacer {
+	return &pacer{base: base, delay: base}
+}
+
+// observe adjusts the delay after a scan
+func (p *pacer) observe(sourcesFailed bool) {
+	if p.base <= 0 || !sourcesFailed {
+		p.delay = p.base
+		return
+	}
+	p.delay = max(p.base, min(max(2*p.delay, paceBackoff), maxPaceDelay))
+}
+
+// wait sleeps for the current delay. It returns false if ctx was canceled
+// first.
+func (p *pacer) wait(ctx context.Context) bool {
+	if p.delay <= 0 {
+		return true
+	}
+	if p.delay > p.base {
+		fmt.Fprintf(os.Stderr, "Data sources failed; waiting %s before the next scan\n", p.delay)
+	}
+	select {
+	case <-ctx.Done():
+		return false
+	case <-time.After(p.delay):
+		return true
+	}
+}
@@ -0,0 +1,269 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/ethanolivertroy/kev-check-demo/internal/models"
+	"github.com/ethanolivertroy/kev-check-demo/internal/queue"
+	"github.com/ethanolivertroy/kev-check-demo/internal/reporter"
+	"github.com/ethanolivertroy/kev-check-demo/internal/scanner"
+	"github.com/ethanolivertroy/kev-check-demo/internal/upload"
+	"github.com/spf13/cobra"
+)
+
+var (
+	flagQueueURL       string
+	flagQueueJobID     string
+	flagQueueRef       string
+	flagQueueInventory string
+
+	flagWorkerResults     string
+	flagWorkerOnce        bool
+	flagWorkerWait        time.Duration
+	flagWorkerHistoryFile string
+	flagWorkerExclusions  string
+	flagWorkerOwners      string
+	flagWorkerDenyList    string
+	flagWorkerKEVOverlay  string
+	flagWorkerPace        time.Duration
+)
+
+var queueCmd = &cobra.Command{
+	Use:   "queue",
+	Short: "Distribute scans across worker instances through a job queue",
+	Long: `queue pushes scan jobs to a shared queue and runs workers that pull and scan
+them, so scans of many repositories can be fanned out across instances.
+
+The queue is a directory on a shared filesystem (a path or dir:// URL) or a
+Redis list (redis://[user:password@]host:port/db?key=kev-checker:jobs, Redis
+6.2 or later).`,
+}
+
+var queuePushCmd = &cobra.Command{
+	Use:   "push [paths...]",
+	Short: "Queue a scan job",
+	Long: `push queues a scan of the given paths (as seen by the workers), manifest
+URLs, or an inventory file. The job ID is printed to stdout and names the
+worker's result file.
+
+Examples:
+  kev-checker queue push --queue redis://queue:6379 /repos/payments
+  kev-checker queue push --queue /mnt/shared/queue --inventory /mnt/shared/inventories/api.json`,
+	RunE: runQueuePush,
+}
+
+var queueWorkCmd = &cobra.Command{
+	Use:   "work",
+	Short: "Pull scan jobs from the queue and write their results",
+	Long: `work claims jobs from the queue one at a time, scans them and writes a JSON
+report per job to --results as <job-id>.json (or <job-id>.error.json if the
+scan failed), then acknowledges the job. Run as many workers as needed; each
+job is claimed by exactly one.
+
+On SIGINT or SIGTERM the worker finishes its current job and exits. With
+--once it exits as soon as the queue is empty. A job that already has a
+<job-id>.json result in a local --results directory, as when its worker
+stopped before acknowledging it, is acknowledged without scanning it again.
+
+--pace waits between jobs; after a job whose data sources failed, as when
+they rate limit, the wait doubles up to 10 minutes until a job scans cleanly.
+
+Workers can share first-seen tracking through a postgres:// --history-file;
+a history file is not safe to share between concurrently running workers.`,
+	Args: cobra.NoArgs,
+	RunE: runQueueWork,
+}
+
+func init() {
+	queueCmd.PersistentFlags().StringVar(&flagQueueURL, "queue", "", "Queue directory or redis:// URL (required)")
+
+	queuePushCmd.Flags().StringVar(&flagQueueJobID, "id", "", "Job ID (default: generated)")
+	queuePushCmd.Flags().StringVar(&flagQueueRef, "ref", "", "Scan manifests at this git ref")
+	queuePushCmd.Flags().StringVar(&flagQueueInventory, "inventory", "", "Scan an inventory file instead of paths")
+
+	queueWorkCmd.Flags().StringVar(&flagWorkerResults, "results", "", "Directory, s3://bucket/prefix or gs://bucket/prefix for job results (required)")
+	queueWorkCmd.Flags().BoolVar(&flagWorkerOnce, "once", false, "Exit when the queue is empty instead of waiting for jobs")
+	queueWorkCmd.Flags().DurationVar(&flagWorkerWait, "wait", 30*time.Second, "How long to wait for a job before polling again (or exiting with --once)")
+	queueWorkCmd.Flags().StringVar(&flagWorkerHistoryFile, "history-file", "", "History store path or postgres:// URL for first-seen tracking (default: none)")
+	queueWorkCmd.Flags().StringVar(&flagWorkerExclusions, "exclusions", "", "TOML file of risk-accepted dependencies")
+	queueWorkCmd.Flags().StringVar(&flagWorkerOwners, "owners", "", "CODEOWNERS-style file mapping manifest paths to owning teams")
+	queueWorkCmd.Flags().StringVar(&flagWorkerDenyList, "deny-list", "", "TOML file of banned packages and vendors")
+	queueWorkCmd.Flags().StringVar(&flagWorkerKEVOverlay, "kev-overlay", "", "Organizational KEV catalog (CISA JSON format) merged with the CISA catalog")
+	queueWorkCmd.Flags().DurationVar(&flagWorkerPace, "pace", 0, "Wait between jobs, backing off while data sources fail")
+
+	queueCmd.AddCommand(queuePushCmd)
+	queueCmd.AddCommand(queueWorkCmd)
+	rootCmd.AddCommand(queueCmd)
+}
+
+func openQueue() (queue.Queue, error) {
+	if flagQueueURL == "" {
+		return nil, fmt.Errorf("--queue is required")
+	}
+	return queue.Open(flagQueueURL)
+}
+
+func runQueuePush(cmd *cobra.Command, args []string) error {
+	job := queue.Job{
+		ID:        flagQueueJobID,
+		Paths:     args,
+		Ref:       flagQueueRef,
+		Inventory: flagQueueInventory,
+		QueuedAt:  time.Now().UTC(),
+	}
+	if job.ID == "" {
+		job.ID = queue.NewJobID()
+	}
+	if err := job.Validate(); err != nil {
+		return err
+	}
+
+	q, err := openQueue()
+	if err != nil {
+		return err
+	}
+	defer q.Close()
+
+	if err := q.Push(context.Background(), job); err != nil {
+		return err
+	}
+	fmt.Println(job.ID)
+	return nil
+}
+
+func runQueueWork(cmd *cobra.Command, args []string) error {
+	if flagWorkerResults == "" {
+		return fmt.Errorf("--results is required")
+	}
+	if !upload.IsRemote(flagWorkerResults) {
+		if err := os.MkdirAll(flagWorkerResults, 0755); err != nil {
+			return fmt.Errorf("failed to create results directory: %w", err)
+		}
+	}
+
+	q, err := openQueue()
+	if err != nil {
+		return err
+	}
+	defer q.Close()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	pace := newPacer(flagWorkerPace)
+	for {
+		job, err := q.Receive(ctx, flagWorkerWait)
+		if errors.Is(err, queue.ErrEmpty) {
+			if flagWorkerOnce {
+				return nil
+			}
+			continue
+		}
+		if ctx.Err() != nil {
+			return nil
+		}
+		if errors.Is(err, queue.ErrInvalidJob) {
+			fmt.Fprintf(os.Stderr, "Warning: rejected job: %v\n", err)
+			continue
+		}
+		if err != nil {
+			// The queue reconnects on the next receive
+			fmt.Fprintf(os.Stderr, "Warning: failed to receive job: %v\n", err)
+			select {
+			case <-ctx.Done():
+				return nil
+			case <-time.After(5 * time.Second):
+			}
+			continue
+		}
+
+		// Finish the claimed job even if a signal arrives meanwhile. A job
+		// whose result couldn't be written stays claimed, to be retried.
+		if err := processJob(job, pace); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: job %s not acknowledged: %v\n", job.ID, err)
+		} else if err := q.Ack(context.Background(), job); err != nil {
+			return err
+		}
+		if ctx.Err() != nil || !pace.wait(ctx) {
+			return nil
+		}
+	}
+}
+
+// processJob scans a job and writes its report, or its error, to the
+// results location. It fails if the result couldn't be written.
+func processJob(job *queue.Job, pace *pacer) error {
+	name := job.ID + ".json"
+	if !upload.IsRemote(flagWorkerResults) {
+		if _, err := os.Stat(filepath.Join(flagWorkerResults, name)); err == nil {
+			fmt.Fprintf(os.Stderr, "Job %s already has a result; not scanning it again\n", job.ID)
+			return nil
+		}
+	}
+
+	start := time.Now()
+	findings, output, failures, err := scanJob(job)
+	pace.observe(len(failures) > 0)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Job %s failed: %v\n", job.ID, err)
+		name = job.ID + ".error.json"
+		output, _ = json.MarshalIndent(map[string]string{"id": job.ID, "error": err.Error()}, "", "  ")
+	} else {
+		fmt.Fprintf(os.Stderr, "Job %s: %d findings in %s\n", job.ID, len(findings), time.Since(start).Round(time.Millisecond))
+	}
+
+	return writeJobResult(name, output)
+}
+
+func scanJob(job *queue.Job) ([]models.Finding, []byte, []models.SourceFailure, error) {
+	config := models.DefaultConfig()
+	config.Paths = job.Paths
+	config.GitRef = job.Ref
+	config.Inventory = job.Inventory
+	config.OutputFormat = "json"
+	config.HistoryFile = flagWorkerHistoryFile
+	config.NoHistory = flagWorkerHistoryFile == ""
+	config.ExclusionsFile = flagWorkerExclusions
+	config.OwnersFile = flagWorkerOwners
+	config.DenyListFile = flagWorkerDenyList
+	config.KEVOverlayFile = flagWorkerKEVOverlay
+
+	s, err := scanner.New(config)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to initialize scanner: %w", err)
+	}
+	findings, err := s.Scan(context.Background())
+	if err != nil {
+		return nil, nil, s.SourceFailures(), fmt.Errorf("scan failed: %w", err)
+	}
+
+	rep := &reporter.JSONReporter{Violations: s.Violations(), Errors: s.ParseErrors(), Local: localComponents(s.Dependencies())}
+	output, err := rep.Report(findings)
+	if err != nil {
+		return nil, nil, s.SourceFailures(), fmt.Errorf("failed to generate report: %w", err)
+	}
+	return findings, output, s.SourceFailures(), nil
+}
+
+func writeJobResult(name string, output []byte) error {
+	if upload.IsRemote(flagWorkerResults) {
+		target := strings.TrimSuffix(flagWorkerResults, "/") + "/" + name
+		if err := upload.Upload(target, output, "application/json"); err != nil {
+			return fmt.Errorf("failed to upload result: %w", err)
+		}
+		return nil
+	}
+	if err := os.WriteFile(filepath.Join(flagWorkerResults, name), output, 0644); err != nil {
+		return fmt.Errorf("failed to write result: %w", err)
+	}
+	return nil
+}
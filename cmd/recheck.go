@@ -0,0 +1,119 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/ethanolivertroy/kev-check-demo/internal/alerting"
+	"github.com/ethanolivertroy/kev-check-demo/internal/clients"
+	"github.com/ethanolivertroy/kev-check-demo/internal/models"
+	"github.com/ethanolivertroy/kev-check-demo/internal/reporter"
+	"github.com/ethanolivertroy/kev-check-demo/internal/scanner"
+	"github.com/spf13/cobra"
+)
+
+var (
+	flagRecheckFormat      string
+	flagRecheckOutput      string
+	flagRecheckHistoryFile string
+	flagRecheckExclusions  string
+	flagRecheckOwners      string
+	flagRecheckPagerDuty   string
+	flagRecheckOpsgenie    string
+	flagRecheckNoFail      bool
+)
+
+var recheckCmd = &cobra.Command{
+	Use:   "recheck",
+	Short: "Alert on new KEVs affecting previously scanned dependencies",
+	Long: `recheck re-evaluates the dependency inventories recorded in the history store
+by earlier scans against the latest KEV catalog. No repositories are walked
+and no vulnerability sources are queried: the CVEs stored for each manifest
+are matched against the current catalog, and only KEVs never reported before
+are output. Run it on a schedule to learn when a newly added KEV affects
+something already inventoried.
+
+New KEVs are recorded in the history store, so each is reported once. With
+--pagerduty-routing-key or --opsgenie-api-key an alert is opened for each.
+
+Exit codes are the same as a scan: 0 no new KEVs, 1 new KEVs, 2 error.`,
+	Args: cobra.NoArgs,
+	RunE: runRecheck,
+}
+
+func init() {
+	recheckCmd.Flags().StringVarP(&flagRecheckFormat, "format", "f", "terminal", "Output format: "+strings.Join(reporter.Formats(), ", "))
+	recheckCmd.Flags().StringVarP(&flagRecheckOutput, "output", "o", "", "Output file path, s3://bucket/key or gs://bucket/object (default: stdout)")
+	recheckCmd.Flags().StringVar(&flagRecheckHistoryFile, "history-file", "", "History store path or postgres:// URL to recheck (default: ~/.cache/kev-checker/history/history.json)")
+	recheckCmd.Flags().StringVar(&flagRecheckExclusions, "exclusions", "", "TOML file of risk-accepted dependencies")
+	recheckCmd.Flags().StringVar(&flagRecheckOwners, "owners", "", "CODEOWNERS-style file mapping paths to owning teams")
+	recheckCmd.Flags().StringVar(&flagRecheckPagerDuty, "pagerduty-routing-key", "", "Open PagerDuty incidents for new KEVs (default: $PAGERDUTY_ROUTING_KEY)")
+	recheckCmd.Flags().StringVar(&flagRecheckOpsgenie, "opsgenie-api-key", "", "Open Opsgenie alerts for new KEVs (default: $OPSGENIE_API_KEY)")
+	recheckCmd.Flags().BoolVar(&flagRecheckNoFail, "no-fail", false, "Don't exit with code 1 if new KEVs are found")
+	rootCmd.AddCommand(recheckCmd)
+}
+
+func runRecheck(cmd *cobra.Command, args []string) error {
+	config := models.DefaultConfig()
+	config.OutputFormat = flagRecheckFormat
+	config.OutputFile = flagRecheckOutput
+	config.HistoryFile = flagRecheckHistoryFile
+	config.ExclusionsFile = flagRecheckExclusions
+	config.OwnersFile = flagRecheckOwners
+	config.PagerDutyRoutingKey = flagRecheckPagerDuty
+	config.OpsgenieAPIKey = flagRecheckOpsgenie
+	if config.PagerDutyRoutingKey == "" {
+		config.PagerDutyRoutingKey = os.Getenv("PAGERDUTY_ROUTING_KEY")
+	}
+	if config.OpsgenieAPIKey == "" {
+		config.OpsgenieAPIKey = os.Getenv("OPSGENIE_API_KEY")
+	}
+
+	if _, ok := reporter.Lookup(config.OutputFormat); !ok {
+		return fmt.Errorf("unsupported format: %s", config.OutputFormat)
+	}
+
+	s, err := scanner.New(config)
+	if err != nil {
+		return fmt.Errorf("failed to initialize scanner: %w", err)
+	}
+	findings, err := s.Recheck()
+	if err != nil {
+		return fmt.Errorf("recheck failed: %w", err)
+	}
+	for _, w := range s.Warnings() {
+		fmt.Fprintf(os.Stderr, "Warning: %s\n", w.Message)
+	}
+
+	rep, reported, _, err := buildReport(config, time.Local, findings, config.OutputFile == "")
+	if err != nil {
+		return err
+	}
+	if err := writeReport(rep, config, reported); err != nil {
+		return err
+	}
+
+	var notifiers []alerting.Notifier
+	if config.PagerDutyRoutingKey != "" {
+		notifiers = append(notifiers, clients.NewPagerDutyClient(config.PagerDutyRoutingKey))
+	}
+	if config.OpsgenieAPIKey != "" {
+		notifiers = append(notifiers, clients.NewOpsgenieClient(config.OpsgenieAPIKey))
+	}
+	if len(notifiers) > 0 {
+		sent, err := alerting.Dispatch(alerting.NewKEVs(findings), notifiers, s.History())
+		if err != nil {
+			return fmt.Errorf("failed to send alerts: %w", err)
+		}
+		if sent > 0 {
+			fmt.Fprintf(os.Stderr, "Sent %d alerts\n", sent)
+		}
+	}
+
+	if !flagRecheckNoFail && len(alerting.NewKEVs(findings)) > 0 {
+		os.Exit(1)
+	}
+	return nil
+}
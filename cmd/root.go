@@ -4,23 +4,129 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
 	"time"
+	_ "time/tzdata" // Embedded so --timezone works without system zoneinfo
 
+	"github.com/ethanolivertroy/kev-check-demo/internal/alerting"
+	"github.com/ethanolivertroy/kev-check-demo/internal/baseline"
+	"github.com/ethanolivertroy/kev-check-demo/internal/batch"
+	"github.com/ethanolivertroy/kev-check-demo/internal/ci"
+	"github.com/ethanolivertroy/kev-check-demo/internal/clients"
+	"github.com/ethanolivertroy/kev-check-demo/internal/history"
 	"github.com/ethanolivertroy/kev-check-demo/internal/models"
+	"github.com/ethanolivertroy/kev-check-demo/internal/policy"
 	"github.com/ethanolivertroy/kev-check-demo/internal/reporter"
 	"github.com/ethanolivertroy/kev-check-demo/internal/scanner"
+	"github.com/ethanolivertroy/kev-check-demo/internal/signing"
+	"github.com/ethanolivertroy/kev-check-demo/internal/summary"
+	"github.com/ethanolivertroy/kev-check-demo/internal/upload"
 	"github.com/spf13/cobra"
 )
 
 var (
-	flagOutput    string
-	flagFormat    string
-	flagThreshold float64
-	flagNoFail    bool
-	flagNoCache   bool
-	flagTimeout   int
+	flagOutput      string
+	flagFormat      string
+	flagThreshold   float64
+	flagPercentile  float64
+	flagMinCVSS     float64
+	flagNoFail      bool
+	flagFailOn      []string
+	flagNoFixExit   int
+	flagFailPotent  bool
+	flagNoCache     bool
+	flagTimeout     int
+	flagDebugHTTP   bool
+	flagSources     []string
+	flagAdvisories  []string
+	flagKEVOverlay  string
+	flagSummaryFile string
+	flagGroupBy     string
+	flagFullText    bool
+	flagDescWidth   int
+	flagActionWidth int
+	flagWidth       int
+	flagTimezone    string
+	flagParallel    int
+	flagMaxFileSize string
+	flagMaxFiles    int
+	flagMaxDepth    int
+	flagFollowLinks bool
+	flagOneFS       bool
+	flagSkipDirs    []string
+	flagVendored    bool
+	flagAllManifest bool
+	flagReqFiles    []string
+
+	flagQueryBudget     int
+	flagBudgetManifests []string
+
+	flagMaxFindings int
+	flagReportPack  string
+	flagTee         bool
+	flagNoCIDetect  bool
+	flagSign        string
+
+	flagPerPathReports string
+
+	flagMaxCatalogAge string
+
+	flagSLARansomware string
+	flagSLADefault    string
+
+	flagChangedFiles bool
+	flagDryRun       bool
+	flagReproducible bool
+	flagRecord       string
+	flagReplay       string
+	flagRef          string
+	flagArchive      string // Set by the archive subcommand
+	flagBatch        string // Set by the batch subcommand
+	flagInventory    string
+
+	flagIncremental bool
+	flagHistoryFile string
+	flagNoHistory   bool
+
+	flagIncrementalTTL string
+
+	flagReachability bool
+
+	flagSeverityConfig string
+
+	flagTyposquat bool
+	flagFreshness bool
+	flagNoEnrich  []string
+
+	flagExclusions     string
+	flagRequireSignoff bool
+	flagBaseline       string
+	flagUpdateBaseline bool
+	flagOwners         string
+	flagDenyList       string
+	flagViolationExit  int
+
+	flagPagerDutyKey string
+	flagOpsgenieKey  string
+
+	flagPush         string
+	flagDDURL        string
+	flagDDToken      string
+	flagDDProduct    string
+	flagDDEngagement string
+
+	flagMISPURL   string
+	flagMISPKey   string
+	flagMISPEvent string
 )
 
+// ciEnv is the detected CI environment, nil outside CI or with --no-ci-detect
+var ciEnv *ci.Environment
+
 // rootCmd represents the base command
 var rootCmd = &cobra.Command{
 	Use:   "kev-checker [paths...]",
@@ -29,9 +135,21 @@ var rootCmd = &cobra.Command{
 known exploited vulnerabilities (KEV) tracked by CISA.
 
 It supports multiple ecosystems:
-  - Python: requirements.txt, pyproject.toml
-  - Node.js: package.json, package-lock.json
+  - Python: requirements.txt, pyproject.toml, poetry.lock, Pipfile, Pipfile.lock
+  - Node.js: package.json, package-lock.json, npm-shrinkwrap.json, bun.lock
+  - Deno: deno.lock, deno.json, import maps (npm packages)
   - Go: go.mod
+  - Rust: Cargo.lock, Cargo.toml
+  - Haskell: stack.yaml.lock, cabal.project.freeze
+  - Erlang: rebar.lock
+  - Java: pom.xml (Maven), gradle.lockfile, build.gradle, build.gradle.kts
+  - Clojure: deps.edn, project.clj
+  - Ansible: requirements.yml, collection MANIFEST.json
+  - PHP: composer.lock, composer.json
+  - WordPress: plugin and theme headers (matched to KEV products)
+  - Drupal: module and theme .info.yml
+  - Browser extensions: manifest.json (bundled libraries)
+  - Vendored JavaScript: jquery.min.js, lodash.js and other known libraries
 
 The tool queries the OSV database to find CVEs affecting your dependencies,
 then cross-references them against the CISA KEV catalog and enriches the
@@ -50,11 +168,32 @@ Examples:
   # Output SARIF for GitHub Code Scanning
   kev-checker --format sarif --output results.sarif
 
+  # Export FedRAMP POA&M rows as CSV
+  kev-checker --format poam --output poam.csv
+
   # Don't fail on KEV findings (exit 0 regardless)
   kev-checker --no-fail
 
   # Only report if EPSS score >= 10%
-  kev-checker --epss-threshold 0.1`,
+  kev-checker --epss-threshold 0.1
+
+  # Only report KEVs above the 95th EPSS percentile
+  kev-checker --epss-percentile-threshold 0.95
+
+  # Scan a release tag without checking it out
+  kev-checker --ref v1.2.3
+
+  # Compare KEV exposure between two refs
+  kev-checker compare-refs main release/1.4
+
+  # Install a pre-commit hook that scans staged manifests
+  kev-checker hook install`,
+	PersistentPreRun: func(cmd *cobra.Command, args []string) {
+		if flagDebugHTTP {
+			clients.EnableHTTPDebug(os.Stderr)
+		}
+	},
+	Args: cobra.ArbitraryArgs,
 	RunE: runCheck,
 }
 
@@ -66,53 +205,672 @@ func Execute() {
 }
 
 func init() {
-	rootCmd.Flags().StringVarP(&flagOutput, "output", "o", "", "Output file path (default: stdout)")
-	rootCmd.Flags().StringVarP(&flagFormat, "format", "f", "terminal", "Output format: terminal, json, sarif")
+	rootCmd.Flags().StringVarP(&flagOutput, "output", "o", "", "Output file path, s3://bucket/key or gs://bucket/object (default: stdout)")
+	rootCmd.Flags().BoolVar(&flagTee, "tee", false, "Also print terminal output to stdout while writing --output")
+	rootCmd.Flags().StringVar(&flagSign, "sign", "", "Write a detached signature for --output: a PEM private key file, or \"keyless\" for Sigstore keyless signing via cosign")
+	rootCmd.Flags().BoolVar(&flagNoCIDetect, "no-ci-detect", false, "Don't adjust output for the detected CI environment (GitHub Actions, GitLab, Jenkins, CircleCI, TeamCity, Azure DevOps)")
+	rootCmd.Flags().StringVarP(&flagFormat, "format", "f", "terminal", "Output format: terminal, json, ndjson, sarif, poam, ocsf, defectdojo, github-actions, azure-devops, teamcity")
+	rootCmd.Flags().StringVar(&flagReportPack, "report-pack", "", "Directory of <format>.tmpl report templates to add as output formats")
+	rootCmd.Flags().StringVar(&flagSeverityConfig, "severity-config", "", "TOML file configuring the severity mapping and SARIF levels and security-severity")
 	rootCmd.Flags().Float64Var(&flagThreshold, "epss-threshold", 0, "Only report KEVs with EPSS >= threshold (0-1)")
+	rootCmd.Flags().Float64Var(&flagPercentile, "epss-percentile-threshold", 0, "Only report KEVs with EPSS percentile >= threshold (0-1, e.g. 0.95 for the 95th percentile)")
+	rootCmd.Flags().Float64Var(&flagMinCVSS, "min-cvss", 0, "Only report KEVs with CVSS v3 base score >= this (0-10); KEVs without a CVSS vector are kept")
 	rootCmd.Flags().BoolVar(&flagNoFail, "no-fail", false, "Don't exit with error code if KEVs found")
+	rootCmd.Flags().StringSliceVar(&flagFailOn, "fail-on", nil, "Only fail when a KEV matches a condition: open>Nd, sla-breach, no-fix, fixable, severity>=LEVEL (repeatable)")
+	rootCmd.Flags().BoolVar(&flagFailPotent, "fail-on-potential", false, "Also fail on potential KEVs of unpinned dependencies")
+	rootCmd.Flags().IntVar(&flagNoFixExit, "no-fix-exit-code", 1, "Exit code when every failing finding has no fixed version available")
+	rootCmd.Flags().StringVar(&flagSLARansomware, "sla-ransomware", "48h", "Internal remediation SLA for ransomware KEVs, from first-seen (e.g. 48h, 2d)")
+	rootCmd.Flags().StringVar(&flagSLADefault, "sla-kev", "7d", "Internal remediation SLA for other KEVs, from first-seen")
 	rootCmd.Flags().BoolVar(&flagNoCache, "no-cache", false, "Disable KEV data caching")
+	rootCmd.Flags().StringVar(&flagMaxCatalogAge, "max-catalog-age", "", "Fail if the KEV catalog release is older than this (e.g. 72h, 3d)")
 	rootCmd.Flags().IntVar(&flagTimeout, "timeout", 60, "HTTP request timeout in seconds")
+	rootCmd.Flags().StringSliceVar(&flagSources, "sources", []string{"osv"}, "Vulnerability sources to query: osv, ossindex")
+	rootCmd.Flags().BoolVar(&flagFullText, "full-text", false, "Don't truncate descriptions or required actions in terminal output")
+	rootCmd.Flags().IntVar(&flagDescWidth, "description-width", reporter.DefaultDescriptionWidth, "Truncate descriptions in terminal output to this many characters")
+	rootCmd.Flags().IntVar(&flagActionWidth, "action-width", reporter.DefaultActionWidth, "Truncate required actions in terminal output to this many characters")
+	rootCmd.Flags().IntVar(&flagWidth, "width", 0, "Wrap terminal output at this many columns (default: terminal width when writing to a terminal, 0 = no wrapping otherwise)")
+	rootCmd.Flags().StringVar(&flagTimezone, "timezone", "UTC", "Timezone for due-date comparisons and displayed times (IANA name, e.g. America/New_York, or Local)")
+	rootCmd.Flags().IntVar(&flagParallel, "parallel", 10, "Number of path arguments to discover and parse concurrently")
+	rootCmd.Flags().StringVar(&flagMaxFileSize, "max-file-size", "64MB", "Skip manifests larger than this (e.g. 512KB, 64MB; 0 = unlimited)")
+	rootCmd.Flags().IntVar(&flagMaxFiles, "max-files", 0, "Stop walking after parsing this many manifests (0 = unlimited)")
+	rootCmd.Flags().IntVar(&flagMaxDepth, "max-depth", 0, "Don't descend more than this many directories below each path (0 = unlimited)")
+	rootCmd.Flags().IntVar(&flagQueryBudget, "query-budget", 0, "Check at most this many registry dependencies, leaving out dev then transitive ones when over (0 = unlimited)")
+	rootCmd.Flags().StringSliceVar(&flagBudgetManifests, "budget-manifests", nil, "Glob patterns for the manifests to check first when over --query-budget, matched against the end of the path (e.g. 'services/api/*.lock')")
+	rootCmd.Flags().BoolVar(&flagFollowLinks, "follow-symlinks", false, "Walk symlinked directories and parse symlinked manifests (loops and duplicates are skipped)")
+	rootCmd.Flags().StringSliceVar(&flagSkipDirs, "skip-dirs", models.DefaultSkipDirs, "Directory names or glob patterns (e.g. '.*') not to walk; replaces the default list")
+	rootCmd.Flags().BoolVar(&flagVendored, "scan-vendored", false, "Walk vendored dependency directories (node_modules, vendor, .venv, venv) even if --skip-dirs lists them")
+	rootCmd.Flags().StringSliceVar(&flagReqFiles, "requirements-files", nil, "Extra glob patterns for pip requirements files, matched against the end of the path (e.g. 'requirements/*.txt')")
+	rootCmd.Flags().BoolVar(&flagAllManifest, "all-manifests", false, "Parse every manifest and lockfile, even ones superseded by a lockfile in the same directory")
+	rootCmd.Flags().BoolVar(&flagOneFS, "one-file-system", false, "Don't descend into directories on other filesystems, such as mounts inside a path (Linux and macOS)")
+	rootCmd.Flags().StringVar(&flagPerPathReports, "per-path-reports", "", "Also write a report per path argument into this directory; --output remains the rollup")
+	rootCmd.Flags().IntVar(&flagMaxFindings, "max-findings", 0, "Cap findings in the report and mark it truncated (0 = unlimited; ndjson is never capped)")
+	rootCmd.Flags().StringVar(&flagGroupBy, "group-by", "", "Group terminal output by manifest: file, project")
+	rootCmd.Flags().StringVar(&flagSummaryFile, "summary-file", "", "Write a JSON run summary (counts, duration, data sources, exit reason) to this file")
+	rootCmd.Flags().StringVar(&flagKEVOverlay, "kev-overlay", "", "Organizational KEV catalog (CISA JSON format) merged with the CISA catalog")
+	rootCmd.Flags().StringSliceVar(&flagAdvisories, "advisories", nil, "Internal OSV-schema advisories (file, directory, or URL) treated as known-exploited")
+	rootCmd.PersistentFlags().BoolVar(&flagDebugHTTP, "debug-http", false, "Log HTTP requests, status codes, sizes and timings to stderr")
+	rootCmd.Flags().BoolVar(&flagChangedFiles, "changed-files", false, "Only scan dependency manifests in the staged git diff (for pre-commit hooks)")
+	rootCmd.Flags().BoolVar(&flagDryRun, "dry-run", false, "Discover dependencies and print the requests the scan would send (endpoints, batch sizes, bodies with -f json) without sending any")
+	rootCmd.Flags().BoolVar(&flagReproducible, "reproducible", false, "Leave timestamps and durations out of reports and the run summary so the same inputs and data give byte-identical output")
+	rootCmd.Flags().StringVar(&flagRecord, "record", "", "Save every upstream HTTP response to this directory for --replay (bypasses the cache)")
+	rootCmd.Flags().StringVar(&flagReplay, "replay", "", "Answer upstream HTTP requests from responses saved by --record, without network access")
+	rootCmd.Flags().StringVar(&flagRef, "ref", "", "Scan manifests at a git ref (tag, branch, commit) without checking it out")
+	rootCmd.Flags().StringVar(&flagInventory, "inventory", "", "Scan the dependencies in an inventory file (from 'inventory export') instead of walking paths")
+	rootCmd.Flags().BoolVar(&flagIncremental, "incremental", false, "Only re-query OSV for manifests changed since the last run")
+	rootCmd.Flags().StringVar(&flagIncrementalTTL, "incremental-ttl", "1d", "Re-query unchanged manifests with --incremental once their stored results are older than this (0 = never)")
+	rootCmd.Flags().BoolVar(&flagNoHistory, "no-history", false, "Don't read or write the history store (disables first-seen tracking)")
+	rootCmd.Flags().StringVar(&flagHistoryFile, "history-file", "", "History store path or postgres:// URL (default: ~/.cache/kev-checker/history/history.json)")
+	rootCmd.Flags().BoolVar(&flagReachability, "reachability", false, "Mark Go KEVs whose vulnerable symbols the module refers to (imports and selectors, not a call graph)")
+	rootCmd.Flags().BoolVar(&flagTyposquat, "typosquat", false, "Warn about dependency names resembling popular packages")
+	rootCmd.Flags().BoolVar(&flagFreshness, "freshness", false, "Enrich findings with last-release date and deprecation status from deps.dev")
+	rootCmd.Flags().StringSliceVar(&flagNoEnrich, "no-enrich", nil, "Skip enrichers: "+strings.Join(scanner.Enrichers, ", "))
+	rootCmd.Flags().StringVar(&flagExclusions, "exclusions", "", "TOML file of risk-accepted dependencies (unused, compile-time-only)")
+	rootCmd.Flags().StringVar(&flagOwners, "owners", "", "CODEOWNERS-style file mapping manifest paths to owning teams")
+	rootCmd.Flags().StringVar(&flagDenyList, "deny-list", "", "TOML file of banned packages and vendors, reported as policy violations")
+	rootCmd.Flags().IntVar(&flagViolationExit, "violation-exit-code", 1, "Exit code when deny-listed dependencies are found and no KEV fails the scan (0 to only report)")
+	rootCmd.Flags().BoolVar(&flagRequireSignoff, "require-signoff", false, "Require approved_by/approved_on on every exclusion entry")
+	rootCmd.Flags().StringVar(&flagBaseline, "baseline", "", "Baseline file of known findings, reported as accepted without failing the scan")
+	rootCmd.Flags().BoolVar(&flagUpdateBaseline, "update-baseline", false, "Write this scan's findings to the --baseline file")
+	rootCmd.Flags().StringVar(&flagPagerDutyKey, "pagerduty-routing-key", "", "Open PagerDuty incidents for new ransomware or overdue KEVs (default: $PAGERDUTY_ROUTING_KEY)")
+	rootCmd.Flags().StringVar(&flagOpsgenieKey, "opsgenie-api-key", "", "Open Opsgenie alerts for new ransomware or overdue KEVs (default: $OPSGENIE_API_KEY)")
+	rootCmd.Flags().StringVar(&flagPush, "push", "", "Push results to a vulnerability management platform: defectdojo, misp")
+	rootCmd.Flags().StringVar(&flagDDURL, "dd-url", "", "DefectDojo base URL")
+	rootCmd.Flags().StringVar(&flagDDToken, "dd-token", "", "DefectDojo API token (default: $DD_API_TOKEN)")
+	rootCmd.Flags().StringVar(&flagDDProduct, "dd-product", "", "DefectDojo product name (required with --push defectdojo)")
+	rootCmd.Flags().StringVar(&flagDDEngagement, "dd-engagement", "kev-checker", "DefectDojo engagement name")
+	rootCmd.Flags().StringVar(&flagMISPURL, "misp-url", "", "MISP base URL")
+	rootCmd.Flags().StringVar(&flagMISPKey, "misp-key", "", "MISP API key (default: $MISP_API_KEY)")
+	rootCmd.Flags().StringVar(&flagMISPEvent, "misp-event", "kev-checker: KEV exposure", "MISP event title; an existing event with this title is updated")
+
+	// The archive subcommand runs the same scan, so it accepts the same flags
+	archiveCmd.Flags().AddFlagSet(rootCmd.Flags())
+	batchCmd.Flags().AddFlagSet(rootCmd.Flags())
 }
 
 func runCheck(cmd *cobra.Command, args []string) error {
+	run := summary.New()
+	if flagReproducible {
+		run.OmitTimestamps()
+	}
+	code, reason, err := executeScan(args, run)
+
+	if flagSummaryFile != "" {
+		if err != nil {
+			run.Finish(2, summary.ReasonError, err)
+		} else {
+			run.Finish(code, reason, nil)
+		}
+		if werr := run.Write(flagSummaryFile); werr != nil {
+			fmt.Fprintf(os.Stderr, "Warning: %v\n", werr)
+		}
+	}
+
+	if err != nil {
+		return err
+	}
+	if code != 0 {
+		os.Exit(code)
+	}
+	return nil
+}
+
+// executeScan runs a scan and returns the exit code and reason. Errors map to
+// exit code 2 in Execute.
+func executeScan(args []string, run *summary.Summary) (int, string, error) {
 	paths := args
+	if flagChangedFiles {
+		staged, err := stagedManifests()
+		if err != nil {
+			return 0, "", fmt.Errorf("failed to list staged files: %w", err)
+		}
+		if len(staged) == 0 {
+			return 0, summary.ReasonClean, nil
+		}
+		paths = staged
+	}
 	if len(paths) == 0 {
 		paths = []string{"."}
 	}
 
 	config := &models.Config{
-		Paths:         paths,
-		OutputFormat:  flagFormat,
-		OutputFile:    flagOutput,
-		FailOnKEV:     !flagNoFail,
-		EPSSThreshold: flagThreshold,
-		NoCache:       flagNoCache,
-		CacheTTL:      24 * time.Hour,
-		Timeout:       time.Duration(flagTimeout) * time.Second,
+		Paths:                paths,
+		GitRef:               flagRef,
+		Archive:              flagArchive,
+		Staged:               flagChangedFiles,
+		Inventory:            flagInventory,
+		OutputFormat:         flagFormat,
+		OutputFile:           flagOutput,
+		SeverityFile:         flagSeverityConfig,
+		MaxFindings:          flagMaxFindings,
+		FailOnKEV:            !flagNoFail,
+		FailOn:               flagFailOn,
+		FailOnPotential:      flagFailPotent,
+		EPSSThreshold:        flagThreshold,
+		EPSSPercentile:       flagPercentile,
+		MinCVSS:              flagMinCVSS,
+		Reachability:         flagReachability,
+		Typosquat:            flagTyposquat,
+		Freshness:            flagFreshness,
+		NoEnrich:             flagNoEnrich,
+		NoCache:              flagNoCache,
+		CacheTTL:             24 * time.Hour,
+		Timeout:              time.Duration(flagTimeout) * time.Second,
+		MaxConcurrent:        flagParallel,
+		MaxFiles:             flagMaxFiles,
+		MaxDepth:             flagMaxDepth,
+		QueryBudget:          flagQueryBudget,
+		BudgetManifests:      flagBudgetManifests,
+		FollowSymlinks:       flagFollowLinks,
+		OneFileSystem:        flagOneFS,
+		SkipDirs:             flagSkipDirs,
+		ScanVendored:         flagVendored,
+		AllManifests:         flagAllManifest,
+		RequirementsPatterns: flagReqFiles,
+		Sources:              flagSources,
+		Advisories:           flagAdvisories,
+		KEVOverlayFile:       flagKEVOverlay,
+		HistoryFile:          flagHistoryFile,
+		Incremental:          flagIncremental,
+		NoHistory:            flagNoHistory,
+
+		ExclusionsFile: flagExclusions,
+		RequireSignoff: flagRequireSignoff,
+		OwnersFile:     flagOwners,
+		DenyListFile:   flagDenyList,
+
+		PagerDutyRoutingKey: flagPagerDutyKey,
+		OpsgenieAPIKey:      flagOpsgenieKey,
+
+		Push:                 flagPush,
+		DefectDojoURL:        flagDDURL,
+		DefectDojoToken:      flagDDToken,
+		DefectDojoProduct:    flagDDProduct,
+		DefectDojoEngagement: flagDDEngagement,
+
+		MISPURL:   flagMISPURL,
+		MISPKey:   flagMISPKey,
+		MISPEvent: flagMISPEvent,
+	}
+	if config.DefectDojoToken == "" {
+		config.DefectDojoToken = os.Getenv("DD_API_TOKEN")
+	}
+	if config.MISPKey == "" {
+		config.MISPKey = os.Getenv("MISP_API_KEY")
+	}
+	if config.PagerDutyRoutingKey == "" {
+		config.PagerDutyRoutingKey = os.Getenv("PAGERDUTY_ROUTING_KEY")
+	}
+	if config.OpsgenieAPIKey == "" {
+		config.OpsgenieAPIKey = os.Getenv("OPSGENIE_API_KEY")
+	}
+
+	if !flagNoCIDetect {
+		ciEnv = ci.Detect()
+	}
+
+	loc, err := time.LoadLocation(flagTimezone)
+	if err != nil {
+		return 0, "", fmt.Errorf("invalid --timezone %q: %w", flagTimezone, err)
+	}
+	if flagReproducible && flagTimezone == "Local" {
+		return 0, "", fmt.Errorf("--reproducible requires a named --timezone, not Local")
+	}
+
+	if config.MaxFileSize, err = parseSize(flagMaxFileSize); err != nil {
+		return 0, "", fmt.Errorf("invalid --max-file-size: %w", err)
+	}
+	if flagMaxFiles < 0 || flagMaxDepth < 0 {
+		return 0, "", fmt.Errorf("--max-files and --max-depth must not be negative")
+	}
+
+	for _, pattern := range flagSkipDirs {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return 0, "", fmt.Errorf("invalid --skip-dirs pattern %q: %w", pattern, err)
+		}
+	}
+	for _, pattern := range flagReqFiles {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return 0, "", fmt.Errorf("invalid --requirements-files pattern %q: %w", pattern, err)
+		}
+	}
+	if flagQueryBudget < 0 {
+		return 0, "", fmt.Errorf("--query-budget must not be negative")
+	}
+	for _, pattern := range flagBudgetManifests {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return 0, "", fmt.Errorf("invalid --budget-manifests pattern %q: %w", pattern, err)
+		}
+	}
+	if len(flagBudgetManifests) > 0 && flagQueryBudget == 0 {
+		return 0, "", fmt.Errorf("--budget-manifests requires --query-budget")
+	}
+
+	if flagPercentile < 0 || flagPercentile > 1 {
+		return 0, "", fmt.Errorf("invalid --epss-percentile-threshold %v: must be between 0 and 1", flagPercentile)
+	}
+
+	switch flagGroupBy {
+	case reporter.GroupByNone, reporter.GroupByFile, reporter.GroupByProject:
+	default:
+		return 0, "", fmt.Errorf("invalid --group-by %q: must be file or project", flagGroupBy)
+	}
+
+	if config.Inventory != "" && (config.GitRef != "" || config.Archive != "" || flagChangedFiles) {
+		return 0, "", fmt.Errorf("--inventory cannot be combined with --ref, --changed-files or archive scans")
+	}
+	if flagChangedFiles && (config.GitRef != "" || config.Archive != "") {
+		return 0, "", fmt.Errorf("--changed-files reads the index and cannot be combined with --ref or archive scans")
+	}
+
+	var targets *batch.File
+	if flagBatch != "" {
+		if config.GitRef != "" || config.Inventory != "" || flagChangedFiles {
+			return 0, "", fmt.Errorf("--ref, --inventory and --changed-files cannot be combined with batch; set them per target")
+		}
+		if targets, err = batch.Load(flagBatch); err != nil {
+			return 0, "", err
+		}
+	}
+
+	// An update takes a fresh baseline, so the previous one isn't applied
+	if flagUpdateBaseline && flagBaseline == "" {
+		return 0, "", fmt.Errorf("--update-baseline requires --baseline")
+	}
+	if !flagUpdateBaseline {
+		config.BaselineFile = flagBaseline
+	}
+
+	if flagTee && config.OutputFile == "" {
+		return 0, "", fmt.Errorf("--tee requires --output")
+	}
+	if flagSign != "" && (config.OutputFile == "" || upload.IsRemote(config.OutputFile)) {
+		return 0, "", fmt.Errorf("--sign requires --output to be a local file")
+	}
+
+	if err := validatePush(config); err != nil {
+		return 0, "", err
+	}
+
+	if _, err := policy.ParseFailOn(config.FailOn); err != nil {
+		return 0, "", err
+	}
+
+	if flagMaxCatalogAge != "" {
+		if config.MaxCatalogAge, err = policy.ParseDuration(flagMaxCatalogAge); err != nil {
+			return 0, "", fmt.Errorf("invalid --max-catalog-age: %w", err)
+		}
+	}
+
+	if config.IncrementalTTL, err = policy.ParseDuration(flagIncrementalTTL); err != nil {
+		return 0, "", fmt.Errorf("invalid --incremental-ttl: %w", err)
+	}
+	if config.SLARansomware, err = policy.ParseDuration(flagSLARansomware); err != nil {
+		return 0, "", fmt.Errorf("invalid --sla-ransomware: %w", err)
+	}
+	if config.SLADefault, err = policy.ParseDuration(flagSLADefault); err != nil {
+		return 0, "", fmt.Errorf("invalid --sla-kev: %w", err)
+	}
+
+	if flagReportPack != "" {
+		if _, err := reporter.LoadTemplatePack(flagReportPack); err != nil {
+			return 0, "", err
+		}
+	}
+	// Checked after loading the pack, which may add the format
+	if _, ok := reporter.Lookup(config.OutputFormat); !ok {
+		return 0, "", fmt.Errorf("unsupported format: %s (available: %s; template formats need --report-pack)",
+			config.OutputFormat, strings.Join(reporter.Formats(), ", "))
+	}
+
+	// Record or replay upstream responses. The cache would answer some
+	// requests without them, so it is bypassed.
+	if flagRecord != "" && flagReplay != "" {
+		return 0, "", fmt.Errorf("--record and --replay cannot be combined")
+	}
+	if flagRecord != "" {
+		if err := clients.EnableHTTPRecord(flagRecord); err != nil {
+			return 0, "", err
+		}
+		config.NoCache = true
+	}
+	if flagReplay != "" {
+		if err := clients.EnableHTTPReplay(flagReplay); err != nil {
+			return 0, "", err
+		}
+		config.NoCache = true
+	}
+
+	if flagDryRun {
+		if targets != nil {
+			return 0, "", fmt.Errorf("--dry-run cannot be combined with batch")
+		}
+		return 0, summary.ReasonClean, runDryRun(config)
+	}
+
+	// Run the scan, or one scan per target of a batch
+	var res *scanResult
+	if targets != nil {
+		res, err = scanBatch(config, targets)
+	} else {
+		res, err = scanOnce(config)
+	}
+	if res != nil {
+		run.SetDependencies(res.deps)
+		run.SetDataSources(res.catalog, res.sourceNames, res.failures)
+		run.SetParseErrors(res.parseErrors)
+		run.SetOverBudget(res.overBudget)
+	}
+	if err != nil {
+		return 0, "", err
+	}
+	findings := res.findings
+	run.SetFindings(findings)
+	if flagUpdateBaseline {
+		if err := baseline.Write(flagBaseline, findings); err != nil {
+			return 0, "", err
+		}
+		fmt.Fprintf(os.Stderr, "Wrote baseline %s\n", flagBaseline)
+	}
+	violations := res.violations
+	run.SetViolations(violations)
+	run.SetLifecycle(res.lifecycle)
+	local := localComponents(res.deps)
+
+	// Report manifest paths relative to the CI checkout
+	if ciEnv != nil {
+		for i := range findings {
+			relocate(&findings[i].Dependency, ciEnv.RelPath)
+		}
+		for i := range violations {
+			relocate(&violations[i].Dependency, ciEnv.RelPath)
+		}
+		for i := range local {
+			local[i].SourceFile = ciEnv.RelPath(local[i].SourceFile)
+		}
+	}
+
+	for _, w := range res.warnings {
+		fmt.Fprintf(os.Stderr, "Warning: %s\n", w)
+	}
+	for _, e := range res.parseErrors {
+		fmt.Fprintf(os.Stderr, "Warning: %s\n", parseErrorMessage(e))
+	}
+
+	// Reports leave out times that vary between runs; pushes and alerts
+	// still see them
+	reportFindings := findings
+	if flagReproducible {
+		reportFindings = reporter.Reproducible(findings)
+	}
+
+	// Generate report, capped at --max-findings except for streaming formats
+	rep, reported, omitted, err := buildReport(config, loc, reportFindings, config.OutputFile == "")
+	if err != nil {
+		return 0, "", err
+	}
+	switch r := rep.(type) {
+	case *reporter.TerminalReporter:
+		r.Violations = violations
+		r.Local = local
+	case *reporter.JSONReporter:
+		r.Violations = violations
+		r.Errors = res.parseErrors
+		r.Local = local
+	default:
+		for _, v := range violations {
+			fmt.Fprintf(os.Stderr, "Policy violation: %s\n", v)
+		}
+		if len(local) > 0 {
+			fmt.Fprintf(os.Stderr, "Note: %d local components not checked (built from the project's own source)\n", len(local))
+		}
+	}
+	if omitted > 0 {
+		fmt.Fprintf(os.Stderr, "Warning: report truncated to %d of %d findings; use --format ndjson for all of them\n", len(reported), len(reportFindings))
+	}
+
+	// Write output
+	if sr, ok := rep.(reporter.StreamReporter); ok && !upload.IsRemote(config.OutputFile) {
+		if err := streamReport(sr, config.OutputFile, reportFindings); err != nil {
+			return 0, "", err
+		}
+	} else if err := writeReport(rep, config, reported); err != nil {
+		return 0, "", err
+	}
+
+	// Sign the written report so consumers can detect later edits
+	if flagSign != "" {
+		if err := signReport(config.OutputFile, flagSign); err != nil {
+			return 0, "", err
+		}
+	}
+
+	// Also show the terminal report when the report went to a file
+	if flagTee {
+		if err := teeTerminal(config, loc, reportFindings, violations, local); err != nil {
+			return 0, "", err
+		}
+	}
+
+	// Annotate the CI run inline when stdout isn't carrying a machine-readable report
+	if ciEnv != nil && ciEnv.Annotations != "" && config.OutputFormat != ciEnv.Annotations &&
+		(config.OutputFile != "" || config.OutputFormat == "terminal") {
+		output, err := reporter.Get(ciEnv.Annotations).Report(reportFindings)
+		if err != nil {
+			return 0, "", fmt.Errorf("failed to generate %s annotations: %w", ciEnv.Annotations, err)
+		}
+		fmt.Print(string(output))
+	}
+
+	// Write one report per path argument alongside the rollup
+	if flagPerPathReports != "" {
+		if err := writePerPathReports(config, loc, reportFindings, flagPerPathReports); err != nil {
+			return 0, "", err
+		}
+	}
+
+	// Push results to external platform
+	switch config.Push {
+	case "defectdojo":
+		report, err := (&reporter.DefectDojoReporter{}).Report(findings)
+		if err != nil {
+			return 0, "", fmt.Errorf("failed to generate DefectDojo report: %w", err)
+		}
+		dd := clients.NewDefectDojoClient(config.DefectDojoURL, config.DefectDojoToken)
+		if err := dd.ReimportScan(config.DefectDojoProduct, config.DefectDojoEngagement, report); err != nil {
+			return 0, "", err
+		}
+		fmt.Fprintf(os.Stderr, "Results pushed to DefectDojo product %q\n", config.DefectDojoProduct)
+	case "misp":
+		misp := clients.NewMISPClient(config.MISPURL, config.MISPKey)
+		eventID, added, err := misp.PushFindings(config.MISPEvent, findings)
+		if err != nil {
+			return 0, "", err
+		}
+		fmt.Fprintf(os.Stderr, "Results pushed to MISP event %s (%d attributes added)\n", eventID, added)
+	}
+
+	// Page on new ransomware-associated or overdue KEVs
+	var notifiers []alerting.Notifier
+	if config.PagerDutyRoutingKey != "" {
+		notifiers = append(notifiers, clients.NewPagerDutyClient(config.PagerDutyRoutingKey))
+	}
+	if config.OpsgenieAPIKey != "" {
+		notifiers = append(notifiers, clients.NewOpsgenieClient(config.OpsgenieAPIKey))
+	}
+	if len(notifiers) > 0 {
+		sent, err := alerting.Dispatch(alerting.Candidates(findings, loc), notifiers, res.history)
+		if err != nil {
+			return 0, "", fmt.Errorf("failed to send alerts: %w", err)
+		}
+		if sent > 0 {
+			fmt.Fprintf(os.Stderr, "Sent %d alerts\n", sent)
+		}
 	}
 
-	// Create scanner
+	// Exit with error code if unaccepted KEVs match the fail policy and not disabled
+	if config.FailOnKEV {
+		if failing := res.failing; len(failing) > 0 {
+			if flagNoFixExit != 1 && policy.AllUnfixable(failing) {
+				return flagNoFixExit, summary.ReasonNoFix, nil
+			}
+			return 1, summary.ReasonKEVsFound, nil
+		}
+	}
+
+	// Exit with the violation code if the deny list matched
+	if len(violations) > 0 && flagViolationExit != 0 {
+		return flagViolationExit, summary.ReasonViolations, nil
+	}
+
+	// Exit with partial-data code if a data source failed or manifests
+	// couldn't be parsed
+	if failures := res.failures; len(failures) > 0 {
+		for _, f := range failures {
+			fmt.Fprintf(os.Stderr, "Data source %s failed: %s\n", f.Source, f.Error)
+		}
+		return 3, summary.ReasonPartialData, nil
+	}
+	if len(res.parseErrors) > 0 {
+		return 3, summary.ReasonPartialData, nil
+	}
+
+	return 0, summary.ReasonClean, nil
+}
+
+// scanResult is the outcome of the scan phase of executeScan
+type scanResult struct {
+	findings    []models.Finding
+	failing     []models.Finding // Findings matching the fail policy
+	violations  []models.Violation
+	deps        []models.Dependency
+	warnings    []models.Warning
+	catalog     clients.KEVCatalogInfo
+	sourceNames []string
+	failures    []models.SourceFailure
+	parseErrors []models.ParseError
+	overBudget  []models.Dependency // Dependencies left unchecked by the query budget
+	lifecycle   map[history.State]int
+	history     *history.Store
+}
+
+// scanOnce runs a single scan of config
+func scanOnce(config *models.Config) (*scanResult, error) {
 	s, err := scanner.New(config)
 	if err != nil {
-		return fmt.Errorf("failed to initialize scanner: %w", err)
+		return nil, fmt.Errorf("failed to initialize scanner: %w", err)
 	}
 
-	// Run scan
-	ctx := context.Background()
-	findings, err := s.Scan(ctx)
+	findings, err := s.Scan(context.Background())
+	res := &scanResult{
+		deps:        s.Dependencies(),
+		catalog:     s.CatalogInfo(),
+		sourceNames: s.SourceNames(),
+		failures:    s.SourceFailures(),
+		parseErrors: s.ParseErrors(),
+		overBudget:  s.OverBudget(),
+	}
+	if err != nil {
+		return res, fmt.Errorf("scan failed: %w", err)
+	}
+	conds, err := policy.ParseFailOn(config.FailOn)
 	if err != nil {
-		return fmt.Errorf("scan failed: %w", err)
+		return res, err
 	}
+	res.findings = findings
+	res.failing = policy.Failing(findings, conds, config.FailOnPotential)
+	res.violations = s.Violations()
+	res.warnings = s.Warnings()
+	res.lifecycle = s.Lifecycle()
+	res.history = s.History()
+	return res, nil
+}
 
-	// Generate report
-	rep := reporter.Get(config.OutputFormat)
+// relocate rewrites the manifest paths of a dependency and its merged
+// duplicates. Duplicates are copied first, since copies of the dependency
+// share them.
+func relocate(dep *models.Dependency, rewrite func(string) string) {
+	dep.SourceFile = rewrite(dep.SourceFile)
+	dep.Duplicates = slices.Clone(dep.Duplicates)
+	for i := range dep.Duplicates {
+		dep.Duplicates[i].File = rewrite(dep.Duplicates[i].File)
+	}
+}
+
+// localComponents returns the dependencies built from the project's own
+// source, which are reported as not checked
+func localComponents(deps []models.Dependency) []models.Dependency {
+	var local []models.Dependency
+	for _, dep := range deps {
+		if dep.Local != "" {
+			local = append(local, dep)
+		}
+	}
+	return local
+}
+
+// parseSize parses a byte size such as 512KB, 64MB or 1GB (binary units)
+func parseSize(value string) (int64, error) {
+	s := strings.ToUpper(strings.TrimSpace(value))
+	units := []struct {
+		suffix string
+		scale  int64
+	}{{"GB", 1 << 30}, {"MB", 1 << 20}, {"KB", 1 << 10}, {"B", 1}}
+	scale := int64(1)
+	for _, u := range units {
+		if strings.HasSuffix(s, u.suffix) {
+			s, scale = strings.TrimSuffix(s, u.suffix), u.scale
+			break
+		}
+	}
+	n, err := strconv.ParseInt(strings.TrimSpace(s), 10, 64)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("%q is not a size like 512KB or 64MB", value)
+	}
+	return n * scale, nil
+}
+
+// parseErrorMessage describes a manifest that could not be parsed
+func parseErrorMessage(e models.ParseError) string {
+	if e.Panic {
+		return fmt.Sprintf("parser crashed on %s, its dependencies were skipped: %s", e.File, e.Error)
+	}
+	return fmt.Sprintf("could not parse %s, its dependencies were skipped: %s", e.File, e.Error)
+}
+
+// validatePush checks that the settings required by the push target are present
+func validatePush(config *models.Config) error {
+	switch config.Push {
+	case "":
+		return nil
+	case "defectdojo":
+		if config.DefectDojoURL == "" || config.DefectDojoToken == "" || config.DefectDojoProduct == "" {
+			return fmt.Errorf("--push defectdojo requires --dd-url, --dd-token and --dd-product")
+		}
+		return nil
+	case "misp":
+		if config.MISPURL == "" || config.MISPKey == "" {
+			return fmt.Errorf("--push misp requires --misp-url and --misp-key")
+		}
+		return nil
+	default:
+		return fmt.Errorf("unknown push target: %s", config.Push)
+	}
+}
+
+// writeReport renders the report and writes it to the output file, remote
+// object or stdout
+func writeReport(rep reporter.Reporter, config *models.Config, findings []models.Finding) error {
 	output, err := rep.Report(findings)
 	if err != nil {
 		return fmt.Errorf("failed to generate report: %w", err)
 	}
 
-	// Write output
-	if config.OutputFile != "" {
+	if upload.IsRemote(config.OutputFile) {
+		if err := upload.Upload(config.OutputFile, output, upload.ContentType(config.OutputFormat)); err != nil {
+			return fmt.Errorf("failed to upload report: %w", err)
+		}
+		fmt.Fprintf(os.Stderr, "Report uploaded to %s\n", config.OutputFile)
+	} else if config.OutputFile != "" {
 		if err := os.WriteFile(config.OutputFile, output, 0644); err != nil {
 			return fmt.Errorf("failed to write output file: %w", err)
 		}
@@ -120,11 +878,191 @@ func runCheck(cmd *cobra.Command, args []string) error {
 	} else {
 		fmt.Print(string(output))
 	}
+	return nil
+}
+
+// signReport writes a detached signature or cosign bundle next to the report
+func signReport(path, key string) error {
+	var sig string
+	var err error
+	if key == signing.Keyless {
+		sig, err = signing.SignKeyless(path)
+	} else {
+		sig, err = signing.Sign(path, key)
+	}
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "Report signature written to %s\n", sig)
+	return nil
+}
 
-	// Exit with error code if KEVs found and not disabled
-	if len(findings) > 0 && config.FailOnKEV {
-		os.Exit(1)
+// teeTerminal prints the terminal report to stdout alongside a report written
+// to --output in another format
+func teeTerminal(config *models.Config, loc *time.Location, findings []models.Finding, violations []models.Violation, local []models.Dependency) error {
+	tee := *config
+	tee.OutputFormat = "terminal"
+	rep, reported, _, err := buildReport(&tee, loc, findings, true)
+	if err != nil {
+		return err
+	}
+	if tr, ok := rep.(*reporter.TerminalReporter); ok {
+		tr.Violations = violations
+		tr.Local = local
 	}
+	output, err := rep.Report(reported)
+	if err != nil {
+		return fmt.Errorf("failed to generate report: %w", err)
+	}
+	fmt.Print(string(output))
+	return nil
+}
 
+// streamReport writes findings one at a time to the output file or stdout
+func streamReport(sr reporter.StreamReporter, path string, findings []models.Finding) error {
+	if path == "" {
+		return reporter.Stream(sr, os.Stdout, findings)
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to write output file: %w", err)
+	}
+	if err := reporter.Stream(sr, f, findings); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to write output file: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("failed to write output file: %w", err)
+	}
+	fmt.Fprintf(os.Stderr, "Report written to %s\n", path)
 	return nil
 }
+
+// buildReport creates and configures the reporter for the output format and
+// applies --max-findings. toTerminal enables wrapping to the terminal width.
+func buildReport(config *models.Config, loc *time.Location, findings []models.Finding, toTerminal bool) (reporter.Reporter, []models.Finding, int, error) {
+	rep := reporter.Get(config.OutputFormat)
+	reported, omitted := findings, 0
+	if _, ok := rep.(reporter.StreamReporter); !ok {
+		reported, omitted = reporter.Truncate(findings, config.MaxFindings)
+	}
+	if tr, ok := rep.(*reporter.TerminalReporter); ok {
+		tr.GroupBy = flagGroupBy
+		tr.Omitted = omitted
+		tr.FullText = flagFullText
+		tr.DescriptionWidth = flagDescWidth
+		tr.ActionWidth = flagActionWidth
+		tr.Width = flagWidth
+		tr.Location = loc
+		if tr.Width == 0 && toTerminal && !flagReproducible {
+			tr.Width = terminalWidth()
+		}
+	}
+	if to, ok := rep.(reporter.TimestampOmitter); ok && flagReproducible {
+		to.OmitTimestamps()
+	}
+	if jr, ok := rep.(*reporter.JSONReporter); ok {
+		jr.Omitted = omitted
+	}
+	if tr, ok := rep.(*reporter.TemplateReporter); ok {
+		tr.Omitted = omitted
+		tr.Location = loc
+	}
+	if sr, ok := rep.(*reporter.SARIFReporter); ok {
+		if config.SeverityFile != "" {
+			policy, err := reporter.LoadSeverityPolicy(config.SeverityFile)
+			if err != nil {
+				return nil, nil, 0, err
+			}
+			policy.Location = loc
+			sr.Severity = policy
+		}
+		if ciEnv != nil {
+			sr.Run = &reporter.SARIFRunDetails{
+				AutomationID:  ciEnv.AutomationID(),
+				RepositoryURI: ciEnv.Repository,
+				RevisionID:    ciEnv.Commit,
+				Branch:        ciEnv.Branch,
+			}
+			if ciEnv.RunURL != "" {
+				sr.Run.Description = "kev-checker scan from " + ciEnv.RunURL
+			}
+		}
+	}
+	return rep, reported, omitted, nil
+}
+
+// writePerPathReports writes a report for each path argument's findings into
+// dir, named after the path
+func writePerPathReports(config *models.Config, loc *time.Location, findings []models.Finding, dir string) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create per-path report directory: %w", err)
+	}
+
+	byPath := make(map[string][]models.Finding)
+	for _, f := range findings {
+		byPath[f.Dependency.ScanPath] = append(byPath[f.Dependency.ScanPath], f)
+	}
+
+	used := make(map[string]bool)
+	for _, path := range config.Paths {
+		name := reportName(path)
+		for i := 2; used[name]; i++ {
+			name = fmt.Sprintf("%s-%d", reportName(path), i)
+		}
+		used[name] = true
+		file := filepath.Join(dir, name+reportExt(config.OutputFormat))
+
+		rep, reported, _, err := buildReport(config, loc, byPath[path], false)
+		if err != nil {
+			return err
+		}
+		if sr, ok := rep.(reporter.StreamReporter); ok {
+			err = streamReport(sr, file, byPath[path])
+		} else {
+			var output []byte
+			if output, err = rep.Report(reported); err == nil {
+				err = os.WriteFile(file, output, 0644)
+			}
+		}
+		if err != nil {
+			return fmt.Errorf("failed to write report for %s: %w", path, err)
+		}
+		fmt.Fprintf(os.Stderr, "Report for %s written to %s\n", path, file)
+	}
+	return nil
+}
+
+// reportName turns a path argument into a file name
+func reportName(path string) string {
+	if !clients.IsManifestURL(path) {
+		path = filepath.Clean(path)
+		if path == "." || path == ".." {
+			if abs, err := filepath.Abs(path); err == nil {
+				path = filepath.Base(abs)
+			}
+		}
+	}
+	name := strings.Trim(filepath.ToSlash(path), "/")
+	name = strings.NewReplacer("/", "_", ":", "_", "\\", "_", "?", "_", "*", "_").Replace(name)
+	if name == "" || name == "." {
+		return "root"
+	}
+	return name
+}
+
+// reportExt returns the file extension for a report format
+func reportExt(format string) string {
+	switch format {
+	case "json", "ocsf", "defectdojo":
+		return ".json"
+	case "ndjson":
+		return ".ndjson"
+	case "sarif":
+		return ".sarif"
+	case "poam":
+		return ".csv"
+	default:
+		return ".txt"
+	}
+}
@@ -0,0 +1,30 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/ethanolivertroy/kev-check-demo/internal/reporter"
+	"github.com/spf13/cobra"
+)
+
+var schemaCmd = &cobra.Command{
+	Use:       "schema <format>",
+	Short:     "Print the JSON Schema for a report format",
+	Long:      "Print the JSON Schema describing a report format. Currently only json is published.",
+	Args:      cobra.ExactArgs(1),
+	ValidArgs: []string{"json"},
+	RunE:      runSchema,
+}
+
+func init() {
+	rootCmd.AddCommand(schemaCmd)
+}
+
+func runSchema(cmd *cobra.Command, args []string) error {
+	schema, err := reporter.Schema(args[0])
+	if err != nil {
+		return err
+	}
+	fmt.Print(string(schema))
+	return nil
+}
@@ -0,0 +1,143 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/ethanolivertroy/kev-check-demo/internal/cache"
+	"github.com/ethanolivertroy/kev-check-demo/internal/clients"
+	"github.com/ethanolivertroy/kev-check-demo/internal/history"
+	"github.com/ethanolivertroy/kev-check-demo/internal/queue"
+	"github.com/ethanolivertroy/kev-check-demo/internal/server"
+	"github.com/spf13/cobra"
+)
+
+var (
+	flagServeListen      string
+	flagServeHistoryFile string
+	flagServeToken       string
+	flagServeReadOnly    []string
+	flagServeReports     string
+	flagServeQueue       string
+)
+
+var serveCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "Serve an HTTP API and report viewer over scan results",
+	Long: `serve answers queries against the dependencies and CVEs recorded in the
+history store by scans, recheck and queue workers, and serves a read-only
+viewer for the JSON reports in --reports (e.g. the --results directory of
+queue workers). Point it at the same --history-file, typically a shared
+postgres:// store.
+
+Endpoints:
+  GET  /v1/exposure?cve=CVE-2021-44228   projects and packages affected by a CVE
+  GET  /reports, /reports/{id}           report viewer (with --reports)
+  GET  /v1/reports, /v1/reports/{id}     report list and JSON reports (with --reports)
+  POST /v1/scans                         queue a scan job (with --queue; admin only)
+  GET  /healthz                          liveness check (no token required)
+
+--token (or $KEV_CHECKER_API_TOKEN) grants full access. Each --read-only-token
+(or comma-separated $KEV_CHECKER_READONLY_TOKENS) can view reports and query
+exposure but not queue scans. Send a token as "Authorization: Bearer <token>";
+browsers are prompted for it as the basic-auth password. Without any token,
+every request has read-only access; --queue requires --token.`,
+	Args: cobra.NoArgs,
+	RunE: runServe,
+}
+
+func init() {
+	serveCmd.Flags().StringVar(&flagServeListen, "listen", "127.0.0.1:8080", "Address to listen on")
+	serveCmd.Flags().StringVar(&flagServeHistoryFile, "history-file", "", "History store path or postgres:// URL (default: ~/.cache/kev-checker/history/history.json)")
+	serveCmd.Flags().StringVar(&flagServeToken, "token", "", "Admin token, required to queue scans (default: $KEV_CHECKER_API_TOKEN)")
+	serveCmd.Flags().StringSliceVar(&flagServeReadOnly, "read-only-token", nil, "Token that may only view reports and query exposure (repeatable; default: $KEV_CHECKER_READONLY_TOKENS)")
+	serveCmd.Flags().StringVar(&flagServeReports, "reports", "", "Directory of JSON reports to serve in the viewer, e.g. the queue work --results directory")
+	serveCmd.Flags().StringVar(&flagServeQueue, "queue", "", "Queue directory or redis:// URL; enables POST /v1/scans")
+	rootCmd.AddCommand(serveCmd)
+}
+
+func runServe(cmd *cobra.Command, args []string) error {
+	historyPath := flagServeHistoryFile
+	if historyPath == "" {
+		var err error
+		if historyPath, err = history.DefaultPath("kev-checker"); err != nil {
+			return err
+		}
+	}
+	// Fail at startup rather than on the first request
+	if _, err := history.Open(historyPath); err != nil {
+		return fmt.Errorf("failed to open history store: %w", err)
+	}
+
+	config := server.Config{
+		HistoryPath:    historyPath,
+		AdminToken:     flagServeToken,
+		ReadOnlyTokens: flagServeReadOnly,
+		ReportsDir:     flagServeReports,
+	}
+	if config.AdminToken == "" {
+		config.AdminToken = os.Getenv("KEV_CHECKER_API_TOKEN")
+	}
+	if len(config.ReadOnlyTokens) == 0 {
+		for _, t := range strings.Split(os.Getenv("KEV_CHECKER_READONLY_TOKENS"), ",") {
+			if t = strings.TrimSpace(t); t != "" {
+				config.ReadOnlyTokens = append(config.ReadOnlyTokens, t)
+			}
+		}
+	}
+	if flagServeQueue != "" && config.AdminToken == "" {
+		return fmt.Errorf("--queue requires --token (or $KEV_CHECKER_API_TOKEN) to authenticate POST /v1/scans")
+	}
+	if config.AdminToken == "" && len(config.ReadOnlyTokens) == 0 {
+		fmt.Fprintln(os.Stderr, "Warning: no --token set; reports and exposure are readable without a token")
+	}
+
+	if config.ReportsDir != "" {
+		if info, err := os.Stat(config.ReportsDir); err != nil || !info.IsDir() {
+			return fmt.Errorf("--reports %s is not a directory", config.ReportsDir)
+		}
+	}
+	if flagServeQueue != "" {
+		q, err := queue.Open(flagServeQueue)
+		if err != nil {
+			return err
+		}
+		defer q.Close()
+		config.Queue = q
+	}
+
+	c, err := cache.New("kev-checker", 24*time.Hour)
+	if err != nil {
+		// Non-fatal: fetch the catalog on every request
+		c = nil
+	}
+
+	srv := &http.Server{
+		Addr:              flagServeListen,
+		Handler:           server.New(config, clients.NewKEVClient(c)).Handler(),
+		ReadHeaderTimeout: 10 * time.Second,
+		WriteTimeout:      2 * time.Minute,
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	go func() {
+		<-ctx.Done()
+		shutdown, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		srv.Shutdown(shutdown)
+	}()
+
+	fmt.Fprintf(os.Stderr, "Listening on %s\n", flagServeListen)
+	if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
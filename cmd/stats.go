@@ -0,0 +1,86 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/ethanolivertroy/kev-check-demo/internal/models"
+	"github.com/ethanolivertroy/kev-check-demo/internal/scanner"
+	"github.com/ethanolivertroy/kev-check-demo/internal/stats"
+	"github.com/spf13/cobra"
+)
+
+var flagStatsFormat string
+
+var statsCmd = &cobra.Command{
+	Use:   "stats [paths...]",
+	Short: "Print anonymized aggregate KEV statistics",
+	Long: `stats scans the given paths and prints KEV counts aggregated by ecosystem,
+vendor, CWE and EPSS band. The output names no repositories, paths, packages
+or versions, so it is suitable for sharing org-wide metrics.`,
+	RunE: runStats,
+}
+
+func init() {
+	statsCmd.Flags().StringVarP(&flagStatsFormat, "format", "f", "terminal", "Output format: terminal, json")
+	rootCmd.AddCommand(statsCmd)
+}
+
+func runStats(cmd *cobra.Command, args []string) error {
+	config := models.DefaultConfig()
+	if len(args) > 0 {
+		config.Paths = args
+	}
+	config.NoHistory = true
+
+	s, err := scanner.New(config)
+	if err != nil {
+		return fmt.Errorf("failed to initialize scanner: %w", err)
+	}
+	findings, err := s.Scan(context.Background())
+	if err != nil {
+		return fmt.Errorf("scan failed: %w", err)
+	}
+
+	result := stats.Aggregate(s.Dependencies(), findings)
+
+	switch flagStatsFormat {
+	case "json":
+		out, err := json.MarshalIndent(result, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(out))
+	case "terminal":
+		printStats(result)
+	default:
+		return fmt.Errorf("unsupported format: %s", flagStatsFormat)
+	}
+	return nil
+}
+
+func printStats(s stats.Stats) {
+	fmt.Printf("Dependencies scanned: %d\n", s.Dependencies)
+	fmt.Printf("Affected packages:    %d\n", s.AffectedPackages)
+	fmt.Printf("KEV exposures:        %d (%d unique CVEs)\n", s.TotalKEVs, s.UniqueCVEs)
+	fmt.Printf("Ransomware-related:   %d\n", s.RansomwareRelated)
+	fmt.Printf("Risk accepted:        %d\n", s.RiskAccepted)
+
+	printBuckets("By ecosystem", s.ByEcosystem)
+	printBuckets("By vendor", s.ByVendor)
+	printBuckets("By CWE", s.ByCWE)
+	printBuckets("By EPSS band", s.ByEPSSBand)
+}
+
+func printBuckets(title string, buckets []stats.Bucket) {
+	fmt.Printf("\n%s\n%s\n", title, strings.Repeat("-", len(title)))
+	if len(buckets) == 0 {
+		fmt.Println("  (none)")
+		return
+	}
+	for _, b := range buckets {
+		fmt.Printf("  %-30s %d\n", b.Key, b.Count)
+	}
+}
@@ -0,0 +1,75 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/ethanolivertroy/kev-check-demo/internal/signing"
+	"github.com/spf13/cobra"
+)
+
+var (
+	flagVerifyKey       string
+	flagVerifySignature string
+	flagVerifyIdentity  string
+	flagVerifyIssuer    string
+)
+
+var verifyReportCmd = &cobra.Command{
+	Use:   "verify-report <report>",
+	Short: "Verify a report signature written by --sign",
+	Long: `verify-report checks that a report hasn't been modified since it was signed
+with --sign. Pass --key with the PEM public key matching the signing key, or
+--certificate-identity and --certificate-oidc-issuer to verify a keyless
+cosign bundle.
+
+Examples:
+  kev-checker verify-report results.sarif --key kev-checker.pub
+  kev-checker verify-report results.sarif \
+    --certificate-identity https://github.com/acme/app/.github/workflows/scan.yml@refs/heads/main \
+    --certificate-oidc-issuer https://token.actions.githubusercontent.com`,
+	Args: cobra.ExactArgs(1),
+	RunE: runVerifyReport,
+}
+
+func init() {
+	verifyReportCmd.Flags().StringVar(&flagVerifyKey, "key", "", "PEM public key for signatures made with --sign <key>")
+	verifyReportCmd.Flags().StringVar(&flagVerifySignature, "signature", "", "Signature or cosign bundle path (default: <report>.sig, or <report>.sigstore.json when keyless)")
+	verifyReportCmd.Flags().StringVar(&flagVerifyIdentity, "certificate-identity", "", "Expected signer identity for keyless signatures")
+	verifyReportCmd.Flags().StringVar(&flagVerifyIssuer, "certificate-oidc-issuer", "", "Expected OIDC issuer for keyless signatures")
+	rootCmd.AddCommand(verifyReportCmd)
+}
+
+func runVerifyReport(cmd *cobra.Command, args []string) error {
+	path := args[0]
+	keyless := flagVerifyIdentity != "" || flagVerifyIssuer != ""
+
+	switch {
+	case flagVerifyKey != "" && keyless:
+		return fmt.Errorf("use either --key or --certificate-identity/--certificate-oidc-issuer, not both")
+	case flagVerifyKey != "":
+		sig := flagVerifySignature
+		if sig == "" {
+			sig = path + signing.SignatureExt
+		}
+		if err := signing.Verify(path, sig, flagVerifyKey); err != nil {
+			return err
+		}
+	case keyless:
+		if flagVerifyIdentity == "" || flagVerifyIssuer == "" {
+			return fmt.Errorf("keyless verification requires both --certificate-identity and --certificate-oidc-issuer")
+		}
+		bundle := flagVerifySignature
+		if bundle == "" {
+			bundle = path + signing.BundleExt
+		}
+		if err := signing.VerifyKeyless(path, bundle, flagVerifyIdentity, flagVerifyIssuer); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("--key or --certificate-identity/--certificate-oidc-issuer is required")
+	}
+
+	fmt.Fprintf(os.Stderr, "Verified %s\n", path)
+	return nil
+}
@@ -0,0 +1,15 @@
+package cmd
+
+import (
+	"os"
+	"strconv"
+)
+
+// terminalWidth returns the width of the terminal on stdout, or 0 if stdout
+// isn't a terminal. $COLUMNS takes precedence when set.
+func terminalWidth() int {
+	if cols, err := strconv.Atoi(os.Getenv("COLUMNS")); err == nil && cols > 0 {
+		return cols
+	}
+	return stdoutWidth()
+}
@@ -0,0 +1,9 @@
+//go:build !linux && !darwin
+
+package cmd
+
+// stdoutWidth is not implemented on this platform; output isn't wrapped
+// unless $COLUMNS or --width is set
+func stdoutWidth() int {
+	return 0
+}
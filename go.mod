@@ -4,8 +4,10 @@ go 1.25.5
 
 require (
 	github.com/BurntSushi/toml v1.5.0
+	github.com/lib/pq v1.10.9
 	github.com/spf13/cobra v1.10.2
 	golang.org/x/mod v0.31.0
+	gopkg.in/yaml.v3 v3.0.1
 )
 
 require (
@@ -0,0 +1,288 @@
+package advisories
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/ethanolivertroy/kev-check-demo/internal/clients"
+	"github.com/ethanolivertroy/kev-check-demo/internal/models"
+	"github.com/ethanolivertroy/kev-check-demo/internal/versions"
+)
+
+// CatalogName labels KEV entries that come from internal advisories
+const CatalogName = "Internal advisory"
+
+// defaultRequiredAction is used when an advisory doesn't specify one
+const defaultRequiredAction = "Apply remediation per internal advisory."
+
+// Feed is a set of OSV-schema advisories supplied by the organization. Every
+// advisory in the feed is treated as known-exploited.
+type Feed struct {
+	records []clients.OSVRecord
+}
+
+// Load reads advisories from files, directories of *.json files, or HTTP(S)
+// endpoints. Each document may hold a single OSV record or an array of them.
+func Load(locations []string) (*Feed, error) {
+	feed := &Feed{}
+	for _, loc := range locations {
+		docs, err := readLocation(loc)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load advisories from %s: %w", loc, err)
+		}
+		for _, doc := range docs {
+			records, err := decodeRecords(doc)
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse advisories from %s: %w", loc, err)
+			}
+			for _, rec := range records {
+				if rec.Withdrawn == nil {
+					feed.records = append(feed.records, rec)
+				}
+			}
+		}
+	}
+	return feed, nil
+}
+
+func readLocation(loc string) ([][]byte, error) {
+	if strings.HasPrefix(loc, "http://") || strings.HasPrefix(loc, "https://") {
+		client := &http.Client{Timeout: 60 * time.Second}
+		resp, err := client.Get(loc)
+		if err != nil {
+			return nil, err
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+		}
+		data, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return nil, err
+		}
+		return [][]byte{data}, nil
+	}
+
+	info, err := os.Stat(loc)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		data, err := os.ReadFile(loc)
+		if err != nil {
+			return nil, err
+		}
+		return [][]byte{data}, nil
+	}
+
+	matches, err := filepath.Glob(filepath.Join(loc, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	var docs [][]byte
+	for _, m := range matches {
+		data, err := os.ReadFile(m)
+		if err != nil {
+			return nil, err
+		}
+		docs = append(docs, data)
+	}
+	return docs, nil
+}
+
+func decodeRecords(data []byte) ([]clients.OSVRecord, error) {
+	data = bytes.TrimSpace(data)
+	if len(data) > 0 && data[0] == '[' {
+		var records []clients.OSVRecord
+		err := json.Unmarshal(data, &records)
+		return records, err
+	}
+	var record clients.OSVRecord
+	if err := json.Unmarshal(data, &record); err != nil {
+		return nil, err
+	}
+	return []clients.OSVRecord{record}, nil
+}
+
+// Name returns the source name
+func (f *Feed) Name() string {
+	return CatalogName
+}
+
+// QueryByPackage implements clients.VulnSource by matching locally
+func (f *Feed) QueryByPackage(deps []models.Dependency) (map[int][]models.CVEInfo, error) {
+	results := make(map[int][]models.CVEInfo)
+	for i, dep := range deps {
+		for _, rec := range f.records {
+			if Affects(rec, dep) {
+				results[i] = append(results[i], models.CVEInfo{
+					ID:         advisoryKey(rec),
+					Summary:    rec.Summary,
+					Source:     CatalogName,
+					AdvisoryID: rec.ID,
+				})
+			}
+		}
+	}
+	return results, nil
+}
+
+// QueryByPurl implements clients.VulnSource by matching affected package purls
+func (f *Feed) QueryByPurl(purls []string) (map[int][]models.CVEInfo, error) {
+	results := make(map[int][]models.CVEInfo)
+	for i, purl := range purls {
+		base, version, _ := strings.Cut(purl, "@")
+		for _, rec := range f.records {
+			for _, a := range rec.Affected {
+				if a.Package.Purl == base && affectsVersion(a, models.Ecosystem(a.Package.Ecosystem), version) {
+					results[i] = append(results[i], models.CVEInfo{
+						ID:         advisoryKey(rec),
+						Summary:    rec.Summary,
+						Source:     CatalogName,
+						AdvisoryID: rec.ID,
+					})
+					break
+				}
+			}
+		}
+	}
+	return results, nil
+}
+
+// Record returns the advisory with the given ID, or nil if the feed has none
+func (f *Feed) Record(id string) *clients.OSVRecord {
+	for i := range f.records {
+		if f.records[i].ID == id {
+			return &f.records[i]
+		}
+	}
+	return nil
+}
+
+// Catalog returns KEV entries for every advisory, keyed like the CVE IDs
+// returned from queries
+func (f *Feed) Catalog() map[string]models.KEVInfo {
+	catalog := make(map[string]models.KEVInfo, len(f.records))
+	for _, rec := range f.records {
+		action, _ := rec.DatabaseSpecific["required_action"].(string)
+		if action == "" {
+			action = defaultRequiredAction
+		}
+		vendor, _ := rec.DatabaseSpecific["vendor_project"].(string)
+		product := ""
+		if len(rec.Affected) > 0 {
+			product = rec.Affected[0].Package.Name
+		}
+
+		kev := models.KEVInfo{
+			CVEID:             advisoryKey(rec),
+			Catalog:           CatalogName,
+			VendorProject:     vendor,
+			Product:           product,
+			VulnerabilityName: rec.Summary,
+			ShortDescription:  rec.Details,
+			RequiredAction:    action,
+			DateAdded:         rec.Published,
+		}
+		if due, ok := rec.DatabaseSpecific["due_date"].(string); ok {
+			kev.DueDate, _ = time.Parse("2006-01-02", due)
+		}
+		if ransomware, ok := rec.DatabaseSpecific["ransomware"].(bool); ok {
+			kev.RansomwareUse = ransomware
+		}
+		catalog[kev.CVEID] = kev
+	}
+	return catalog
+}
+
+// advisoryKey prefers a CVE alias so internal advisories line up with other
+// sources, falling back to the advisory's own ID
+func advisoryKey(rec clients.OSVRecord) string {
+	if strings.HasPrefix(rec.ID, "CVE-") {
+		return rec.ID
+	}
+	for _, alias := range rec.Aliases {
+		if strings.HasPrefix(alias, "CVE-") {
+			return alias
+		}
+	}
+	return rec.ID
+}
+
+// Affects reports whether an OSV record applies to the dependency. Unpinned
+// dependencies match any affected version.
+func Affects(rec clients.OSVRecord, dep models.Dependency) bool {
+	for _, a := range rec.Affected {
+		if !strings.EqualFold(a.Package.Ecosystem, dep.Ecosystem.OSVName()) {
+			continue
+		}
+		if models.NormalizeName(dep.Ecosystem, a.Package.Name) != models.NormalizeName(dep.Ecosystem, dep.Name) {
+			continue
+		}
+		if dep.Unpinned || affectsVersion(a, dep.Ecosystem, dep.Version) {
+			return true
+		}
+	}
+	return false
+}
+
+// affectsVersion checks explicit versions and introduced/fixed ranges, using
+// the version ordering of the ecosystem
+func affectsVersion(a clients.OSVAffected, eco models.Ecosystem, version string) bool {
+	if len(a.Versions) == 0 && len(a.Ranges) == 0 {
+		return true // All versions affected
+	}
+	if version == "" {
+		return false
+	}
+
+	v := strings.TrimPrefix(version, "v")
+	for _, av := range a.Versions {
+		if strings.TrimPrefix(av, "v") == v {
+			return true
+		}
+	}
+
+	for _, r := range a.Ranges {
+		if r.Type == "GIT" {
+			continue
+		}
+		// SEMVER ranges are semver regardless of ecosystem
+		scheme := eco.Info().Versions
+		if r.Type == "SEMVER" {
+			scheme = models.VersionSemver
+		}
+		compare := func(x, y string) int {
+			return versions.Compare(scheme, x, y)
+		}
+
+		affected := false
+		for _, e := range r.Events {
+			switch {
+			case e.Introduced != "":
+				if e.Introduced == "0" || compare(v, e.Introduced) >= 0 {
+					affected = true
+				}
+			case e.Fixed != "":
+				if compare(v, e.Fixed) >= 0 {
+					affected = false
+				}
+			case e.LastAffected != "":
+				if compare(v, e.LastAffected) > 0 {
+					affected = false
+				}
+			}
+		}
+		if affected {
+			return true
+		}
+	}
+	return false
+}
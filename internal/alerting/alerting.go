@@ -0,0 +1,129 @@
+package alerting
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ethanolivertroy/kev-check-demo/internal/history"
+	"github.com/ethanolivertroy/kev-check-demo/internal/models"
+)
+
+// Notifier opens an incident for an alert, deduplicated by key
+type Notifier interface {
+	Name() string
+	Trigger(dedupKey, summary, component string, details map[string]string) error
+}
+
+// Alert is a finding that warrants paging someone
+type Alert struct {
+	Fingerprint string
+	Summary     string
+	Component   string
+	Details     map[string]string
+}
+
+// Candidates returns alerts for unaccepted KEVs that are ransomware-associated
+// or past their CISA due date in loc
+func Candidates(findings []models.Finding, loc *time.Location) []Alert {
+	var alerts []Alert
+	now := time.Now()
+
+	for _, f := range findings {
+		for _, kev := range f.KEVs {
+			if kev.Accepted != nil {
+				continue
+			}
+			overdue := kev.Overdue(now, loc)
+			if !kev.RansomwareUse && !overdue {
+				continue
+			}
+
+			reason := "ransomware-associated"
+			if !kev.RansomwareUse {
+				reason = "past CISA due date"
+			} else if overdue {
+				reason = "ransomware-associated and past CISA due date"
+			}
+
+			alerts = append(alerts, newAlert(f, kev, reason))
+		}
+	}
+
+	return alerts
+}
+
+// NewKEVs returns an alert for every unaccepted KEV, for findings that are
+// already known to be new
+func NewKEVs(findings []models.Finding) []Alert {
+	var alerts []Alert
+	for _, f := range findings {
+		for _, kev := range f.KEVs {
+			if kev.Accepted == nil {
+				alerts = append(alerts, newAlert(f, kev, "newly added to KEV"))
+			}
+		}
+	}
+	return alerts
+}
+
+func newAlert(f models.Finding, kev models.KEVInfo, reason string) Alert {
+	alert := Alert{
+		Fingerprint: f.Fingerprint(kev.CVEID),
+		Summary:     fmt.Sprintf("KEV %s (%s) in %s", kev.CVEID, reason, f.Dependency.String()),
+		Component:   f.Dependency.String(),
+		Details: map[string]string{
+			"cve":             kev.CVEID,
+			"vulnerability":   kev.VulnerabilityName,
+			"package":         f.Dependency.String(),
+			"ecosystem":       string(f.Dependency.Ecosystem),
+			"source_file":     f.Dependency.SourceFile,
+			"due_date":        kev.DueDate.Format("2006-01-02"),
+			"required_action": kev.RequiredAction,
+		},
+	}
+	if len(f.Owners) > 0 {
+		alert.Details["owner"] = strings.Join(f.Owners, ", ")
+	}
+	return alert
+}
+
+// Dispatch sends each alert to every notifier. Alerts already sent in a
+// previous run (tracked in the history store, when available) are skipped.
+// Each alert is marked as sent once every notifier delivered it, and the
+// store is saved even if a later alert fails, so delivered alerts aren't
+// repeated. It returns the number of alerts sent.
+func Dispatch(alerts []Alert, notifiers []Notifier, store *history.Store) (int, error) {
+	sent := 0
+	var err error
+	for _, a := range alerts {
+		if store != nil && store.Alerted(a.Fingerprint) {
+			continue
+		}
+		if err = trigger(a, notifiers); err != nil {
+			break
+		}
+		sent++
+
+		if store != nil {
+			store.MarkAlerted(a.Fingerprint, time.Now())
+		}
+	}
+
+	if store != nil && sent > 0 {
+		if saveErr := store.Save(); saveErr != nil && err == nil {
+			err = fmt.Errorf("failed to save history store: %w", saveErr)
+		}
+	}
+	return sent, err
+}
+
+// trigger sends an alert to every notifier, stopping at the first failure
+func trigger(a Alert, notifiers []Notifier) error {
+	for _, n := range notifiers {
+		if err := n.Trigger(a.Fingerprint, a.Summary, a.Component, a.Details); err != nil {
+			return fmt.Errorf("%s: %w", n.Name(), err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,146 @@
+package archive
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"strings"
+)
+
+// maxEntrySize caps how much of a single archive entry is read into memory
+const maxEntrySize = 64 << 20
+
+// Supported reports whether path has a recognized archive extension
+func Supported(path string) bool {
+	return format(path) != ""
+}
+
+func format(path string) string {
+	lower := strings.ToLower(path)
+	switch {
+	case strings.HasSuffix(lower, ".zip"):
+		return "zip"
+	case strings.HasSuffix(lower, ".tar.gz"), strings.HasSuffix(lower, ".tgz"):
+		return "tgz"
+	case strings.HasSuffix(lower, ".tar"):
+		return "tar"
+	}
+	return ""
+}
+
+// Entry is a regular file in an archive
+type Entry struct {
+	Name string
+	Size int64 // Uncompressed size from the entry's header
+}
+
+// List returns the regular files in the archive without reading their
+// content
+func List(path string) ([]Entry, error) {
+	var entries []Entry
+	err := Walk(path, func(e Entry) bool {
+		entries = append(entries, e)
+		return false
+	}, nil)
+	return entries, err
+}
+
+// Walk calls fn with the content of every regular file in the archive for
+// which want returns true. Entries are read into memory one at a time;
+// nothing is written to disk. An entry that can't be read, or is larger than
+// 64 MB, is passed to fn with its error and the walk continues. fn may return
+// fs.SkipAll to stop early.
+func Walk(path string, want func(Entry) bool, fn func(e Entry, content []byte, err error) error) error {
+	var err error
+	switch format(path) {
+	case "zip":
+		err = walkZip(path, want, fn)
+	case "tgz", "tar":
+		err = walkTar(path, want, fn)
+	default:
+		return fmt.Errorf("unsupported archive format: %s (expected .zip, .tar, .tar.gz or .tgz)", path)
+	}
+	if err == fs.SkipAll {
+		return nil
+	}
+	return err
+}
+
+func walkZip(path string, want func(Entry) bool, fn func(Entry, []byte, error) error) error {
+	r, err := zip.OpenReader(path)
+	if err != nil {
+		return fmt.Errorf("failed to open archive: %w", err)
+	}
+	defer r.Close()
+
+	for _, f := range r.File {
+		e := Entry{Name: strings.TrimPrefix(f.Name, "./"), Size: int64(f.UncompressedSize64)}
+		if f.FileInfo().IsDir() || !want(e) {
+			continue
+		}
+		var content []byte
+		rc, err := f.Open()
+		if err == nil {
+			content, err = readEntry(rc, e.Name)
+			rc.Close()
+		} else {
+			err = fmt.Errorf("failed to read %s: %w", f.Name, err)
+		}
+		if err := fn(e, content, err); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func walkTar(path string, want func(Entry) bool, fn func(Entry, []byte, error) error) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to open archive: %w", err)
+	}
+	defer file.Close()
+
+	var r io.Reader = file
+	if format(path) == "tgz" {
+		gz, err := gzip.NewReader(file)
+		if err != nil {
+			return fmt.Errorf("failed to decompress archive: %w", err)
+		}
+		defer gz.Close()
+		r = gz
+	}
+
+	tr := tar.NewReader(r)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read archive: %w", err)
+		}
+		e := Entry{Name: strings.TrimPrefix(hdr.Name, "./"), Size: hdr.Size}
+		if hdr.Typeflag != tar.TypeReg || !want(e) {
+			continue
+		}
+		content, err := readEntry(tr, e.Name)
+		if err := fn(e, content, err); err != nil {
+			return err
+		}
+	}
+}
+
+func readEntry(r io.Reader, name string) ([]byte, error) {
+	content, err := io.ReadAll(io.LimitReader(r, maxEntrySize+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", name, err)
+	}
+	if len(content) > maxEntrySize {
+		return nil, fmt.Errorf("%s exceeds %d MB", name, maxEntrySize>>20)
+	}
+	return content, nil
+}
@@ -0,0 +1,129 @@
+package archive
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// testEntries are written to every test archive, in order
+var testEntries = []struct {
+	name string
+	size int
+}{
+	{"./package.json", 16},
+	{"big/requirements.txt", maxEntrySize + 1},
+	{"app/go.mod", 32},
+}
+
+func TestWalk(t *testing.T) {
+	tests := []struct {
+		name  string
+		file  string
+		write func(w io.Writer) error
+	}{
+		{"zip", "src.zip", writeZip},
+		{"tar", "src.tar", writeTar},
+		{"tgz", "src.tgz", func(w io.Writer) error {
+			gz := gzip.NewWriter(w)
+			if err := writeTar(gz); err != nil {
+				return err
+			}
+			return gz.Close()
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := tt.write(&buf); err != nil {
+				t.Fatal(err)
+			}
+			path := filepath.Join(t.TempDir(), tt.file)
+			if err := os.WriteFile(path, buf.Bytes(), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			entries, err := List(path)
+			if err != nil {
+				t.Fatalf("List() error = %v", err)
+			}
+			if len(entries) != len(testEntries) {
+				t.Fatalf("List() = %v, want %d entries", entries, len(testEntries))
+			}
+
+			// Every entry is visited; the oversized one with an error
+			got := make(map[string]string)
+			err = Walk(path, func(Entry) bool { return true }, func(e Entry, content []byte, err error) error {
+				if err != nil {
+					got[e.Name] = err.Error()
+				} else {
+					got[e.Name] = string(content)
+				}
+				return nil
+			})
+			if err != nil {
+				t.Fatalf("Walk() error = %v", err)
+			}
+			if want := strings.Repeat("x", 16); got["package.json"] != want {
+				t.Errorf("package.json = %q, want %q", got["package.json"], want)
+			}
+			if !strings.Contains(got["big/requirements.txt"], "exceeds 64 MB") {
+				t.Errorf("big/requirements.txt error = %q, want size limit", got["big/requirements.txt"])
+			}
+			if len(got["app/go.mod"]) != 32 {
+				t.Errorf("app/go.mod read %d bytes, want 32", len(got["app/go.mod"]))
+			}
+
+			// Unwanted entries aren't read, and fs.SkipAll stops the walk
+			var visited []string
+			err = Walk(path, func(e Entry) bool { return e.Size <= maxEntrySize }, func(e Entry, content []byte, err error) error {
+				visited = append(visited, e.Name)
+				return fs.SkipAll
+			})
+			if err != nil || len(visited) != 1 || visited[0] != "package.json" {
+				t.Errorf("Walk() with SkipAll visited %v, error %v; want [package.json]", visited, err)
+			}
+		})
+	}
+}
+
+func writeZip(w io.Writer) error {
+	zw := zip.NewWriter(w)
+	if _, err := zw.Create("big/"); err != nil {
+		return err
+	}
+	for _, e := range testEntries {
+		f, err := zw.Create(e.name)
+		if err != nil {
+			return err
+		}
+		if _, err := f.Write(bytes.Repeat([]byte("x"), e.size)); err != nil {
+			return err
+		}
+	}
+	return zw.Close()
+}
+
+func writeTar(w io.Writer) error {
+	tw := tar.NewWriter(w)
+	if err := tw.WriteHeader(&tar.Header{Name: "big/", Typeflag: tar.TypeDir, Mode: 0755}); err != nil {
+		return err
+	}
+	for _, e := range testEntries {
+		if err := tw.WriteHeader(&tar.Header{Name: e.name, Typeflag: tar.TypeReg, Mode: 0644, Size: int64(e.size)}); err != nil {
+			return err
+		}
+		if _, err := tw.Write(bytes.Repeat([]byte("x"), e.size)); err != nil {
+			return err
+		}
+	}
+	return tw.Close()
+}
@@ -0,0 +1,107 @@
+// Package baseline reads and writes baseline files: the fingerprints of KEV
+// findings known when the baseline was taken. Findings in the baseline are
+// reported as accepted and don't fail the scan, so a project can adopt
+// kev-checker and only fail on findings introduced afterwards.
+package baseline
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/ethanolivertroy/kev-check-demo/internal/models"
+)
+
+// formatVersion is the version of the baseline file format
+const formatVersion = 1
+
+// ReasonBaseline is the risk acceptance reason of baselined findings
+const ReasonBaseline = "baseline"
+
+// File is the on-disk baseline
+type File struct {
+	Version  int       `json:"version"`
+	Created  time.Time `json:"created"`
+	Findings []Entry   `json:"findings"`
+}
+
+// Entry is a baselined finding. Only the fingerprint is matched; the other
+// fields make the file reviewable.
+type Entry struct {
+	Fingerprint string `json:"fingerprint"`
+	CVE         string `json:"cve"`
+	Package     string `json:"package"`
+	SourceFile  string `json:"source_file"`
+}
+
+// Baseline is a loaded baseline file
+type Baseline struct {
+	path         string
+	fingerprints map[string]bool
+}
+
+// Load reads a baseline file
+func Load(path string) (*Baseline, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read baseline: %w", err)
+	}
+	var f File
+	if err := json.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("failed to parse baseline %s: %w", path, err)
+	}
+	if f.Version != formatVersion {
+		return nil, fmt.Errorf("unsupported baseline version %d in %s", f.Version, path)
+	}
+
+	b := &Baseline{path: path, fingerprints: make(map[string]bool)}
+	for _, e := range f.Findings {
+		b.fingerprints[e.Fingerprint] = true
+	}
+	return b, nil
+}
+
+// Match returns a risk acceptance for a KEV finding present in the baseline,
+// or nil. A nil Baseline matches nothing.
+func (b *Baseline) Match(f models.Finding, cveID string) *models.RiskAcceptance {
+	if b == nil || !b.fingerprints[f.Fingerprint(cveID)] {
+		return nil
+	}
+	return &models.RiskAcceptance{
+		Reason:        ReasonBaseline,
+		Justification: "present in baseline " + b.path,
+	}
+}
+
+// Write records every KEV finding as the baseline at path. Findings accepted
+// through an exclusion are left out, so they fail again once it expires.
+func Write(path string, findings []models.Finding) error {
+	f := File{Version: formatVersion, Created: time.Now().UTC(), Findings: []Entry{}}
+	for _, finding := range findings {
+		for _, kev := range finding.KEVs {
+			if kev.Accepted != nil && kev.Accepted.Reason != ReasonBaseline {
+				continue
+			}
+			f.Findings = append(f.Findings, Entry{
+				Fingerprint: finding.Fingerprint(kev.CVEID),
+				CVE:         kev.CVEID,
+				Package:     finding.Dependency.String(),
+				SourceFile:  models.RelativePath(finding.Dependency.SourceFile, finding.Dependency.ScanPath),
+			})
+		}
+	}
+	sort.Slice(f.Findings, func(i, j int) bool {
+		return f.Findings[i].Fingerprint < f.Findings[j].Fingerprint
+	})
+
+	data, err := json.MarshalIndent(f, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write baseline: %w", err)
+	}
+	return nil
+}
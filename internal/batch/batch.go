@@ -0,0 +1,361 @@
+// Package batch loads targets files listing many scan targets, each with
+// optional config overrides, for scanning in one run.
+package batch
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/ethanolivertroy/kev-check-demo/internal/git"
+	"github.com/ethanolivertroy/kev-check-demo/internal/models"
+	"github.com/ethanolivertroy/kev-check-demo/internal/policy"
+	"gopkg.in/yaml.v3"
+)
+
+// File is a targets file
+type File struct {
+	// Defaults are overrides applied to every target before its own
+	Defaults Overrides `yaml:"defaults"`
+	Targets  []Target  `yaml:"targets"`
+}
+
+// Target is one thing to scan. Exactly one of Path, Paths, Git, Archive or
+// Inventory is set; Ref applies to Path (a local repository) or Git.
+type Target struct {
+	Name      string   `yaml:"name"`
+	Path      string   `yaml:"path"`
+	Paths     []string `yaml:"paths"`
+	Git       string   `yaml:"git"`
+	Ref       string   `yaml:"ref"`
+	Archive   string   `yaml:"archive"`
+	Inventory string   `yaml:"inventory"`
+	Image     string   `yaml:"image"`
+	SBOM      string   `yaml:"sbom"`
+
+	Overrides `yaml:",inline"`
+}
+
+// Overrides replace scan settings for a target. Unset fields keep the value
+// from the command line. A repository's .kev-checker.yaml holds the same
+// fields.
+type Overrides struct {
+	Exclusions    *string  `yaml:"exclusions"`
+	Owners        *string  `yaml:"owners"`
+	DenyList      *string  `yaml:"deny_list"`
+	KEVOverlay    *string  `yaml:"kev_overlay"`
+	Sources       []string `yaml:"sources"`
+	EPSSThreshold *float64 `yaml:"epss_threshold"`
+	MinCVSS       *float64 `yaml:"min_cvss"`
+	// Ignore lists CVE IDs or package names whose KEVs are not reported
+	Ignore []string `yaml:"ignore"`
+	// Ecosystems limits the target to these ecosystems, e.g. [npm, PyPI]
+	Ecosystems []string `yaml:"ecosystems"`
+	// Fail set to false reports the target's KEVs without failing the run
+	Fail *bool `yaml:"fail"`
+	// FailOn replaces --fail-on for the target, e.g. ["open>30d"]
+	FailOn []string `yaml:"fail_on"`
+	// SkipDirs replaces --skip-dirs for the target
+	SkipDirs []string `yaml:"skip_dirs"`
+	// ScanVendored walks vendored dependency directories
+	ScanVendored *bool `yaml:"scan_vendored"`
+	// RequirementsFiles replaces --requirements-files for the target
+	RequirementsFiles []string `yaml:"requirements_files"`
+
+	// RepoConfig set to false ignores the target's own .kev-checker.yaml.
+	// Only valid in the targets file.
+	RepoConfig *bool `yaml:"repo_config"`
+}
+
+// RepoConfigFile is the file in a repository's scanned directory holding the
+// team's own overrides, applied before those in the targets file
+const RepoConfigFile = ".kev-checker.yaml"
+
+// Load reads a targets file. Relative paths in it are resolved against the
+// file's directory.
+func Load(path string) (*File, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read targets file: %w", err)
+	}
+
+	var f File
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&f); err != nil {
+		return nil, fmt.Errorf("failed to parse targets file %s: %w", path, err)
+	}
+	if len(f.Targets) == 0 {
+		return nil, fmt.Errorf("targets file %s lists no targets", path)
+	}
+
+	base := filepath.Dir(path)
+	if err := f.Defaults.validate(); err != nil {
+		return nil, fmt.Errorf("defaults in %s: %w", path, err)
+	}
+	f.Defaults.resolve(base)
+	names := make(map[string]bool)
+	for i := range f.Targets {
+		t := &f.Targets[i]
+		if err := t.validate(); err != nil {
+			return nil, fmt.Errorf("target %d in %s: %w", i+1, path, err)
+		}
+		if t.Name == "" {
+			t.Name = t.source()
+		}
+		if names[t.Name] {
+			return nil, fmt.Errorf("duplicate target name %q in %s", t.Name, path)
+		}
+		names[t.Name] = true
+		t.resolve(base)
+	}
+	return &f, nil
+}
+
+func (t *Target) validate() error {
+	if t.Image != "" {
+		return fmt.Errorf("image targets are not supported; export the image filesystem with 'docker export' and use an archive target")
+	}
+	if t.SBOM != "" {
+		return fmt.Errorf("SBOM targets are not supported; use an inventory target")
+	}
+
+	set := 0
+	for _, v := range []bool{t.Path != "", len(t.Paths) > 0, t.Git != "", t.Archive != "", t.Inventory != ""} {
+		if v {
+			set++
+		}
+	}
+	if set != 1 {
+		return fmt.Errorf("exactly one of path, paths, git, archive or inventory is required")
+	}
+	if t.Ref != "" && t.Path == "" && t.Git == "" {
+		return fmt.Errorf("ref requires path or git")
+	}
+	return t.Overrides.validate()
+}
+
+func (o *Overrides) validate() error {
+	for _, name := range o.Ecosystems {
+		if _, ok := models.ParseEcosystem(name); !ok {
+			return fmt.Errorf("unknown ecosystem %q", name)
+		}
+	}
+	if _, err := policy.ParseFailOn(o.FailOn); err != nil {
+		return err
+	}
+	for _, pattern := range o.SkipDirs {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid skip_dirs pattern %q: %w", pattern, err)
+		}
+	}
+	for _, pattern := range o.RequirementsFiles {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid requirements_files pattern %q: %w", pattern, err)
+		}
+	}
+	return nil
+}
+
+// UsesRepoConfig reports whether the target's own .kev-checker.yaml applies,
+// which it does unless disabled for the target or in defaults. Only
+// single-directory targets (path or git) have one.
+func (f *File) UsesRepoConfig(t Target) bool {
+	if t.Path == "" && t.Git == "" {
+		return false
+	}
+	if t.RepoConfig != nil {
+		return *t.RepoConfig
+	}
+	return f.Defaults.RepoConfig == nil || *f.Defaults.RepoConfig
+}
+
+// LoadRepoConfig reads the .kev-checker.yaml in dir, from the tree of ref if
+// set, otherwise from the working tree. It returns nil if there is none.
+// Relative paths in it are resolved against dir, and must stay inside it.
+func LoadRepoConfig(dir, ref string) (*Overrides, error) {
+	var data []byte
+	if ref != "" {
+		top, err := git.TopLevel(dir)
+		if err != nil {
+			return nil, err
+		}
+		abs, err := filepath.Abs(dir)
+		if err != nil {
+			return nil, err
+		}
+		rel, err := filepath.Rel(top, filepath.Join(abs, RepoConfigFile))
+		if err != nil {
+			return nil, err
+		}
+		rel = filepath.ToSlash(rel)
+		if files, err := git.ListFiles(top, ref, rel); err != nil || len(files) == 0 {
+			return nil, err
+		}
+		if data, err = git.ReadFile(top, ref, rel); err != nil {
+			return nil, err
+		}
+	} else {
+		var err error
+		data, err = os.ReadFile(filepath.Join(dir, RepoConfigFile))
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", RepoConfigFile, err)
+		}
+	}
+
+	var o Overrides
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&o); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("failed to parse %s: %w", RepoConfigFile, err)
+	}
+	if o.RepoConfig != nil {
+		return nil, fmt.Errorf("%s: repo_config is only valid in the targets file", RepoConfigFile)
+	}
+	if err := o.validate(); err != nil {
+		return nil, fmt.Errorf("%s: %w", RepoConfigFile, err)
+	}
+	if err := o.confine(dir); err != nil {
+		return nil, fmt.Errorf("%s: %w", RepoConfigFile, err)
+	}
+	o.resolve(dir)
+	return &o, nil
+}
+
+// confine rejects file settings that lead outside dir, through "..", an
+// absolute path, a URL or a symlink, so a scanned repository can't make the
+// scan read files it doesn't contain
+func (o *Overrides) confine(dir string) error {
+	root, err := filepath.EvalSymlinks(dir)
+	if err != nil {
+		return err
+	}
+	for _, f := range []struct {
+		name string
+		path *string
+	}{
+		{"exclusions", o.Exclusions},
+		{"owners", o.Owners},
+		{"deny_list", o.DenyList},
+		{"kev_overlay", o.KEVOverlay},
+	} {
+		if f.path == nil || *f.path == "" {
+			continue
+		}
+		p := *f.path
+		if filepath.IsAbs(p) || strings.Contains(p, "://") {
+			return fmt.Errorf("%s %q must be a relative path inside the repository", f.name, p)
+		}
+		target := filepath.Join(root, p)
+		if resolved, err := filepath.EvalSymlinks(target); err == nil {
+			target = resolved
+		}
+		if rel, err := filepath.Rel(root, target); err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return fmt.Errorf("%s %q is outside the repository", f.name, p)
+		}
+	}
+	return nil
+}
+
+// source describes what the target scans, used as its default name
+func (t *Target) source() string {
+	switch {
+	case t.Path != "":
+		return t.Path
+	case len(t.Paths) > 0:
+		return t.Paths[0]
+	case t.Git != "":
+		return t.Git
+	case t.Archive != "":
+		return t.Archive
+	default:
+		return t.Inventory
+	}
+}
+
+func (t *Target) resolve(base string) {
+	t.Path = resolvePath(base, t.Path)
+	for i, p := range t.Paths {
+		t.Paths[i] = resolvePath(base, p)
+	}
+	t.Archive = resolvePath(base, t.Archive)
+	t.Inventory = resolvePath(base, t.Inventory)
+	t.Overrides.resolve(base)
+}
+
+func (o *Overrides) resolve(base string) {
+	for _, p := range []*string{o.Exclusions, o.Owners, o.DenyList, o.KEVOverlay} {
+		if p != nil {
+			*p = resolvePath(base, *p)
+		}
+	}
+}
+
+// Apply returns a copy of config with the overrides applied
+func (o Overrides) Apply(config models.Config) models.Config {
+	if o.Exclusions != nil {
+		config.ExclusionsFile = *o.Exclusions
+	}
+	if o.Owners != nil {
+		config.OwnersFile = *o.Owners
+	}
+	if o.DenyList != nil {
+		config.DenyListFile = *o.DenyList
+	}
+	if o.KEVOverlay != nil {
+		config.KEVOverlayFile = *o.KEVOverlay
+	}
+	if o.Sources != nil {
+		config.Sources = o.Sources
+	}
+	if o.EPSSThreshold != nil {
+		config.EPSSThreshold = *o.EPSSThreshold
+	}
+	if o.MinCVSS != nil {
+		config.MinCVSS = *o.MinCVSS
+	}
+	if o.Ignore != nil {
+		config.Ignore = o.Ignore
+	}
+	if o.Ecosystems != nil {
+		config.Ecosystems = nil
+		for _, name := range o.Ecosystems {
+			eco, _ := models.ParseEcosystem(name)
+			config.Ecosystems = append(config.Ecosystems, eco)
+		}
+	}
+	if o.Fail != nil {
+		config.FailOnKEV = *o.Fail
+	}
+	if o.FailOn != nil {
+		config.FailOn = o.FailOn
+	}
+	if o.SkipDirs != nil {
+		config.SkipDirs = o.SkipDirs
+	}
+	if o.ScanVendored != nil {
+		config.ScanVendored = *o.ScanVendored
+	}
+	if o.RequirementsFiles != nil {
+		config.RequirementsPatterns = o.RequirementsFiles
+	}
+	return config
+}
+
+// resolvePath makes a relative local path relative to base; URLs and empty
+// paths are returned unchanged
+func resolvePath(base, path string) string {
+	if path == "" || filepath.IsAbs(path) || strings.Contains(path, "://") {
+		return path
+	}
+	return filepath.Join(base, path)
+}
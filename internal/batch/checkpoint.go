@@ -0,0 +1,117 @@
+package batch
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/ethanolivertroy/kev-check-demo/internal/models"
+)
+
+// Checkpoint records the results of the targets a batch run has finished, so
+// an interrupted run resumes with the remaining targets. A target's entry is
+// only reused while its settings, and those of the run, are unchanged.
+type Checkpoint struct {
+	path    string
+	Targets map[string]CheckpointEntry `json:"targets"`
+}
+
+// CheckpointEntry is a finished target's results
+type CheckpointEntry struct {
+	Key        string          `json:"key"` // Digest of the settings it was scanned with
+	FinishedAt time.Time       `json:"finished_at"`
+	Result     json.RawMessage `json:"result"`
+}
+
+// OpenCheckpoint reads the checkpoint at path, or starts an empty one if the
+// file doesn't exist
+func OpenCheckpoint(path string) (*Checkpoint, error) {
+	c := &Checkpoint{path: path, Targets: make(map[string]CheckpointEntry)}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return c, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read checkpoint: %w", err)
+	}
+	if err := json.Unmarshal(data, c); err != nil {
+		return nil, fmt.Errorf("invalid checkpoint %s (delete it to start over): %w", path, err)
+	}
+	if c.Targets == nil {
+		c.Targets = make(map[string]CheckpointEntry)
+	}
+	return c, nil
+}
+
+// Lookup returns the recorded results of a target scanned with the settings
+// digested in key. A nil checkpoint has none.
+func (c *Checkpoint) Lookup(name, key string) (json.RawMessage, bool) {
+	if c == nil {
+		return nil, false
+	}
+	entry, ok := c.Targets[name]
+	if !ok || entry.Key != key {
+		return nil, false
+	}
+	return entry.Result, true
+}
+
+// Record stores a finished target's results and saves the checkpoint
+func (c *Checkpoint) Record(name, key string, result any) error {
+	data, err := json.Marshal(result)
+	if err != nil {
+		return err
+	}
+	c.Targets[name] = CheckpointEntry{Key: key, FinishedAt: time.Now().UTC(), Result: data}
+	return c.save()
+}
+
+// Remove deletes the checkpoint once the batch is complete
+func (c *Checkpoint) Remove() error {
+	if err := os.Remove(c.path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("failed to remove checkpoint: %w", err)
+	}
+	return nil
+}
+
+// save writes the checkpoint through a temporary file in the same directory,
+// so an interruption never leaves it truncated
+func (c *Checkpoint) save() error {
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(c.path), ".kev-checker-checkpoint-*")
+	if err != nil {
+		return fmt.Errorf("failed to write checkpoint: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write checkpoint: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write checkpoint: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), c.path); err != nil {
+		return fmt.Errorf("failed to write checkpoint: %w", err)
+	}
+	return nil
+}
+
+// TargetKey digests the settings a target is scanned with: the run's config,
+// the file's defaults and the target itself
+func (f *File) TargetKey(config models.Config, t Target) (string, error) {
+	data, err := json.Marshal([]any{config, f.Defaults, t})
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
@@ -17,14 +17,22 @@ type Cache struct {
 // DefaultTTL is the default cache time-to-live
 const DefaultTTL = 24 * time.Hour
 
-// New creates a new cache with the specified app name
+// DirEnv overrides the cache directory, e.g. to bake a warmed cache into a
+// container image at a fixed path
+const DirEnv = "KEV_CHECKER_CACHE_DIR"
+
+// New creates a new cache with the specified app name, in $KEV_CHECKER_CACHE_DIR
+// if set, otherwise ~/.cache/<appName>
 func New(appName string, ttl time.Duration) (*Cache, error) {
-	homeDir, err := os.UserHomeDir()
-	if err != nil {
-		return nil, err
+	cacheDir := os.Getenv(DirEnv)
+	if cacheDir == "" {
+		homeDir, err := os.UserHomeDir()
+		if err != nil {
+			return nil, err
+		}
+		cacheDir = filepath.Join(homeDir, ".cache", appName)
 	}
 
-	cacheDir := filepath.Join(homeDir, ".cache", appName)
 	if err := os.MkdirAll(cacheDir, 0755); err != nil {
 		return nil, err
 	}
@@ -72,6 +80,16 @@ func (c *Cache) Get(key string) ([]byte, bool) {
 	return data, true
 }
 
+// GetStale retrieves data from cache regardless of age, for use when a fresh
+// copy can't be fetched
+func (c *Cache) GetStale(key string) ([]byte, bool) {
+	data, err := os.ReadFile(c.Path(key))
+	if err != nil {
+		return nil, false
+	}
+	return data, true
+}
+
 // Set stores data in the cache
 func (c *Cache) Set(key string, data []byte) error {
 	path := c.Path(key)
@@ -0,0 +1,72 @@
+package catalog
+
+import (
+	"sort"
+	"time"
+
+	"github.com/ethanolivertroy/kev-check-demo/internal/models"
+)
+
+// Diff lists the changes between two KEV catalog releases
+type Diff struct {
+	Added          []models.KEVInfo
+	DueDateChanges []DueDateChange
+	NewRansomware  []models.KEVInfo
+}
+
+// DueDateChange is a KEV whose remediation due date moved
+type DueDateChange struct {
+	KEV    models.KEVInfo
+	OldDue time.Time
+}
+
+// Compare returns entries added to current since previous, entries whose due
+// date changed, and entries newly designated as used in ransomware campaigns
+func Compare(previous, current map[string]models.KEVInfo) Diff {
+	var d Diff
+	for id, kev := range current {
+		old, existed := previous[id]
+		if !existed {
+			d.Added = append(d.Added, kev)
+			continue
+		}
+		if !old.DueDate.Equal(kev.DueDate) {
+			d.DueDateChanges = append(d.DueDateChanges, DueDateChange{KEV: kev, OldDue: old.DueDate})
+		}
+		if kev.RansomwareUse && !old.RansomwareUse {
+			d.NewRansomware = append(d.NewRansomware, kev)
+		}
+	}
+	d.sort()
+	return d
+}
+
+// AddedSince returns a diff of the entries added to the catalog on or after
+// since, for when no earlier release is available to compare against
+func AddedSince(current map[string]models.KEVInfo, since time.Time) Diff {
+	var d Diff
+	for _, kev := range current {
+		if !kev.DateAdded.Before(since) {
+			d.Added = append(d.Added, kev)
+		}
+	}
+	d.sort()
+	return d
+}
+
+// sort orders entries newest first, then by CVE ID
+func (d *Diff) sort() {
+	byAdded := func(list []models.KEVInfo) {
+		sort.Slice(list, func(i, j int) bool {
+			if !list[i].DateAdded.Equal(list[j].DateAdded) {
+				return list[i].DateAdded.After(list[j].DateAdded)
+			}
+			return list[i].CVEID < list[j].CVEID
+		})
+	}
+	byAdded(d.Added)
+	byAdded(d.NewRansomware)
+	sort.Slice(d.DueDateChanges, func(i, j int) bool {
+		return d.DueDateChanges[i].KEV.CVEID < d.DueDateChanges[j].KEV.CVEID
+	})
+}
@@ -0,0 +1,141 @@
+package ci
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Environment describes the CI system the scan is running in
+type Environment struct {
+	Provider    string // "github-actions", "gitlab", "jenkins", "circleci", "teamcity", "azure-devops"
+	Workspace   string // Repository checkout directory
+	Annotations string // Output format for inline annotations, "" if none
+	Repository  string // Repository URL or slug
+	Commit      string
+	Branch      string
+	Job         string
+	RunID       string
+	RunURL      string
+}
+
+// Detect returns the CI environment from well-known variables, or nil when
+// not running in a recognized CI system
+func Detect() *Environment {
+	switch {
+	case os.Getenv("GITHUB_ACTIONS") == "true":
+		env := &Environment{
+			Provider:    "github-actions",
+			Workspace:   os.Getenv("GITHUB_WORKSPACE"),
+			Annotations: "github-actions",
+			Commit:      os.Getenv("GITHUB_SHA"),
+			Branch:      os.Getenv("GITHUB_REF_NAME"),
+			Job:         os.Getenv("GITHUB_WORKFLOW"),
+			RunID:       os.Getenv("GITHUB_RUN_ID"),
+		}
+		if server, repo := os.Getenv("GITHUB_SERVER_URL"), os.Getenv("GITHUB_REPOSITORY"); server != "" && repo != "" {
+			env.Repository = server + "/" + repo
+			if env.RunID != "" {
+				env.RunURL = env.Repository + "/actions/runs/" + env.RunID
+			}
+		}
+		return env
+	case os.Getenv("GITLAB_CI") == "true":
+		return &Environment{
+			Provider:   "gitlab",
+			Workspace:  os.Getenv("CI_PROJECT_DIR"),
+			Repository: os.Getenv("CI_PROJECT_URL"),
+			Commit:     os.Getenv("CI_COMMIT_SHA"),
+			Branch:     os.Getenv("CI_COMMIT_REF_NAME"),
+			Job:        os.Getenv("CI_JOB_NAME"),
+			RunID:      os.Getenv("CI_PIPELINE_ID"),
+			RunURL:     os.Getenv("CI_JOB_URL"),
+		}
+	case os.Getenv("JENKINS_URL") != "":
+		return &Environment{
+			Provider:   "jenkins",
+			Workspace:  os.Getenv("WORKSPACE"),
+			Repository: os.Getenv("GIT_URL"),
+			Commit:     os.Getenv("GIT_COMMIT"),
+			Branch:     os.Getenv("GIT_BRANCH"),
+			Job:        os.Getenv("JOB_NAME"),
+			RunID:      os.Getenv("BUILD_ID"),
+			RunURL:     os.Getenv("BUILD_URL"),
+		}
+	case os.Getenv("CIRCLECI") == "true":
+		return &Environment{
+			Provider:   "circleci",
+			Workspace:  expandHome(os.Getenv("CIRCLE_WORKING_DIRECTORY")),
+			Repository: os.Getenv("CIRCLE_REPOSITORY_URL"),
+			Commit:     os.Getenv("CIRCLE_SHA1"),
+			Branch:     os.Getenv("CIRCLE_BRANCH"),
+			Job:        os.Getenv("CIRCLE_JOB"),
+			RunID:      os.Getenv("CIRCLE_BUILD_NUM"),
+			RunURL:     os.Getenv("CIRCLE_BUILD_URL"),
+		}
+	case os.Getenv("TEAMCITY_VERSION") != "":
+		return &Environment{
+			Provider:    "teamcity",
+			Annotations: "teamcity",
+			Job:         os.Getenv("TEAMCITY_BUILDCONF_NAME"),
+			RunID:       os.Getenv("BUILD_NUMBER"),
+		}
+	case strings.EqualFold(os.Getenv("TF_BUILD"), "true"):
+		return &Environment{
+			Provider:    "azure-devops",
+			Workspace:   os.Getenv("BUILD_SOURCESDIRECTORY"),
+			Annotations: "azure-devops",
+			Repository:  os.Getenv("BUILD_REPOSITORY_URI"),
+			Commit:      os.Getenv("BUILD_SOURCEVERSION"),
+			Branch:      os.Getenv("BUILD_SOURCEBRANCHNAME"),
+			Job:         os.Getenv("BUILD_DEFINITIONNAME"),
+			RunID:       os.Getenv("BUILD_BUILDID"),
+		}
+	}
+	return nil
+}
+
+// RelPath returns path relative to the workspace using forward slashes, or
+// path unchanged if there is no workspace or it lies outside
+func (e *Environment) RelPath(path string) string {
+	if e == nil || e.Workspace == "" {
+		return path
+	}
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return path
+	}
+	root, err := filepath.Abs(e.Workspace)
+	if err != nil {
+		return path
+	}
+	rel, err := filepath.Rel(root, abs)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return path
+	}
+	return filepath.ToSlash(rel)
+}
+
+// AutomationID identifies this run for SARIF automationDetails, as
+// "kev-checker/<job>/<run>"
+func (e *Environment) AutomationID() string {
+	job := e.Job
+	if job == "" {
+		job = e.Provider
+	}
+	id := "kev-checker/" + job + "/"
+	if e.RunID != "" {
+		id += e.RunID
+	}
+	return id
+}
+
+// expandHome expands a leading ~ as CircleCI leaves it unexpanded
+func expandHome(path string) string {
+	if path == "~" || strings.HasPrefix(path, "~/") {
+		if home, err := os.UserHomeDir(); err == nil {
+			return filepath.Join(home, strings.TrimPrefix(path, "~"))
+		}
+	}
+	return path
+}
@@ -0,0 +1,108 @@
+package clients
+
+import (
+	"crypto/tls"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptrace"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// debugTransport logs request details and timings for every HTTP round trip
+type debugTransport struct {
+	base http.RoundTripper
+	out  io.Writer
+	mu   sync.Mutex
+	seq  atomic.Int64
+}
+
+// EnableHTTPDebug wraps the default transport so that every client request
+// logs its URL, status, response size and connection timings to out
+func EnableHTTPDebug(out io.Writer) {
+	http.DefaultTransport = &debugTransport{base: http.DefaultTransport, out: out}
+}
+
+func (t *debugTransport) logf(format string, args ...interface{}) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	fmt.Fprintf(t.out, format+"\n", args...)
+}
+
+func (t *debugTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	id := t.seq.Add(1)
+	start := time.Now()
+	var dnsStart, connStart, tlsStart time.Time
+
+	trace := &httptrace.ClientTrace{
+		GetConn: func(hostPort string) {
+			t.logf("[http %d] %s %s", id, req.Method, req.URL.Redacted())
+		},
+		GotConn: func(info httptrace.GotConnInfo) {
+			if info.Reused {
+				t.logf("[http %d] reused connection to %s", id, info.Conn.RemoteAddr())
+			}
+		},
+		DNSStart: func(httptrace.DNSStartInfo) { dnsStart = time.Now() },
+		DNSDone: func(info httptrace.DNSDoneInfo) {
+			if info.Err != nil {
+				t.logf("[http %d] dns error after %s: %v", id, time.Since(dnsStart), info.Err)
+				return
+			}
+			t.logf("[http %d] dns %s -> %v", id, time.Since(dnsStart), info.Addrs)
+		},
+		ConnectStart: func(network, addr string) { connStart = time.Now() },
+		ConnectDone: func(network, addr string, err error) {
+			if err != nil {
+				t.logf("[http %d] connect %s failed after %s: %v", id, addr, time.Since(connStart), err)
+				return
+			}
+			t.logf("[http %d] connect %s %s", id, addr, time.Since(connStart))
+		},
+		TLSHandshakeStart: func() { tlsStart = time.Now() },
+		TLSHandshakeDone: func(state tls.ConnectionState, err error) {
+			if err != nil {
+				t.logf("[http %d] tls handshake failed after %s: %v", id, time.Since(tlsStart), err)
+				return
+			}
+			t.logf("[http %d] tls %s %s server=%s", id, time.Since(tlsStart),
+				tls.VersionName(state.Version), state.ServerName)
+		},
+		GotFirstResponseByte: func() {
+			t.logf("[http %d] first byte after %s", id, time.Since(start))
+		},
+	}
+
+	resp, err := t.base.RoundTrip(req.WithContext(httptrace.WithClientTrace(req.Context(), trace)))
+	if err != nil {
+		t.logf("[http %d] error after %s: %v", id, time.Since(start), err)
+		return nil, err
+	}
+
+	t.logf("[http %d] status %d after %s (content-length %d)", id, resp.StatusCode, time.Since(start), resp.ContentLength)
+	resp.Body = &countingBody{ReadCloser: resp.Body, onClose: func(n int64) {
+		t.logf("[http %d] read %d bytes, total %s", id, n, time.Since(start))
+	}}
+	return resp, nil
+}
+
+// countingBody reports the number of bytes read when closed
+type countingBody struct {
+	io.ReadCloser
+	n       int64
+	once    sync.Once
+	onClose func(int64)
+}
+
+func (b *countingBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.n += int64(n)
+	return n, err
+}
+
+func (b *countingBody) Close() error {
+	b.once.Do(func() { b.onClose(b.n) })
+	return b.ReadCloser.Close()
+}
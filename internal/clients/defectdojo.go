@@ -0,0 +1,82 @@
+package clients
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// DefectDojoClient pushes scan results to a DefectDojo instance
+type DefectDojoClient struct {
+	httpClient *http.Client
+	baseURL    string
+	token      string
+}
+
+// NewDefectDojoClient creates a new DefectDojo client
+func NewDefectDojoClient(baseURL, token string) *DefectDojoClient {
+	return &DefectDojoClient{
+		httpClient: &http.Client{Timeout: 120 * time.Second},
+		baseURL:    strings.TrimSuffix(baseURL, "/"),
+		token:      token,
+	}
+}
+
+// ReimportScan uploads a Generic Findings Import report to the given product and
+// engagement. DefectDojo creates the product/engagement if needed, updates
+// matching findings, and closes findings that are no longer present.
+func (c *DefectDojoClient) ReimportScan(product, engagement string, report []byte) error {
+	var body bytes.Buffer
+	w := multipart.NewWriter(&body)
+
+	fields := map[string]string{
+		"scan_type":           "Generic Findings Import",
+		"product_name":        product,
+		"engagement_name":     engagement,
+		"auto_create_context": "true",
+		"close_old_findings":  "true",
+		"active":              "true",
+		"verified":            "false",
+		"scan_date":           time.Now().Format("2006-01-02"),
+	}
+	for k, v := range fields {
+		if err := w.WriteField(k, v); err != nil {
+			return err
+		}
+	}
+
+	part, err := w.CreateFormFile("file", "kev-checker.json")
+	if err != nil {
+		return err
+	}
+	if _, err := part.Write(report); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, c.baseURL+"/api/v2/reimport-scan/", &body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", w.FormDataContentType())
+	req.Header.Set("Authorization", "Token "+c.token)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to push to DefectDojo: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("DefectDojo API returned status %d: %s", resp.StatusCode, strings.TrimSpace(string(msg)))
+	}
+
+	return nil
+}
@@ -0,0 +1,91 @@
+package clients
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/ethanolivertroy/kev-check-demo/internal/models"
+)
+
+const depsDevURL = "https://api.deps.dev/v3/systems"
+
+// DepsDevClient handles requests to the deps.dev package metadata API
+type DepsDevClient struct {
+	httpClient *http.Client
+}
+
+// NewDepsDevClient creates a new deps.dev client
+func NewDepsDevClient() *DepsDevClient {
+	return &DepsDevClient{
+		httpClient: &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+type depsDevPackage struct {
+	Versions []struct {
+		VersionKey struct {
+			Version string `json:"version"`
+		} `json:"versionKey"`
+		PublishedAt time.Time `json:"publishedAt"`
+		IsDefault   bool      `json:"isDefault"`
+	} `json:"versions"`
+}
+
+type depsDevVersion struct {
+	IsDeprecated     bool   `json:"isDeprecated"`
+	DeprecatedReason string `json:"deprecatedReason"`
+}
+
+// FetchPackageHealth returns release and deprecation metadata for a dependency
+func (c *DepsDevClient) FetchPackageHealth(dep models.Dependency) (*models.PackageHealth, error) {
+	info := dep.Ecosystem.Info()
+	system := info.DepsDev
+	if system == "" {
+		return nil, fmt.Errorf("ecosystem %s not supported by deps.dev", dep.Ecosystem)
+	}
+
+	pkgURL := fmt.Sprintf("%s/%s/packages/%s", depsDevURL, system, url.PathEscape(dep.Name))
+
+	var pkg depsDevPackage
+	if err := c.getJSON(pkgURL, &pkg); err != nil {
+		return nil, err
+	}
+
+	health := &models.PackageHealth{}
+	for _, v := range pkg.Versions {
+		if v.PublishedAt.After(health.LastRelease) {
+			health.LastRelease = v.PublishedAt
+		}
+		if v.IsDefault {
+			health.LatestVersion = v.VersionKey.Version
+		}
+	}
+
+	if dep.Version != "" {
+		var ver depsDevVersion
+		verURL := fmt.Sprintf("%s/versions/%s", pkgURL, url.PathEscape(info.RegistryVersion(dep.Version)))
+		if err := c.getJSON(verURL, &ver); err == nil {
+			health.Deprecated = ver.IsDeprecated
+			health.DeprecatedReason = ver.DeprecatedReason
+		}
+	}
+
+	return health, nil
+}
+
+func (c *DepsDevClient) getJSON(u string, v interface{}) error {
+	resp, err := c.httpClient.Get(u)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("deps.dev API returned status %d", resp.StatusCode)
+	}
+
+	return json.NewDecoder(resp.Body).Decode(v)
+}
@@ -8,20 +8,28 @@ import (
 	"strings"
 	"time"
 
+	"github.com/ethanolivertroy/kev-check-demo/internal/cache"
 	"github.com/ethanolivertroy/kev-check-demo/internal/models"
 )
 
 const epssURL = "https://api.first.org/data/v1/epss"
 
+// epssBatchSize is how many CVEs are requested at once, to avoid URL length
+// issues
+const epssBatchSize = 100
+
 // EPSSClient handles requests to the EPSS API
 type EPSSClient struct {
 	httpClient *http.Client
+	cache      *cache.Cache
 }
 
-// NewEPSSClient creates a new EPSS client
-func NewEPSSClient() *EPSSClient {
+// NewEPSSClient creates a new EPSS client. Scores are cached in c if it is
+// non-nil.
+func NewEPSSClient(c *cache.Cache) *EPSSClient {
 	return &EPSSClient{
 		httpClient: &http.Client{Timeout: 30 * time.Second},
+		cache:      c,
 	}
 }
 
@@ -43,7 +51,8 @@ type EPSSData struct {
 }
 
 // FetchScores fetches EPSS scores for the given CVE IDs
-// Returns a map of CVE ID -> EPSSScore
+// Returns a map of CVE ID -> EPSSScore. Cached scores are reused within the
+// cache TTL, and older ones are used for CVEs the API doesn't answer for.
 func (c *EPSSClient) FetchScores(cveIDs []string) (map[string]models.EPSSScore, error) {
 	scores := make(map[string]models.EPSSScore)
 
@@ -51,10 +60,67 @@ func (c *EPSSClient) FetchScores(cveIDs []string) (map[string]models.EPSSScore,
 		return scores, nil
 	}
 
-	// EPSS API allows batch queries, chunk to avoid URL length issues
-	const chunkSize = 100
-	for i := 0; i < len(cveIDs); i += chunkSize {
-		end := i + chunkSize
+	cached, fresh := c.cachedScores()
+	var missing []string
+	for _, id := range cveIDs {
+		if score, ok := cached[id]; ok && fresh {
+			scores[id] = score
+		} else {
+			missing = append(missing, id)
+		}
+	}
+	if len(missing) == 0 {
+		return scores, nil
+	}
+
+	// Don't fail completely on EPSS errors, just skip
+	fetched, _ := c.query(missing)
+	for _, id := range missing {
+		if score, ok := fetched[id]; ok {
+			scores[id] = score
+		} else if score, ok := cached[id]; ok {
+			scores[id] = score
+		}
+	}
+
+	if len(fetched) > 0 {
+		// Only fresh scores may be kept alongside the new ones, since storing
+		// resets the cache age
+		if fresh {
+			for id, score := range fetched {
+				cached[id] = score
+			}
+			fetched = cached
+		}
+		c.storeScores(fetched)
+	}
+	return scores, nil
+}
+
+// RefreshScores fetches and caches scores for all the given CVE IDs,
+// replacing previously cached scores. It returns the number of scores cached.
+func (c *EPSSClient) RefreshScores(cveIDs []string) (int, error) {
+	scores, err := c.query(cveIDs)
+	if len(scores) == 0 {
+		if err == nil {
+			err = fmt.Errorf("no EPSS scores returned")
+		}
+		return 0, err
+	}
+	if err := c.storeScores(scores); err != nil {
+		return 0, err
+	}
+	return len(scores), nil
+}
+
+// query fetches scores from the API, returning those it got and the last
+// error, if any
+func (c *EPSSClient) query(cveIDs []string) (map[string]models.EPSSScore, error) {
+	scores := make(map[string]models.EPSSScore)
+	var lastErr error
+
+	for i := 0; i < len(cveIDs); i += epssBatchSize {
+		end := i + epssBatchSize
 		if end > len(cveIDs) {
 			end = len(cveIDs)
 		}
@@ -63,18 +129,20 @@ func (c *EPSSClient) FetchScores(cveIDs []string) (map[string]models.EPSSScore,
 		url := fmt.Sprintf("%s?cve=%s", epssURL, strings.Join(chunk, ","))
 		resp, err := c.httpClient.Get(url)
 		if err != nil {
-			// Don't fail completely on EPSS errors, just skip
+			lastErr = fmt.Errorf("failed to fetch EPSS scores: %w", err)
 			continue
 		}
 
 		if resp.StatusCode != http.StatusOK {
 			resp.Body.Close()
+			lastErr = fmt.Errorf("unexpected EPSS status code: %d", resp.StatusCode)
 			continue
 		}
 
 		var epssResp EPSSResponse
 		if err := json.NewDecoder(resp.Body).Decode(&epssResp); err != nil {
 			resp.Body.Close()
+			lastErr = fmt.Errorf("failed to parse EPSS response: %w", err)
 			continue
 		}
 		resp.Body.Close()
@@ -89,5 +157,38 @@ func (c *EPSSClient) FetchScores(cveIDs []string) (map[string]models.EPSSScore,
 		}
 	}
 
-	return scores, nil
+	return scores, lastErr
+}
+
+// cachedScores returns the cached scores of any age, and whether they are
+// within the cache TTL
+func (c *EPSSClient) cachedScores() (map[string]models.EPSSScore, bool) {
+	scores := make(map[string]models.EPSSScore)
+	if c.cache == nil {
+		return scores, false
+	}
+	data, fresh := c.cache.Get(epssURL)
+	if !fresh {
+		var ok bool
+		if data, ok = c.cache.GetStale(epssURL); !ok {
+			return scores, false
+		}
+	}
+	if err := json.Unmarshal(data, &scores); err != nil {
+		return make(map[string]models.EPSSScore), false
+	}
+	return scores, fresh
+}
+
+// storeScores caches scores by CVE ID. Map keys are marshaled in sorted
+// order, so the same scores always produce the same file.
+func (c *EPSSClient) storeScores(scores map[string]models.EPSSScore) error {
+	if c.cache == nil {
+		return nil
+	}
+	data, err := json.Marshal(scores)
+	if err != nil {
+		return err
+	}
+	return c.cache.Set(epssURL, data)
 }
@@ -0,0 +1,160 @@
+package clients
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+)
+
+// fixture is a recorded HTTP response, stored in a fixture directory as
+// <key>.json where key hashes the request
+type fixture struct {
+	Method      string `json:"method"`
+	URL         string `json:"url"` // Without credentials, for reading
+	Status      int    `json:"status"`
+	ContentType string `json:"content_type,omitempty"`
+	Body        string `json:"body,omitempty"`        // UTF-8 bodies
+	BodyBase64  []byte `json:"body_base64,omitempty"` // Other bodies
+}
+
+// recordTransport saves every response it receives to a fixture directory
+type recordTransport struct {
+	base http.RoundTripper
+	dir  string
+}
+
+// replayTransport answers requests from a fixture directory without network
+// access
+type replayTransport struct {
+	dir string
+}
+
+// EnableHTTPRecord wraps the default transport so that every client response
+// is also written to dir, creating it if needed
+func EnableHTTPRecord(dir string) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create record directory: %w", err)
+	}
+	http.DefaultTransport = &recordTransport{base: http.DefaultTransport, dir: dir}
+	return nil
+}
+
+// EnableHTTPReplay replaces the default transport with one that answers
+// client requests from responses recorded in dir. Requests that weren't
+// recorded fail.
+func EnableHTTPReplay(dir string) error {
+	info, err := os.Stat(dir)
+	if err != nil {
+		return fmt.Errorf("failed to open replay directory: %w", err)
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("replay path %s is not a directory", dir)
+	}
+	http.DefaultTransport = &replayTransport{dir: dir}
+	return nil
+}
+
+func (t *recordTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	key, err := fixtureKey(req)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	f := fixture{
+		Method:      req.Method,
+		URL:         req.URL.Redacted(),
+		Status:      resp.StatusCode,
+		ContentType: resp.Header.Get("Content-Type"),
+	}
+	if utf8.Valid(body) {
+		f.Body = string(body)
+	} else {
+		f.BodyBase64 = body
+	}
+	data, err := json.MarshalIndent(f, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(filepath.Join(t.dir, key+".json"), append(data, '\n'), 0644); err != nil {
+		return nil, fmt.Errorf("failed to record response: %w", err)
+	}
+	return resp, nil
+}
+
+func (t *replayTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	key, err := fixtureKey(req)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(filepath.Join(t.dir, key+".json"))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("no recorded response for %s %s in %s", req.Method, req.URL.Redacted(), t.dir)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read recorded response: %w", err)
+	}
+
+	var f fixture
+	if err := json.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("failed to parse recorded response %s: %w", key, err)
+	}
+	body := f.BodyBase64
+	if body == nil {
+		body = []byte(f.Body)
+	}
+	header := make(http.Header)
+	if f.ContentType != "" {
+		header.Set("Content-Type", f.ContentType)
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", f.Status, http.StatusText(f.Status)),
+		StatusCode:    f.Status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}, nil
+}
+
+// fixtureKey identifies a request by its method, URL and body. The body is
+// read and restored so the request can still be sent.
+func fixtureKey(req *http.Request) (string, error) {
+	var body []byte
+	if req.Body != nil {
+		var err error
+		if body, err = io.ReadAll(req.Body); err != nil {
+			return "", err
+		}
+		req.Body.Close()
+		req.Body = io.NopCloser(bytes.NewReader(body))
+	}
+
+	h := sha256.New()
+	io.WriteString(h, strings.ToUpper(req.Method)+" "+req.URL.String()+"\n")
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil)[:16]), nil
+}
@@ -5,6 +5,10 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/ethanolivertroy/kev-check-demo/internal/cache"
@@ -13,10 +17,22 @@ import (
 
 const kevURL = "https://raw.githubusercontent.com/cisagov/kev-data/main/known_exploited_vulnerabilities.json"
 
+// OverlayCatalogName labels entries from an organizational catalog overlay
+// that doesn't set its own title
+const OverlayCatalogName = "Organizational"
+
 // KEVClient handles requests to the CISA KEV catalog
 type KEVClient struct {
 	httpClient *http.Client
 	cache      *cache.Cache
+	info       KEVCatalogInfo
+}
+
+// KEVCatalogInfo identifies the catalog release used for a scan
+type KEVCatalogInfo struct {
+	Version      string
+	DateReleased string
+	Count        int
 }
 
 // NewKEVClient creates a new KEV client
@@ -62,42 +78,225 @@ func (c *KEVClient) FetchKEVCatalog() (map[string]models.KEVInfo, error) {
 		}
 	}
 
-	// Fetch from remote if not cached
+	// Fetch from remote if not cached, falling back to an expired copy (e.g.
+	// one baked into an image by cache warm) when offline
 	if data == nil {
-		resp, err := c.httpClient.Get(kevURL)
+		fetched, err := c.fetch()
 		if err != nil {
-			return nil, fmt.Errorf("failed to fetch KEV data: %w", err)
+			stale, ok := c.staleCatalog()
+			if !ok {
+				return nil, err
+			}
+			fetched = stale
+		} else if c.cache != nil {
+			c.cache.Set(kevURL, fetched)
+		}
+		data = fetched
+	}
+	return c.load(data)
+}
+
+// RefreshKEVCatalog fetches the catalog even if a cached copy is still fresh,
+// and caches it
+func (c *KEVClient) RefreshKEVCatalog() (map[string]models.KEVInfo, error) {
+	data, err := c.fetch()
+	if err != nil {
+		return nil, err
+	}
+	if c.cache != nil {
+		if err := c.cache.Set(kevURL, data); err != nil {
+			return nil, fmt.Errorf("failed to cache KEV data: %w", err)
 		}
-		defer resp.Body.Close()
+	}
+	return c.load(data)
+}
+
+// load parses catalog data and records its release
+func (c *KEVClient) load(data []byte) (map[string]models.KEVInfo, error) {
+	catalog, info, err := parseKEVData(data, "CISA")
+	if err != nil {
+		return nil, err
+	}
+	c.info = info
+
+	// Keep every release for catalog diffs; non-fatal
+	if c.cache != nil && info.Version != "" {
+		_ = saveSnapshot(c.cache, info.Version, data)
+	}
+	return catalog, nil
+}
+
+// fetch downloads the current catalog
+func (c *KEVClient) fetch() ([]byte, error) {
+	resp, err := c.httpClient.Get(kevURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch KEV data: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+	return data, nil
+}
+
+// staleCatalog returns the cached catalog regardless of age. The catalog's
+// release date still triggers the stale-catalog warning.
+func (c *KEVClient) staleCatalog() ([]byte, bool) {
+	if c.cache == nil {
+		return nil, false
+	}
+	return c.cache.GetStale(kevURL)
+}
+
+// snapshotDir holds one copy of each KEV catalog release seen, by version
+func snapshotDir(c *cache.Cache) string {
+	return filepath.Join(c.Dir, "kev-catalogs")
+}
+
+func saveSnapshot(c *cache.Cache, version string, data []byte) error {
+	path := filepath.Join(snapshotDir(c), snapshotFile(version))
+	if _, err := os.Stat(path); err == nil {
+		return nil
+	}
+	if err := os.MkdirAll(snapshotDir(c), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+// snapshotFile turns a catalog version into a file name
+func snapshotFile(version string) string {
+	return strings.NewReplacer("/", "_", "\\", "_", ":", "_").Replace(version) + ".json"
+}
 
-		if resp.StatusCode != http.StatusOK {
-			return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+// KEVSnapshots lists the catalog releases kept in the cache, oldest first
+func KEVSnapshots(c *cache.Cache) ([]KEVCatalogInfo, error) {
+	entries, err := os.ReadDir(snapshotDir(c))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
 		}
+		return nil, fmt.Errorf("failed to list KEV catalog snapshots: %w", err)
+	}
 
-		data, err = io.ReadAll(resp.Body)
+	var infos []KEVCatalogInfo
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasSuffix(e.Name(), ".json") {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(snapshotDir(c), e.Name()))
 		if err != nil {
-			return nil, fmt.Errorf("failed to read response body: %w", err)
+			continue
 		}
+		if _, info, err := parseKEVData(data, "CISA"); err == nil {
+			infos = append(infos, info)
+		}
+	}
+	sort.Slice(infos, func(i, j int) bool {
+		return infos[i].Released().Before(infos[j].Released())
+	})
+	return infos, nil
+}
 
-		// Cache the response
-		if c.cache != nil {
-			c.cache.Set(kevURL, data)
+// LoadKEVSnapshot reads a cached catalog release by version
+func LoadKEVSnapshot(c *cache.Cache, version string) (map[string]models.KEVInfo, KEVCatalogInfo, error) {
+	data, err := os.ReadFile(filepath.Join(snapshotDir(c), snapshotFile(version)))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, KEVCatalogInfo{}, fmt.Errorf("no cached KEV catalog version %s", version)
 		}
+		return nil, KEVCatalogInfo{}, fmt.Errorf("failed to read KEV catalog snapshot: %w", err)
 	}
+	return parseKEVData(data, "CISA")
+}
+
+// Info returns the release metadata of the last fetched catalog
+func (c *KEVClient) Info() KEVCatalogInfo {
+	return c.info
+}
 
-	return c.parseKEVData(data)
+// Released parses DateReleased, returning the zero time if it is missing or
+// malformed
+func (i KEVCatalogInfo) Released() time.Time {
+	t, err := time.Parse(time.RFC3339Nano, i.DateReleased)
+	if err != nil {
+		return time.Time{}
+	}
+	return t
 }
 
-func (c *KEVClient) parseKEVData(data []byte) (map[string]models.KEVInfo, error) {
+// LoadKEVOverlay reads an organizational catalog in the CISA KEV JSON format.
+// Entries are labelled with the file's title, or "Organizational" if unset.
+func LoadKEVOverlay(path string) (map[string]models.KEVInfo, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read KEV overlay: %w", err)
+	}
+
+	var header struct {
+		Title string `json:"title"`
+	}
+	if err := json.Unmarshal(data, &header); err != nil {
+		return nil, fmt.Errorf("failed to parse KEV overlay: %w", err)
+	}
+	label := strings.TrimSpace(header.Title)
+	if label == "" {
+		label = OverlayCatalogName
+	}
+
+	catalog, _, err := parseKEVData(data, label)
+	return catalog, err
+}
+
+// MergeKEVOverlay adds overlay entries to the catalog. For CVEs already in the
+// catalog, the overlay's due date, required action and ransomware flag take
+// precedence when set, and both sources are listed in the label.
+func MergeKEVOverlay(catalog, overlay map[string]models.KEVInfo) {
+	for id, o := range overlay {
+		existing, ok := catalog[id]
+		if !ok {
+			catalog[id] = o
+			continue
+		}
+		if !o.DueDate.IsZero() {
+			existing.DueDate = o.DueDate
+		}
+		if o.RequiredAction != "" {
+			existing.RequiredAction = o.RequiredAction
+		}
+		if o.RansomwareUse {
+			existing.RansomwareUse = true
+		}
+		if o.Notes != "" {
+			existing.Notes = o.Notes
+		}
+		existing.Catalog = existing.Catalog + ", " + o.Catalog
+		catalog[id] = existing
+	}
+}
+
+func parseKEVData(data []byte, label string) (map[string]models.KEVInfo, KEVCatalogInfo, error) {
 	var kevResp KEVResponse
 	if err := json.Unmarshal(data, &kevResp); err != nil {
-		return nil, fmt.Errorf("failed to parse KEV data: %w", err)
+		return nil, KEVCatalogInfo{}, fmt.Errorf("failed to parse KEV data: %w", err)
+	}
+	info := KEVCatalogInfo{
+		Version:      kevResp.CatalogVersion,
+		DateReleased: kevResp.DateReleased,
+		Count:        len(kevResp.Vulnerabilities),
 	}
 
 	catalog := make(map[string]models.KEVInfo, len(kevResp.Vulnerabilities))
 	for _, v := range kevResp.Vulnerabilities {
 		kev := models.KEVInfo{
 			CVEID:             v.CVEID,
+			Catalog:           label,
 			VendorProject:     v.VendorProject,
 			Product:           v.Product,
 			VulnerabilityName: v.VulnerabilityName,
@@ -112,5 +311,5 @@ func (c *KEVClient) parseKEVData(data []byte) (map[string]models.KEVInfo, error)
 		catalog[v.CVEID] = kev
 	}
 
-	return catalog, nil
+	return catalog, info, nil
 }
@@ -0,0 +1,82 @@
+package clients
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+)
+
+// maxManifestSize caps the size of a manifest fetched over HTTPS
+const maxManifestSize = 32 << 20
+
+// IsManifestURL reports whether a scan path is an HTTPS URL
+func IsManifestURL(p string) bool {
+	return strings.HasPrefix(p, "https://")
+}
+
+// ManifestURLName returns the file name of a manifest URL, used to pick a
+// parser, e.g. "requirements.txt" for https://host/app/requirements.txt?raw=1
+func ManifestURLName(rawURL string) (string, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "", fmt.Errorf("invalid manifest URL %s: %w", RedactURL(rawURL), withoutURL(err))
+	}
+	return path.Base(u.Path), nil
+}
+
+// FetchManifest downloads a raw manifest over HTTPS. Credentials in the URL's
+// userinfo are sent as basic auth.
+func FetchManifest(rawURL string) ([]byte, error) {
+	if !IsManifestURL(rawURL) {
+		return nil, fmt.Errorf("manifest URLs must use https: %s", RedactURL(rawURL))
+	}
+
+	client := &http.Client{Timeout: 60 * time.Second}
+	resp, err := client.Get(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch manifest %s: %w", RedactURL(rawURL), withoutURL(err))
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch manifest %s: unexpected status code: %d", RedactURL(rawURL), resp.StatusCode)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxManifestSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read manifest: %w", err)
+	}
+	if len(data) > maxManifestSize {
+		return nil, fmt.Errorf("manifest %s exceeds %d MB", RedactURL(rawURL), maxManifestSize>>20)
+	}
+	return data, nil
+}
+
+// withoutURL returns the cause of a *url.Error, whose message repeats the
+// full URL, tokens included
+func withoutURL(err error) error {
+	var uerr *url.Error
+	if errors.As(err, &uerr) {
+		return fmt.Errorf("%s: %w", uerr.Op, uerr.Err)
+	}
+	return err
+}
+
+// RedactURL strips userinfo and the query string, which may hold tokens or
+// signatures, from a URL for display. URLs that don't parse are left out
+// entirely.
+func RedactURL(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "<invalid URL>"
+	}
+	u.User = nil
+	u.RawQuery = ""
+	u.Fragment = ""
+	return u.String()
+}
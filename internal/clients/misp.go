@@ -0,0 +1,214 @@
+package clients
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/ethanolivertroy/kev-check-demo/internal/models"
+)
+
+// MISPClient records scan results as a MISP event
+type MISPClient struct {
+	httpClient *http.Client
+	baseURL    string
+	apiKey     string
+}
+
+// NewMISPClient creates a new MISP client
+func NewMISPClient(baseURL, apiKey string) *MISPClient {
+	return &MISPClient{
+		httpClient: &http.Client{Timeout: 60 * time.Second},
+		baseURL:    strings.TrimSuffix(baseURL, "/"),
+		apiKey:     apiKey,
+	}
+}
+
+type mispEvent struct {
+	ID            string          `json:"id,omitempty"`
+	Info          string          `json:"info"`
+	Distribution  string          `json:"distribution,omitempty"`
+	ThreatLevelID string          `json:"threat_level_id,omitempty"`
+	Analysis      string          `json:"analysis,omitempty"`
+	Attribute     []mispAttribute `json:"Attribute,omitempty"`
+	Tag           []mispTag       `json:"Tag,omitempty"`
+}
+
+type mispAttribute struct {
+	Type     string    `json:"type"`
+	Category string    `json:"category"`
+	Value    string    `json:"value"`
+	Comment  string    `json:"comment,omitempty"`
+	ToIDS    bool      `json:"to_ids"`
+	Tag      []mispTag `json:"Tag,omitempty"`
+}
+
+type mispTag struct {
+	Name string `json:"name"`
+}
+
+type mispEventWrapper struct {
+	Event mispEvent `json:"Event"`
+}
+
+// PushFindings creates the event titled info, or adds the attributes it is
+// missing if it already exists. Each affected package becomes a text
+// attribute and each KEV a vulnerability attribute, tagged with its CVE ID.
+// It returns the event ID and the number of attributes added.
+func (c *MISPClient) PushFindings(info string, findings []models.Finding) (string, int, error) {
+	attrs, tags := mispAttributes(findings)
+
+	existing, err := c.findEvent(info)
+	if err != nil {
+		return "", 0, err
+	}
+
+	if existing == nil {
+		event := mispEvent{
+			Info:          info,
+			Distribution:  "0", // Your organisation only
+			ThreatLevelID: mispThreatLevel(findings),
+			Analysis:      "2", // Completed
+			Attribute:     attrs,
+			Tag:           tags,
+		}
+		var created mispEventWrapper
+		if err := c.post("/events/add", mispEventWrapper{Event: event}, &created); err != nil {
+			return "", 0, err
+		}
+		return created.Event.ID, len(attrs), nil
+	}
+
+	have := make(map[string]bool)
+	for _, a := range existing.Attribute {
+		have[a.Type+"|"+a.Value+"|"+a.Comment] = true
+	}
+	added := 0
+	for _, a := range attrs {
+		if have[a.Type+"|"+a.Value+"|"+a.Comment] {
+			continue
+		}
+		if err := c.post("/attributes/add/"+existing.ID, a, nil); err != nil {
+			return "", added, err
+		}
+		added++
+	}
+	return existing.ID, added, nil
+}
+
+// findEvent returns the event with exactly the given info, or nil
+func (c *MISPClient) findEvent(info string) (*mispEvent, error) {
+	query := map[string]any{
+		"returnFormat": "json",
+		"eventinfo":    info,
+	}
+	var result struct {
+		Response []mispEventWrapper `json:"response"`
+	}
+	if err := c.post("/events/restSearch", query, &result); err != nil {
+		return nil, err
+	}
+	// eventinfo is a substring match, so confirm the title
+	for _, r := range result.Response {
+		if r.Event.Info == info {
+			event := r.Event
+			return &event, nil
+		}
+	}
+	return nil, nil
+}
+
+func (c *MISPClient) post(path string, payload, out any) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, c.baseURL+path, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("Authorization", c.apiKey)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to push to MISP: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("MISP API returned status %d: %s", resp.StatusCode, strings.TrimSpace(string(msg)))
+	}
+	if out != nil {
+		if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+			return fmt.Errorf("failed to decode MISP response: %w", err)
+		}
+	}
+	return nil
+}
+
+// mispAttributes converts findings to event attributes and event tags
+func mispAttributes(findings []models.Finding) ([]mispAttribute, []mispTag) {
+	var attrs []mispAttribute
+	seenCVE := make(map[string]bool)
+	ransomware := false
+
+	for _, f := range findings {
+		var cveTags []mispTag
+		for _, kev := range f.KEVs {
+			cveTags = append(cveTags, mispTag{Name: kev.CVEID})
+			if kev.RansomwareUse {
+				ransomware = true
+			}
+			if seenCVE[kev.CVEID] {
+				continue
+			}
+			seenCVE[kev.CVEID] = true
+			attrs = append(attrs, mispAttribute{
+				Type:     "vulnerability",
+				Category: "External analysis",
+				Value:    kev.CVEID,
+				Comment:  kev.VulnerabilityName,
+				Tag:      []mispTag{{Name: kev.CVEID}},
+			})
+		}
+
+		value := string(f.Dependency.Ecosystem) + ":" + f.Dependency.String()
+		if purl := f.Dependency.Purl(); purl != "" {
+			value = purl
+		}
+		attrs = append(attrs, mispAttribute{
+			Type:     "text",
+			Category: "Other",
+			Value:    value,
+			Comment:  "Affected package in " + f.Dependency.SourceFile,
+			Tag:      cveTags,
+		})
+	}
+
+	tags := []mispTag{{Name: "kev-checker"}, {Name: "cisa-kev"}}
+	if ransomware {
+		tags = append(tags, mispTag{Name: "ransomware"})
+	}
+	return attrs, tags
+}
+
+// mispThreatLevel is High (1) when any KEV is used by ransomware, otherwise
+// Medium (2)
+func mispThreatLevel(findings []models.Finding) string {
+	for _, f := range findings {
+		for _, kev := range f.KEVs {
+			if kev.RansomwareUse {
+				return "1"
+			}
+		}
+	}
+	return "2"
+}
@@ -0,0 +1,88 @@
+package clients
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+const opsgenieAlertsURL = "https://api.opsgenie.com/v2/alerts"
+
+// OpsgenieClient creates alerts via the Opsgenie Alert API
+type OpsgenieClient struct {
+	httpClient *http.Client
+	apiKey     string
+}
+
+// NewOpsgenieClient creates a new Opsgenie client for an API integration key
+func NewOpsgenieClient(apiKey string) *OpsgenieClient {
+	return &OpsgenieClient{
+		httpClient: &http.Client{Timeout: 30 * time.Second},
+		apiKey:     apiKey,
+	}
+}
+
+type opsgenieAlert struct {
+	Message     string            `json:"message"`
+	Alias       string            `json:"alias"`
+	Description string            `json:"description,omitempty"`
+	Priority    string            `json:"priority"`
+	Source      string            `json:"source"`
+	Entity      string            `json:"entity,omitempty"`
+	Tags        []string          `json:"tags"`
+	Details     map[string]string `json:"details,omitempty"`
+}
+
+// Name returns the notifier name
+func (c *OpsgenieClient) Name() string {
+	return "Opsgenie"
+}
+
+// Trigger creates an alert; Opsgenie deduplicates open alerts by alias
+func (c *OpsgenieClient) Trigger(dedupKey, summary, component string, details map[string]string) error {
+	alert := opsgenieAlert{
+		Message:  truncate(summary, 130),
+		Alias:    dedupKey,
+		Priority: "P1",
+		Source:   "kev-checker",
+		Entity:   component,
+		Tags:     []string{"kev", "cisa"},
+		Details:  details,
+	}
+	if len(summary) > 130 {
+		alert.Description = summary
+	}
+
+	body, err := json.Marshal(alert)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, opsgenieAlertsURL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "GenieKey "+c.apiKey)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusAccepted {
+		return fmt.Errorf("Opsgenie API returned status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// truncate shortens s to at most n bytes
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return s[:n-3] + "..."
+}
@@ -0,0 +1,181 @@
+package clients
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/ethanolivertroy/kev-check-demo/internal/models"
+)
+
+const ossIndexURL = "https://ossindex.sonatype.org/api/v3/component-report"
+
+// ossIndexBatchSize is the most coordinates OSS Index accepts per request
+const ossIndexBatchSize = 128
+
+// OSSIndexClient handles requests to Sonatype OSS Index
+type OSSIndexClient struct {
+	httpClient *http.Client
+	username   string
+	token      string
+}
+
+// NewOSSIndexClient creates a new OSS Index client. Credentials are read from
+// OSSINDEX_USERNAME and OSSINDEX_TOKEN if set, raising rate limits. A zero
+// timeout means 60 seconds.
+func NewOSSIndexClient(timeout time.Duration) *OSSIndexClient {
+	if timeout <= 0 {
+		timeout = 60 * time.Second
+	}
+	return &OSSIndexClient{
+		httpClient: &http.Client{Timeout: timeout},
+		username:   os.Getenv("OSSINDEX_USERNAME"),
+		token:      os.Getenv("OSSINDEX_TOKEN"),
+	}
+}
+
+type ossIndexRequest struct {
+	Coordinates []string `json:"coordinates"`
+}
+
+type ossIndexComponent struct {
+	Coordinates     string `json:"coordinates"`
+	Vulnerabilities []struct {
+		ID    string `json:"id"`
+		Title string `json:"title"`
+		CVE   string `json:"cve"`
+	} `json:"vulnerabilities"`
+}
+
+// Name returns the source name
+func (c *OSSIndexClient) Name() string {
+	return "OSS Index"
+}
+
+// QueryByPackage implements VulnSource by converting dependencies to purls
+func (c *OSSIndexClient) QueryByPackage(deps []models.Dependency) (map[int][]models.CVEInfo, error) {
+	return c.QueryByPurl(ossIndexPurls(deps))
+}
+
+// PlanByPackage implements Planner, listing the component report requests
+// QueryByPackage would send
+func (c *OSSIndexClient) PlanByPackage(deps []models.Dependency) ([]Request, error) {
+	var requests []Request
+	for _, chunk := range ossIndexChunks(ossIndexPurls(deps)) {
+		body, err := json.Marshal(ossIndexRequest{Coordinates: chunk.purls})
+		if err != nil {
+			return nil, err
+		}
+		requests = append(requests, Request{Method: http.MethodPost, URL: ossIndexURL, Items: len(chunk.purls), Body: body})
+	}
+	return requests, nil
+}
+
+// ossIndexPurls returns the purl of each dependency, or "" for those that
+// can't be queried
+func ossIndexPurls(deps []models.Dependency) []string {
+	purls := make([]string, len(deps))
+	for i, dep := range deps {
+		if dep.Unpinned {
+			continue // Component reports need an exact version
+		}
+		purls[i] = dep.Purl()
+	}
+	return purls
+}
+
+// ossIndexChunk is one request's coordinates and their indices in the purls
+// queried
+type ossIndexChunk struct {
+	purls   []string
+	indices []int
+}
+
+// ossIndexChunks splits purls into requests, skipping empty ones
+func ossIndexChunks(purls []string) []ossIndexChunk {
+	var chunks []ossIndexChunk
+	for i := 0; i < len(purls); i += ossIndexBatchSize {
+		var chunk ossIndexChunk
+		for j := i; j < min(i+ossIndexBatchSize, len(purls)); j++ {
+			if purls[j] != "" {
+				chunk.purls = append(chunk.purls, purls[j])
+				chunk.indices = append(chunk.indices, j)
+			}
+		}
+		if len(chunk.purls) > 0 {
+			chunks = append(chunks, chunk)
+		}
+	}
+	return chunks
+}
+
+// QueryByPurl implements VulnSource
+func (c *OSSIndexClient) QueryByPurl(purls []string) (map[int][]models.CVEInfo, error) {
+	results := make(map[int][]models.CVEInfo)
+
+	for _, chunk := range ossIndexChunks(purls) {
+		components, err := c.queryChunk(chunk.purls)
+		if err != nil {
+			return nil, err
+		}
+
+		byPurl := make(map[string]int, len(chunk.purls))
+		for k, purl := range chunk.purls {
+			byPurl[purl] = chunk.indices[k]
+		}
+		for _, comp := range components {
+			idx, ok := byPurl[comp.Coordinates]
+			if !ok {
+				continue
+			}
+			for _, v := range comp.Vulnerabilities {
+				if v.CVE == "" {
+					continue
+				}
+				results[idx] = append(results[idx], models.CVEInfo{
+					ID:         v.CVE,
+					Summary:    v.Title,
+					Source:     "OSS Index",
+					AdvisoryID: v.ID,
+				})
+			}
+		}
+	}
+
+	return results, nil
+}
+
+func (c *OSSIndexClient) queryChunk(purls []string) ([]ossIndexComponent, error) {
+	body, err := json.Marshal(ossIndexRequest{Coordinates: purls})
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, ossIndexURL, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if c.username != "" {
+		req.SetBasicAuth(c.username, c.token)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("OSS Index API returned status %d", resp.StatusCode)
+	}
+
+	var components []ossIndexComponent
+	if err := json.NewDecoder(resp.Body).Decode(&components); err != nil {
+		return nil, err
+	}
+	return components, nil
+}
@@ -9,43 +9,107 @@ import (
 	"time"
 
 	"github.com/ethanolivertroy/kev-check-demo/internal/models"
+	"golang.org/x/mod/module"
 )
 
-const osvBatchURL = "https://api.osv.dev/v1/querybatch"
+const (
+	osvBatchURL = "https://api.osv.dev/v1/querybatch"
+	osvVulnURL  = "https://api.osv.dev/v1/vulns/"
+
+	// osvBatchSize is how many queries are sent per batch request. The
+	// batch API allows up to 1000, but we'll use 100 for safety.
+	osvBatchSize = 100
+)
 
 // OSVClient handles requests to the OSV vulnerability database
 type OSVClient struct {
 	httpClient *http.Client
 }
 
-// NewOSVClient creates a new OSV client
-func NewOSVClient() *OSVClient {
+// NewOSVClient creates a new OSV client. A zero timeout means 60 seconds.
+func NewOSVClient(timeout time.Duration) *OSVClient {
+	if timeout <= 0 {
+		timeout = 60 * time.Second
+	}
 	return &OSVClient{
-		httpClient: &http.Client{Timeout: 60 * time.Second},
+		httpClient: &http.Client{Timeout: timeout},
 	}
 }
 
+// osvQuery looks up a package version, or a commit of a source repository
 type osvQuery struct {
+	Package *osvPackage `json:"package,omitempty"`
+	Version string      `json:"version,omitempty"`
+	Commit  string      `json:"commit,omitempty"`
+}
+
+type osvPackage struct {
+	Name      string `json:"name,omitempty"`
+	Ecosystem string `json:"ecosystem,omitempty"`
+	Purl      string `json:"purl,omitempty"`
+}
+
+type osvBatchRequest struct {
+	Queries []osvQuery `json:"queries"`
+}
+
+type osvVulnerability struct {
+	ID        string        `json:"id"`
+	Aliases   []string      `json:"aliases"`
+	Summary   string        `json:"summary"`
+	Details   string        `json:"details"`
+	Withdrawn *time.Time    `json:"withdrawn"`
+	Severity  []OSVSeverity `json:"severity"`
+}
+
+// OSVRecord is the subset of a full OSV advisory record used for enrichment
+// and for checking whether an advisory has been withdrawn
+type OSVRecord struct {
+	ID               string                 `json:"id"`
+	Aliases          []string               `json:"aliases"`
+	Summary          string                 `json:"summary"`
+	Details          string                 `json:"details"`
+	Published        time.Time              `json:"published"`
+	Withdrawn        *time.Time             `json:"withdrawn"`
+	Severity         []OSVSeverity          `json:"severity"`
+	Affected         []OSVAffected          `json:"affected"`
+	DatabaseSpecific map[string]interface{} `json:"database_specific"`
+}
+
+// OSVSeverity is a severity score in an OSV record, e.g. a CVSS_V3 vector
+type OSVSeverity struct {
+	Type  string `json:"type"`
+	Score string `json:"score"`
+}
+
+// OSVAffected describes one affected package in an OSV record
+type OSVAffected struct {
 	Package struct {
 		Name      string `json:"name"`
 		Ecosystem string `json:"ecosystem"`
+		Purl      string `json:"purl"`
 	} `json:"package"`
-	Version string `json:"version"`
+	Ranges            []OSVRange `json:"ranges"`
+	Versions          []string   `json:"versions"`
+	EcosystemSpecific struct {
+		Imports []OSVImport `json:"imports"`
+	} `json:"ecosystem_specific"`
 }
 
-type osvBatchRequest struct {
-	Queries []osvQuery `json:"queries"`
+// OSVRange is an affected version range made of introduced/fixed events
+type OSVRange struct {
+	Type   string `json:"type"`
+	Events []struct {
+		Introduced   string `json:"introduced,omitempty"`
+		Fixed        string `json:"fixed,omitempty"`
+		LastAffected string `json:"last_affected,omitempty"`
+	} `json:"events"`
 }
 
-type osvVulnerability struct {
-	ID       string   `json:"id"`
-	Aliases  []string `json:"aliases"`
-	Summary  string   `json:"summary"`
-	Details  string   `json:"details"`
-	Severity []struct {
-		Type  string `json:"type"`
-		Score string `json:"score"`
-	} `json:"severity"`
+// OSVImport lists vulnerable symbols within a Go package
+type OSVImport struct {
+	Path    string   `json:"path"`
+	Symbols []string `json:"symbols"`
 }
 
 type osvBatchResponse struct {
@@ -54,23 +118,124 @@ type osvBatchResponse struct {
 	} `json:"results"`
 }
 
+// Name returns the source name
+func (c *OSVClient) Name() string {
+	return "OSV"
+}
+
+// QueryByPackage implements VulnSource using name/ecosystem/version queries
+func (c *OSVClient) QueryByPackage(deps []models.Dependency) (map[int][]models.CVEInfo, error) {
+	return c.QueryBatch(deps)
+}
+
+// QueryByPurl implements VulnSource using package URL queries
+func (c *OSVClient) QueryByPurl(purls []string) (map[int][]models.CVEInfo, error) {
+	queries := make([]osvQuery, len(purls))
+	for j, purl := range purls {
+		queries[j].Package = &osvPackage{Purl: purl}
+	}
+	return c.queryAll(queries)
+}
+
 // QueryBatch queries OSV for vulnerabilities affecting the given dependencies
 // Returns a map of dependency index -> []CVEInfo
 func (c *OSVClient) QueryBatch(deps []models.Dependency) (map[int][]models.CVEInfo, error) {
+	queries, indices := packageQueries(deps)
+	results, err := c.queryAll(queries)
+	if err != nil {
+		return nil, err
+	}
+	byDep := make(map[int][]models.CVEInfo, len(results))
+	for k, cves := range results {
+		for _, cve := range cves {
+			byDep[indices[k]] = mergeCVE(byDep[indices[k]], cve)
+		}
+	}
+	return byDep, nil
+}
+
+// PlanByPackage implements Planner, listing the batch requests QueryBatch
+// would send
+func (c *OSVClient) PlanByPackage(deps []models.Dependency) ([]Request, error) {
+	queries, _ := packageQueries(deps)
+	var requests []Request
+	for i := 0; i < len(queries); i += osvBatchSize {
+		chunk := queries[i:min(i+osvBatchSize, len(queries))]
+		body, err := json.Marshal(osvBatchRequest{Queries: chunk})
+		if err != nil {
+			return nil, err
+		}
+		requests = append(requests, Request{Method: http.MethodPost, URL: osvBatchURL, Items: len(chunk), Body: body})
+	}
+	return requests, nil
+}
+
+// packageQueries builds the OSV queries for dependencies and the index in
+// deps of each query's dependency
+func packageQueries(deps []models.Dependency) (queries []osvQuery, indices []int) {
+	for j, dep := range deps {
+		info := dep.Ecosystem.Info()
+		if info.NotInOSV {
+			// OSV would reject the whole batch
+			continue
+		}
+		q := osvQuery{Package: &osvPackage{}}
+		if purl := dep.Purl(); info.OSVPurl && !dep.Unpinned && purl != "" {
+			// The purl carries the version, in the registry's own form
+			q.Package.Purl = purl
+		} else {
+			q.Package.Name = dep.Name
+			q.Package.Ecosystem = dep.Ecosystem.OSVName()
+			if !dep.Unpinned {
+				// Unpinned dependencies are queried by package only and
+				// reported as potential matches
+				q.Version = dep.Version
+			}
+		}
+		queries = append(queries, q)
+		indices = append(indices, j)
+
+		// A Go pseudo-version pins a commit, which advisories with git
+		// ranges match even when the version doesn't
+		if rev := pseudoVersionCommit(dep); rev != "" {
+			queries = append(queries, osvQuery{Commit: rev})
+			indices = append(indices, j)
+		}
+	}
+	return queries, indices
+}
+
+// pseudoVersionCommit returns the commit hash prefix in a pinned Go
+// pseudo-version such as v0.0.0-20210405180319-a5a99cb37ef4, or ""
+func pseudoVersionCommit(dep models.Dependency) string {
+	if dep.Ecosystem != models.EcosystemGo || dep.Unpinned {
+		return ""
+	}
+	version := dep.Ecosystem.Info().RegistryVersion(dep.Version)
+	if !module.IsPseudoVersion(version) {
+		return ""
+	}
+	rev, err := module.PseudoVersionRev(version)
+	if err != nil {
+		return ""
+	}
+	return rev
+}
+
+// queryAll sends queries in batches and maps results back to query indices
+func (c *OSVClient) queryAll(queries []osvQuery) (map[int][]models.CVEInfo, error) {
 	results := make(map[int][]models.CVEInfo)
 
-	if len(deps) == 0 {
+	if len(queries) == 0 {
 		return results, nil
 	}
 
-	// OSV batch API allows up to 1000 queries, but we'll use 100 for safety
-	const batchSize = 100
-	for i := 0; i < len(deps); i += batchSize {
-		end := i + batchSize
-		if end > len(deps) {
-			end = len(deps)
+	for i := 0; i < len(queries); i += osvBatchSize {
+		end := i + osvBatchSize
+		if end > len(queries) {
+			end = len(queries)
 		}
-		chunk := deps[i:end]
+		chunk := queries[i:end]
 
 		chunkResults, err := c.queryChunk(chunk)
 		if err != nil {
@@ -88,13 +253,8 @@ func (c *OSVClient) QueryBatch(deps []models.Dependency) (map[int][]models.CVEIn
 	return results, nil
 }
 
-func (c *OSVClient) queryChunk(deps []models.Dependency) (map[int][]models.CVEInfo, error) {
-	req := osvBatchRequest{Queries: make([]osvQuery, len(deps))}
-	for j, dep := range deps {
-		req.Queries[j].Package.Name = dep.Name
-		req.Queries[j].Package.Ecosystem = string(dep.Ecosystem)
-		req.Queries[j].Version = dep.Version
-	}
+func (c *OSVClient) queryChunk(queries []osvQuery) (map[int][]models.CVEInfo, error) {
+	req := osvBatchRequest{Queries: queries}
 
 	body, err := json.Marshal(req)
 	if err != nil {
@@ -119,12 +279,23 @@ func (c *OSVClient) queryChunk(deps []models.Dependency) (map[int][]models.CVEIn
 	results := make(map[int][]models.CVEInfo)
 	for j, result := range batchResp.Results {
 		for _, vuln := range result.Vulns {
-			cves := extractCVEIDs(vuln.ID, vuln.Aliases)
+			if vuln.Withdrawn != nil {
+				continue // Retracted advisory
+			}
+			summary := vuln.Summary
+			if summary == "" {
+				summary, _, _ = strings.Cut(strings.TrimSpace(vuln.Details), "\n")
+			}
+
+			// A GHSA and the CVE record it aliases both map to the same
+			// CVE; keep one entry per CVE per dependency
+			cves := ExtractCVEIDs(vuln.ID, vuln.Aliases)
 			for _, cveID := range cves {
-				results[j] = append(results[j], models.CVEInfo{
-					ID:      cveID,
-					Summary: vuln.Summary,
-					Source:  "OSV",
+				results[j] = mergeCVE(results[j], models.CVEInfo{
+					ID:         cveID,
+					Summary:    summary,
+					Source:     "OSV",
+					AdvisoryID: vuln.ID,
 				})
 			}
 		}
@@ -133,24 +304,45 @@ func (c *OSVClient) queryChunk(deps []models.Dependency) (map[int][]models.CVEIn
 	return results, nil
 }
 
-// extractCVEIDs extracts CVE IDs from the OSV ID and aliases
-func extractCVEIDs(id string, aliases []string) []string {
-	seen := make(map[string]bool)
-	var cves []string
+// GetVuln fetches the full OSV record for an advisory ID
+func (c *OSVClient) GetVuln(id string) (*OSVRecord, error) {
+	resp, err := c.httpClient.Get(osvVulnURL + id)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
 
-	if strings.HasPrefix(id, "CVE-") {
-		if !seen[id] {
-			cves = append(cves, id)
-			seen[id] = true
-		}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("OSV API returned status %d for %s", resp.StatusCode, id)
 	}
 
-	for _, alias := range aliases {
-		if strings.HasPrefix(alias, "CVE-") {
-			if !seen[alias] {
-				cves = append(cves, alias)
-				seen[alias] = true
-			}
+	var record OSVRecord
+	if err := json.NewDecoder(resp.Body).Decode(&record); err != nil {
+		return nil, err
+	}
+
+	return &record, nil
+}
+
+// canonicalCVE returns the upper-cased CVE ID, or "" if id isn't a CVE
+func canonicalCVE(id string) string {
+	id = strings.ToUpper(strings.TrimSpace(id))
+	if !strings.HasPrefix(id, "CVE-") {
+		return ""
+	}
+	return id
+}
+
+// ExtractCVEIDs extracts CVE IDs from an OSV record ID and its aliases
+func ExtractCVEIDs(id string, aliases []string) []string {
+	seen := make(map[string]bool)
+	var cves []string
+
+	for _, candidate := range append([]string{id}, aliases...) {
+		cve := canonicalCVE(candidate)
+		if cve != "" && !seen[cve] {
+			cves = append(cves, cve)
+			seen[cve] = true
 		}
 	}
 
@@ -0,0 +1,77 @@
+package clients
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+const pagerDutyEventsURL = "https://events.pagerduty.com/v2/enqueue"
+
+// PagerDutyClient sends events to the PagerDuty Events API v2
+type PagerDutyClient struct {
+	httpClient *http.Client
+	routingKey string
+}
+
+// NewPagerDutyClient creates a new PagerDuty client for an integration routing key
+func NewPagerDutyClient(routingKey string) *PagerDutyClient {
+	return &PagerDutyClient{
+		httpClient: &http.Client{Timeout: 30 * time.Second},
+		routingKey: routingKey,
+	}
+}
+
+type pagerDutyEvent struct {
+	RoutingKey  string           `json:"routing_key"`
+	EventAction string           `json:"event_action"`
+	DedupKey    string           `json:"dedup_key"`
+	Payload     pagerDutyPayload `json:"payload"`
+}
+
+type pagerDutyPayload struct {
+	Summary       string            `json:"summary"`
+	Source        string            `json:"source"`
+	Severity      string            `json:"severity"`
+	Component     string            `json:"component,omitempty"`
+	CustomDetails map[string]string `json:"custom_details,omitempty"`
+}
+
+// Name returns the notifier name
+func (c *PagerDutyClient) Name() string {
+	return "PagerDuty"
+}
+
+// Trigger opens (or re-triggers) an incident deduplicated by dedupKey
+func (c *PagerDutyClient) Trigger(dedupKey, summary, component string, details map[string]string) error {
+	event := pagerDutyEvent{
+		RoutingKey:  c.routingKey,
+		EventAction: "trigger",
+		DedupKey:    dedupKey,
+		Payload: pagerDutyPayload{
+			Summary:       summary,
+			Source:        "kev-checker",
+			Severity:      "critical",
+			Component:     component,
+			CustomDetails: details,
+		},
+	}
+
+	body, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	resp, err := c.httpClient.Post(pagerDutyEventsURL, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusAccepted {
+		return fmt.Errorf("PagerDuty API returned status %d", resp.StatusCode)
+	}
+	return nil
+}
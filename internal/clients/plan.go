@@ -0,0 +1,44 @@
+package clients
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/ethanolivertroy/kev-check-demo/internal/models"
+)
+
+// Request describes an HTTP request a client would send, for dry runs
+type Request struct {
+	Method string          `json:"method"`
+	URL    string          `json:"url"`
+	Items  int             `json:"items,omitempty"` // Queries or coordinates in the request
+	Body   json.RawMessage `json:"body,omitempty"`
+}
+
+// Planner is implemented by vulnerability sources that can describe the
+// requests QueryByPackage would send without sending them
+type Planner interface {
+	PlanByPackage(deps []models.Dependency) ([]Request, error)
+}
+
+// Endpoints called while enriching findings, which depend on the KEVs matched.
+// Braces mark the parts filled in per request.
+const (
+	OSVVulnEndpoint = osvVulnURL + "{id}"
+	EPSSEndpoint    = epssURL + "?cve={ids}"
+	DepsDevEndpoint = depsDevURL + "/{system}/packages/{name}"
+
+	// EPSSBatchSize is the most CVEs requested per EPSS request
+	EPSSBatchSize = epssBatchSize
+)
+
+// PlanFetch returns the request FetchKEVCatalog would send, or nil if the
+// cached catalog is still fresh
+func (c *KEVClient) PlanFetch() []Request {
+	if c.cache != nil {
+		if _, ok := c.cache.Get(kevURL); ok {
+			return nil
+		}
+	}
+	return []Request{{Method: http.MethodGet, URL: kevURL}}
+}
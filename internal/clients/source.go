@@ -0,0 +1,86 @@
+package clients
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/ethanolivertroy/kev-check-demo/internal/models"
+)
+
+// VulnSource is a vulnerability database that maps packages to CVEs.
+// Both query methods return a map of input index -> CVEs.
+type VulnSource interface {
+	// Name returns a short name used in reports and errors
+	Name() string
+
+	// QueryByPackage looks up vulnerabilities by name, ecosystem and version
+	QueryByPackage(deps []models.Dependency) (map[int][]models.CVEInfo, error)
+
+	// QueryByPurl looks up vulnerabilities by package URL
+	QueryByPurl(purls []string) (map[int][]models.CVEInfo, error)
+}
+
+// NewVulnSources creates the named sources, with timeout for each HTTP
+// request. The OSV client is passed in so it can be shared with other OSV
+// lookups.
+func NewVulnSources(names []string, osv *OSVClient, timeout time.Duration) ([]VulnSource, error) {
+	if len(names) == 0 {
+		return []VulnSource{osv}, nil
+	}
+
+	var sources []VulnSource
+	for _, name := range names {
+		switch strings.ToLower(strings.TrimSpace(name)) {
+		case "osv":
+			sources = append(sources, osv)
+		case "ossindex":
+			sources = append(sources, NewOSSIndexClient(timeout))
+		default:
+			return nil, fmt.Errorf("unknown vulnerability source: %s", name)
+		}
+	}
+	return sources, nil
+}
+
+// MergeCVEs merges per-dependency results from several sources, keeping one
+// entry per CVE ID per dependency and preferring the entry with the longest
+// summary
+func MergeCVEs(resultSets ...map[int][]models.CVEInfo) map[int][]models.CVEInfo {
+	merged := make(map[int][]models.CVEInfo)
+
+	for _, results := range resultSets {
+		for idx, cves := range results {
+			for _, cve := range cves {
+				merged[idx] = mergeCVE(merged[idx], cve)
+			}
+		}
+	}
+
+	return merged
+}
+
+// mergeCVE adds cve to list unless its ID is already present, in which case
+// the richer summary wins and cve's advisories are added to the entry's
+func mergeCVE(list []models.CVEInfo, cve models.CVEInfo) []models.CVEInfo {
+	if canonical := canonicalCVE(cve.ID); canonical != "" {
+		cve.ID = canonical
+	}
+	for i := range list {
+		if list[i].ID == cve.ID {
+			if len(cve.Summary) > len(list[i].Summary) {
+				list[i].Summary = cve.Summary
+			}
+			known := list[i].Advisories()
+			for _, ref := range cve.Advisories() {
+				if ref.ID != "" && !slices.Contains(known, ref) {
+					list[i].Merged = append(list[i].Merged, ref)
+					known = append(known, ref)
+				}
+			}
+			return list
+		}
+	}
+	return append(list, cve)
+}
@@ -0,0 +1,107 @@
+package cvss
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// weights holds CVSS v3 base metric weights. PR has scope-dependent values,
+// handled in BaseScore.
+var weights = map[string]map[string]float64{
+	"AV": {"N": 0.85, "A": 0.62, "L": 0.55, "P": 0.2},
+	"AC": {"L": 0.77, "H": 0.44},
+	"PR": {"N": 0.85, "L": 0.62, "H": 0.27},
+	"UI": {"N": 0.85, "R": 0.62},
+	"C":  {"H": 0.56, "L": 0.22, "N": 0},
+	"I":  {"H": 0.56, "L": 0.22, "N": 0},
+	"A":  {"H": 0.56, "L": 0.22, "N": 0},
+}
+
+// baseMetrics are the metrics required in every v3 vector
+var baseMetrics = []string{"AV", "AC", "PR", "UI", "S", "C", "I", "A"}
+
+// BaseScore computes the CVSS v3.0/v3.1 base score for a vector string such
+// as "CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H"
+func BaseScore(vector string) (float64, error) {
+	parts := strings.Split(vector, "/")
+	if len(parts) < 2 || (parts[0] != "CVSS:3.0" && parts[0] != "CVSS:3.1") {
+		return 0, fmt.Errorf("unsupported CVSS vector: %s", vector)
+	}
+
+	metrics := make(map[string]string)
+	for _, p := range parts[1:] {
+		k, v, ok := strings.Cut(p, ":")
+		if !ok {
+			return 0, fmt.Errorf("malformed CVSS metric %q", p)
+		}
+		metrics[k] = v
+	}
+	for _, m := range baseMetrics {
+		if _, ok := metrics[m]; !ok {
+			return 0, fmt.Errorf("CVSS vector missing %s: %s", m, vector)
+		}
+	}
+
+	changed := metrics["S"] == "C"
+	if metrics["S"] != "U" && !changed {
+		return 0, fmt.Errorf("invalid CVSS scope %q", metrics["S"])
+	}
+
+	w := make(map[string]float64)
+	for _, m := range []string{"AV", "AC", "PR", "UI", "C", "I", "A"} {
+		v, ok := weights[m][metrics[m]]
+		if !ok {
+			return 0, fmt.Errorf("invalid CVSS value %s:%s", m, metrics[m])
+		}
+		w[m] = v
+	}
+	if changed {
+		switch metrics["PR"] {
+		case "L":
+			w["PR"] = 0.68
+		case "H":
+			w["PR"] = 0.5
+		}
+	}
+
+	iss := 1 - (1-w["C"])*(1-w["I"])*(1-w["A"])
+	var impact float64
+	if changed {
+		impact = 7.52*(iss-0.029) - 3.25*math.Pow(iss-0.02, 15)
+	} else {
+		impact = 6.42 * iss
+	}
+	if impact <= 0 {
+		return 0, nil
+	}
+
+	exploitability := 8.22 * w["AV"] * w["AC"] * w["PR"] * w["UI"]
+	if changed {
+		return roundUp(math.Min(1.08*(impact+exploitability), 10)), nil
+	}
+	return roundUp(math.Min(impact+exploitability, 10)), nil
+}
+
+// MinorVersion returns the v3 minor version of a vector: 0 for
+// "CVSS:3.0/...", 1 for "CVSS:3.1/...", and -1 for anything else
+func MinorVersion(vector string) int {
+	prefix, _, _ := strings.Cut(vector, "/")
+	switch prefix {
+	case "CVSS:3.0":
+		return 0
+	case "CVSS:3.1":
+		return 1
+	}
+	return -1
+}
+
+// roundUp implements the CVSS v3.1 Roundup function, avoiding floating point
+// artifacts by working in integers
+func roundUp(x float64) float64 {
+	i := int(math.Round(x * 100000))
+	if i%10000 == 0 {
+		return float64(i) / 100000
+	}
+	return float64(i/10000+1) / 10
+}
@@ -0,0 +1,190 @@
+// Package dashboard renders a static executive dashboard from the finding
+// lifecycle recorded in the history store: KEV exposure over time, time to
+// remediate, and the vendors and weaknesses behind open findings.
+package dashboard
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/ethanolivertroy/kev-check-demo/internal/history"
+	"github.com/ethanolivertroy/kev-check-demo/internal/models"
+	"github.com/ethanolivertroy/kev-check-demo/internal/stats"
+)
+
+// topN is how many vendors and CWEs the dashboard lists
+const topN = 10
+
+// Dashboard is the data behind the dashboard page, also written as JSON
+type Dashboard struct {
+	GeneratedAt time.Time `json:"generated_at"`
+	Findings    int       `json:"findings"` // Findings ever recorded, excluding suppressed
+
+	Open           int `json:"open"`
+	OpenRansomware int `json:"open_ransomware"`
+	Accepted       int `json:"accepted"` // Open findings that are risk accepted
+	Resolved       int `json:"resolved"`
+	ResolvedLast30 int `json:"resolved_last_30_days"`
+
+	// Time to remediate resolved findings, from first seen to resolved
+	MTTRDays   float64 `json:"mttr_days"`
+	MedianDays float64 `json:"median_days_to_resolve"`
+
+	Exposure   []Week         `json:"exposure"`
+	TopVendors []stats.Bucket `json:"top_vendors"`
+	TopCWEs    []stats.Bucket `json:"top_cwes"`
+}
+
+// Week is the exposure at the end of one week
+type Week struct {
+	Start    string `json:"week"` // Monday starting the week, YYYY-MM-DD
+	Open     int    `json:"open"` // Findings open at the end of the week
+	New      int    `json:"new"`
+	Resolved int    `json:"resolved"`
+}
+
+// Build computes the dashboard from the history store's finding records over
+// the last weeks weeks. The KEV catalog supplies vendors, CWEs and ransomware
+// use; findings recorded before their CVE was stored count as "unknown".
+// Suppressed findings aren't exposure and are left out.
+func Build(records map[string]history.FindingRecord, catalog map[string]models.KEVInfo, weeks int, now time.Time) Dashboard {
+	now = now.UTC()
+	d := Dashboard{GeneratedAt: now}
+
+	var days []float64
+	vendors := make(map[string]int)
+	cwes := make(map[string]int)
+	var tracked []history.FindingRecord
+	for _, rec := range records {
+		if rec.State == history.StateSuppressed || rec.FirstSeen.IsZero() {
+			continue
+		}
+		tracked = append(tracked, rec)
+
+		if rec.State == history.StateResolved {
+			d.Resolved++
+			if now.Sub(rec.ResolvedAt) <= 30*24*time.Hour {
+				d.ResolvedLast30++
+			}
+			days = append(days, rec.ResolvedAt.Sub(rec.FirstSeen).Hours()/24)
+			continue
+		}
+
+		d.Open++
+		if rec.State == history.StateAccepted {
+			d.Accepted++
+		}
+		kev, ok := catalog[rec.CVEID]
+		if !ok {
+			vendors["unknown"]++
+			cwes["unknown"]++
+			continue
+		}
+		if kev.RansomwareUse {
+			d.OpenRansomware++
+		}
+		vendors[kev.VendorProject]++
+		if len(kev.CWEs) == 0 {
+			cwes["unknown"]++
+		}
+		for _, cwe := range kev.CWEs {
+			cwes[cwe]++
+		}
+	}
+	d.Findings = len(tracked)
+
+	if len(days) > 0 {
+		sort.Float64s(days)
+		total := 0.0
+		for _, n := range days {
+			total += n
+		}
+		d.MTTRDays = round1(total / float64(len(days)))
+		d.MedianDays = round1(days[len(days)/2])
+		if len(days)%2 == 0 {
+			d.MedianDays = round1((days[len(days)/2-1] + days[len(days)/2]) / 2)
+		}
+	}
+
+	d.Exposure = exposure(tracked, weeks, now)
+	d.TopVendors = top(stats.SortedBuckets(vendors))
+	d.TopCWEs = top(stats.SortedBuckets(cwes))
+	return d
+}
+
+// exposure counts the findings open at the end of each of the last weeks
+// weeks, and those first seen and resolved during it
+func exposure(records []history.FindingRecord, weeks int, now time.Time) []Week {
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	monday := today.AddDate(0, 0, -((int(today.Weekday()) + 6) % 7))
+
+	out := make([]Week, 0, weeks)
+	for i := weeks - 1; i >= 0; i-- {
+		start := monday.AddDate(0, 0, -7*i)
+		end := start.AddDate(0, 0, 7)
+		if end.After(now) {
+			end = now
+		}
+		w := Week{Start: start.Format("2006-01-02")}
+		for _, rec := range records {
+			resolved := rec.State == history.StateResolved
+			if !rec.FirstSeen.After(end) && (!resolved || rec.ResolvedAt.After(end)) {
+				w.Open++
+			}
+			if !rec.FirstSeen.Before(start) && rec.FirstSeen.Before(end) {
+				w.New++
+			}
+			if resolved && !rec.ResolvedAt.Before(start) && rec.ResolvedAt.Before(end) {
+				w.Resolved++
+			}
+		}
+		out = append(out, w)
+	}
+	return out
+}
+
+// top returns the first topN buckets
+func top(buckets []stats.Bucket) []stats.Bucket {
+	if len(buckets) > topN {
+		return buckets[:topN]
+	}
+	return buckets
+}
+
+// round1 rounds to one decimal place
+func round1(f float64) float64 {
+	return float64(int(f*10+0.5)) / 10
+}
+
+// Write renders the dashboard into dir as index.html and dashboard.json,
+// creating dir if needed
+func (d Dashboard) Write(dir string) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create dashboard directory: %w", err)
+	}
+
+	data, err := json.MarshalIndent(d, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(filepath.Join(dir, "dashboard.json"), append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write dashboard: %w", err)
+	}
+
+	f, err := os.Create(filepath.Join(dir, "index.html"))
+	if err != nil {
+		return fmt.Errorf("failed to write dashboard: %w", err)
+	}
+	if err := pageTemplate.Execute(f, newPage(d)); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to render dashboard: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("failed to write dashboard: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,102 @@
+package dashboard
+
+import "html/template"
+
+// Chart dimensions, in SVG user units
+const (
+	chartWidth  = 800
+	chartHeight = 200
+)
+
+// page is the dashboard with the exposure chart laid out for the template
+type page struct {
+	Dashboard
+	Bars []bar
+	Max  int // Open findings at the tallest bar
+}
+
+// bar is one week of the exposure chart
+type bar struct {
+	Week
+	X, Y, Width, Height float64
+}
+
+// newPage scales the weekly open counts into chart bars
+func newPage(d Dashboard) page {
+	p := page{Dashboard: d}
+	for _, w := range d.Exposure {
+		p.Max = max(p.Max, w.Open)
+	}
+	if len(d.Exposure) == 0 {
+		return p
+	}
+
+	slot := float64(chartWidth) / float64(len(d.Exposure))
+	for i, w := range d.Exposure {
+		h := 0.0
+		if p.Max > 0 {
+			h = float64(w.Open) / float64(p.Max) * chartHeight
+		}
+		p.Bars = append(p.Bars, bar{
+			Week:   w,
+			X:      float64(i)*slot + slot*0.1,
+			Y:      chartHeight - h,
+			Width:  slot * 0.8,
+			Height: h,
+		})
+	}
+	return p
+}
+
+const pageStyle = `<style>
+body { font-family: system-ui, sans-serif; margin: 2em; color: #222; }
+table { border-collapse: collapse; width: 100%; }
+th, td { text-align: left; padding: .4em .6em; border-bottom: 1px solid #ddd; vertical-align: top; }
+th { background: #f4f4f4; }
+.ransomware { color: #b00; font-weight: 600; }
+.muted { color: #777; }
+.tiles { display: flex; flex-wrap: wrap; gap: 1em; margin: 1em 0; }
+.tile { border: 1px solid #ddd; padding: .6em 1em; min-width: 9em; }
+.tile strong { display: block; font-size: 1.8em; }
+.columns { display: flex; gap: 2em; }
+.columns > div { flex: 1; }
+svg rect { fill: #c44; }
+</style>`
+
+var pageTemplate = template.Must(template.New("dashboard").Parse(`<!DOCTYPE html>
+<html><head><meta charset="utf-8"><title>KEV exposure - kev-checker</title>` + pageStyle + `</head>
+<body>
+<h1>KEV exposure</h1>
+<p class="muted">Generated {{.GeneratedAt.Format "2006-01-02 15:04 MST"}} from the kev-checker history store &middot; <a href="dashboard.json">JSON</a></p>
+
+<div class="tiles">
+<div class="tile"><strong>{{.Open}}</strong>open findings</div>
+<div class="tile"><strong{{if .OpenRansomware}} class="ransomware"{{end}}>{{.OpenRansomware}}</strong>ransomware-related</div>
+<div class="tile"><strong>{{.Accepted}}</strong>risk accepted</div>
+<div class="tile"><strong>{{.ResolvedLast30}}</strong>resolved in 30 days</div>
+<div class="tile"><strong>{{if .Resolved}}{{.MTTRDays}}d{{else}}&ndash;{{end}}</strong>mean time to remediate</div>
+<div class="tile"><strong>{{if .Resolved}}{{.MedianDays}}d{{else}}&ndash;{{end}}</strong>median time to remediate</div>
+</div>
+
+<h2>Open findings by week</h2>
+{{if not .Max}}<p class="muted">No findings open in this period.</p>{{else}}
+<svg viewBox="0 0 800 220" width="100%" role="img" aria-label="Open findings by week">
+{{range .Bars}}<rect x="{{printf "%.1f" .X}}" y="{{printf "%.1f" .Y}}" width="{{printf "%.1f" .Width}}" height="{{printf "%.1f" .Height}}"><title>Week of {{.Start}}: {{.Open}} open, {{.New}} new, {{.Resolved}} resolved</title></rect>
+{{end}}<text x="0" y="215" font-size="12" fill="#777">{{(index .Bars 0).Start}}</text>
+<text x="800" y="215" font-size="12" fill="#777" text-anchor="end">peak {{.Max}} open</text>
+</svg>{{end}}
+
+<div class="columns">
+<div><h2>Top vendors</h2>
+{{if not .TopVendors}}<p class="muted">No open findings.</p>{{else}}<table>
+<tr><th>Vendor</th><th>Open findings</th></tr>
+{{range .TopVendors}}<tr><td>{{.Key}}</td><td>{{.Count}}</td></tr>
+{{end}}</table>{{end}}</div>
+<div><h2>Top CWEs</h2>
+{{if not .TopCWEs}}<p class="muted">No open findings.</p>{{else}}<table>
+<tr><th>CWE</th><th>Open findings</th></tr>
+{{range .TopCWEs}}<tr><td>{{.Key}}</td><td>{{.Count}}</td></tr>
+{{end}}</table>{{end}}</div>
+</div>
+</body></html>
+`))
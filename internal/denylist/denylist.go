@@ -0,0 +1,159 @@
+package denylist
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"unicode"
+
+	"github.com/BurntSushi/toml"
+	"github.com/ethanolivertroy/kev-check-demo/internal/models"
+)
+
+// Rule bans (or, in the allow list, exempts) dependencies by package or by
+// vendor
+type Rule struct {
+	Package   string `toml:"package"`   // Package name; either this or vendor is required
+	Ecosystem string `toml:"ecosystem"` // Optional, matches any ecosystem if empty
+	Version   string `toml:"version"`   // Optional, matches any version if empty
+	Vendor    string `toml:"vendor"`    // KEV vendor, e.g. "Ivanti"
+	Reason    string `toml:"reason"`    // Required for deny rules
+}
+
+// File is the on-disk deny list
+type File struct {
+	Deny  []Rule `toml:"deny"`
+	Allow []Rule `toml:"allow"` // Exceptions to deny rules
+}
+
+// List is a loaded deny list
+type List struct {
+	deny  []Rule
+	allow []Rule
+}
+
+// Load reads and validates a deny list file
+func Load(path string) (*List, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read deny list: %w", err)
+	}
+
+	var f File
+	if err := toml.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("failed to parse deny list: %w", err)
+	}
+
+	for i, r := range f.Deny {
+		if r.Package == "" && r.Vendor == "" {
+			return nil, fmt.Errorf("deny rule %d: package or vendor is required", i+1)
+		}
+		if r.Reason == "" {
+			return nil, fmt.Errorf("deny rule %d (%s): reason is required", i+1, r.describe())
+		}
+	}
+	for i, r := range f.Allow {
+		if r.Package == "" && r.Vendor == "" {
+			return nil, fmt.Errorf("allow rule %d: package or vendor is required", i+1)
+		}
+	}
+
+	return &List{deny: f.Deny, allow: f.Allow}, nil
+}
+
+// Check returns a violation for each dependency matched by a deny rule and
+// not exempted by an allow rule. Vendor rules match dependencies whose KEV
+// findings name the vendor, or whose package name contains it as a word.
+func (l *List) Check(deps []models.Dependency, findings []models.Finding) []models.Violation {
+	if l == nil {
+		return nil
+	}
+
+	// KEV vendors seen per dependency
+	vendors := make(map[string][]string)
+	for _, f := range findings {
+		key := depKey(f.Dependency)
+		for _, kev := range f.KEVs {
+			vendors[key] = append(vendors[key], kev.VendorProject)
+		}
+	}
+
+	var violations []models.Violation
+	seen := make(map[string]bool)
+	for _, dep := range deps {
+		key := depKey(dep)
+		if seen[key] {
+			continue
+		}
+		for _, r := range l.deny {
+			if !r.matches(dep, vendors[key]) || l.allowed(dep, vendors[key]) {
+				continue
+			}
+			seen[key] = true
+			violations = append(violations, models.Violation{
+				Dependency: dep,
+				Rule:       r.describe(),
+				Reason:     r.Reason,
+			})
+			break
+		}
+	}
+	return violations
+}
+
+func (l *List) allowed(dep models.Dependency, vendors []string) bool {
+	for _, r := range l.allow {
+		if r.matches(dep, vendors) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r Rule) matches(dep models.Dependency, vendors []string) bool {
+	if r.Ecosystem != "" && !strings.EqualFold(r.Ecosystem, string(dep.Ecosystem)) {
+		return false
+	}
+	if r.Version != "" && r.Version != dep.Version {
+		return false
+	}
+	if r.Package != "" && models.NormalizeName(dep.Ecosystem, r.Package) != models.NormalizeName(dep.Ecosystem, dep.Name) {
+		return false
+	}
+	if r.Vendor != "" && !matchesVendor(r.Vendor, dep.Name, vendors) {
+		return false
+	}
+	return true
+}
+
+func (r Rule) describe() string {
+	if r.Package != "" {
+		return "package " + r.Package
+	}
+	return "vendor " + r.Vendor
+}
+
+// matchesVendor reports whether a KEV vendor matches, or the vendor appears
+// as a word in the package name (e.g. "apache" in org.apache.struts:struts2-core)
+func matchesVendor(vendor, name string, kevVendors []string) bool {
+	for _, v := range kevVendors {
+		if strings.EqualFold(v, vendor) {
+			return true
+		}
+	}
+	want := strings.ToLower(vendor)
+	words := strings.FieldsFunc(strings.ToLower(name), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	for _, w := range words {
+		if w == want {
+			return true
+		}
+	}
+	return false
+}
+
+// depKey identifies a dependency occurrence
+func depKey(dep models.Dependency) string {
+	return string(dep.Ecosystem) + "|" + dep.Name + "|" + dep.Version + "|" + dep.SourceFile
+}
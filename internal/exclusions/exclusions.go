@@ -0,0 +1,117 @@
+package exclusions
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"github.com/ethanolivertroy/kev-check-demo/internal/models"
+)
+
+// Valid exclusion reasons
+const (
+	ReasonUnused          = "unused"
+	ReasonCompileTimeOnly = "compile-time-only"
+	ReasonVendoredUnused  = "vendored-unused"
+)
+
+// Entry declares a dependency whose findings are accepted as risk
+type Entry struct {
+	Package       string `toml:"package"`
+	Ecosystem     string `toml:"ecosystem"` // Optional, matches any ecosystem if empty
+	Version       string `toml:"version"`   // Optional, matches any version if empty
+	CVE           string `toml:"cve"`       // Optional, matches any CVE if empty
+	Reason        string `toml:"reason"`
+	Justification string `toml:"justification"`
+	ApprovedBy    string `toml:"approved_by"`
+	ApprovedOn    string `toml:"approved_on"`
+	Expires       string `toml:"expires"` // Optional YYYY-MM-DD, entry ignored after this date
+}
+
+// File is the on-disk exclusions file
+type File struct {
+	Exclude []Entry `toml:"exclude"`
+}
+
+// List is a loaded set of exclusion entries
+type List struct {
+	entries []Entry
+}
+
+// Load reads and validates an exclusions file. When requireSignoff is set,
+// every entry must carry approved_by and approved_on fields.
+func Load(path string, requireSignoff bool) (*List, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read exclusions file: %w", err)
+	}
+
+	var f File
+	if err := toml.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("failed to parse exclusions file: %w", err)
+	}
+
+	for i, e := range f.Exclude {
+		if e.Package == "" {
+			return nil, fmt.Errorf("exclusion %d: package is required", i+1)
+		}
+		switch e.Reason {
+		case ReasonUnused, ReasonCompileTimeOnly, ReasonVendoredUnused:
+		default:
+			return nil, fmt.Errorf("exclusion %d (%s): reason must be one of %s, %s, %s",
+				i+1, e.Package, ReasonUnused, ReasonCompileTimeOnly, ReasonVendoredUnused)
+		}
+		if e.Justification == "" {
+			return nil, fmt.Errorf("exclusion %d (%s): justification is required", i+1, e.Package)
+		}
+		if requireSignoff && (e.ApprovedBy == "" || e.ApprovedOn == "") {
+			return nil, fmt.Errorf("exclusion %d (%s): approved_by and approved_on are required", i+1, e.Package)
+		}
+		if e.Expires != "" {
+			if _, err := time.Parse("2006-01-02", e.Expires); err != nil {
+				return nil, fmt.Errorf("exclusion %d (%s): invalid expires date: %w", i+1, e.Package, err)
+			}
+		}
+	}
+
+	return &List{entries: f.Exclude}, nil
+}
+
+// Match returns the risk acceptance for the dependency and CVE, or nil
+func (l *List) Match(dep models.Dependency, cveID string) *models.RiskAcceptance {
+	if l == nil {
+		return nil
+	}
+
+	for _, e := range l.entries {
+		if !strings.EqualFold(e.Package, dep.Name) {
+			continue
+		}
+		if e.Ecosystem != "" && !strings.EqualFold(e.Ecosystem, string(dep.Ecosystem)) {
+			continue
+		}
+		if e.Version != "" && e.Version != dep.Version {
+			continue
+		}
+		if e.CVE != "" && e.CVE != cveID {
+			continue
+		}
+		if e.Expires != "" {
+			expires, _ := time.Parse("2006-01-02", e.Expires)
+			if time.Now().After(expires.AddDate(0, 0, 1)) {
+				continue
+			}
+		}
+
+		return &models.RiskAcceptance{
+			Reason:        e.Reason,
+			Justification: e.Justification,
+			ApprovedBy:    e.ApprovedBy,
+			ApprovedOn:    e.ApprovedOn,
+		}
+	}
+
+	return nil
+}
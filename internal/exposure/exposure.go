@@ -0,0 +1,193 @@
+package exposure
+
+import (
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/ethanolivertroy/kev-check-demo/internal/models"
+)
+
+// minWordLength skips product names too short to match package names reliably
+const minWordLength = 3
+
+// genericProducts are KEV product names that say nothing about a package
+var genericProducts = map[string]bool{
+	"multiple products": true,
+	"multiple":          true,
+	"core":              true,
+	"server":            true,
+	"client":            true,
+	"framework":         true,
+}
+
+// Report is the exposure summary for a scanned inventory
+type Report struct {
+	Dependencies int       `json:"dependencies"`
+	Products     []Product `json:"products"`
+}
+
+// Product is a KEV vendor/product that appears in the scanned inventory
+type Product struct {
+	Vendor            string              `json:"vendor"`
+	Product           string              `json:"product"`
+	KEVs              int                 `json:"kevs"`
+	RansomwareRelated int                 `json:"ransomware_related"`
+	LatestAdded       time.Time           `json:"latest_added"`
+	Dependencies      []models.Dependency `json:"-"`
+	Packages          []string            `json:"packages"`
+}
+
+// Match returns the KEV vendors/products whose product name appears in any
+// dependency name, whether or not the version in use has a KEV. Products are
+// ordered by the number of KEVs, most exploited first.
+func Match(deps []models.Dependency, catalog map[string]models.KEVInfo) []Product {
+	type key struct{ vendor, product string }
+	products := make(map[key]*Product)
+	for _, kev := range catalog {
+		k := key{kev.VendorProject, kev.Product}
+		p := products[k]
+		if p == nil {
+			p = &Product{Vendor: kev.VendorProject, Product: kev.Product}
+			products[k] = p
+		}
+		p.KEVs++
+		if kev.RansomwareUse {
+			p.RansomwareRelated++
+		}
+		if kev.DateAdded.After(p.LatestAdded) {
+			p.LatestAdded = kev.DateAdded
+		}
+	}
+
+	depWords := make([]map[string]bool, len(deps))
+	for i, dep := range deps {
+		depWords[i] = make(map[string]bool)
+		for _, w := range words(dep.Name) {
+			depWords[i][w] = true
+		}
+	}
+
+	var matched []Product
+	for _, p := range products {
+		if genericProducts[strings.ToLower(p.Product)] {
+			continue
+		}
+		want := words(p.Product)
+		if len(want) == 0 {
+			continue
+		}
+		joined := strings.Join(want, "")
+		if len(joined) < minWordLength {
+			continue
+		}
+
+		seen := make(map[string]bool)
+		for i, dep := range deps {
+			if !depWords[i][joined] && !containsAll(depWords[i], want) {
+				continue
+			}
+			p.Dependencies = append(p.Dependencies, dep)
+			if !seen[dep.Name] {
+				seen[dep.Name] = true
+				p.Packages = append(p.Packages, dep.Name)
+			}
+		}
+		if len(p.Dependencies) > 0 {
+			sort.Strings(p.Packages)
+			matched = append(matched, *p)
+		}
+	}
+
+	sort.Slice(matched, func(i, j int) bool {
+		if matched[i].KEVs != matched[j].KEVs {
+			return matched[i].KEVs > matched[j].KEVs
+		}
+		if matched[i].Vendor != matched[j].Vendor {
+			return matched[i].Vendor < matched[j].Vendor
+		}
+		return matched[i].Product < matched[j].Product
+	})
+	return matched
+}
+
+// ProductSource is the CVE source recorded for KEVs matched by PlatformMatches
+const ProductSource = "KEV product"
+
+// pluginWords are left out when comparing plugin names with KEV products,
+// which often say "WP Foo Plugin" for the "foo" plugin
+var pluginWords = map[string]bool{
+	"wp":      true,
+	"plugin":  true,
+	"plugins": true,
+	"theme":   true,
+	"themes":  true,
+	"for":     true,
+}
+
+// PlatformMatches returns the CVEs of KEV entries for plugins of a platform,
+// such as WordPress, whose product name matches the dependency's name. KEV
+// entries carry no affected versions, so a match says the plugin has been
+// exploited, not that the installed version is affected.
+func PlatformMatches(dep models.Dependency, platform string, catalog map[string]models.KEVInfo) []models.CVEInfo {
+	ignore := map[string]bool{}
+	for _, w := range words(platform) {
+		ignore[w] = true
+	}
+	significant := func(s string) []string {
+		var kept []string
+		for _, w := range words(s) {
+			if !ignore[w] && !pluginWords[w] {
+				kept = append(kept, w)
+			}
+		}
+		return kept
+	}
+
+	depWords := make(map[string]bool)
+	for _, w := range significant(dep.Name) {
+		depWords[w] = true
+	}
+
+	var cves []models.CVEInfo
+	for id, kev := range catalog {
+		if !containsAll(wordSet(kev.VendorProject+" "+kev.Product+" "+kev.VulnerabilityName), words(platform)) {
+			continue
+		}
+		want := significant(kev.Product)
+		joined := strings.Join(want, "")
+		if len(joined) < minWordLength || genericProducts[strings.ToLower(strings.Join(want, " "))] {
+			continue
+		}
+		if depWords[joined] || containsAll(depWords, want) {
+			cves = append(cves, models.CVEInfo{ID: id, Summary: kev.VulnerabilityName, Source: ProductSource})
+		}
+	}
+	sort.Slice(cves, func(i, j int) bool { return cves[i].ID < cves[j].ID })
+	return cves
+}
+
+func wordSet(s string) map[string]bool {
+	set := make(map[string]bool)
+	for _, w := range words(s) {
+		set[w] = true
+	}
+	return set
+}
+
+func containsAll(set map[string]bool, want []string) bool {
+	for _, w := range want {
+		if !set[w] {
+			return false
+		}
+	}
+	return true
+}
+
+// words splits a name into lowercase alphanumeric words
+func words(s string) []string {
+	return strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
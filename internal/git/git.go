@@ -0,0 +1,120 @@
+package git
+
+import (
+	"bytes"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// run executes a git command in dir and returns its stdout
+func run(dir string, args ...string) ([]byte, error) {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("git %s: %s", strings.Join(args, " "), strings.TrimSpace(stderr.String()))
+	}
+	return out, nil
+}
+
+// TopLevel returns the root directory of the repository containing dir
+func TopLevel(dir string) (string, error) {
+	out, err := run(dir, "rev-parse", "--show-toplevel")
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// HooksDir returns the hooks directory of the repository containing dir
+func HooksDir(dir string) (string, error) {
+	out, err := run(dir, "rev-parse", "--git-path", "hooks")
+	if err != nil {
+		return "", err
+	}
+	path := strings.TrimSpace(string(out))
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(dir, path)
+	}
+	return path, nil
+}
+
+// StagedFiles returns paths (relative to the repository root) of files
+// added, copied, modified or renamed in the index
+func StagedFiles(dir string) ([]string, error) {
+	out, err := run(dir, "diff", "--cached", "--name-only", "--diff-filter=ACMR", "-z")
+	if err != nil {
+		return nil, err
+	}
+
+	var files []string
+	for _, name := range strings.Split(string(out), "\x00") {
+		if name != "" {
+			files = append(files, name)
+		}
+	}
+	return files, nil
+}
+
+// ListFiles returns all file paths (relative to the repository root) in the
+// tree of ref under the given pathspec
+func ListFiles(dir, ref, pathspec string) ([]string, error) {
+	args := []string{"ls-tree", "-r", "--name-only", "--full-tree", "-z", "--end-of-options", ref}
+	if pathspec != "" && pathspec != "." {
+		args = append(args, "--", pathspec)
+	}
+	out, err := run(dir, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	var files []string
+	for _, name := range strings.Split(string(out), "\x00") {
+		if name != "" {
+			files = append(files, name)
+		}
+	}
+	return files, nil
+}
+
+// IndexFiles returns all file paths (relative to the repository root) in
+// the index under the given pathspec
+func IndexFiles(dir, pathspec string) ([]string, error) {
+	args := []string{"ls-files", "-z", "--full-name"}
+	if pathspec != "" && pathspec != "." {
+		args = append(args, "--", pathspec)
+	}
+	out, err := run(dir, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	var files []string
+	for _, name := range strings.Split(string(out), "\x00") {
+		if name != "" {
+			files = append(files, name)
+		}
+	}
+	return files, nil
+}
+
+// ReadFile returns the contents of path (relative to the repository root) at
+// ref, or as staged in the index if ref is empty
+func ReadFile(dir, ref, path string) ([]byte, error) {
+	return run(dir, "cat-file", "--end-of-options", "blob", ref+":"+path)
+}
+
+// Clone makes a shallow clone of url into dir, at ref (a branch or tag) if
+// set, otherwise the default branch
+func Clone(url, ref, dir string) error {
+	args := []string{"clone", "--quiet", "--depth", "1"}
+	if ref != "" {
+		args = append(args, "--branch", ref)
+	}
+	_, err := run("", append(args, "--", url, dir)...)
+	return err
+}
@@ -0,0 +1,441 @@
+package history
+
+import (
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/ethanolivertroy/kev-check-demo/internal/models"
+)
+
+// storeVersion is bumped when the on-disk format changes incompatibly
+const storeVersion = 1
+
+// Store records results of previous scans in a local JSON file, or in a
+// Postgres database shared between instances
+type Store struct {
+	path string
+	data storeData
+
+	// observed holds the fingerprints observed since Open, which Resolve
+	// leaves open
+	observed map[string]bool
+
+	// Postgres backend only: connection pool and keys changed since Open
+	db             *sql.DB
+	dirtyManifests map[string]bool
+	dirtyFindings  map[string]bool
+
+	// Postgres backend only: rows are read on first use, so keys already
+	// looked up (found or not) are remembered, as is the first read error
+	loadedManifests map[string]bool
+	loadedFindings  map[string]bool
+	loadedAll       bool
+	loadErr         error
+}
+
+type storeData struct {
+	Version   int                       `json:"version"`
+	Manifests map[string]ManifestRecord `json:"manifests"`
+	Findings  map[string]FindingRecord  `json:"findings,omitempty"`
+}
+
+// FindingRecord tracks when a finding (by fingerprint) was observed and its
+// lifecycle state
+type FindingRecord struct {
+	FirstSeen  time.Time `json:"first_seen"`
+	LastSeen   time.Time `json:"last_seen"`
+	AlertedAt  time.Time `json:"alerted_at,omitempty"`
+	State      State     `json:"state,omitempty"`
+	Manifest   string    `json:"manifest,omitempty"` // Absolute path of the declaring manifest
+	ResolvedAt time.Time `json:"resolved_at,omitempty"`
+	CVEID      string    `json:"cve_id,omitempty"`
+	Ecosystem  string    `json:"ecosystem,omitempty"`
+}
+
+// Observation describes a finding seen by a scan
+type Observation struct {
+	Manifest  string // Manifest declaring the dependency
+	CVEID     string
+	Ecosystem models.Ecosystem
+	// State is StateAccepted or StateSuppressed, or empty for a reported
+	// finding
+	State State
+}
+
+// Observed returns the observation of a finding's KEV with the given state
+func Observed(f models.Finding, cveID string, state State) Observation {
+	return Observation{
+		Manifest:  f.Dependency.SourceFile,
+		CVEID:     cveID,
+		Ecosystem: f.Dependency.Ecosystem,
+		State:     state,
+	}
+}
+
+// State is where a finding is in its lifecycle across runs
+type State string
+
+const (
+	// StateNew is a finding first observed in the latest run that saw it
+	StateNew State = "new"
+	// StateRecurring is a finding also observed by an earlier run
+	StateRecurring State = "recurring"
+	// StateResolved is a finding absent from a later scan of its manifest
+	StateResolved State = "resolved"
+	// StateAccepted is a finding risk accepted through an exclusion
+	StateAccepted State = "accepted"
+	// StateSuppressed is a finding matched but not reported, because of
+	// --ignore or an EPSS or CVSS threshold
+	StateSuppressed State = "suppressed"
+)
+
+// States lists the lifecycle states in reporting order
+var States = []State{StateNew, StateRecurring, StateResolved, StateAccepted, StateSuppressed}
+
+// ManifestRecord holds the last scan results for one dependency file
+type ManifestRecord struct {
+	Hash         string             `json:"hash"`
+	ScannedAt    time.Time          `json:"scanned_at"`
+	Dependencies []DependencyRecord `json:"dependencies"`
+}
+
+// DependencyRecord holds a dependency and the CVEs OSV reported for it
+type DependencyRecord struct {
+	Dependency models.Dependency `json:"dependency"`
+	CVEs       []models.CVEInfo  `json:"cves,omitempty"`
+}
+
+// DefaultPath returns the default history file location
+func DefaultPath(appName string) (string, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(homeDir, ".cache", appName, "history", "history.json"), nil
+}
+
+// Open loads the history store at path, starting empty if it doesn't exist.
+// A store that can't be parsed or was written by an incompatible version is
+// an error rather than being discarded. A postgres:// URL opens the store in
+// that database.
+func Open(path string) (*Store, error) {
+	if IsDatabaseURL(path) {
+		return openPostgres(path)
+	}
+
+	s := &Store{path: path}
+	s.reset()
+
+	data, err := readFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if data != nil {
+		s.data = *data
+	}
+	return s, nil
+}
+
+// readFile reads the store file at path, returning nil if it doesn't exist
+func readFile(path string) (*storeData, error) {
+	content, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var data storeData
+	if err := json.Unmarshal(content, &data); err != nil {
+		return nil, fmt.Errorf("history store %s is unreadable (move it aside to start over): %w", path, err)
+	}
+	if data.Version != storeVersion {
+		return nil, fmt.Errorf("history store %s has version %d, expected %d (move it aside to start over)", path, data.Version, storeVersion)
+	}
+	if data.Manifests == nil {
+		data.Manifests = make(map[string]ManifestRecord)
+	}
+	if data.Findings == nil {
+		data.Findings = make(map[string]FindingRecord)
+	}
+	return &data, nil
+}
+
+func (s *Store) reset() {
+	s.data = storeData{
+		Version:   storeVersion,
+		Manifests: make(map[string]ManifestRecord),
+		Findings:  make(map[string]FindingRecord),
+	}
+	s.observed = make(map[string]bool)
+	s.dirtyManifests = make(map[string]bool)
+	s.dirtyFindings = make(map[string]bool)
+	s.loadedManifests = make(map[string]bool)
+	s.loadedFindings = make(map[string]bool)
+}
+
+// Manifest returns the stored record for a dependency file
+func (s *Store) Manifest(path string) (ManifestRecord, bool) {
+	key := manifestKey(path)
+	s.loadManifests([]string{key})
+	rec, ok := s.data.Manifests[key]
+	return rec, ok
+}
+
+// Manifests returns every stored manifest record, keyed by absolute path
+func (s *Store) Manifests() map[string]ManifestRecord {
+	s.loadAll()
+	manifests := make(map[string]ManifestRecord, len(s.data.Manifests))
+	for path, rec := range s.data.Manifests {
+		manifests[path] = rec
+	}
+	return manifests
+}
+
+// ManifestsWithCVE returns the stored manifest records with a dependency
+// the CVE was reported for, and the number of manifests searched
+func (s *Store) ManifestsWithCVE(cveID string) (map[string]ManifestRecord, int, error) {
+	if s.db != nil {
+		return s.pgManifestsWithCVE(cveID)
+	}
+	manifests := make(map[string]ManifestRecord)
+	for path, rec := range s.data.Manifests {
+		for _, d := range rec.Dependencies {
+			if slices.ContainsFunc(d.CVEs, func(c models.CVEInfo) bool { return c.ID == cveID }) {
+				manifests[path] = rec
+				break
+			}
+		}
+	}
+	return manifests, len(s.data.Manifests), nil
+}
+
+// Prefetch reads the records of the given manifests and fingerprints in one
+// round trip each, ahead of Manifest and Observe. The file store holds
+// everything already.
+func (s *Store) Prefetch(manifests, fingerprints []string) {
+	keys := make([]string, len(manifests))
+	for i, m := range manifests {
+		keys[i] = manifestKey(m)
+	}
+	s.loadManifests(keys)
+	s.loadFindings(fingerprints)
+}
+
+// SetManifest records scan results for a dependency file
+func (s *Store) SetManifest(path string, rec ManifestRecord) {
+	key := manifestKey(path)
+	s.data.Manifests[key] = rec
+	s.dirtyManifests[key] = true
+}
+
+// Observe records that a finding was seen at time now and returns its
+// record. The finding becomes new on first sight and recurring afterwards,
+// including when it reappears after being resolved, unless the observation
+// is accepted or suppressed.
+func (s *Store) Observe(fingerprint string, obs Observation, now time.Time) FindingRecord {
+	s.loadFindings([]string{fingerprint})
+	rec, ok := s.data.Findings[fingerprint]
+	if !ok {
+		rec.FirstSeen = now
+	}
+	state := obs.State
+	if state == "" {
+		state = StateRecurring
+		if !ok || rec.State == StateNew && s.observed[fingerprint] {
+			state = StateNew // Also when reported twice in one run
+		}
+	}
+	rec.LastSeen = now
+	rec.State = state
+	rec.Manifest = manifestKey(obs.Manifest)
+	rec.ResolvedAt = time.Time{}
+	rec.CVEID = obs.CVEID
+	rec.Ecosystem = string(obs.Ecosystem)
+	s.data.Findings[fingerprint] = rec
+	s.observed[fingerprint] = true
+	s.dirtyFindings[fingerprint] = true
+	return rec
+}
+
+// Resolve marks findings as resolved at time now when a scan covered their
+// manifest without observing them: the manifest was parsed, or it lay under
+// one of roots and has been deleted. It returns the number resolved.
+func (s *Store) Resolve(manifests, roots []string, now time.Time) int {
+	parsed := make(map[string]bool, len(manifests))
+	keys := make([]string, len(manifests))
+	for i, m := range manifests {
+		keys[i] = manifestKey(m)
+		parsed[keys[i]] = true
+	}
+	rootKeys := make([]string, len(roots))
+	for i, root := range roots {
+		rootKeys[i] = manifestKey(root)
+	}
+	s.loadResolvable(keys, rootKeys)
+
+	resolved := 0
+	for fingerprint, rec := range s.data.Findings {
+		if rec.Manifest == "" || rec.State == StateResolved || s.observed[fingerprint] {
+			continue
+		}
+		if !parsed[rec.Manifest] && !(underAny(rec.Manifest, rootKeys) && !exists(rec.Manifest)) {
+			continue
+		}
+		rec.State = StateResolved
+		rec.ResolvedAt = now
+		s.data.Findings[fingerprint] = rec
+		s.dirtyFindings[fingerprint] = true
+		resolved++
+	}
+	return resolved
+}
+
+// Findings returns every stored finding record, keyed by fingerprint
+func (s *Store) Findings() map[string]FindingRecord {
+	s.loadAll()
+	findings := make(map[string]FindingRecord, len(s.data.Findings))
+	for fingerprint, rec := range s.data.Findings {
+		findings[fingerprint] = rec
+	}
+	return findings
+}
+
+// underAny reports whether path is one of roots or inside one of them
+func underAny(path string, roots []string) bool {
+	for _, root := range roots {
+		if path == root || strings.HasPrefix(path, strings.TrimSuffix(root, string(filepath.Separator))+string(filepath.Separator)) {
+			return true
+		}
+	}
+	return false
+}
+
+// exists reports whether a file exists; errors other than not-existing
+// count as existing, so findings aren't resolved on an unreadable disk
+func exists(path string) bool {
+	_, err := os.Stat(path)
+	return !errors.Is(err, os.ErrNotExist)
+}
+
+// Seen returns true if the finding was observed in a previous run
+func (s *Store) Seen(fingerprint string) bool {
+	s.loadFindings([]string{fingerprint})
+	_, ok := s.data.Findings[fingerprint]
+	return ok
+}
+
+// Alerted returns true if an alert was already sent for the fingerprint
+func (s *Store) Alerted(fingerprint string) bool {
+	s.loadFindings([]string{fingerprint})
+	return !s.data.Findings[fingerprint].AlertedAt.IsZero()
+}
+
+// MarkAlerted records that an alert was sent for the fingerprint
+func (s *Store) MarkAlerted(fingerprint string, now time.Time) {
+	s.loadFindings([]string{fingerprint})
+	rec := s.data.Findings[fingerprint]
+	if rec.FirstSeen.IsZero() {
+		rec.FirstSeen = now
+		rec.LastSeen = now
+	}
+	rec.AlertedAt = now
+	s.data.Findings[fingerprint] = rec
+	s.dirtyFindings[fingerprint] = true
+}
+
+// Save writes the store to disk, or its changes to the database. The file is
+// locked and re-read first, so only the records this run changed replace
+// those saved by concurrent runs since Open.
+func (s *Store) Save() error {
+	if s.db != nil {
+		return s.savePostgres()
+	}
+
+	dir := filepath.Dir(s.path)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	unlock, err := lockFile(s.path + ".lock")
+	if err != nil {
+		return fmt.Errorf("failed to lock history store: %w", err)
+	}
+	defer unlock()
+
+	current, err := readFile(s.path)
+	if err != nil {
+		return err
+	}
+	if current != nil {
+		for key := range s.dirtyManifests {
+			current.Manifests[key] = s.data.Manifests[key]
+		}
+		for fingerprint := range s.dirtyFindings {
+			current.Findings[fingerprint] = s.data.Findings[fingerprint]
+		}
+		s.data = *current
+	}
+
+	data, err := json.MarshalIndent(s.data, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	// Written beside the store and renamed over it, so readers never see a
+	// partial file
+	tmp, err := os.CreateTemp(dir, ".history-*.json")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if err := tmp.Chmod(0644); err != nil {
+		tmp.Close()
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp.Name(), s.path); err != nil {
+		return err
+	}
+
+	clear(s.dirtyManifests)
+	clear(s.dirtyFindings)
+	return nil
+}
+
+// HashFile returns the SHA-256 of a file's contents
+func HashFile(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// manifestKey makes manifest paths independent of the working directory.
+// Manifests named after a repository URL are kept as they are.
+func manifestKey(path string) string {
+	if strings.Contains(path, "://") {
+		return path
+	}
+	if abs, err := filepath.Abs(path); err == nil {
+		return abs
+	}
+	return path
+}
@@ -0,0 +1,117 @@
+package history
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestOpen(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string // Empty for a missing file
+		wantErr string
+	}{
+		{"missing", "", ""},
+		{"empty store", `{"version": 1, "manifests": {}}`, ""},
+		{"corrupt", `{"version": 1, "manif`, "unreadable"},
+		{"older version", `{"version": 0, "manifests": {}}`, "version 0"},
+		{"newer version", `{"version": 2, "manifests": {}}`, "version 2"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "history.json")
+			if tt.content != "" {
+				if err := os.WriteFile(path, []byte(tt.content), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			s, err := Open(path)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("Open() error = %v", err)
+				}
+				if len(s.Findings()) != 0 || len(s.Manifests()) != 0 {
+					t.Errorf("Open() store not empty")
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Open() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestSave(t *testing.T) {
+	now := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	obs := Observation{Manifest: "/src/go.mod", CVEID: "CVE-2021-44228"}
+
+	tests := []struct {
+		name string
+		// Fingerprints observed by two runs that opened the same store
+		// before either saved
+		first, second []string
+		want          map[string]State
+	}{
+		{"disjoint", []string{"a"}, []string{"b"}, map[string]State{"a": StateNew, "b": StateNew}},
+		{"overlapping", []string{"a", "b"}, []string{"b", "c"}, map[string]State{"a": StateNew, "b": StateNew, "c": StateNew}},
+		{"second run only", nil, []string{"c"}, map[string]State{"c": StateNew}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "nested", "history.json")
+			first, err := Open(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			second, err := Open(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, fp := range tt.first {
+				first.Observe(fp, obs, now)
+			}
+			for _, fp := range tt.second {
+				second.Observe(fp, obs, now)
+			}
+			if err := first.Save(); err != nil {
+				t.Fatalf("Save() error = %v", err)
+			}
+			if err := second.Save(); err != nil {
+				t.Fatalf("Save() error = %v", err)
+			}
+
+			reopened, err := Open(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := reopened.Findings()
+			if len(got) != len(tt.want) {
+				t.Errorf("saved %d findings, want %d", len(got), len(tt.want))
+			}
+			for fp, state := range tt.want {
+				rec, ok := got[fp]
+				if !ok || rec.State != state || !rec.FirstSeen.Equal(now) {
+					t.Errorf("finding %s = %+v, want state %s first seen %s", fp, rec, state, now)
+				}
+			}
+
+			// No temporary files are left beside the store
+			entries, err := os.ReadDir(filepath.Dir(path))
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, e := range entries {
+				if e.Name() != "history.json" && e.Name() != "history.json.lock" {
+					t.Errorf("unexpected file %s", e.Name())
+				}
+			}
+		})
+	}
+}
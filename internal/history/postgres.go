@@ -0,0 +1,317 @@
+package history
+
+import (
+	"cmp"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/lib/pq" // Also registers the "postgres" database/sql driver
+)
+
+// pgSchema creates the history tables. Each manifest and finding is a row,
+// so instances sharing the database merge their results instead of
+// overwriting each other's.
+const pgSchema = `
+CREATE TABLE IF NOT EXISTS kev_checker_manifests (
+	path         TEXT PRIMARY KEY,
+	hash         TEXT NOT NULL,
+	scanned_at   TIMESTAMPTZ NOT NULL,
+	dependencies JSONB NOT NULL
+);
+CREATE TABLE IF NOT EXISTS kev_checker_findings (
+	fingerprint TEXT PRIMARY KEY,
+	first_seen  TIMESTAMPTZ NOT NULL,
+	last_seen   TIMESTAMPTZ NOT NULL,
+	alerted_at  TIMESTAMPTZ
+);
+ALTER TABLE kev_checker_findings
+	ADD COLUMN IF NOT EXISTS state TEXT NOT NULL DEFAULT '',
+	ADD COLUMN IF NOT EXISTS manifest TEXT NOT NULL DEFAULT '',
+	ADD COLUMN IF NOT EXISTS resolved_at TIMESTAMPTZ,
+	ADD COLUMN IF NOT EXISTS cve_id TEXT NOT NULL DEFAULT '',
+	ADD COLUMN IF NOT EXISTS ecosystem TEXT NOT NULL DEFAULT '';`
+
+// pgNewerState is true when the row being written changed state no earlier
+// than the stored row, so concurrent instances keep the latest state
+const pgNewerState = `COALESCE(EXCLUDED.resolved_at, EXCLUDED.last_seen) >= COALESCE(kev_checker_findings.resolved_at, kev_checker_findings.last_seen)`
+
+// pgPools holds one connection pool per database URL, so long-running
+// processes that open the store for every scan reuse connections
+var (
+	pgPoolsMu sync.Mutex
+	pgPools   = make(map[string]*sql.DB)
+)
+
+// IsDatabaseURL returns true if a history location is a Postgres
+// connection URL rather than a file path
+func IsDatabaseURL(path string) bool {
+	return strings.HasPrefix(path, "postgres://") || strings.HasPrefix(path, "postgresql://")
+}
+
+// pgPool returns the shared connection pool for url, creating the history
+// tables on first use
+func pgPool(url string) (*sql.DB, error) {
+	pgPoolsMu.Lock()
+	defer pgPoolsMu.Unlock()
+
+	if db, ok := pgPools[url]; ok {
+		return db, nil
+	}
+	db, err := sql.Open("postgres", url)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open history database: %w", err)
+	}
+	if _, err := db.Exec(pgSchema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to create history tables: %w", err)
+	}
+	pgPools[url] = db
+	return db, nil
+}
+
+// openPostgres opens the history store in a Postgres database. Records are
+// read as they are needed rather than up front, so opening the store stays
+// cheap however many projects share the database.
+func openPostgres(url string) (*Store, error) {
+	db, err := pgPool(url)
+	if err != nil {
+		return nil, err
+	}
+	s := &Store{path: url, db: db}
+	s.reset()
+	return s, nil
+}
+
+// pgFindingColumns are the columns queryFindings scans
+const pgFindingColumns = `fingerprint, first_seen, last_seen, alerted_at, state, manifest, resolved_at, cve_id, ecosystem`
+
+// loadManifests reads the manifest records of keys not looked up yet.
+// Records changed since Open are kept.
+func (s *Store) loadManifests(keys []string) {
+	if s.db == nil || s.loadedAll {
+		return
+	}
+	var missing []string
+	for _, key := range keys {
+		if !s.loadedManifests[key] {
+			missing = append(missing, key)
+			s.loadedManifests[key] = true
+		}
+	}
+	if len(missing) == 0 {
+		return
+	}
+	s.queryManifests(`SELECT path, hash, scanned_at, dependencies FROM kev_checker_manifests WHERE path = ANY($1)`, pq.Array(missing))
+}
+
+// loadFindings reads the finding records of fingerprints not looked up yet
+func (s *Store) loadFindings(fingerprints []string) {
+	if s.db == nil || s.loadedAll {
+		return
+	}
+	var missing []string
+	for _, fingerprint := range fingerprints {
+		if !s.loadedFindings[fingerprint] {
+			missing = append(missing, fingerprint)
+			s.loadedFindings[fingerprint] = true
+		}
+	}
+	if len(missing) == 0 {
+		return
+	}
+	s.queryFindings(`SELECT `+pgFindingColumns+` FROM kev_checker_findings WHERE fingerprint = ANY($1)`, pq.Array(missing))
+}
+
+// loadResolvable reads the unresolved findings Resolve may resolve: those
+// of the parsed manifests and under the roots
+func (s *Store) loadResolvable(manifests, roots []string) {
+	if s.db == nil || s.loadedAll {
+		return
+	}
+	prefixes := make([]string, len(roots))
+	for i, root := range roots {
+		prefixes[i] = pgLikeEscaper.Replace(strings.TrimSuffix(root, string(filepath.Separator))+string(filepath.Separator)) + "%"
+	}
+	s.queryFindings(`SELECT `+pgFindingColumns+` FROM kev_checker_findings
+		WHERE state <> $1 AND (manifest = ANY($2) OR manifest = ANY($3) OR manifest LIKE ANY($4))`,
+		StateResolved, pq.Array(manifests), pq.Array(roots), pq.Array(prefixes))
+}
+
+// pgLikeEscaper escapes LIKE wildcards with the default escape character
+var pgLikeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// loadAll reads every record, for reports across the whole store
+func (s *Store) loadAll() {
+	if s.db == nil || s.loadedAll {
+		return
+	}
+	s.queryManifests(`SELECT path, hash, scanned_at, dependencies FROM kev_checker_manifests`)
+	s.queryFindings(`SELECT ` + pgFindingColumns + ` FROM kev_checker_findings`)
+	s.loadedAll = true
+}
+
+// queryManifests adds the manifest rows a query returns to the store.
+// Read errors are kept for Save to report.
+func (s *Store) queryManifests(query string, args ...any) {
+	manifests, err := pgManifests(s.db, query, args...)
+	if err != nil {
+		s.loadErr = cmp.Or(s.loadErr, err)
+		return
+	}
+	for path, rec := range manifests {
+		if !s.dirtyManifests[path] {
+			s.data.Manifests[path] = rec
+		}
+		s.loadedManifests[path] = true
+	}
+}
+
+// pgManifests runs a query for manifest rows
+func pgManifests(db *sql.DB, query string, args ...any) (map[string]ManifestRecord, error) {
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load history: %w", err)
+	}
+	defer rows.Close()
+	manifests := make(map[string]ManifestRecord)
+	for rows.Next() {
+		var path string
+		var rec ManifestRecord
+		var deps []byte
+		if err := rows.Scan(&path, &rec.Hash, &rec.ScannedAt, &deps); err != nil {
+			return nil, fmt.Errorf("failed to load history: %w", err)
+		}
+		if err := json.Unmarshal(deps, &rec.Dependencies); err != nil {
+			// Written by an incompatible version; re-query this manifest
+			continue
+		}
+		manifests[path] = rec
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to load history: %w", err)
+	}
+	return manifests, nil
+}
+
+// queryFindings adds the finding rows a query returns to the store. Records
+// changed since Open are kept.
+func (s *Store) queryFindings(query string, args ...any) {
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		s.loadErr = cmp.Or(s.loadErr, fmt.Errorf("failed to load history: %w", err))
+		return
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var fingerprint string
+		var rec FindingRecord
+		var alerted, resolved sql.NullTime
+		if err := rows.Scan(&fingerprint, &rec.FirstSeen, &rec.LastSeen, &alerted, &rec.State, &rec.Manifest, &resolved, &rec.CVEID, &rec.Ecosystem); err != nil {
+			s.loadErr = cmp.Or(s.loadErr, fmt.Errorf("failed to load history: %w", err))
+			return
+		}
+		rec.AlertedAt = alerted.Time
+		rec.ResolvedAt = resolved.Time
+		if !s.dirtyFindings[fingerprint] {
+			s.data.Findings[fingerprint] = rec
+		}
+		s.loadedFindings[fingerprint] = true
+	}
+	if err := rows.Err(); err != nil {
+		s.loadErr = cmp.Or(s.loadErr, fmt.Errorf("failed to load history: %w", err))
+	}
+}
+
+// pgManifestsWithCVE finds the manifests with a dependency the CVE was
+// reported for in the database, without reading the others
+func (s *Store) pgManifestsWithCVE(cveID string) (map[string]ManifestRecord, int, error) {
+	var searched int
+	if err := s.db.QueryRow(`SELECT count(*) FROM kev_checker_manifests`).Scan(&searched); err != nil {
+		return nil, 0, fmt.Errorf("failed to load history: %w", err)
+	}
+	// Matches manifests with any dependency whose CVEs include the ID
+	contains, err := json.Marshal([]map[string]any{{"cves": []map[string]string{{"ID": cveID}}}})
+	if err != nil {
+		return nil, 0, err
+	}
+	manifests, err := pgManifests(s.db, `SELECT path, hash, scanned_at, dependencies FROM kev_checker_manifests WHERE dependencies @> $1::jsonb`, string(contains))
+	if err != nil {
+		return nil, 0, err
+	}
+	return manifests, searched, nil
+}
+
+// savePostgres writes the records changed since the store was opened. Rows
+// written concurrently by other instances are merged: the newest manifest
+// scan wins, and findings keep the earliest first-seen, any alert and the
+// most recent lifecycle state. Nothing is written if reading records
+// failed, since the changes may have been made without them.
+func (s *Store) savePostgres() error {
+	if s.loadErr != nil {
+		return s.loadErr
+	}
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to save history: %w", err)
+	}
+	defer tx.Rollback()
+
+	for path := range s.dirtyManifests {
+		rec := s.data.Manifests[path]
+		deps, err := json.Marshal(rec.Dependencies)
+		if err != nil {
+			return err
+		}
+		_, err = tx.Exec(`
+			INSERT INTO kev_checker_manifests (path, hash, scanned_at, dependencies)
+			VALUES ($1, $2, $3, $4)
+			ON CONFLICT (path) DO UPDATE SET
+				hash = EXCLUDED.hash,
+				scanned_at = EXCLUDED.scanned_at,
+				dependencies = EXCLUDED.dependencies
+			WHERE kev_checker_manifests.scanned_at <= EXCLUDED.scanned_at`,
+			path, rec.Hash, rec.ScannedAt, deps)
+		if err != nil {
+			return fmt.Errorf("failed to save history: %w", err)
+		}
+	}
+
+	for fingerprint := range s.dirtyFindings {
+		rec := s.data.Findings[fingerprint]
+		var alerted, resolved *time.Time
+		if !rec.AlertedAt.IsZero() {
+			alerted = &rec.AlertedAt
+		}
+		if !rec.ResolvedAt.IsZero() {
+			resolved = &rec.ResolvedAt
+		}
+		_, err := tx.Exec(`
+			INSERT INTO kev_checker_findings (fingerprint, first_seen, last_seen, alerted_at, state, manifest, resolved_at, cve_id, ecosystem)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+			ON CONFLICT (fingerprint) DO UPDATE SET
+				first_seen = LEAST(kev_checker_findings.first_seen, EXCLUDED.first_seen),
+				last_seen = GREATEST(kev_checker_findings.last_seen, EXCLUDED.last_seen),
+				alerted_at = COALESCE(kev_checker_findings.alerted_at, EXCLUDED.alerted_at),
+				state = CASE WHEN `+pgNewerState+` THEN EXCLUDED.state ELSE kev_checker_findings.state END,
+				manifest = CASE WHEN `+pgNewerState+` THEN EXCLUDED.manifest ELSE kev_checker_findings.manifest END,
+				resolved_at = CASE WHEN `+pgNewerState+` THEN EXCLUDED.resolved_at ELSE kev_checker_findings.resolved_at END,
+				cve_id = GREATEST(kev_checker_findings.cve_id, EXCLUDED.cve_id),
+				ecosystem = GREATEST(kev_checker_findings.ecosystem, EXCLUDED.ecosystem)`,
+			fingerprint, rec.FirstSeen, rec.LastSeen, alerted, rec.State, rec.Manifest, resolved, rec.CVEID, rec.Ecosystem)
+		if err != nil {
+			return fmt.Errorf("failed to save history: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to save history: %w", err)
+	}
+	clear(s.dirtyManifests)
+	clear(s.dirtyFindings)
+	return nil
+}
@@ -0,0 +1,130 @@
+// Package inventory reads and writes dependency inventory files: the output
+// of the parse stage of a scan, as package URLs and the locations that
+// declared them. Scans can consume an inventory instead of walking paths, so
+// manifests parsed once can be matched against vulnerability data later.
+package inventory
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/ethanolivertroy/kev-check-demo/internal/models"
+)
+
+// Version is the inventory format version written by Export. Load accepts
+// any version up to this one.
+const Version = 1
+
+// File is a dependency inventory
+type File struct {
+	Version     int       `json:"inventory_version"`
+	GeneratedAt time.Time `json:"generated_at"`
+	Packages    []Package `json:"packages"`
+}
+
+// Package is a dependency and every location that declares it. Dependencies
+// without a purl (e.g. unpinned with no version, or in an ecosystem with no
+// purl type) record their ecosystem, name and any version instead. Local
+// packages record the path or workspace reference they're built from.
+type Package struct {
+	Purl       string           `json:"purl,omitempty"`
+	Ecosystem  models.Ecosystem `json:"ecosystem,omitempty"`
+	Name       string           `json:"name,omitempty"`
+	Version    string           `json:"version,omitempty"`
+	Local      string           `json:"local,omitempty"`
+	Unpinned   bool             `json:"unpinned,omitempty"`
+	Transitive bool             `json:"transitive,omitempty"`
+	Dev        bool             `json:"dev,omitempty"`
+	Locations  []Location       `json:"locations"`
+}
+
+// Location is a manifest line that declares a package
+type Location struct {
+	File     string `json:"file"`
+	Line     int    `json:"line,omitempty"`
+	ScanPath string `json:"scan_path,omitempty"`
+}
+
+// Export builds an inventory from parsed dependencies, merging identical
+// packages into one entry with several locations
+func Export(deps []models.Dependency) File {
+	inv := File{Version: Version, GeneratedAt: time.Now().UTC(), Packages: []Package{}}
+	index := make(map[string]int)
+
+	for _, dep := range deps {
+		pkg := Package{Purl: dep.Purl(), Local: dep.Local, Unpinned: dep.Unpinned, Transitive: dep.Transitive, Dev: dep.Dev}
+		if pkg.Purl == "" {
+			pkg.Ecosystem = dep.Ecosystem
+			pkg.Name = dep.Name
+			pkg.Version = dep.Version
+		}
+		key := fmt.Sprintf("%s|%s|%s|%s|%s|%t|%t|%t", pkg.Purl, pkg.Ecosystem, pkg.Name, pkg.Version, pkg.Local, pkg.Unpinned, pkg.Transitive, pkg.Dev)
+
+		i, ok := index[key]
+		if !ok {
+			i = len(inv.Packages)
+			index[key] = i
+			inv.Packages = append(inv.Packages, pkg)
+		}
+		inv.Packages[i].Locations = append(inv.Packages[i].Locations, Location{
+			File:     dep.SourceFile,
+			Line:     dep.Line,
+			ScanPath: dep.ScanPath,
+		})
+		for _, loc := range dep.Duplicates {
+			inv.Packages[i].Locations = append(inv.Packages[i].Locations, Location{
+				File:     loc.File,
+				Line:     loc.Line,
+				ScanPath: dep.ScanPath,
+			})
+		}
+	}
+	return inv
+}
+
+// Load reads an inventory file and returns a dependency per location
+func Load(path string) ([]models.Dependency, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read inventory: %w", err)
+	}
+
+	var inv File
+	if err := json.Unmarshal(data, &inv); err != nil {
+		return nil, fmt.Errorf("failed to parse inventory %s: %w", path, err)
+	}
+	if inv.Version < 1 || inv.Version > Version {
+		return nil, fmt.Errorf("unsupported inventory version %d in %s (supported: 1-%d)", inv.Version, path, Version)
+	}
+
+	var deps []models.Dependency
+	for _, pkg := range inv.Packages {
+		dep := models.Dependency{Name: pkg.Name, Ecosystem: pkg.Ecosystem, Version: pkg.Version}
+		if pkg.Purl != "" {
+			dep, err = models.ParsePurl(pkg.Purl)
+			if err != nil {
+				return nil, fmt.Errorf("invalid inventory %s: %w", path, err)
+			}
+		} else if pkg.Name == "" || pkg.Ecosystem == "" {
+			return nil, fmt.Errorf("invalid inventory %s: package needs a purl or an ecosystem and name", path)
+		}
+		dep.Local = pkg.Local
+		dep.Unpinned = pkg.Unpinned
+		dep.Transitive = pkg.Transitive
+		dep.Dev = pkg.Dev
+
+		for _, loc := range pkg.Locations {
+			d := dep
+			d.SourceFile = loc.File
+			d.Line = loc.Line
+			d.ScanPath = loc.ScanPath
+			if d.ScanPath == "" {
+				d.ScanPath = path
+			}
+			deps = append(deps, d)
+		}
+	}
+	return deps, nil
+}
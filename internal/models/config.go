@@ -5,25 +5,139 @@ import "time"
 // Config holds configuration for the scanner
 type Config struct {
 	// Paths to scan for dependency files
-	Paths []string
+	Paths   []string
+	GitRef  string // Read manifests from this git ref instead of the working tree
+	Archive string // Read manifests from this .zip/.tar/.tar.gz instead of Paths
+	CloneOf string // Repository URL that Paths[0] is a temporary clone of
+	Staged  bool   // Read manifests in Paths from the git index instead of the working tree
+
+	Inventory string // Read dependencies from this inventory file instead of Paths
 
 	// Output settings
 	OutputFormat string // "terminal", "json", "sarif"
 	OutputFile   string // Optional output file path
+	SeverityFile string // Optional TOML severity mapping and SARIF severity policy
+	MaxFindings  int    // Cap findings in the report (0 = unlimited); NDJSON is never capped
 
 	// Behavior settings
-	FailOnKEV     bool    // Exit with code 1 if KEVs found
-	EPSSThreshold float64 // Only report if EPSS >= threshold (0-1)
+	FailOnKEV bool     // Exit with code 1 if KEVs found
+	FailOn    []string // Optional conditions that must match to fail, e.g. "open>30d"
+
+	// FailOnPotential lets potential findings of unpinned dependencies fail
+	// the run
+	FailOnPotential bool
+
+	// Internal remediation SLAs, measured from first-seen
+	SLARansomware time.Duration
+	SLADefault    time.Duration
+
+	EPSSThreshold  float64 // Only report if EPSS >= threshold (0-1)
+	EPSSPercentile float64 // Only report if EPSS percentile >= threshold (0-1)
+	MinCVSS        float64 // Only report if CVSS base score >= this (0-10); unscored KEVs are kept
+	Reachability   bool    // Analyze whether vulnerable Go symbols are referenced
+	Typosquat      bool    // Warn about names resembling popular packages
+	Freshness      bool    // Enrich findings with last-release and deprecation data
+
+	// NoEnrich names enrichers to leave out of the pipeline, e.g. "epss"
+	NoEnrich []string
+
+	// Ecosystems limits the scan to these ecosystems (empty = all)
+	Ecosystems []Ecosystem
+
+	// Exclusion settings
+	ExclusionsFile string   // Optional TOML file of risk-accepted dependencies
+	Ignore         []string // CVE IDs or package names whose KEVs are not reported
+	RequireSignoff bool     // Require approved_by/approved_on on every exclusion
+	BaselineFile   string   // Optional baseline of known findings, accepted as risk
+
+	OwnersFile   string // Optional CODEOWNERS-style file mapping paths to teams
+	DenyListFile string // Optional TOML file of banned packages and vendors
 
 	// Cache settings
-	CacheTTL time.Duration
-	NoCache  bool
+	CacheTTL      time.Duration
+	NoCache       bool
+	MaxCatalogAge time.Duration // Fail if the KEV catalog release is older than this (0 = warn only)
+
+	// History settings
+	HistoryFile string // Defaults to ~/.cache/kev-checker/history/history.json
+	Incremental bool   // Only re-query OSV for manifests changed since last run
+	NoHistory   bool   // Don't read or write the history store
+
+	// IncrementalTTL is how long Incremental reuses stored results of an
+	// unchanged manifest before re-querying it (0 = until it changes)
+	IncrementalTTL time.Duration
+
+	// Vulnerability sources to query, e.g. "osv", "ossindex" (default: osv)
+	Sources []string
+
+	// DryRun discovers dependencies and plans the requests a scan would send
+	// without making them
+	DryRun bool
+
+	// Advisories lists files, directories, or URLs of OSV-schema internal
+	// advisories that are treated as known-exploited
+	Advisories []string
+
+	// KEVOverlayFile is an organizational catalog in CISA KEV JSON format
+	// merged over the CISA catalog
+	KEVOverlayFile string
 
 	// API settings
 	Timeout       time.Duration
-	MaxConcurrent int
+	MaxConcurrent int // Paths discovered and parsed in parallel
+
+	// Walker limits (0 = unlimited)
+	MaxFileSize int64 // Skip manifests larger than this many bytes
+	MaxFiles    int   // Stop after parsing this many manifests
+	MaxDepth    int   // Don't descend more than this many directories below a path
+
+	// QueryBudget caps the registry dependencies sent to vulnerability
+	// sources (0 = unlimited). Over budget, dependencies outside
+	// BudgetManifests, then dev and then transitive ones are left unchecked.
+	QueryBudget     int
+	BudgetManifests []string // Glob patterns matched against the end of manifest paths
+
+	// SkipDirs are directory names, or glob patterns such as ".*", the walker
+	// doesn't descend into. ScanVendored walks VendoredDirs even if listed.
+	SkipDirs     []string
+	ScanVendored bool
+
+	// RequirementsPatterns are extra glob patterns for pip requirements
+	// files, e.g. "requirements/*.txt"
+	RequirementsPatterns []string
+
+	// AllManifests parses manifests such as package.json even when a
+	// lockfile for them sits in the same directory
+	AllManifests bool
+
+	// FollowSymlinks walks symlinked directories and parses symlinked
+	// manifests; OneFileSystem doesn't descend into other mounted filesystems
+	FollowSymlinks bool
+	OneFileSystem  bool
+
+	// Alerting settings
+	PagerDutyRoutingKey string
+	OpsgenieAPIKey      string
+
+	// Push settings
+	Push                 string // Push target after scan: "defectdojo", "misp"
+	DefectDojoURL        string
+	DefectDojoToken      string
+	DefectDojoProduct    string
+	DefectDojoEngagement string
+
+	MISPURL   string
+	MISPKey   string
+	MISPEvent string // Title of the event created or updated per scan
 }
 
+// DefaultSkipDirs are the directories skipped by default: VCS metadata,
+// caches, and vendored or installed dependencies
+var DefaultSkipDirs = []string{"node_modules", ".git", "vendor", "__pycache__", ".venv", "venv"}
+
+// VendoredDirs hold copies of third-party code, which audits may need to scan
+var VendoredDirs = []string{"node_modules", "vendor", ".venv", "venv"}
+
 // DefaultConfig returns a Config with sensible defaults
 func DefaultConfig() *Config {
 	return &Config{
@@ -31,9 +145,13 @@ func DefaultConfig() *Config {
 		OutputFormat:  "terminal",
 		FailOnKEV:     true,
 		EPSSThreshold: 0,
+		SLARansomware: 48 * time.Hour,
+		SLADefault:    7 * 24 * time.Hour,
 		CacheTTL:      24 * time.Hour,
 		NoCache:       false,
 		Timeout:       60 * time.Second,
 		MaxConcurrent: 10,
+		MaxFileSize:   64 << 20,
+		SkipDirs:      DefaultSkipDirs,
 	}
 }
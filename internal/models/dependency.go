@@ -1,12 +1,8 @@
 package models
 
-// Ecosystem represents a package ecosystem
-type Ecosystem string
-
-const (
-	EcosystemPyPI Ecosystem = "PyPI"
-	EcosystemNpm  Ecosystem = "npm"
-	EcosystemGo   Ecosystem = "Go"
+import (
+	"fmt"
+	"strings"
 )
 
 // Dependency represents a single package dependency
@@ -16,9 +12,81 @@ type Dependency struct {
 	Ecosystem  Ecosystem
 	SourceFile string // File where this dependency was found
 	Line       int    // Line number in source file (if available)
+	Snippet    string // Source line text that declared the dependency (if available)
+	Unpinned   bool   // Version is missing or a range, not an exact pin
+	Transitive bool   // Pulled in by another dependency rather than declared
+	Dev        bool   // Only needed for development, e.g. a dev dependency group
+	ScanPath   string // Path argument the dependency was discovered under
+
+	// Replacement is the fork or local directory a Go replace directive
+	// substitutes for the module, if any
+	Replacement string
+
+	// Local is the path or workspace reference of a dependency built from
+	// the project's own source, e.g. "../shared" or "workspace:*". Local
+	// dependencies have no upstream package, so they aren't queried.
+	Local string
+
+	// Duplicates are other declarations of the same package merged into
+	// this one, e.g. "PyYAML" in one manifest and "pyyaml" in another
+	Duplicates []SourceLocation
+}
+
+// SourceLocation is a manifest line declaring a dependency
+type SourceLocation struct {
+	File string
+	Line int
+}
+
+// Files returns the manifests declaring the dependency, including those of
+// merged duplicates
+func (d Dependency) Files() []string {
+	files := []string{d.SourceFile}
+	for _, loc := range d.Duplicates {
+		files = append(files, loc.File)
+	}
+	return files
 }
 
 // String returns a human-readable representation
 func (d Dependency) String() string {
 	return d.Name + "@" + d.Version
 }
+
+// Purl returns the package URL for this dependency, or "" if the ecosystem
+// has no purl type or the version is unknown
+func (d Dependency) Purl() string {
+	info := d.Ecosystem.Info()
+	if info.PurlType == "" || d.Version == "" {
+		return ""
+	}
+	return "pkg:" + info.PurlType + "/" + info.PurlName(d.Name) + "@" + info.RegistryVersion(d.Version)
+}
+
+// ParsePurl returns the dependency identified by a package URL, as produced
+// by Purl. Qualifiers and subpaths are ignored.
+func ParsePurl(purl string) (Dependency, error) {
+	rest, ok := strings.CutPrefix(purl, "pkg:")
+	if !ok {
+		return Dependency{}, fmt.Errorf("invalid purl %q: missing pkg: scheme", purl)
+	}
+	rest, _, _ = strings.Cut(rest, "#")
+	rest, _, _ = strings.Cut(rest, "?")
+
+	purlType, rest, ok := strings.Cut(rest, "/")
+	if !ok {
+		return Dependency{}, fmt.Errorf("invalid purl %q: missing name", purl)
+	}
+	eco, ok := EcosystemForPurlType(strings.ToLower(purlType))
+	if !ok {
+		return Dependency{}, fmt.Errorf("unsupported purl type %q in %q", purlType, purl)
+	}
+
+	name, version, _ := strings.Cut(rest, "@")
+	info := eco.Info()
+	return Dependency{
+		Name:      info.NameFromPurl(name),
+		Version:   info.ParseVersion(version),
+		Ecosystem: eco,
+	}, nil
+}
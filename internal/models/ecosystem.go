@@ -0,0 +1,256 @@
+package models
+
+import (
+	"net/url"
+	"sort"
+	"strings"
+
+	"golang.org/x/mod/module"
+)
+
+// Ecosystem represents a package ecosystem
+type Ecosystem string
+
+const (
+	EcosystemPyPI Ecosystem = "PyPI"
+	EcosystemNpm  Ecosystem = "npm"
+	EcosystemGo   Ecosystem = "Go"
+
+	EcosystemCratesIO Ecosystem = "crates.io" // Rust crates
+
+	EcosystemHackage Ecosystem = "Hackage"
+	EcosystemHex     Ecosystem = "Hex"
+	EcosystemMaven   Ecosystem = "Maven"
+	EcosystemAnsible Ecosystem = "Ansible" // Galaxy roles and collections
+
+	EcosystemPackagist Ecosystem = "Packagist"
+	EcosystemWordPress Ecosystem = "WordPress" // Plugins and themes
+)
+
+// VersionScheme identifies how versions in an ecosystem are ordered
+type VersionScheme string
+
+const (
+	VersionSemver  VersionScheme = "semver"
+	VersionPEP440  VersionScheme = "pep440"
+	VersionMaven   VersionScheme = "maven"
+	VersionGeneric VersionScheme = "generic" // Dotted numeric segments
+)
+
+// EcosystemInfo describes how an ecosystem maps onto external data sources.
+// Adding a parser for a new ecosystem only requires registering it here.
+type EcosystemInfo struct {
+	OSV           string        // OSV ecosystem name
+	PurlType      string        // Package URL type ("" if none)
+	DepsDev       string        // deps.dev system name ("" if unsupported)
+	Versions      VersionScheme // Version ordering used for local range matching
+	VersionPrefix string        // Prefix registries expect on versions, e.g. "v" for Go
+
+	// NotInOSV marks ecosystems OSV has no advisories for. Their dependencies
+	// are inventoried and matched against internal advisories, but not sent
+	// to OSV.
+	NotInOSV bool
+
+	// OSVPurl queries OSV by package URL instead of name and ecosystem, for
+	// ecosystems whose names are easy to get wrong: a purl spells out the
+	// Maven group and artifact, and the Go module path and version as Go
+	// does.
+	OSVPurl bool
+
+	// MergeDuplicates reports a package declared more than once at the same
+	// version, in several manifests or under name variants that normalize
+	// alike, as one dependency with every location
+	MergeDuplicates bool
+
+	// KEVPlatform is the product, e.g. "WordPress", whose KEV entries name
+	// the ecosystem's packages. Without version-level advisories, packages
+	// are matched to those entries by name and reported as potential.
+	KEVPlatform string
+
+	normalizeName func(string) string // Canonical package name, for matching
+	purlName      func(string) string // Package name as it appears in a purl
+	fromPurlName  func(string) string // Inverse of purlName, after unescaping
+}
+
+// ecosystems is the registry of supported ecosystems
+var ecosystems = map[Ecosystem]EcosystemInfo{
+	EcosystemPyPI: {
+		OSV:      "PyPI",
+		PurlType: "pypi",
+		DepsDev:  "pypi",
+		Versions: VersionPEP440,
+		// Names differing only in case and runs of "-", "_" and "." are the
+		// same project (PEP 503), so "typing_extensions" and
+		// "Typing-Extensions" are one package
+		MergeDuplicates: true,
+		normalizeName: func(name string) string {
+			return pypiNameSeparators.ReplaceAllString(strings.ToLower(name), "-")
+		},
+	},
+	EcosystemNpm: {
+		OSV:           "npm",
+		PurlType:      "npm",
+		DepsDev:       "npm",
+		Versions:      VersionSemver,
+		normalizeName: strings.ToLower,
+		purlName: func(name string) string {
+			return strings.Replace(name, "@", "%40", 1)
+		},
+	},
+	EcosystemGo: {
+		OSV:           "Go",
+		PurlType:      "golang",
+		DepsDev:       "go",
+		Versions:      VersionSemver,
+		VersionPrefix: "v",
+		OSVPurl:       true,
+		// Module paths are case-sensitive and keep their /vN major version
+		// suffix; module proxies and caches escape uppercase letters as "!"
+		// plus the lowercase letter
+		fromPurlName: func(name string) string {
+			if unescaped, err := module.UnescapePath(name); err == nil {
+				return unescaped
+			}
+			return name
+		},
+	},
+	EcosystemCratesIO: {
+		OSV:      "crates.io",
+		PurlType: "cargo",
+		DepsDev:  "cargo",
+		Versions: VersionSemver,
+	},
+	EcosystemHackage: {
+		OSV:      "Hackage",
+		PurlType: "hackage",
+		Versions: VersionGeneric,
+	},
+	EcosystemHex: {
+		OSV:           "Hex",
+		PurlType:      "hex",
+		Versions:      VersionSemver,
+		normalizeName: strings.ToLower,
+	},
+	EcosystemAnsible: {
+		OSV:           "Ansible",
+		Versions:      VersionSemver,
+		NotInOSV:      true,
+		normalizeName: strings.ToLower,
+	},
+	EcosystemPackagist: {
+		OSV:           "Packagist",
+		PurlType:      "composer",
+		Versions:      VersionGeneric,
+		normalizeName: strings.ToLower,
+	},
+	EcosystemWordPress: {
+		OSV:           "WordPress",
+		Versions:      VersionGeneric,
+		NotInOSV:      true,
+		KEVPlatform:   "WordPress",
+		normalizeName: strings.ToLower,
+	},
+	EcosystemMaven: {
+		OSV:      "Maven",
+		PurlType: "maven",
+		DepsDev:  "maven",
+		Versions: VersionMaven,
+		OSVPurl:  true,
+		purlName: func(name string) string {
+			return strings.Replace(name, ":", "/", 1)
+		},
+		fromPurlName: func(name string) string {
+			return strings.Replace(name, "/", ":", 1)
+		},
+	},
+}
+
+// Info returns the registry entry for the ecosystem. Unregistered ecosystems
+// are passed to OSV as-is and compared as generic dotted versions.
+func (e Ecosystem) Info() EcosystemInfo {
+	if info, ok := ecosystems[e]; ok {
+		return info
+	}
+	return EcosystemInfo{OSV: string(e), Versions: VersionGeneric}
+}
+
+// EcosystemForPurlType returns the ecosystem registered for a package URL
+// type
+func EcosystemForPurlType(purlType string) (Ecosystem, bool) {
+	for eco, info := range ecosystems {
+		if info.PurlType == purlType {
+			return eco, true
+		}
+	}
+	return "", false
+}
+
+// Ecosystems returns the registered ecosystems, sorted by name
+func Ecosystems() []Ecosystem {
+	list := make([]Ecosystem, 0, len(ecosystems))
+	for eco := range ecosystems {
+		list = append(list, eco)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i] < list[j] })
+	return list
+}
+
+// ParseEcosystem returns the registered ecosystem with the given name,
+// ignoring case
+func ParseEcosystem(name string) (Ecosystem, bool) {
+	for eco := range ecosystems {
+		if strings.EqualFold(string(eco), name) {
+			return eco, true
+		}
+	}
+	return "", false
+}
+
+// OSVName returns the ecosystem name used by OSV
+func (e Ecosystem) OSVName() string {
+	return e.Info().OSV
+}
+
+// PurlName returns the package name as encoded in a package URL
+func (i EcosystemInfo) PurlName(name string) string {
+	if i.normalizeName != nil {
+		name = i.normalizeName(name)
+	}
+	if i.purlName == nil {
+		return name
+	}
+	return i.purlName(name)
+}
+
+// NameFromPurl returns the package name encoded in a package URL, the
+// inverse of PurlName up to normalization
+func (i EcosystemInfo) NameFromPurl(name string) string {
+	if unescaped, err := url.PathUnescape(name); err == nil {
+		name = unescaped
+	}
+	if i.fromPurlName == nil {
+		return name
+	}
+	return i.fromPurlName(name)
+}
+
+// ParseVersion returns a version as dependencies store it, without the
+// registry's prefix: "v1.2.3" and "v2.0.0+incompatible" become "1.2.3" and
+// "2.0.0+incompatible" for Go. A "v" that isn't a prefix, as in a branch name,
+// is kept.
+func (i EcosystemInfo) ParseVersion(version string) string {
+	rest, ok := strings.CutPrefix(version, i.VersionPrefix)
+	if !ok || i.VersionPrefix == "" || rest == "" || rest[0] < '0' || rest[0] > '9' {
+		return version
+	}
+	return rest
+}
+
+// RegistryVersion returns a version in the form registries expect, restoring
+// any prefix ParseVersion strips (e.g. "v" for Go modules)
+func (i EcosystemInfo) RegistryVersion(version string) string {
+	if i.VersionPrefix == "" || version == "" || version[0] < '0' || version[0] > '9' {
+		return version
+	}
+	return i.VersionPrefix + version
+}
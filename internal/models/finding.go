@@ -5,8 +5,75 @@ import "time"
 // Finding represents a vulnerability finding for a dependency
 type Finding struct {
 	Dependency Dependency
-	CVEs       []CVEInfo // All CVEs affecting this dependency
-	KEVs       []KEVInfo // CVEs that are in the KEV catalog
+	CVEs       []CVEInfo      // All CVEs affecting this dependency
+	KEVs       []KEVInfo      // CVEs that are in the KEV catalog
+	Health     *PackageHealth // Registry metadata, if freshness enrichment ran
+	Confidence Confidence     // How sure we are the installed version is affected
+	FixVersion string         // Lowest version fixing every KEV, if one exists
+	Effort     Effort         // Remediation effort; empty if fix data was unavailable
+	Owners     []string       // Teams owning the manifest, from the owners file
+}
+
+// Confidence describes whether a finding matched an exact version
+type Confidence string
+
+const (
+	// ConfidenceConfirmed means the dependency's exact version is affected
+	ConfidenceConfirmed Confidence = "confirmed"
+	// ConfidencePotential means the dependency is unpinned, so some version
+	// of the package is affected but the installed one couldn't be checked
+	ConfidencePotential Confidence = "potential"
+)
+
+// Severity is a KEV's normalized severity, combining CVSS, EPSS and
+// ransomware use into one scale shared by reporters and --fail-on. Every
+// KEV is known to be exploited, so there is no "low".
+type Severity string
+
+const (
+	SeverityCritical Severity = "critical"
+	SeverityHigh     Severity = "high"
+	SeverityMedium   Severity = "medium"
+)
+
+// Severities lists the severities from most to least severe
+var Severities = []Severity{SeverityCritical, SeverityHigh, SeverityMedium}
+
+// Rank orders severities: 3 for critical down to 1 for medium, and 0 if
+// unset or unknown
+func (s Severity) Rank() int {
+	switch s {
+	case SeverityCritical:
+		return 3
+	case SeverityHigh:
+		return 2
+	case SeverityMedium:
+		return 1
+	}
+	return 0
+}
+
+// AtLeast reports whether s is as severe as min or more
+func (s Severity) AtLeast(min Severity) bool {
+	return s.Rank() >= min.Rank()
+}
+
+// Effort classifies the work needed to remediate a finding
+type Effort string
+
+const (
+	// EffortDirectBump means a declared dependency can be upgraded to the fix
+	EffortDirectBump Effort = "direct-bump"
+	// EffortTransitiveOverride means the package is pulled in by another
+	// dependency, so the fix needs an override/resolution or a parent upgrade
+	EffortTransitiveOverride Effort = "transitive-override"
+	// EffortNoFix means at least one KEV has no fixed version published
+	EffortNoFix Effort = "no-fix"
+)
+
+// Potential returns true if the finding is version-unconfirmed
+func (f Finding) Potential() bool {
+	return f.Confidence == ConfidencePotential
 }
 
 // HasKEV returns true if this finding has any KEV vulnerabilities
@@ -14,16 +81,44 @@ func (f Finding) HasKEV() bool {
 	return len(f.KEVs) > 0
 }
 
+// HasUnacceptedKEV returns true if any KEV has not been risk accepted
+func (f Finding) HasUnacceptedKEV() bool {
+	for _, kev := range f.KEVs {
+		if kev.Accepted == nil {
+			return true
+		}
+	}
+	return false
+}
+
 // CVEInfo represents information about a CVE
 type CVEInfo struct {
-	ID      string
-	Summary string
-	Source  string // e.g., "OSV", "GHSA"
+	ID         string
+	Summary    string
+	Source     string // e.g., "OSV", "GHSA"
+	AdvisoryID string // Upstream advisory ID, e.g. "GO-2022-0001" or "GHSA-..."
+
+	// Merged lists the other advisories reporting the CVE for the same
+	// dependency, from this or other sources
+	Merged []AdvisoryRef `json:",omitempty"`
+}
+
+// AdvisoryRef identifies an upstream advisory and the source reporting it
+type AdvisoryRef struct {
+	Source string
+	ID     string
+}
+
+// Advisories returns every advisory reporting the CVE, the primary one first
+func (c CVEInfo) Advisories() []AdvisoryRef {
+	refs := []AdvisoryRef{{Source: c.Source, ID: c.AdvisoryID}}
+	return append(refs, c.Merged...)
 }
 
 // KEVInfo represents a Known Exploited Vulnerability from CISA
 type KEVInfo struct {
 	CVEID             string
+	Catalog           string // Catalog the entry came from: "CISA" or an organizational source
 	VendorProject     string
 	Product           string
 	VulnerabilityName string
@@ -36,6 +131,71 @@ type KEVInfo struct {
 	Notes             string
 	EPSSScore         float64
 	EPSSPercentile    float64
+	EPSSScored        bool            // EPSS data was found for the CVE
+	CVSSScore         float64         // CVSS v3 base score (zero if unknown)
+	CVSSVector        string          // CVSS vector string from the advisory, if any
+	Accepted          *RiskAcceptance // Non-nil if covered by an exclusion entry
+	Reachability      Reachability    // Empty unless --reachability ran
+	FirstSeen         time.Time       // First scan this finding was observed in (zero if untracked)
+	SLADeadline       time.Time       // Internal remediation deadline (zero if untracked)
+	Severity          Severity        // Normalized severity from the severity mapping
+}
+
+// SLABreached returns true if the internal remediation deadline has passed
+func (k KEVInfo) SLABreached() bool {
+	return !k.SLADeadline.IsZero() && time.Now().After(k.SLADeadline)
+}
+
+// DueIn returns the number of calendar days until the CISA due date as seen
+// in loc (nil means UTC): positive before the date, zero on it, negative once
+// overdue. ok is false if the KEV has no due date.
+func (k KEVInfo) DueIn(now time.Time, loc *time.Location) (days int, ok bool) {
+	if k.DueDate.IsZero() {
+		return 0, false
+	}
+	if loc == nil {
+		loc = time.UTC
+	}
+	// Due dates are calendar dates, so compare them as dates in loc
+	y, m, d := k.DueDate.Date()
+	due := time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+	y, m, d = now.In(loc).Date()
+	today := time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+	return int(due.Sub(today).Hours() / 24), true
+}
+
+// Overdue returns true once the CISA due date has passed in loc
+func (k KEVInfo) Overdue(now time.Time, loc *time.Location) bool {
+	days, ok := k.DueIn(now, loc)
+	return ok && days < 0
+}
+
+// DaysOpen returns the number of whole days since the finding was first seen
+func (k KEVInfo) DaysOpen() int {
+	if k.FirstSeen.IsZero() {
+		return 0
+	}
+	return int(time.Since(k.FirstSeen).Hours() / 24)
+}
+
+// Reachability describes whether the project's source refers to the
+// vulnerable code. It comes from imports and selectors, not a call graph, so
+// a referenced symbol may never be called and code reached through another
+// dependency isn't seen.
+type Reachability string
+
+const (
+	ReachabilityReferenced   Reachability = "referenced"
+	ReachabilityUnreferenced Reachability = "not-referenced"
+	ReachabilityUnknown      Reachability = "unknown"
+)
+
+// RiskAcceptance records why a KEV finding was accepted rather than remediated
+type RiskAcceptance struct {
+	Reason        string // "unused", "compile-time-only", "vendored-unused"
+	Justification string
+	ApprovedBy    string
+	ApprovedOn    string
 }
 
 // EPSSScore represents EPSS scoring data
@@ -43,3 +203,20 @@ type EPSSScore struct {
 	Score      float64
 	Percentile float64
 }
+
+// PackageHealth describes release activity and deprecation status of a package
+type PackageHealth struct {
+	LatestVersion    string
+	LastRelease      time.Time
+	Deprecated       bool
+	DeprecatedReason string
+}
+
+// UnmaintainedAfter is how long without a release before a package is
+// considered unmaintained
+const UnmaintainedAfter = 2 * 365 * 24 * time.Hour
+
+// Unmaintained returns true if the package has had no release recently
+func (h PackageHealth) Unmaintained() bool {
+	return !h.LastRelease.IsZero() && time.Since(h.LastRelease) > UnmaintainedAfter
+}
@@ -0,0 +1,95 @@
+package models
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// pypiNameSeparators matches runs of characters PEP 503 treats as equivalent
+var pypiNameSeparators = regexp.MustCompile(`[-_.]+`)
+
+// Fingerprint returns a stable identifier for a KEV finding on this
+// dependency. It is derived from the ecosystem, normalized package name and
+// version, CVE ID and the slash-separated path of the source file relative to
+// its repository (see RelativePath), so it does not change when dependencies
+// are reordered, line numbers shift or the scan runs from another directory.
+func (f Finding) Fingerprint(cveID string) string {
+	dep := f.Dependency
+	parts := []string{
+		string(dep.Ecosystem),
+		NormalizeName(dep.Ecosystem, dep.Name),
+		normalizeVersion(dep.Version),
+		cveID,
+		RelativePath(dep.SourceFile, dep.ScanPath),
+	}
+
+	sum := sha256.Sum256([]byte(strings.Join(parts, "\x00")))
+	return hex.EncodeToString(sum[:16])
+}
+
+// NormalizeName returns the canonical form of a package name for its ecosystem
+func NormalizeName(eco Ecosystem, name string) string {
+	if normalize := eco.Info().normalizeName; normalize != nil {
+		return normalize(name)
+	}
+	return name
+}
+
+// normalizeVersion strips formatting differences that don't change the version
+func normalizeVersion(version string) string {
+	return strings.TrimPrefix(strings.TrimSpace(version), "v")
+}
+
+// RelativePath returns a manifest path relative to the top level of the git
+// repository containing it, using forward slashes. Files outside a
+// repository are made relative to root, the scanned path, when it's a local
+// directory holding them, and are otherwise only cleaned. URLs and archive
+// members ("<archive>!/<path>") are returned as is.
+func RelativePath(path, root string) string {
+	if strings.Contains(path, "://") || strings.Contains(path, "!/") {
+		return path
+	}
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return filepath.ToSlash(filepath.Clean(path))
+	}
+	base := gitTopLevel(filepath.Dir(abs))
+	if base == "" && root != "" {
+		if info, err := os.Stat(root); err == nil {
+			if !info.IsDir() {
+				root = filepath.Dir(root)
+			}
+			base, _ = filepath.Abs(root)
+		}
+	}
+	if base != "" {
+		if rel, err := filepath.Rel(base, abs); err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return filepath.ToSlash(rel)
+		}
+	}
+	return filepath.ToSlash(filepath.Clean(path))
+}
+
+// topLevels caches gitTopLevel by directory
+var topLevels sync.Map
+
+// gitTopLevel returns the nearest directory at or above dir holding a .git
+// directory or file, or "" if there is none
+func gitTopLevel(dir string) string {
+	if top, ok := topLevels.Load(dir); ok {
+		return top.(string)
+	}
+	top := ""
+	if _, err := os.Stat(filepath.Join(dir, ".git")); err == nil {
+		top = dir
+	} else if parent := filepath.Dir(dir); parent != dir {
+		top = gitTopLevel(parent)
+	}
+	topLevels.Store(dir, top)
+	return top
+}
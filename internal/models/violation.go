@@ -0,0 +1,14 @@
+package models
+
+// Violation is a dependency banned by the deny list, reported separately from
+// KEV findings
+type Violation struct {
+	Dependency Dependency
+	Rule       string // The deny rule that matched, e.g. "package log4j-core"
+	Reason     string
+}
+
+// String returns a human-readable representation
+func (v Violation) String() string {
+	return v.Dependency.String() + " (" + v.Dependency.SourceFile + "): denied by " + v.Rule + ": " + v.Reason
+}
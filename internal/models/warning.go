@@ -0,0 +1,43 @@
+package models
+
+// WarningKind categorizes a non-finding scan warning
+type WarningKind string
+
+const (
+	WarningTyposquat WarningKind = "typosquat"
+	WarningDegraded  WarningKind = "degraded"
+	WarningWithdrawn WarningKind = "withdrawn"
+	WarningStaleData WarningKind = "stale-catalog"
+	WarningLimit     WarningKind = "limit"    // A walker limit skipped files
+	WarningSkipped   WarningKind = "skipped"  // Symlinks or mounts not walked
+	WarningReplaced  WarningKind = "replaced" // Replaced by a fork or local copy
+)
+
+// Warning is an advisory notice raised during a scan that is not a KEV finding
+type Warning struct {
+	Kind       WarningKind
+	Dependency Dependency
+	Message    string
+}
+
+// String returns a human-readable representation
+func (w Warning) String() string {
+	if w.Dependency.Name == "" {
+		return string(w.Kind) + ": " + w.Message
+	}
+	return string(w.Kind) + ": " + w.Dependency.String() + " (" + w.Dependency.SourceFile + "): " + w.Message
+}
+
+// SourceFailure records a vulnerability data source that could not be reached
+type SourceFailure struct {
+	Source string
+	Error  string
+}
+
+// ParseError records a manifest that could not be read or parsed. Its
+// dependencies are missing from the results; other manifests are unaffected.
+type ParseError struct {
+	File  string
+	Error string
+	Panic bool // The parser crashed rather than rejecting the file
+}
@@ -0,0 +1,205 @@
+// Package osvmirror keeps a local copy of the OSV records behind KEV CVEs,
+// one file per ecosystem, so scans can match dependencies when the OSV API
+// is unreachable. Only KEVs are ever reported, so these records are enough to
+// produce complete findings.
+package osvmirror
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ethanolivertroy/kev-check-demo/internal/advisories"
+	"github.com/ethanolivertroy/kev-check-demo/internal/cache"
+	"github.com/ethanolivertroy/kev-check-demo/internal/clients"
+	"github.com/ethanolivertroy/kev-check-demo/internal/models"
+)
+
+// dumpURL is OSV's archive of every record for an ecosystem
+const dumpURL = "https://osv-vulnerabilities.storage.googleapis.com/%s/all.zip"
+
+// file is the on-disk mirror of one ecosystem. It holds no timestamps and
+// records are sorted by ID, so identical upstream data gives identical files.
+type file struct {
+	Ecosystem string              `json:"ecosystem"`
+	Records   []clients.OSVRecord `json:"records"`
+}
+
+// Mirror is the set of mirrored ecosystems loaded from the cache
+type Mirror struct {
+	records    []clients.OSVRecord
+	ecosystems map[string]bool // OSV ecosystem names
+	updated    time.Time       // Oldest ecosystem file
+}
+
+// dir holds one <ecosystem>.json per mirrored ecosystem
+func dir(c *cache.Cache) string {
+	return filepath.Join(c.Dir, "osv-mirror")
+}
+
+// Warm downloads every OSV record for the ecosystem and caches those aliased
+// to a CVE in the KEV catalog. It returns the number of records kept.
+func Warm(c *cache.Cache, eco models.Ecosystem, kevCatalog map[string]models.KEVInfo) (int, error) {
+	name := eco.OSVName()
+	tmp, err := os.CreateTemp("", "osv-*.zip")
+	if err != nil {
+		return 0, err
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+
+	// Ecosystem dumps run to hundreds of megabytes
+	client := &http.Client{Timeout: 15 * time.Minute}
+	resp, err := client.Get(fmt.Sprintf(dumpURL, url.PathEscape(name)))
+	if err != nil {
+		return 0, fmt.Errorf("failed to download OSV records for %s: %w", name, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return 0, fmt.Errorf("failed to download OSV records for %s: status %d", name, resp.StatusCode)
+	}
+	size, err := io.Copy(tmp, resp.Body)
+	if err != nil {
+		return 0, fmt.Errorf("failed to download OSV records for %s: %w", name, err)
+	}
+
+	zr, err := zip.NewReader(tmp, size)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read OSV archive for %s: %w", name, err)
+	}
+
+	out := file{Ecosystem: name, Records: []clients.OSVRecord{}}
+	for _, zf := range zr.File {
+		if !strings.HasSuffix(zf.Name, ".json") {
+			continue
+		}
+		rc, err := zf.Open()
+		if err != nil {
+			return 0, fmt.Errorf("failed to read %s from OSV archive: %w", zf.Name, err)
+		}
+		var rec clients.OSVRecord
+		err = json.NewDecoder(rc).Decode(&rec)
+		rc.Close()
+		if err != nil {
+			continue
+		}
+		for _, cve := range clients.ExtractCVEIDs(rec.ID, rec.Aliases) {
+			if _, ok := kevCatalog[cve]; ok {
+				out.Records = append(out.Records, rec)
+				break
+			}
+		}
+	}
+	sort.Slice(out.Records, func(i, j int) bool {
+		return out.Records[i].ID < out.Records[j].ID
+	})
+
+	data, err := json.MarshalIndent(out, "", "  ")
+	if err != nil {
+		return 0, err
+	}
+	if err := os.MkdirAll(dir(c), 0755); err != nil {
+		return 0, err
+	}
+	if err := os.WriteFile(filepath.Join(dir(c), name+".json"), data, 0644); err != nil {
+		return 0, fmt.Errorf("failed to write OSV mirror for %s: %w", name, err)
+	}
+	return len(out.Records), nil
+}
+
+// Load reads the mirrored ecosystems from the cache. It returns nil if none
+// have been mirrored.
+func Load(c *cache.Cache) (*Mirror, error) {
+	entries, err := os.ReadDir(dir(c))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read OSV mirror: %w", err)
+	}
+
+	m := &Mirror{ecosystems: make(map[string]bool)}
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasSuffix(e.Name(), ".json") {
+			continue
+		}
+		path := filepath.Join(dir(c), e.Name())
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read OSV mirror: %w", err)
+		}
+		var f file
+		if err := json.Unmarshal(data, &f); err != nil {
+			return nil, fmt.Errorf("failed to parse OSV mirror %s: %w", path, err)
+		}
+		info, err := e.Info()
+		if err != nil {
+			return nil, err
+		}
+
+		m.records = append(m.records, f.Records...)
+		m.ecosystems[strings.ToLower(f.Ecosystem)] = true
+		if m.updated.IsZero() || info.ModTime().Before(m.updated) {
+			m.updated = info.ModTime()
+		}
+	}
+	if len(m.ecosystems) == 0 {
+		return nil, nil
+	}
+	return m, nil
+}
+
+// Covers reports whether the dependency's ecosystem has been mirrored, or
+// has no OSV records to mirror
+func (m *Mirror) Covers(dep models.Dependency) bool {
+	return dep.Ecosystem.Info().NotInOSV || m.ecosystems[strings.ToLower(dep.Ecosystem.OSVName())]
+}
+
+// Updated returns when the least recently warmed ecosystem was written
+func (m *Mirror) Updated() time.Time {
+	return m.updated
+}
+
+// QueryByPackage matches dependencies against the mirrored records locally,
+// returning the same CVE entries an OSV batch query would
+func (m *Mirror) QueryByPackage(deps []models.Dependency) map[int][]models.CVEInfo {
+	results := make(map[int][]models.CVEInfo)
+	for i, dep := range deps {
+		for _, rec := range m.records {
+			if rec.Withdrawn != nil || !advisories.Affects(rec, dep) {
+				continue
+			}
+			summary := rec.Summary
+			if summary == "" {
+				summary, _, _ = strings.Cut(strings.TrimSpace(rec.Details), "\n")
+			}
+			for _, cveID := range clients.ExtractCVEIDs(rec.ID, rec.Aliases) {
+				results[i] = append(results[i], models.CVEInfo{
+					ID:         cveID,
+					Summary:    summary,
+					Source:     "OSV",
+					AdvisoryID: rec.ID,
+				})
+			}
+		}
+	}
+	return clients.MergeCVEs(results)
+}
+
+// Record returns the mirrored record with the given ID, or nil
+func (m *Mirror) Record(id string) *clients.OSVRecord {
+	for i := range m.records {
+		if m.records[i].ID == id {
+			return &m.records[i]
+		}
+	}
+	return nil
+}
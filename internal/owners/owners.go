@@ -0,0 +1,138 @@
+package owners
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// rule maps a path pattern to the owners responsible for matching files
+type rule struct {
+	pattern *regexp.Regexp
+	owners  []string
+}
+
+// Map assigns owners to file paths using CODEOWNERS rules. As in GitHub's
+// CODEOWNERS, the last matching rule wins.
+type Map struct {
+	root  string
+	rules []rule
+}
+
+// Load reads a CODEOWNERS-style file: one "pattern owner..." rule per line,
+// with # comments. Paths are matched relative to the repository root, which
+// is the parent of a .github or docs directory holding the file, otherwise the
+// file's own directory.
+func Load(path string) (*Map, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read owners file: %w", err)
+	}
+
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+	root := filepath.Dir(abs)
+	if base := filepath.Base(root); base == ".github" || base == "docs" {
+		root = filepath.Dir(root)
+	}
+
+	m := &Map{root: root}
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	lineNum := 0
+	for scanner.Scan() {
+		lineNum++
+		line := strings.TrimSpace(scanner.Text())
+		if i := strings.Index(line, " #"); i >= 0 {
+			line = strings.TrimSpace(line[:i])
+		}
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		fields := strings.Fields(line)
+		re, err := compilePattern(fields[0])
+		if err != nil {
+			return nil, fmt.Errorf("owners file line %d: %w", lineNum, err)
+		}
+		// A pattern with no owners un-assigns matching paths
+		m.rules = append(m.rules, rule{pattern: re, owners: fields[1:]})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read owners file: %w", err)
+	}
+	return m, nil
+}
+
+// Owners returns the owners of a scanned file, or nil if no rule matches or
+// the file is outside the repository root
+func (m *Map) Owners(file string) []string {
+	abs, err := filepath.Abs(file)
+	if err != nil {
+		return nil
+	}
+	rel, err := filepath.Rel(m.root, abs)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return nil
+	}
+	rel = filepath.ToSlash(rel)
+
+	var owners []string
+	for _, r := range m.rules {
+		if r.pattern.MatchString(rel) {
+			owners = r.owners
+		}
+	}
+	return owners
+}
+
+// compilePattern converts a gitignore-style CODEOWNERS pattern to a regexp.
+// Patterns containing a slash (other than a trailing one) are anchored at the
+// root; others match at any depth. A pattern matching a directory also
+// matches everything beneath it.
+func compilePattern(pattern string) (*regexp.Regexp, error) {
+	dirOnly := strings.HasSuffix(pattern, "/")
+	p := strings.TrimSuffix(pattern, "/")
+	anchored := strings.Contains(p, "/")
+	p = strings.TrimPrefix(p, "/")
+	if p == "" {
+		return nil, fmt.Errorf("invalid pattern %q", pattern)
+	}
+
+	var sb strings.Builder
+	if anchored {
+		sb.WriteString("^")
+	} else {
+		sb.WriteString("^(?:.*/)?")
+	}
+	for i := 0; i < len(p); i++ {
+		switch c := p[i]; {
+		case strings.HasPrefix(p[i:], "**/"):
+			sb.WriteString("(?:.*/)?")
+			i += 2
+		case strings.HasPrefix(p[i:], "/**") && i+3 == len(p):
+			sb.WriteString("/.*")
+			i += 2
+		case strings.HasPrefix(p[i:], "**"):
+			sb.WriteString(".*")
+			i++
+		case c == '*':
+			sb.WriteString("[^/]*")
+		case c == '?':
+			sb.WriteString("[^/]")
+		default:
+			sb.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	if dirOnly {
+		sb.WriteString("/.*$")
+	} else {
+		sb.WriteString("(?:/.*)?$")
+	}
+	return regexp.Compile(sb.String())
+}
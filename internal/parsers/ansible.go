@@ -0,0 +1,155 @@
+package parsers
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/ethanolivertroy/kev-check-demo/internal/models"
+	"gopkg.in/yaml.v3"
+)
+
+// AnsibleRequirementsParser parses Ansible Galaxy requirements.yml files
+type AnsibleRequirementsParser struct{}
+
+// CanParse returns true for requirements.yml files
+func (p *AnsibleRequirementsParser) CanParse(filename string) bool {
+	return filename == "requirements.yml" || filename == "requirements.yaml"
+}
+
+// Parse extracts Galaxy roles and collections. The file is either a list of
+// roles or a mapping with "roles" and "collections" lists; entries are a
+// name or a mapping with name (or src), version and type. Roles and
+// collections installed from git, URLs or local paths are skipped.
+func (p *AnsibleRequirementsParser) Parse(filepath string, content []byte) ([]models.Dependency, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(content, &doc); err != nil {
+		return nil, err
+	}
+	if len(doc.Content) == 0 {
+		return nil, nil
+	}
+	lines := strings.Split(string(content), "\n")
+
+	root := doc.Content[0]
+	var entries []*yaml.Node
+	switch root.Kind {
+	case yaml.SequenceNode:
+		// Legacy format: a list of roles
+		entries = root.Content
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(root.Content); i += 2 {
+			key, val := root.Content[i].Value, root.Content[i+1]
+			if (key == "roles" || key == "collections") && val.Kind == yaml.SequenceNode {
+				entries = append(entries, val.Content...)
+			}
+		}
+	default:
+		return nil, nil
+	}
+
+	var deps []models.Dependency
+	for _, entry := range entries {
+		name, version, ok := galaxyRequirement(entry)
+		if !ok {
+			continue
+		}
+		dep := models.Dependency{
+			Name:       strings.ToLower(name),
+			Version:    strings.TrimPrefix(version, "=="),
+			Unpinned:   !isExactGalaxyVersion(version),
+			Ecosystem:  models.EcosystemAnsible,
+			SourceFile: filepath,
+			Line:       entry.Line,
+		}
+		if entry.Line > 0 && entry.Line <= len(lines) {
+			dep.Snippet = strings.TrimRight(lines[entry.Line-1], "\r")
+		}
+		deps = append(deps, dep)
+	}
+	return deps, nil
+}
+
+// galaxyRequirement reads the Galaxy name and version of a requirements.yml
+// entry. It returns false for entries not installed from Galaxy.
+func galaxyRequirement(entry *yaml.Node) (name, version string, ok bool) {
+	switch entry.Kind {
+	case yaml.ScalarNode:
+		name = entry.Value
+	case yaml.MappingNode:
+		fields := make(map[string]string)
+		for i := 0; i+1 < len(entry.Content); i += 2 {
+			fields[entry.Content[i].Value] = entry.Content[i+1].Value
+		}
+		switch fields["type"] {
+		case "", "galaxy":
+		default:
+			return "", "", false // git, url, file, dir or subdirs
+		}
+		if fields["scm"] != "" {
+			return "", "", false
+		}
+		// Roles name their Galaxy source in src; name is then a local alias
+		name = fields["src"]
+		if name == "" {
+			name = fields["name"]
+		}
+		version = strings.TrimSpace(fields["version"])
+	default:
+		return "", "", false
+	}
+
+	// Galaxy names are namespace.name; anything else is a path or URL
+	name = strings.TrimSpace(name)
+	if strings.ContainsAny(name, "/:") || !strings.Contains(name, ".") || strings.HasSuffix(name, ".tar.gz") {
+		return "", "", false
+	}
+	return name, version, true
+}
+
+// isExactGalaxyVersion reports whether a Galaxy version spec names a single
+// version. Collections accept ranges such as ">=1.0.0,<2.0.0" and "*".
+func isExactGalaxyVersion(spec string) bool {
+	spec = strings.TrimPrefix(spec, "==")
+	if spec == "" || strings.ContainsAny(spec, "*<>=!, ") {
+		return false
+	}
+	return spec[0] >= '0' && spec[0] <= '9'
+}
+
+// AnsibleCollectionManifestParser parses the MANIFEST.json of an installed
+// Ansible collection
+type AnsibleCollectionManifestParser struct{}
+
+// CanParse returns true for MANIFEST.json files
+func (p *AnsibleCollectionManifestParser) CanParse(filename string) bool {
+	return filename == "MANIFEST.json"
+}
+
+// collectionManifest is the subset of a collection's MANIFEST.json we read
+type collectionManifest struct {
+	CollectionInfo *struct {
+		Namespace string `json:"namespace"`
+		Name      string `json:"name"`
+		Version   string `json:"version"`
+	} `json:"collection_info"`
+}
+
+// Parse returns the installed collection itself. Files without
+// collection_info are other tools' manifests and yield nothing.
+func (p *AnsibleCollectionManifestParser) Parse(filepath string, content []byte) ([]models.Dependency, error) {
+	var m collectionManifest
+	if err := json.Unmarshal(content, &m); err != nil {
+		return nil, err
+	}
+	info := m.CollectionInfo
+	if info == nil || info.Namespace == "" || info.Name == "" {
+		return nil, nil
+	}
+	return []models.Dependency{{
+		Name:       strings.ToLower(info.Namespace + "." + info.Name),
+		Version:    info.Version,
+		Unpinned:   info.Version == "",
+		Ecosystem:  models.EcosystemAnsible,
+		SourceFile: filepath,
+	}}, nil
+}
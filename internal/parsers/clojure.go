@@ -0,0 +1,99 @@
+package parsers
+
+import (
+	"bytes"
+	"regexp"
+	"strings"
+
+	"github.com/ethanolivertroy/kev-check-demo/internal/models"
+)
+
+// ClojureDepsEdnParser parses deps.edn files
+type ClojureDepsEdnParser struct{}
+
+// CanParse returns true for deps.edn files
+func (p *ClojureDepsEdnParser) CanParse(filename string) bool {
+	return filename == "deps.edn"
+}
+
+// depsEdnPattern matches lib coordinates like
+// org.clojure/clojure {:mvn/version "1.11.1"}. Git and local coordinates
+// have no Maven version and are skipped.
+var depsEdnPattern = regexp.MustCompile(`([A-Za-z0-9_.\-]+(?:/[A-Za-z0-9_.\-]+)?)\s*\{\s*:mvn/version\s+"([^"]+)"`)
+
+// depsEdnLocalPattern matches local coordinates like
+// my.org/shared {:local/root "../shared"}
+var depsEdnLocalPattern = regexp.MustCompile(`([A-Za-z0-9_.\-]+(?:/[A-Za-z0-9_.\-]+)?)\s*\{\s*:local/root\s+"([^"]+)"`)
+
+// Parse extracts Maven dependencies from deps.edn content, including
+// alias :extra-deps. Libs from a :local/root are reported as local.
+func (p *ClojureDepsEdnParser) Parse(filepath string, content []byte) ([]models.Dependency, error) {
+	content = stripClojureComments(content)
+	deps := parseClojureCoordinates(filepath, content, depsEdnPattern)
+	for _, dep := range parseClojureCoordinates(filepath, content, depsEdnLocalPattern) {
+		dep.Version, dep.Local = "", dep.Version
+		deps = append(deps, dep)
+	}
+	return deps, nil
+}
+
+// ClojureProjectParser parses Leiningen project.clj files
+type ClojureProjectParser struct{}
+
+// CanParse returns true for project.clj files
+func (p *ClojureProjectParser) CanParse(filename string) bool {
+	return filename == "project.clj"
+}
+
+// leinDepPattern matches dependency vectors like [ring/ring-core "1.9.6"]
+var leinDepPattern = regexp.MustCompile(`\[\s*([A-Za-z0-9_.\-]+(?:/[A-Za-z0-9_.\-]+)?)\s+"([^"]+)"`)
+
+// Parse extracts Maven dependencies from project.clj content
+func (p *ClojureProjectParser) Parse(filepath string, content []byte) ([]models.Dependency, error) {
+	return parseClojureCoordinates(filepath, stripClojureComments(content), leinDepPattern), nil
+}
+
+// parseClojureCoordinates maps lib symbols to Maven group:artifact names. A
+// symbol without a group (e.g. "cheshire") uses the artifact as group, as
+// both tools do.
+func parseClojureCoordinates(filepath string, content []byte, pattern *regexp.Regexp) []models.Dependency {
+	var deps []models.Dependency
+
+	for _, m := range pattern.FindAllSubmatchIndex(content, -1) {
+		lib := string(content[m[2]:m[3]])
+		group, artifact, ok := strings.Cut(lib, "/")
+		if !ok {
+			artifact = group
+		}
+		deps = append(deps, models.Dependency{
+			Name:       group + ":" + artifact,
+			Version:    string(content[m[4]:m[5]]),
+			Ecosystem:  models.EcosystemMaven,
+			SourceFile: filepath,
+			Line:       lineAt(content, m[0]),
+		})
+	}
+
+	return deps
+}
+
+// stripClojureComments blanks ";" line comments, keeping offsets intact so
+// line numbers still match the original file
+func stripClojureComments(content []byte) []byte {
+	out := bytes.Clone(content)
+	inString := false
+	for i := 0; i < len(out); i++ {
+		switch {
+		case out[i] == '\\' && inString:
+			i++
+		case out[i] == '"':
+			inString = !inString
+		case out[i] == ';' && !inString:
+			for i < len(out) && out[i] != '\n' {
+				out[i] = ' '
+				i++
+			}
+		}
+	}
+	return out
+}
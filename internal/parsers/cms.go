@@ -0,0 +1,133 @@
+package parsers
+
+import (
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/ethanolivertroy/kev-check-demo/internal/models"
+	"gopkg.in/yaml.v3"
+)
+
+// WordPressParser reads the version of installed WordPress plugins and
+// themes from their file headers
+type WordPressParser struct{}
+
+// CanParse returns false: plugins and themes are recognized by their path
+func (p *WordPressParser) CanParse(filename string) bool {
+	return false
+}
+
+// CanParsePath returns true for PHP files directly inside a plugin directory
+// and for theme stylesheets, e.g. wp-content/plugins/akismet/akismet.php and
+// wp-content/themes/twentytwenty/style.css
+func (p *WordPressParser) CanParsePath(file string) bool {
+	return MatchPathSuffix("plugins/*/*.php", file) || MatchPathSuffix("themes/*/style.css", file)
+}
+
+// wordPressHeaderBytes is how far into a file WordPress reads its headers
+const wordPressHeaderBytes = 8192
+
+// wordPressHeader matches a "Name: value" header line in a file comment
+var wordPressHeader = regexp.MustCompile(`(?mi)^[ \t/*#@]*(Plugin Name|Theme Name|Version):[ \t]*(.*)$`)
+
+// Parse returns the plugin or theme declared by the file's header, named by
+// its directory (the WordPress.org slug). Files without a Plugin Name or
+// Theme Name header, such as a plugin's other PHP files, yield nothing.
+func (p *WordPressParser) Parse(filepath string, content []byte) ([]models.Dependency, error) {
+	if len(content) > wordPressHeaderBytes {
+		content = content[:wordPressHeaderBytes]
+	}
+
+	var named bool
+	var version string
+	var line int
+	for _, m := range wordPressHeader.FindAllSubmatchIndex(content, -1) {
+		field := strings.ToLower(string(content[m[2]:m[3]]))
+		value := strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(string(content[m[4]:m[5]])), "*/"))
+		switch {
+		case field == "version" && version == "":
+			version = value
+			line = lineAt(content, m[0])
+		case field != "version" && value != "":
+			named = true
+		}
+	}
+	if !named {
+		return nil, nil
+	}
+
+	dep := models.Dependency{
+		Name:       strings.ToLower(path.Base(path.Dir(strings.ReplaceAll(filepath, "\\", "/")))),
+		Version:    version,
+		Unpinned:   version == "",
+		Ecosystem:  models.EcosystemWordPress,
+		SourceFile: filepath,
+		Line:       line,
+	}
+	if line > 0 {
+		dep.Snippet = strings.TrimRight(strings.Split(string(content), "\n")[line-1], "\r")
+	}
+	return []models.Dependency{dep}, nil
+}
+
+// DrupalInfoParser parses the .info.yml files of Drupal modules, themes and
+// profiles
+type DrupalInfoParser struct{}
+
+// CanParse returns true for .info.yml files
+func (p *DrupalInfoParser) CanParse(filename string) bool {
+	return strings.HasSuffix(filename, ".info.yml")
+}
+
+// drupalInfo is the subset of a .info.yml file we read. Releases packaged by
+// drupal.org add project and version.
+type drupalInfo struct {
+	Project string `yaml:"project"`
+	Version string `yaml:"version"`
+}
+
+// Parse returns the Drupal project the file belongs to, as its Composer
+// package on Packagist (drupal/<project>, or drupal/core for core). A project
+// is reported once, from the extension named after it (or core's system
+// module), rather than from each of its submodules.
+func (p *DrupalInfoParser) Parse(filepath string, content []byte) ([]models.Dependency, error) {
+	var info drupalInfo
+	if err := yaml.Unmarshal(content, &info); err != nil {
+		return nil, err
+	}
+
+	file := strings.ReplaceAll(filepath, "\\", "/")
+	machineName := strings.TrimSuffix(path.Base(file), ".info.yml")
+	project := info.Project
+	switch {
+	case project != "":
+	case strings.Contains(file, "core/modules/") || strings.Contains(file, "core/themes/") || strings.Contains(file, "core/profiles/"):
+		project = "drupal" // A core checkout without packaging information
+	default:
+		project = machineName
+	}
+	switch {
+	case project == "drupal" && machineName == "system":
+		project = "core"
+	case project == "drupal" || project != machineName:
+		return nil, nil
+	}
+
+	// Contrib versions carry the core compatibility prefix, e.g. 8.x-3.14;
+	// "VERSION" and -dev releases are development snapshots
+	version := info.Version
+	if i := strings.Index(version, ".x-"); i >= 0 {
+		version = version[i+len(".x-"):]
+	}
+	if version == "VERSION" || strings.HasSuffix(version, "-dev") {
+		version = ""
+	}
+	return []models.Dependency{{
+		Name:       "drupal/" + strings.ToLower(project),
+		Version:    version,
+		Unpinned:   version == "",
+		Ecosystem:  models.EcosystemPackagist,
+		SourceFile: filepath,
+	}}, nil
+}
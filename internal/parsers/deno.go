@@ -0,0 +1,234 @@
+package parsers
+
+import (
+	"encoding/json"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/ethanolivertroy/kev-check-demo/internal/models"
+)
+
+// Deno projects pull npm packages through npm: specifiers or CDN URLs. JSR
+// packages (jsr: specifiers) have no OSV ecosystem, so they are not reported.
+
+// DenoLockParser parses deno.lock files
+type DenoLockParser struct{}
+
+// CanParse returns true for deno.lock files
+func (p *DenoLockParser) CanParse(filename string) bool {
+	return filename == "deno.lock"
+}
+
+// denoLock covers lockfile versions 2 and 3, which nest the package tables,
+// and 4 onwards, which keep them at the top level
+type denoLock struct {
+	Specifiers map[string]string          `json:"specifiers"`
+	Npm        json.RawMessage            `json:"npm"`
+	Packages   *denoPackages              `json:"packages"`
+	Remote     map[string]json.RawMessage `json:"remote"`
+}
+
+// denoPackages is the package section of a version 2 or 3 lockfile
+type denoPackages struct {
+	Specifiers map[string]string          `json:"specifiers"`
+	Npm        map[string]json.RawMessage `json:"npm"`
+	Packages   map[string]json.RawMessage `json:"packages"` // Version 2, under "npm"
+}
+
+// Parse extracts resolved npm packages from deno.lock content, along with
+// npm packages imported from CDN URLs in its "remote" section
+func (p *DenoLockParser) Parse(filepath string, content []byte) ([]models.Dependency, error) {
+	var lock denoLock
+	if err := json.Unmarshal(content, &lock); err != nil {
+		return nil, err
+	}
+
+	specifiers := lock.Specifiers
+	var npm map[string]json.RawMessage
+	switch {
+	case lock.Packages != nil:
+		// Version 3
+		specifiers = lock.Packages.Specifiers
+		npm = lock.Packages.Npm
+	case len(lock.Npm) > 0:
+		// Version 2 nests packages and specifiers under "npm"; version 4
+		// maps packages directly
+		var v2 denoPackages
+		if err := json.Unmarshal(lock.Npm, &v2); err == nil && v2.Packages != nil {
+			specifiers = v2.Specifiers
+			npm = v2.Packages
+		} else if err := json.Unmarshal(lock.Npm, &npm); err != nil {
+			return nil, err
+		}
+	}
+
+	// Packages named by the project's own npm: specifiers are direct
+	direct := make(map[string]bool)
+	for spec := range specifiers {
+		spec = strings.TrimPrefix(spec, "npm:")
+		if name, _ := splitNpmSpecifier(spec); name != "" {
+			direct[name] = true
+		}
+	}
+
+	keys := make([]string, 0, len(npm))
+	for key := range npm {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var deps []models.Dependency
+	seen := make(map[string]bool)
+	for _, key := range keys {
+		// Keys are "name@version", with peer dependencies appended after
+		// an underscore: "name@version_peer@1.0.0"
+		name, version := splitNpmSpecifier(key)
+		version, _, _ = strings.Cut(version, "_")
+		if name == "" || version == "" || seen[name+"@"+version] {
+			continue
+		}
+		seen[name+"@"+version] = true
+		deps = append(deps, models.Dependency{
+			Name:       name,
+			Version:    version,
+			Ecosystem:  models.EcosystemNpm,
+			SourceFile: filepath,
+			Transitive: !direct[name],
+		})
+	}
+
+	remotes := make([]string, 0, len(lock.Remote))
+	for u := range lock.Remote {
+		remotes = append(remotes, u)
+	}
+	sort.Strings(remotes)
+	for _, u := range remotes {
+		name, version := npmFromCDN(u)
+		if name == "" || !isExactNpmVersion(version) || seen[name+"@"+version] {
+			continue
+		}
+		seen[name+"@"+version] = true
+		deps = append(deps, models.Dependency{
+			Name:       name,
+			Version:    version,
+			Ecosystem:  models.EcosystemNpm,
+			SourceFile: filepath,
+		})
+	}
+	return deps, nil
+}
+
+// DenoImportMapParser parses the import map in deno.json or deno.jsonc, or a
+// standalone import_map.json
+type DenoImportMapParser struct{}
+
+// CanParse returns true for deno.json, deno.jsonc and import_map.json files
+func (p *DenoImportMapParser) CanParse(filename string) bool {
+	return filename == "deno.json" || filename == "deno.jsonc" || filename == "import_map.json"
+}
+
+// importMap is the subset of an import map, or deno.json, we read
+type importMap struct {
+	Imports map[string]string            `json:"imports"`
+	Scopes  map[string]map[string]string `json:"scopes"`
+}
+
+// Parse extracts npm packages mapped by npm: specifiers or CDN URLs. Ranges
+// such as npm:lodash@^4 are reported as unpinned.
+func (p *DenoImportMapParser) Parse(filepath string, content []byte) ([]models.Dependency, error) {
+	var m importMap
+	if err := json.Unmarshal(stripJSONC(content), &m); err != nil {
+		return nil, err
+	}
+
+	targets := make([]string, 0, len(m.Imports))
+	for _, target := range m.Imports {
+		targets = append(targets, target)
+	}
+	for _, scope := range m.Scopes {
+		for _, target := range scope {
+			targets = append(targets, target)
+		}
+	}
+	sort.Strings(targets)
+
+	var deps []models.Dependency
+	seen := make(map[string]bool)
+	for _, target := range targets {
+		var name, version string
+		if spec, ok := strings.CutPrefix(target, "npm:"); ok {
+			name, version = splitNpmSpecifier(spec)
+		} else {
+			name, version = npmFromCDN(target)
+		}
+		if name == "" || seen[name+"@"+version] {
+			continue
+		}
+		seen[name+"@"+version] = true
+		deps = append(deps, models.Dependency{
+			Name:       name,
+			Version:    cleanNpmVersion(version),
+			Unpinned:   !isExactNpmVersion(version),
+			Ecosystem:  models.EcosystemNpm,
+			SourceFile: filepath,
+		})
+	}
+	return deps, nil
+}
+
+// splitNpmSpecifier splits "name@version/subpath" or "@scope/name@version"
+// into the package name and version, dropping any subpath
+func splitNpmSpecifier(spec string) (name, version string) {
+	spec = strings.TrimPrefix(spec, "/")
+
+	// A scoped name keeps its first slash
+	start := 0
+	if strings.HasPrefix(spec, "@") {
+		slash := strings.Index(spec, "/")
+		if slash < 0 {
+			return "", ""
+		}
+		start = slash + 1
+	}
+
+	rest := spec[start:]
+	i := strings.IndexAny(rest, "@/")
+	if i < 0 {
+		return spec, ""
+	}
+	if rest[i] == '@' {
+		version, _, _ = strings.Cut(rest[i+1:], "/")
+	}
+	return spec[:start+i], version
+}
+
+// cdnVersionSegment matches esm.sh build prefixes such as /v135/ and /stable/
+var cdnVersionSegment = regexp.MustCompile(`^/(v\d+|stable)/`)
+
+// npmFromCDN returns the npm package and version served by a CDN URL such as
+// https://esm.sh/preact@10.19.2 or https://cdn.jsdelivr.net/npm/lodash@4.17.21
+func npmFromCDN(rawURL string) (name, version string) {
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "https" && u.Scheme != "http") {
+		return "", ""
+	}
+
+	path := u.Path
+	switch u.Host {
+	case "esm.sh", "cdn.skypack.dev", "unpkg.com", "esm.run":
+		path = cdnVersionSegment.ReplaceAllString(path, "/")
+		path = strings.TrimPrefix(path, "/*") // esm.sh's external-dependencies marker
+	case "cdn.jsdelivr.net":
+		var ok bool
+		if path, ok = strings.CutPrefix(path, "/npm/"); !ok {
+			return "", ""
+		}
+	case "ga.jspm.io", "jspm.dev":
+		path = strings.TrimPrefix(strings.TrimPrefix(path, "/"), "npm:")
+	default:
+		return "", ""
+	}
+	return splitNpmSpecifier(path)
+}
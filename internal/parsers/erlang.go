@@ -0,0 +1,37 @@
+package parsers
+
+import (
+	"regexp"
+
+	"github.com/ethanolivertroy/kev-check-demo/internal/models"
+)
+
+// ErlangRebarLockParser parses rebar.lock files
+type ErlangRebarLockParser struct{}
+
+// CanParse returns true for rebar.lock files
+func (p *ErlangRebarLockParser) CanParse(filename string) bool {
+	return filename == "rebar.lock"
+}
+
+// rebarPkgPattern matches Hex package locks such as
+// {<<"cowboy">>,{pkg,<<"cowboy">>,<<"2.9.0">>},0}. Git and path
+// dependencies have no Hex version and are skipped.
+var rebarPkgPattern = regexp.MustCompile(`\{pkg\s*,\s*<<"([^"]+)">>\s*,\s*<<"([^"]+)">>`)
+
+// Parse extracts Hex packages from rebar.lock content
+func (p *ErlangRebarLockParser) Parse(filepath string, content []byte) ([]models.Dependency, error) {
+	var deps []models.Dependency
+
+	for _, m := range rebarPkgPattern.FindAllSubmatchIndex(content, -1) {
+		deps = append(deps, models.Dependency{
+			Name:       string(content[m[2]:m[3]]),
+			Version:    string(content[m[4]:m[5]]),
+			Ecosystem:  models.EcosystemHex,
+			SourceFile: filepath,
+			Line:       lineAt(content, m[0]),
+		})
+	}
+
+	return deps, nil
+}
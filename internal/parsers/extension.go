@@ -0,0 +1,90 @@
+package parsers
+
+import (
+	"bytes"
+	"encoding/json"
+	"strconv"
+	"strings"
+
+	"github.com/ethanolivertroy/kev-check-demo/internal/models"
+)
+
+// WebExtensionManifestParser parses browser extension (Chrome, Firefox and
+// Edge) manifest.json files for the libraries they bundle
+type WebExtensionManifestParser struct{}
+
+// CanParse returns true for manifest.json files
+func (p *WebExtensionManifestParser) CanParse(filename string) bool {
+	return filename == "manifest.json"
+}
+
+// webExtensionManifest is the subset of an extension manifest we read
+type webExtensionManifest struct {
+	ManifestVersion int `json:"manifest_version"`
+	ContentScripts  []struct {
+		JS []string `json:"js"`
+	} `json:"content_scripts"`
+	Background struct {
+		Scripts       []string `json:"scripts"`
+		ServiceWorker string   `json:"service_worker"`
+	} `json:"background"`
+	// Manifest V2 lists paths; V3 lists objects with a resources list
+	WebAccessibleResources []json.RawMessage `json:"web_accessible_resources"`
+}
+
+// Parse returns the libraries among the extension's scripts whose file names
+// carry a version, e.g. lib/jquery-3.3.1.min.js. Other manifest.json files,
+// such as web app manifests, have no manifest_version and yield nothing.
+// Libraries installed with npm are found by the package.json parsers instead.
+func (p *WebExtensionManifestParser) Parse(filepath string, content []byte) ([]models.Dependency, error) {
+	var m webExtensionManifest
+	if err := json.Unmarshal(content, &m); err != nil {
+		return nil, err
+	}
+	if m.ManifestVersion == 0 {
+		return nil, nil
+	}
+
+	var scripts []string
+	for _, cs := range m.ContentScripts {
+		scripts = append(scripts, cs.JS...)
+	}
+	scripts = append(scripts, m.Background.Scripts...)
+	if m.Background.ServiceWorker != "" {
+		scripts = append(scripts, m.Background.ServiceWorker)
+	}
+	for _, raw := range m.WebAccessibleResources {
+		var resource string
+		var v3 struct {
+			Resources []string `json:"resources"`
+		}
+		if json.Unmarshal(raw, &resource) == nil {
+			scripts = append(scripts, resource)
+		} else if json.Unmarshal(raw, &v3) == nil {
+			scripts = append(scripts, v3.Resources...)
+		}
+	}
+
+	lines := strings.Split(string(content), "\n")
+	var deps []models.Dependency
+	seen := make(map[string]bool)
+	for _, script := range scripts {
+		name, version := jsLibraryFromFilename(script)
+		if name == "" || version == "" || seen[name+"@"+version] {
+			continue
+		}
+		seen[name+"@"+version] = true
+		dep := models.Dependency{
+			Name:       name,
+			Version:    version,
+			Ecosystem:  models.EcosystemNpm,
+			SourceFile: filepath,
+		}
+		if i := bytes.Index(content, []byte(strconv.Quote(script))); i >= 0 {
+			dep.Line = lineAt(content, i)
+			dep.Snippet = strings.TrimRight(lines[dep.Line-1], "\r")
+		}
+		deps = append(deps, dep)
+	}
+	return deps, nil
+}
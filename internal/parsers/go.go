@@ -5,6 +5,7 @@ import (
 
 	"github.com/ethanolivertroy/kev-check-demo/internal/models"
 	"golang.org/x/mod/modfile"
+	"golang.org/x/mod/module"
 )
 
 // GoModParser parses go.mod files
@@ -17,7 +18,12 @@ func (p *GoModParser) CanParse(filename string) bool {
 	return filename == "go.mod"
 }
 
-// Parse extracts dependencies from go.mod content
+// Parse extracts dependencies from go.mod content. A replace directive
+// pinning the same module to another version changes the version reported;
+// one pointing at a fork or a local directory is recorded as the
+// dependency's Replacement, and the original module is still checked. A
+// module that only exists locally, required at a placeholder version and
+// replaced by a directory, is reported as local.
 func (p *GoModParser) Parse(filepath string, content []byte) ([]models.Dependency, error) {
 	mod, err := modfile.Parse(filepath, content, nil)
 	if err != nil {
@@ -32,17 +38,53 @@ func (p *GoModParser) Parse(filepath string, content []byte) ([]models.Dependenc
 			continue
 		}
 
-		// Clean up version (remove v prefix for OSV)
-		version := req.Mod.Version
-		version = strings.TrimPrefix(version, "v")
-
-		deps = append(deps, models.Dependency{
+		// The module path is kept as written, including any /vN suffix
+		dep := models.Dependency{
 			Name:       req.Mod.Path,
-			Version:    version,
+			Version:    models.EcosystemGo.Info().ParseVersion(req.Mod.Version),
 			Ecosystem:  models.EcosystemGo,
 			SourceFile: filepath,
-		})
+			Transitive: req.Indirect,
+		}
+		if rep := replacement(mod.Replace, req.Mod); rep != nil {
+			switch {
+			case rep.New.Version == "" && isPlaceholderVersion(req.Mod.Version):
+				dep.Version, dep.Local = "", rep.New.Path
+			case rep.New.Version == "":
+				dep.Replacement = rep.New.Path // Local directory
+			case rep.New.Path == req.Mod.Path:
+				dep.Version = models.EcosystemGo.Info().ParseVersion(rep.New.Version)
+			default:
+				dep.Replacement = rep.New.Path + "@" + rep.New.Version
+			}
+		}
+		deps = append(deps, dep)
 	}
 
 	return deps, nil
 }
+
+// isPlaceholderVersion reports whether a required version is one used for
+// modules that are never published: v0.0.0, or the zero pseudo-version
+// go mod tidy writes for them
+func isPlaceholderVersion(version string) bool {
+	return version == "v0.0.0" || strings.HasPrefix(version, "v0.0.0-00010101000000-")
+}
+
+// replacement returns the replace directive applying to a required module:
+// one for its exact version, or else one for every version
+func replacement(replaces []*modfile.Replace, mod module.Version) *modfile.Replace {
+	var wildcard *modfile.Replace
+	for _, rep := range replaces {
+		if rep.Old.Path != mod.Path {
+			continue
+		}
+		if rep.Old.Version == mod.Version {
+			return rep
+		}
+		if rep.Old.Version == "" {
+			wildcard = rep
+		}
+	}
+	return wildcard
+}
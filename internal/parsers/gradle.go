@@ -0,0 +1,152 @@
+package parsers
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/ethanolivertroy/kev-check-demo/internal/models"
+)
+
+// GradleLockfileParser parses gradle.lockfile files, which pin every module
+// in the locked configurations, transitive ones included
+type GradleLockfileParser struct{}
+
+// CanParse returns true for gradle.lockfile files
+func (p *GradleLockfileParser) CanParse(filename string) bool {
+	return filename == "gradle.lockfile"
+}
+
+// Parse extracts the locked modules from gradle.lockfile content, one
+// group:artifact:version=configurations line each. Modules locked only in
+// test configurations are marked as dev dependencies.
+func (p *GradleLockfileParser) Parse(filepath string, content []byte) ([]models.Dependency, error) {
+	var deps []models.Dependency
+	for i, raw := range strings.Split(string(content), "\n") {
+		line := strings.TrimSpace(raw)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		coords, configurations, _ := strings.Cut(line, "=")
+		parts := strings.Split(coords, ":")
+		if len(parts) != 3 {
+			continue // "empty=..." lists configurations without modules
+		}
+		dev := configurations != ""
+		for _, conf := range strings.Split(configurations, ",") {
+			dev = dev && isGradleTestConfiguration(conf)
+		}
+		deps = append(deps, models.Dependency{
+			Name:       parts[0] + ":" + parts[1],
+			Version:    parts[2],
+			Ecosystem:  models.EcosystemMaven,
+			SourceFile: filepath,
+			Line:       i + 1,
+			Snippet:    line,
+			Dev:        dev,
+		})
+	}
+	return deps, nil
+}
+
+// GradleBuildParser parses build.gradle and build.gradle.kts files (direct
+// dependencies only)
+type GradleBuildParser struct{}
+
+// CanParse returns true for Groovy and Kotlin build scripts
+func (p *GradleBuildParser) CanParse(filename string) bool {
+	return filename == "build.gradle" || filename == "build.gradle.kts"
+}
+
+// gradleStringPattern matches string coordinates passed to a configuration,
+// such as implementation "g:a:1.0" or testImplementation("g:a:1.0:tests")
+var gradleStringPattern = regexp.MustCompile(`\b([a-z][A-Za-z]*)\s*\(?\s*["']([^"'\s:$]+):([^"'\s:$]+):([^"'\s:@]+)(?::[^"'\s@]*)?(?:@[A-Za-z]+)?["']`)
+
+// gradleMapPattern matches Groovy map coordinates such as
+// implementation group: 'g', name: 'a', version: '1.0'
+var gradleMapPattern = regexp.MustCompile(`\b([a-z][A-Za-z]*)\s*\(?\s*group\s*[:=]\s*["']([^"']+)["']\s*,\s*name\s*[:=]\s*["']([^"']+)["']\s*,\s*version\s*[:=]\s*["']([^"']+)["']`)
+
+// gradleConfigurations are the dependency configurations of the Java and
+// Android plugins, without any source set prefix
+var gradleConfigurations = []string{
+	"implementation", "api", "compileOnly", "runtimeOnly", "compileOnlyApi",
+	"annotationProcessor", "kapt", "ksp", "compile", "runtime",
+}
+
+// Parse extracts the dependencies a build script declares as literal
+// coordinates. Versions from variables or version catalogs, dynamic
+// versions ("1.+", "latest.release") and ranges are reported as unpinned.
+// Test configurations are marked as dev dependencies. Commented-out lines
+// are skipped; platform, project and buildscript classpath dependencies
+// aren't read.
+func (p *GradleBuildParser) Parse(filepath string, content []byte) ([]models.Dependency, error) {
+	var deps []models.Dependency
+	inComment := false
+	for i, raw := range strings.Split(string(content), "\n") {
+		line := strings.TrimSpace(raw)
+		if inComment {
+			if _, rest, ok := strings.Cut(line, "*/"); ok {
+				inComment, line = false, rest
+			} else {
+				continue
+			}
+		}
+		if before, _, ok := strings.Cut(line, "/*"); ok && !strings.Contains(line, "*/") {
+			inComment, line = true, before
+		}
+		if strings.HasPrefix(line, "//") {
+			continue
+		}
+
+		for _, pattern := range []*regexp.Regexp{gradleStringPattern, gradleMapPattern} {
+			for _, m := range pattern.FindAllStringSubmatch(line, -1) {
+				if !isGradleConfiguration(m[1]) {
+					continue
+				}
+				dep := models.Dependency{
+					Name:       m[2] + ":" + m[3],
+					Ecosystem:  models.EcosystemMaven,
+					SourceFile: filepath,
+					Line:       i + 1,
+					Snippet:    line,
+					Dev:        isGradleTestConfiguration(m[1]),
+				}
+				if isExactGradleVersion(m[4]) {
+					dep.Version = m[4]
+				} else {
+					dep.Unpinned = true
+				}
+				deps = append(deps, dep)
+			}
+		}
+	}
+	return deps, nil
+}
+
+// isGradleConfiguration reports whether name is a dependency configuration,
+// possibly prefixed by a source set as in testImplementation or
+// debugRuntimeOnly
+func isGradleConfiguration(name string) bool {
+	for _, conf := range gradleConfigurations {
+		if name == conf || strings.HasSuffix(name, strings.ToUpper(conf[:1])+conf[1:]) {
+			return true
+		}
+	}
+	return false
+}
+
+// isGradleTestConfiguration reports whether a configuration only serves
+// tests, e.g. testImplementation, testRuntimeClasspath or
+// androidTestImplementation
+func isGradleTestConfiguration(name string) bool {
+	name = strings.TrimSpace(name)
+	return strings.HasPrefix(name, "test") || strings.Contains(name, "Test")
+}
+
+// isExactGradleVersion reports whether a version names a single release.
+// Interpolated variables, dynamic versions and ranges don't.
+func isExactGradleVersion(version string) bool {
+	if version == "" || strings.HasPrefix(version, "latest.") {
+		return false
+	}
+	return !strings.ContainsAny(version, "$+[](),")
+}
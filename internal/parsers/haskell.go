@@ -0,0 +1,126 @@
+package parsers
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/ethanolivertroy/kev-check-demo/internal/models"
+)
+
+// HaskellStackLockParser parses stack.yaml.lock files
+type HaskellStackLockParser struct{}
+
+// CanParse returns true for stack.yaml.lock files
+func (p *HaskellStackLockParser) CanParse(filename string) bool {
+	return filename == "stack.yaml.lock"
+}
+
+// stackHackagePattern matches "hackage: name-1.2.3@sha256:..." entries.
+// Package names may contain dashes, so the version is the last dash-separated
+// component starting with a digit.
+var stackHackagePattern = regexp.MustCompile(`^\s*-?\s*hackage:\s*([A-Za-z0-9][A-Za-z0-9-]*?)-([0-9][0-9.]*)(?:@|\s|$)`)
+
+// Parse extracts Hackage packages from stack.yaml.lock content. Each package
+// appears under both "completed" and "original"; duplicates are dropped.
+func (p *HaskellStackLockParser) Parse(filepath string, content []byte) ([]models.Dependency, error) {
+	var deps []models.Dependency
+	seen := make(map[string]bool)
+
+	for lineNum, line := range strings.Split(string(content), "\n") {
+		matches := stackHackagePattern.FindStringSubmatch(line)
+		if matches == nil {
+			continue
+		}
+		name, version := matches[1], matches[2]
+		if seen[name+"@"+version] {
+			continue
+		}
+		seen[name+"@"+version] = true
+
+		deps = append(deps, models.Dependency{
+			Name:       name,
+			Version:    version,
+			Ecosystem:  models.EcosystemHackage,
+			SourceFile: filepath,
+			Line:       lineNum + 1,
+		})
+	}
+
+	return deps, nil
+}
+
+// HaskellCabalFreezeParser parses cabal.project.freeze files
+type HaskellCabalFreezeParser struct{}
+
+// CanParse returns true for cabal.project.freeze files
+func (p *HaskellCabalFreezeParser) CanParse(filename string) bool {
+	return filename == "cabal.project.freeze"
+}
+
+// Parse extracts pinned packages from the constraints field of a cabal
+// freeze file, e.g. "constraints: any.aeson ==2.1.2.1, any.base ==4.16.4.0".
+// Flag-only constraints ("aeson -ordered-keymap") are skipped.
+func (p *HaskellCabalFreezeParser) Parse(filepath string, content []byte) ([]models.Dependency, error) {
+	var deps []models.Dependency
+	inConstraints := false
+
+	for lineNum, line := range strings.Split(string(content), "\n") {
+		trimmed := strings.TrimSpace(line)
+
+		// A new top-level field ends the constraints block
+		if line != "" && line[0] != ' ' && line[0] != '\t' {
+			inConstraints = false
+			if rest, ok := strings.CutPrefix(line, "constraints:"); ok {
+				inConstraints = true
+				trimmed = strings.TrimSpace(rest)
+			}
+		}
+		if !inConstraints || trimmed == "" {
+			continue
+		}
+
+		for _, constraint := range strings.Split(trimmed, ",") {
+			name, version := parseCabalConstraint(constraint)
+			if name == "" || version == "" {
+				continue
+			}
+			deps = append(deps, models.Dependency{
+				Name:       name,
+				Version:    version,
+				Ecosystem:  models.EcosystemHackage,
+				SourceFile: filepath,
+				Line:       lineNum + 1,
+			})
+		}
+	}
+
+	return deps, nil
+}
+
+// parseCabalConstraint parses "any.name [+flag -flag] ==version". Qualified
+// constraints other than "any." (e.g. "setup.Cabal") apply to build tooling
+// and are skipped.
+func parseCabalConstraint(constraint string) (name, version string) {
+	fields := strings.Fields(constraint)
+	if len(fields) == 0 {
+		return "", ""
+	}
+
+	name = fields[0]
+	if qualifier, rest, ok := strings.Cut(name, "."); ok {
+		if qualifier != "any" {
+			return "", ""
+		}
+		name = rest
+	}
+
+	for i, f := range fields[1:] {
+		if v, ok := strings.CutPrefix(f, "=="); ok {
+			if v == "" && i+2 < len(fields) {
+				v = fields[i+2]
+			}
+			return name, v
+		}
+	}
+	return name, ""
+}
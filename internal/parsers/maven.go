@@ -0,0 +1,218 @@
+package parsers
+
+import (
+	"bytes"
+	"encoding/xml"
+	"io"
+	"regexp"
+	"strings"
+
+	"github.com/ethanolivertroy/kev-check-demo/internal/models"
+)
+
+// MavenPomParser parses Maven pom.xml files
+type MavenPomParser struct{}
+
+// CanParse returns true for pom.xml files
+func (p *MavenPomParser) CanParse(filename string) bool {
+	return filename == "pom.xml"
+}
+
+// pomDependency is a <dependency> of a pom.xml
+type pomDependency struct {
+	GroupID    string `xml:"groupId"`
+	ArtifactID string `xml:"artifactId"`
+	Version    string `xml:"version"`
+	Scope      string `xml:"scope"`
+	line       int
+}
+
+// pomProperty matches a ${name} placeholder
+var pomProperty = regexp.MustCompile(`\$\{([^}]+)\}`)
+
+// Parse extracts the dependencies declared in <dependencies> of a pom.xml,
+// resolving ${...} placeholders from <properties> and the project's own
+// coordinates. <dependencyManagement> only constrains versions, so its
+// entries aren't dependencies themselves: a dependency without a version or
+// scope takes them from <dependencyManagement> in the same pom; versions
+// managed by a parent or an imported BOM, ranges and unresolved placeholders
+// are reported as unpinned. Test-scoped dependencies are marked as dev
+// dependencies, and BOM imports are skipped. Dependencies of plugins and
+// profiles aren't read.
+func (p *MavenPomParser) Parse(filepath string, content []byte) ([]models.Dependency, error) {
+	pom, err := readPom(content)
+	if err != nil {
+		return nil, err
+	}
+
+	managed := make(map[string]pomDependency)
+	for _, d := range pom.managed {
+		name := pom.resolve(d.GroupID) + ":" + pom.resolve(d.ArtifactID)
+		if _, ok := managed[name]; !ok {
+			managed[name] = d
+		}
+	}
+
+	var deps []models.Dependency
+	seen := make(map[string]bool)
+	for _, d := range pom.dependencies {
+		name := pom.resolve(d.GroupID) + ":" + pom.resolve(d.ArtifactID)
+		if d.GroupID == "" || d.ArtifactID == "" || strings.Contains(name, "${") || seen[name] {
+			continue
+		}
+		seen[name] = true
+
+		version := pom.resolve(d.Version)
+		if version == "" {
+			version = pom.resolve(managed[name].Version)
+		}
+		scope := d.Scope
+		if scope == "" {
+			scope = managed[name].Scope
+		}
+		dep := models.Dependency{
+			Name:       name,
+			Ecosystem:  models.EcosystemMaven,
+			SourceFile: filepath,
+			Dev:        scope == "test",
+			Line:       d.line,
+		}
+		if isExactMavenVersion(version) {
+			dep.Version = version
+		} else {
+			dep.Unpinned = true
+		}
+		deps = append(deps, dep)
+	}
+	return deps, nil
+}
+
+// pomFile is what Parse reads from a pom.xml
+type pomFile struct {
+	properties   map[string]string
+	dependencies []pomDependency
+	managed      []pomDependency // From <dependencyManagement>
+}
+
+// readPom walks a pom.xml, recording the line of each dependency. Only
+// elements directly under <project> are read, so plugin and profile
+// dependencies are left out.
+func readPom(content []byte) (*pomFile, error) {
+	pom := &pomFile{properties: make(map[string]string)}
+	dec := xml.NewDecoder(bytes.NewReader(content))
+	var path []string
+	for {
+		offset := dec.InputOffset()
+		tok, err := dec.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		switch t := tok.(type) {
+		case xml.StartElement:
+			at := strings.Join(append(path, t.Name.Local), "/")
+			switch {
+			case strings.HasPrefix(at, "project/properties/") && len(path) == 2:
+				var value string
+				if err := dec.DecodeElement(&value, &t); err != nil {
+					return nil, err
+				}
+				pom.properties[t.Name.Local] = strings.TrimSpace(value)
+				continue
+			case at == "project/groupId", at == "project/artifactId", at == "project/version",
+				at == "project/parent/groupId", at == "project/parent/version":
+				var value string
+				if err := dec.DecodeElement(&value, &t); err != nil {
+					return nil, err
+				}
+				pom.properties[pomCoordinate(at)] = strings.TrimSpace(value)
+				continue
+			case at == "project/dependencies/dependency", at == "project/dependencyManagement/dependencies/dependency":
+				d := pomDependency{line: lineAt(content, int(offset))}
+				if err := dec.DecodeElement(&d, &t); err != nil {
+					return nil, err
+				}
+				if strings.HasPrefix(at, "project/dependencies/") {
+					pom.dependencies = append(pom.dependencies, d)
+				} else {
+					pom.managed = append(pom.managed, d)
+				}
+				continue
+			}
+			path = append(path, t.Name.Local)
+		case xml.EndElement:
+			if len(path) > 0 {
+				path = path[:len(path)-1]
+			}
+		}
+	}
+	return pom, nil
+}
+
+// pomCoordinate returns the property name Maven gives a project coordinate,
+// e.g. "project.version" for project/version
+func pomCoordinate(at string) string {
+	return strings.ReplaceAll(at, "/", ".")
+}
+
+// resolve substitutes ${...} placeholders from the pom's properties. Maven's
+// legacy "pom." and bare "version" aliases and the parent's coordinates are
+// recognized; placeholders that can't be resolved are left in place.
+func (p *pomFile) resolve(s string) string {
+	s = strings.TrimSpace(s)
+	for range 10 { // Properties may reference properties
+		if !strings.Contains(s, "${") {
+			return s
+		}
+		next := pomProperty.ReplaceAllStringFunc(s, func(m string) string {
+			name := m[2 : len(m)-1]
+			if v, ok := p.property(name); ok {
+				return v
+			}
+			return m
+		})
+		if next == s {
+			break
+		}
+		s = next
+	}
+	return s
+}
+
+// property looks up a property or project coordinate
+func (p *pomFile) property(name string) (string, bool) {
+	if v, ok := p.properties[name]; ok {
+		return v, true
+	}
+	switch name {
+	case "version", "pom.version":
+		name = "project.version"
+	case "pom.groupId":
+		name = "project.groupId"
+	case "pom.artifactId":
+		name = "project.artifactId"
+	}
+	if v, ok := p.properties[name]; ok {
+		return v, true
+	}
+	// Projects inherit their group and version from the parent
+	if rest, ok := strings.CutPrefix(name, "project."); ok {
+		if v, ok := p.properties["project.parent."+rest]; ok {
+			return v, true
+		}
+	}
+	return "", false
+}
+
+// isExactMavenVersion reports whether a version names a single release.
+// Ranges such as "[1.0,2.0)", LATEST, RELEASE and unresolved placeholders
+// don't.
+func isExactMavenVersion(version string) bool {
+	if version == "" || version == "LATEST" || version == "RELEASE" {
+		return false
+	}
+	return !strings.ContainsAny(version, "[](),$ ")
+}
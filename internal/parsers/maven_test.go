@@ -0,0 +1,100 @@
+package parsers
+
+import (
+	"testing"
+)
+
+func TestMavenPomParser(t *testing.T) {
+	const pom = `<project>
+  <groupId>com.example</groupId>
+  <artifactId>app</artifactId>
+  <version>1.0.0</version>
+  <properties>
+    <log4j.version>2.14.1</log4j.version>
+  </properties>
+  <dependencyManagement>
+    <dependencies>
+      <dependency>
+        <groupId>org.apache.logging.log4j</groupId>
+        <artifactId>log4j-core</artifactId>
+        <version>${log4j.version}</version>
+      </dependency>
+      <dependency>
+        <groupId>junit</groupId>
+        <artifactId>junit</artifactId>
+        <version>4.13.2</version>
+        <scope>test</scope>
+      </dependency>
+      <dependency>
+        <groupId>org.springframework</groupId>
+        <artifactId>spring-core</artifactId>
+        <version>5.3.17</version>
+      </dependency>
+      <dependency>
+        <groupId>org.springframework.boot</groupId>
+        <artifactId>spring-boot-dependencies</artifactId>
+        <version>2.6.4</version>
+        <type>pom</type>
+        <scope>import</scope>
+      </dependency>
+    </dependencies>
+  </dependencyManagement>
+  <dependencies>
+    <dependency>
+      <groupId>org.apache.logging.log4j</groupId>
+      <artifactId>log4j-core</artifactId>
+    </dependency>
+    <dependency>
+      <groupId>junit</groupId>
+      <artifactId>junit</artifactId>
+    </dependency>
+    <dependency>
+      <groupId>com.fasterxml.jackson.core</groupId>
+      <artifactId>jackson-databind</artifactId>
+    </dependency>
+    <dependency>
+      <groupId>org.apache.commons</groupId>
+      <artifactId>commons-text</artifactId>
+      <version>[1.9,)</version>
+    </dependency>
+  </dependencies>
+</project>`
+
+	deps, err := (&MavenPomParser{}).Parse("pom.xml", []byte(pom))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		version  string
+		unpinned bool
+		dev      bool
+	}{
+		// Version from <dependencyManagement>, resolved from <properties>
+		{"org.apache.logging.log4j:log4j-core", "2.14.1", false, false},
+		// Scope from <dependencyManagement>
+		{"junit:junit", "4.13.2", false, true},
+		// Managed by a BOM that isn't fetched
+		{"com.fasterxml.jackson.core:jackson-databind", "", true, false},
+		// Version range
+		{"org.apache.commons:commons-text", "", true, false},
+	}
+
+	// spring-core is only managed and the BOM import isn't a dependency
+	if len(deps) != len(tests) {
+		t.Errorf("Parse() returned %d dependencies, want %d: %+v", len(deps), len(tests), deps)
+	}
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if i >= len(deps) {
+				t.Fatalf("missing %s", tt.name)
+			}
+			d := deps[i]
+			if d.Name != tt.name || d.Version != tt.version || d.Unpinned != tt.unpinned || d.Dev != tt.dev {
+				t.Errorf("dependency %d = %s@%s unpinned %t dev %t, want %s@%s unpinned %t dev %t",
+					i, d.Name, d.Version, d.Unpinned, d.Dev, tt.name, tt.version, tt.unpinned, tt.dev)
+			}
+		})
+	}
+}
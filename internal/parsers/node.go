@@ -1,89 +1,184 @@
 package parsers
 
 import (
+	"bytes"
 	"encoding/json"
+	"fmt"
+	"sort"
 	"strings"
 
 	"github.com/ethanolivertroy/kev-check-demo/internal/models"
 )
 
-// NodePackageLockParser parses package-lock.json files
+// NodePackageLockParser parses package-lock.json files, and
+// npm-shrinkwrap.json, which shares its format
 type NodePackageLockParser struct{}
 
-// CanParse returns true for package-lock.json files
+// CanParse returns true for package-lock.json and npm-shrinkwrap.json files
 func (p *NodePackageLockParser) CanParse(filename string) bool {
-	return filename == "package-lock.json"
+	return filename == "package-lock.json" || filename == "npm-shrinkwrap.json"
 }
 
-// packageLock represents the structure of package-lock.json (v2/v3)
-type packageLock struct {
-	LockfileVersion int `json:"lockfileVersion"`
-	// V2/V3 format
-	Packages map[string]struct {
-		Version string `json:"version"`
-		Dev     bool   `json:"dev"`
-	} `json:"packages"`
-	// V1 format
-	Dependencies map[string]struct {
-		Version string `json:"version"`
-		Dev     bool   `json:"dev"`
-	} `json:"dependencies"`
+// lockPackage is the subset of a package-lock.json v2/v3 "packages" entry we
+// read. The dependency maps are only used from the root ("") entry.
+type lockPackage struct {
+	Version              string            `json:"version"`
+	Resolved             string            `json:"resolved"`
+	Link                 bool              `json:"link"` // Symlink to a workspace or file: package
+	Dependencies         map[string]string `json:"dependencies"`
+	DevDependencies      map[string]string `json:"devDependencies"`
+	OptionalDependencies map[string]string `json:"optionalDependencies"`
 }
 
-// Parse extracts dependencies from package-lock.json content
+// lockV1Dependency is the subset of a v1 "dependencies" entry we read
+type lockV1Dependency struct {
+	Version string `json:"version"`
+}
+
+// Parse extracts dependencies from package-lock.json content. Lockfiles for
+// large apps can exceed 50MB, so the document is walked token by token and
+// only one package entry is decoded at a time.
 func (p *NodePackageLockParser) Parse(filepath string, content []byte) ([]models.Dependency, error) {
-	var lock packageLock
-	if err := json.Unmarshal(content, &lock); err != nil {
+	dec := json.NewDecoder(bytes.NewReader(content))
+	if err := expectDelim(dec, '{'); err != nil {
 		return nil, err
 	}
 
-	var deps []models.Dependency
+	var deps, v1Deps []models.Dependency
 	seen := make(map[string]bool)
+	direct := make(map[string]bool)
+	var installPaths []string // Parallel to deps
 
-	// V2/V3 format (packages map)
-	for path, pkg := range lock.Packages {
-		if path == "" {
-			continue // Skip root package
+	for dec.More() {
+		key, err := dec.Token()
+		if err != nil {
+			return nil, err
 		}
+		switch key {
+		case "packages":
+			// V2/V3 format, keyed by install path
+			err = eachLockEntry(dec, func(path string, pkg lockPackage) {
+				if path == "" {
+					// Root package: remember what it declares directly
+					for _, m := range []map[string]string{pkg.Dependencies, pkg.DevDependencies, pkg.OptionalDependencies} {
+						for name := range m {
+							direct["node_modules/"+name] = true
+						}
+					}
+					return
+				}
 
-		// Extract package name from path like "node_modules/lodash" or "node_modules/@types/node"
-		name := path
-		if strings.HasPrefix(path, "node_modules/") {
-			name = strings.TrimPrefix(path, "node_modules/")
-			// Handle nested node_modules
-			if idx := strings.LastIndex(name, "node_modules/"); idx >= 0 {
-				name = name[idx+len("node_modules/"):]
-			}
-		}
+				// Extract package name from path like "node_modules/lodash",
+				// "node_modules/@types/node" or nested installs. Other paths
+				// are the workspace packages themselves, which are reported
+				// through the links to them.
+				idx := strings.LastIndex(path, "node_modules/")
+				if idx < 0 {
+					return
+				}
+				name := path[idx+len("node_modules/"):]
 
-		if name == "" || seen[name+"@"+pkg.Version] {
-			continue
+				dep := models.Dependency{
+					Name:       name,
+					Version:    pkg.Version,
+					Ecosystem:  models.EcosystemNpm,
+					SourceFile: filepath,
+				}
+				if pkg.Link {
+					dep.Version, dep.Local = "", pkg.Resolved
+				}
+				if name == "" || seen[name+"@"+dep.Version+"@"+dep.Local] {
+					return
+				}
+				seen[name+"@"+dep.Version+"@"+dep.Local] = true
+
+				deps = append(deps, dep)
+				installPaths = append(installPaths, path)
+			})
+		case "dependencies":
+			// V1 format, keyed by package name
+			err = eachLockEntry(dec, func(name string, pkg lockV1Dependency) {
+				v1Deps = append(v1Deps, models.Dependency{
+					Name:       name,
+					Version:    pkg.Version,
+					Ecosystem:  models.EcosystemNpm,
+					SourceFile: filepath,
+				})
+			})
+		default:
+			err = skipJSONValue(dec)
+		}
+		if err != nil {
+			return nil, err
 		}
-		seen[name+"@"+pkg.Version] = true
+	}
 
-		deps = append(deps, models.Dependency{
-			Name:       name,
-			Version:    pkg.Version,
-			Ecosystem:  models.EcosystemNpm,
-			SourceFile: filepath,
-		})
+	// Only top-level installs of packages the root declares are direct
+	for i := range deps {
+		deps[i].Transitive = !direct[installPaths[i]]
 	}
 
 	// V1 format fallback (if no packages found)
 	if len(deps) == 0 {
-		for name, pkg := range lock.Dependencies {
-			deps = append(deps, models.Dependency{
-				Name:       name,
-				Version:    pkg.Version,
-				Ecosystem:  models.EcosystemNpm,
-				SourceFile: filepath,
-			})
-		}
+		deps = v1Deps
 	}
 
 	return deps, nil
 }
 
+// eachLockEntry decodes the entries of a lockfile object one at a time
+func eachLockEntry[T any](dec *json.Decoder, fn func(key string, entry T)) error {
+	if err := expectDelim(dec, '{'); err != nil {
+		return err
+	}
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return err
+		}
+		key, _ := tok.(string)
+		var entry T
+		if err := dec.Decode(&entry); err != nil {
+			return err
+		}
+		fn(key, entry)
+	}
+	_, err := dec.Token() // closing brace
+	return err
+}
+
+// expectDelim reads the next token and checks it is the given delimiter
+func expectDelim(dec *json.Decoder, want json.Delim) error {
+	tok, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	if d, ok := tok.(json.Delim); !ok || d != want {
+		return fmt.Errorf("expected %q, got %v", want, tok)
+	}
+	return nil
+}
+
+// skipJSONValue consumes the next value without decoding it
+func skipJSONValue(dec *json.Decoder) error {
+	depth := 0
+	for {
+		tok, err := dec.Token()
+		if err != nil {
+			return err
+		}
+		switch tok {
+		case json.Delim('{'), json.Delim('['):
+			depth++
+		case json.Delim('}'), json.Delim(']'):
+			depth--
+		}
+		if depth == 0 {
+			return nil
+		}
+	}
+}
+
 // NodePackageJSONParser parses package.json files (direct dependencies only)
 type NodePackageJSONParser struct{}
 
@@ -109,25 +204,56 @@ func (p *NodePackageJSONParser) Parse(filepath string, content []byte) ([]models
 
 	// Add production dependencies
 	for name, version := range pkg.Dependencies {
-		deps = append(deps, models.Dependency{
-			Name:       name,
-			Version:    cleanNpmVersion(version),
-			Ecosystem:  models.EcosystemNpm,
-			SourceFile: filepath,
-		})
+		deps = append(deps, packageJSONDependency(filepath, name, version))
 	}
 
 	// Add dev dependencies
 	for name, version := range pkg.DevDependencies {
-		deps = append(deps, models.Dependency{
+		deps = append(deps, packageJSONDependency(filepath, name, version))
+	}
+
+	return deps, nil
+}
+
+// packageJSONDependency returns the dependency declared by a package.json
+// entry
+func packageJSONDependency(filepath, name, spec string) models.Dependency {
+	if isLocalNpmSpec(spec) {
+		return models.Dependency{
 			Name:       name,
-			Version:    cleanNpmVersion(version),
+			Local:      spec,
 			Ecosystem:  models.EcosystemNpm,
 			SourceFile: filepath,
-		})
+		}
+	}
+	return models.Dependency{
+		Name:       name,
+		Version:    cleanNpmVersion(spec),
+		Unpinned:   !isExactNpmVersion(spec),
+		Ecosystem:  models.EcosystemNpm,
+		SourceFile: filepath,
 	}
+}
 
-	return deps, nil
+// isLocalNpmSpec reports whether a dependency spec points at the project's
+// own source, a workspace package or a path, rather than the registry
+func isLocalNpmSpec(spec string) bool {
+	for _, prefix := range []string{"workspace:", "file:", "link:", "portal:", "./", "../", "/", "~/"} {
+		if strings.HasPrefix(spec, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// isExactNpmVersion reports whether a package.json spec names one version.
+// Ranges, tags like "latest", and URLs resolve to whatever is installed.
+func isExactNpmVersion(spec string) bool {
+	spec = strings.TrimPrefix(strings.TrimSpace(spec), "=")
+	if spec == "" || strings.ContainsAny(spec, "^~<>*xX| ") {
+		return false
+	}
+	return spec[0] >= '0' && spec[0] <= '9'
 }
 
 // cleanNpmVersion removes version prefixes like ^, ~, etc.
@@ -141,3 +267,276 @@ func cleanNpmVersion(version string) string {
 	version = strings.TrimPrefix(version, "=")
 	return version
 }
+
+// BunLockParser parses Bun's text lockfile, bun.lock
+type BunLockParser struct{}
+
+// CanParse returns true for bun.lock files
+func (p *BunLockParser) CanParse(filename string) bool {
+	return filename == "bun.lock"
+}
+
+// bunLock is the subset of bun.lock we read. Each package is an array whose
+// first element is the resolved "name@version".
+type bunLock struct {
+	Workspaces map[string]struct {
+		Dependencies         map[string]string `json:"dependencies"`
+		DevDependencies      map[string]string `json:"devDependencies"`
+		OptionalDependencies map[string]string `json:"optionalDependencies"`
+		PeerDependencies     map[string]string `json:"peerDependencies"`
+	} `json:"workspaces"`
+	Packages map[string][]json.RawMessage `json:"packages"`
+}
+
+// Parse extracts resolved packages from bun.lock content. Workspace, file
+// and link packages are reported as local; git packages are skipped, as
+// they aren't resolved from the registry.
+func (p *BunLockParser) Parse(filepath string, content []byte) ([]models.Dependency, error) {
+	var lock bunLock
+	if err := json.Unmarshal(stripJSONC(content), &lock); err != nil {
+		return nil, err
+	}
+
+	direct := make(map[string]bool)
+	for _, ws := range lock.Workspaces {
+		for _, m := range []map[string]string{ws.Dependencies, ws.DevDependencies, ws.OptionalDependencies, ws.PeerDependencies} {
+			for name := range m {
+				direct[name] = true
+			}
+		}
+	}
+
+	// Keys are install paths such as "lodash" or "parent/lodash"; sort them
+	// so output doesn't depend on map order
+	keys := make([]string, 0, len(lock.Packages))
+	for key := range lock.Packages {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var deps []models.Dependency
+	seen := make(map[string]bool)
+	for _, key := range keys {
+		entry := lock.Packages[key]
+		if len(entry) == 0 {
+			continue
+		}
+		var ident string
+		if err := json.Unmarshal(entry[0], &ident); err != nil {
+			continue
+		}
+		name, version := splitNpmIdent(ident)
+		if name == "" || version == "" || seen[ident] {
+			continue
+		}
+		dep := models.Dependency{
+			Name:       name,
+			Version:    version,
+			Ecosystem:  models.EcosystemNpm,
+			SourceFile: filepath,
+			Transitive: key != name || !direct[name],
+		}
+		if strings.Contains(version, ":") {
+			if !isLocalNpmSpec(version) {
+				continue
+			}
+			dep.Version, dep.Local = "", version
+		}
+		seen[ident] = true
+
+		deps = append(deps, dep)
+	}
+	return deps, nil
+}
+
+// BunLockbParser recognizes Bun's legacy binary lockfile, bun.lockb. Its
+// layout is internal to Bun and changes between releases, so it isn't
+// supported: the parse error says so and how to convert it to bun.lock.
+type BunLockbParser struct{}
+
+// CanParse returns true for bun.lockb files
+func (p *BunLockbParser) CanParse(filename string) bool {
+	return filename == "bun.lockb"
+}
+
+// bunLockbHeader starts every binary Bun lockfile
+const bunLockbHeader = "#!/usr/bin/env bun\nbun-lockfile-format-v0\n"
+
+// Parse always fails: a valid bun.lockb can't be read, and anything else
+// isn't a Bun lockfile
+func (p *BunLockbParser) Parse(filepath string, content []byte) ([]models.Dependency, error) {
+	if !bytes.HasPrefix(content, []byte(bunLockbHeader)) {
+		return nil, fmt.Errorf("not a Bun binary lockfile")
+	}
+	return nil, fmt.Errorf("bun.lockb is not supported: convert it to bun.lock with 'bun install --save-text-lockfile' (Bun 1.1.39 or later), which is scanned instead; until then only package.json is checked")
+}
+
+// YarnLockParser parses yarn.lock files written by Yarn classic (v1), and
+// by Yarn 2+ (Berry), which uses the same layout in YAML syntax
+type YarnLockParser struct{}
+
+// CanParse returns true for yarn.lock files
+func (p *YarnLockParser) CanParse(filename string) bool {
+	return filename == "yarn.lock"
+}
+
+// Parse extracts the resolved packages from yarn.lock content. Each entry
+// starts with an unindented line listing the specs it satisfies, e.g.
+// `"@babel/core@^7.0.0", "@babel/core@^7.12.3":`, followed by an indented
+// version field; the dependency's line is that of the spec list. Workspace,
+// file and link packages are reported as local; git, tarball and patched
+// packages are skipped, as they aren't resolved from the registry.
+func (p *YarnLockParser) Parse(filepath string, content []byte) ([]models.Dependency, error) {
+	var deps []models.Dependency
+	seen := make(map[string]bool)
+
+	var name, spec, header string
+	var headerLine int
+	for i, raw := range strings.Split(string(content), "\n") {
+		line := strings.TrimRight(raw, "\r")
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+
+		if line[0] != ' ' && line[0] != '\t' {
+			// A new entry: the first spec names the package
+			name, spec, header, headerLine = "", "", line, i+1
+			first, _, _ := strings.Cut(strings.TrimSuffix(trimmed, ":"), ",")
+			first = strings.Trim(strings.TrimSpace(first), `"`)
+			if first == "__metadata" {
+				continue
+			}
+			name, spec = splitYarnSpec(first)
+			if name == "" {
+				return nil, fmt.Errorf("line %d: expected a package spec, got %q", i+1, trimmed)
+			}
+			name, spec = yarnAlias(name, spec)
+			if isLocalNpmSpec(spec) && !seen[name+"@@"+spec] {
+				seen[name+"@@"+spec] = true
+				deps = append(deps, models.Dependency{
+					Name:       name,
+					Local:      spec,
+					Ecosystem:  models.EcosystemNpm,
+					SourceFile: filepath,
+					Line:       headerLine,
+					Snippet:    header,
+				})
+			}
+			continue
+		}
+
+		field, value, ok := strings.Cut(trimmed, " ")
+		if !ok || name == "" || strings.TrimSuffix(field, ":") != "version" {
+			continue
+		}
+		version := strings.Trim(strings.TrimSpace(value), `"`)
+		if version == "" || isLocalNpmSpec(spec) || strings.ContainsAny(spec, ":/") || seen[name+"@"+version] {
+			continue
+		}
+		seen[name+"@"+version] = true
+		deps = append(deps, models.Dependency{
+			Name:       name,
+			Version:    version,
+			Ecosystem:  models.EcosystemNpm,
+			SourceFile: filepath,
+			Line:       headerLine,
+			Snippet:    header,
+		})
+	}
+	return deps, nil
+}
+
+// yarnAlias resolves the package and spec behind a yarn.lock spec. Yarn 2+
+// prefixes registry ranges with "npm:", and aliases such as
+// "string-width-cjs@npm:string-width@^4.2.0" install another package under
+// the alias's name.
+func yarnAlias(name, spec string) (string, string) {
+	rest, ok := strings.CutPrefix(spec, "npm:")
+	if !ok {
+		return name, spec
+	}
+	if target, targetSpec := splitYarnSpec(rest); target != "" {
+		return target, targetSpec
+	}
+	return name, rest
+}
+
+// splitYarnSpec splits "name@range" or "@scope/name@range" at the "@" after
+// the name; unlike a resolved version, the range may itself contain "@".
+// The name is "" if there is no range.
+func splitYarnSpec(spec string) (name, rng string) {
+	i := strings.Index(spec[min(1, len(spec)):], "@")
+	if i < 0 {
+		return "", ""
+	}
+	return spec[:i+1], spec[i+2:]
+}
+
+// splitNpmIdent splits "name@version" or "@scope/name@version"
+func splitNpmIdent(ident string) (name, version string) {
+	i := strings.LastIndex(ident, "@")
+	if i <= 0 {
+		return ident, ""
+	}
+	return ident[:i], ident[i+1:]
+}
+
+// stripJSONC turns JSON with comments and trailing commas, as written by Bun
+// and Deno, into plain JSON
+func stripJSONC(content []byte) []byte {
+	// First drop comments, then commas before a closing bracket
+	var uncommented []byte
+	scanJSONC(content, func(i int, inString bool) int {
+		if !inString && content[i] == '/' && i+1 < len(content) {
+			switch content[i+1] {
+			case '/':
+				if end := bytes.IndexByte(content[i:], '\n'); end >= 0 {
+					return i + end
+				}
+				return len(content)
+			case '*':
+				if end := bytes.Index(content[i+2:], []byte("*/")); end >= 0 {
+					return i + 2 + end + 2
+				}
+				return len(content)
+			}
+		}
+		uncommented = append(uncommented, content[i])
+		return i + 1
+	})
+
+	out := make([]byte, 0, len(uncommented))
+	scanJSONC(uncommented, func(i int, inString bool) int {
+		if !inString && uncommented[i] == ',' {
+			rest := bytes.TrimLeft(uncommented[i+1:], " \t\r\n")
+			if len(rest) > 0 && (rest[0] == '}' || rest[0] == ']') {
+				return i + 1
+			}
+		}
+		out = append(out, uncommented[i])
+		return i + 1
+	})
+	return out
+}
+
+// scanJSONC calls fn for each byte position, tracking whether it lies inside
+// a string literal. fn returns the next position to visit.
+func scanJSONC(content []byte, fn func(i int, inString bool) int) {
+	inString, escaped := false, false
+	for i := 0; i < len(content); {
+		c := content[i]
+		next := fn(i, inString)
+		if next == i+1 {
+			switch {
+			case escaped:
+				escaped = false
+			case inString && c == '\\':
+				escaped = true
+			case c == '"':
+				inString = !inString
+			}
+		}
+		i = next
+	}
+}
@@ -0,0 +1,27 @@
+package parsers
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestBunLockbParser(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		wantErr string
+	}{
+		{"binary lockfile", bunLockbHeader + "\x00\x01\x02", "bun.lockb is not supported: convert it to bun.lock"},
+		{"other content", "lockfileVersion: 1", "not a Bun binary lockfile"},
+	}
+
+	p := &BunLockbParser{}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			deps, err := p.Parse("bun.lockb", []byte(tt.content))
+			if len(deps) != 0 || err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Parse() = %v, %v, want error %q", deps, err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -1,6 +1,13 @@
 package parsers
 
-import "github.com/ethanolivertroy/kev-check-demo/internal/models"
+import (
+	"bytes"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/ethanolivertroy/kev-check-demo/internal/models"
+)
 
 // Parser is the interface for dependency file parsers
 type Parser interface {
@@ -11,13 +18,147 @@ type Parser interface {
 	Parse(filepath string, content []byte) ([]models.Dependency, error)
 }
 
+// PathParser is implemented by parsers that can also match files by their
+// directory, not just their name
+type PathParser interface {
+	// CanParsePath returns true if this parser can handle the file at the
+	// slash-separated path
+	CanParsePath(path string) bool
+}
+
 // GetAllParsers returns all available parsers
 func GetAllParsers() []Parser {
 	return []Parser{
 		&PythonRequirementsParser{},
 		&PythonPyProjectParser{},
+		&PythonPoetryLockParser{},
+		&PythonPipfileParser{},
+		&PythonPipfileLockParser{},
 		&NodePackageLockParser{},
 		&NodePackageJSONParser{},
+		&YarnLockParser{},
+		&BunLockParser{},
+		&BunLockbParser{},
+		&DenoLockParser{},
+		&DenoImportMapParser{},
 		&GoModParser{},
+		&CargoLockParser{},
+		&CargoTomlParser{},
+		&HaskellStackLockParser{},
+		&HaskellCabalFreezeParser{},
+		&ErlangRebarLockParser{},
+		&ClojureDepsEdnParser{},
+		&ClojureProjectParser{},
+		&MavenPomParser{},
+		&GradleLockfileParser{},
+		&GradleBuildParser{},
+		&AnsibleRequirementsParser{},
+		&AnsibleCollectionManifestParser{},
+		&ComposerLockParser{},
+		&ComposerJSONParser{},
+		&WordPressParser{},
+		&DrupalInfoParser{},
+		&WebExtensionManifestParser{},
+		&VendoredJSParser{},
+	}
+}
+
+// NewParsers returns all available parsers, with requirements files also
+// matched by the given glob patterns
+func NewParsers(requirementsPatterns []string) []Parser {
+	all := GetAllParsers()
+	for _, p := range all {
+		if req, ok := p.(*PythonRequirementsParser); ok {
+			req.Patterns = requirementsPatterns
+		}
+	}
+	return all
+}
+
+// Find returns the first parser that can handle the file at path, or nil
+func Find(all []Parser, file string) Parser {
+	file = filepath.ToSlash(file)
+	name := path.Base(file)
+	for _, p := range all {
+		if p.CanParse(name) {
+			return p
+		}
+		if pp, ok := p.(PathParser); ok && pp.CanParsePath(file) {
+			return p
+		}
+	}
+	return nil
+}
+
+// Supported returns true if any parser can handle the given filename
+func Supported(filename string) bool {
+	return Find(GetAllParsers(), filename) != nil
+}
+
+// MatchPathSuffix reports whether the last components of a slash-separated
+// path match a glob pattern with the same number of components
+func MatchPathSuffix(pattern, file string) bool {
+	n := strings.Count(pattern, "/") + 1
+	parts := strings.Split(file, "/")
+	if len(parts) < n {
+		return false
+	}
+	ok, _ := path.Match(pattern, strings.Join(parts[len(parts)-n:], "/"))
+	return ok
+}
+
+// lockfiles maps manifests that declare version ranges to the lockfiles that
+// pin the same dependencies to exact versions. Lockfiles that a newer one
+// replaces are listed too: npm ignores package-lock.json when
+// npm-shrinkwrap.json exists, and Bun prefers bun.lock to bun.lockb.
+var lockfiles = map[string][]string{
+	"package.json":      {"npm-shrinkwrap.json", "package-lock.json", "yarn.lock", "bun.lock"},
+	"package-lock.json": {"npm-shrinkwrap.json"},
+	"bun.lockb":         {"bun.lock"},
+	"deno.json":         {"deno.lock"},
+	"deno.jsonc":        {"deno.lock"},
+	"import_map.json":   {"deno.lock"},
+	"pyproject.toml":    {"poetry.lock"},
+	"Pipfile":           {"Pipfile.lock"},
+	"composer.json":     {"composer.lock"},
+	"Cargo.toml":        {"Cargo.lock"},
+	"build.gradle":      {"gradle.lockfile"},
+	"build.gradle.kts":  {"gradle.lockfile"},
+}
+
+// Lockfiles returns the lockfiles that take precedence over a manifest found
+// in the same directory. Lockfiles without a parser are left out.
+func Lockfiles(manifest string) []string {
+	var names []string
+	for _, name := range lockfiles[manifest] {
+		if Supported(name) {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// lineAt returns the 1-based line number of a byte offset in content
+func lineAt(content []byte, offset int) int {
+	return bytes.Count(content[:offset], []byte("\n")) + 1
+}
+
+// packageNameLines returns the 1-based line of the name key of each
+// [[package]] entry in a TOML lockfile such as poetry.lock or Cargo.lock, in
+// file order
+func packageNameLines(lines []string) []int {
+	var found []int
+	inPackage := false
+	for i, raw := range lines {
+		line := strings.TrimSpace(raw)
+		if strings.HasPrefix(line, "[") {
+			inPackage = line == "[[package]]"
+			continue
+		}
+		if key, _, ok := strings.Cut(line, "="); inPackage && ok && strings.TrimSpace(key) == "name" {
+			found = append(found, i+1)
+			inPackage = false
+		}
 	}
+	return found
 }
@@ -0,0 +1,142 @@
+package parsers
+
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+
+	"github.com/ethanolivertroy/kev-check-demo/internal/models"
+)
+
+// ComposerLockParser parses composer.lock files
+type ComposerLockParser struct{}
+
+// CanParse returns true for composer.lock files
+func (p *ComposerLockParser) CanParse(filename string) bool {
+	return filename == "composer.lock"
+}
+
+// composerLock is the subset of composer.lock we read
+type composerLock struct {
+	Packages    []composerLockPackage `json:"packages"`
+	PackagesDev []composerLockPackage `json:"packages-dev"`
+}
+
+type composerLockPackage struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+// Parse extracts the installed packages from composer.lock content. Branch
+// checkouts such as "dev-main" have no release version and are reported as
+// unpinned.
+func (p *ComposerLockParser) Parse(filepath string, content []byte) ([]models.Dependency, error) {
+	var lock composerLock
+	if err := json.Unmarshal(content, &lock); err != nil {
+		return nil, err
+	}
+
+	var deps []models.Dependency
+	for _, pkg := range append(lock.Packages, lock.PackagesDev...) {
+		eco, name := composerPackage(pkg.Name)
+		if name == "" {
+			continue
+		}
+		version := strings.TrimPrefix(pkg.Version, "v")
+		unpinned := strings.HasPrefix(version, "dev-") || strings.HasSuffix(version, "-dev")
+		if unpinned {
+			version = ""
+		}
+		deps = append(deps, models.Dependency{
+			Name:       name,
+			Version:    version,
+			Unpinned:   unpinned,
+			Ecosystem:  eco,
+			SourceFile: filepath,
+		})
+	}
+	return deps, nil
+}
+
+// ComposerJSONParser parses composer.json files (direct dependencies only)
+type ComposerJSONParser struct{}
+
+// CanParse returns true for composer.json files
+func (p *ComposerJSONParser) CanParse(filename string) bool {
+	return filename == "composer.json"
+}
+
+// composerJSON is the subset of composer.json we read
+type composerJSON struct {
+	Require    map[string]string `json:"require"`
+	RequireDev map[string]string `json:"require-dev"`
+}
+
+// Parse extracts the required packages from composer.json content. Platform
+// requirements (php, ext-*, lib-*) are skipped, and constraints other than an
+// exact version are reported as unpinned.
+func (p *ComposerJSONParser) Parse(filepath string, content []byte) ([]models.Dependency, error) {
+	var manifest composerJSON
+	if err := json.Unmarshal(content, &manifest); err != nil {
+		return nil, err
+	}
+
+	names := make([]string, 0, len(manifest.Require)+len(manifest.RequireDev))
+	constraints := make(map[string]string)
+	for _, section := range []map[string]string{manifest.Require, manifest.RequireDev} {
+		for name, constraint := range section {
+			if _, dup := constraints[name]; !dup {
+				names = append(names, name)
+				constraints[name] = constraint
+			}
+		}
+	}
+	sort.Strings(names)
+
+	var deps []models.Dependency
+	for _, pkgName := range names {
+		eco, name := composerPackage(pkgName)
+		if name == "" {
+			continue
+		}
+		constraint := strings.TrimSpace(constraints[pkgName])
+		exact := isExactComposerVersion(constraint)
+		version := ""
+		if exact {
+			version = strings.TrimPrefix(strings.TrimPrefix(constraint, "=="), "v")
+		}
+		deps = append(deps, models.Dependency{
+			Name:       name,
+			Version:    version,
+			Unpinned:   !exact,
+			Ecosystem:  eco,
+			SourceFile: filepath,
+		})
+	}
+	return deps, nil
+}
+
+// composerPackage returns the ecosystem and name of a Composer package.
+// WordPress plugins and themes mirrored by WPackagist are reported by their
+// WordPress slug; platform requirements return an empty name.
+func composerPackage(name string) (models.Ecosystem, string) {
+	vendor, pkg, ok := strings.Cut(strings.ToLower(name), "/")
+	if !ok || pkg == "" {
+		return "", "" // php, ext-json, composer-plugin-api, ...
+	}
+	switch vendor {
+	case "wpackagist-plugin", "wpackagist-theme":
+		return models.EcosystemWordPress, pkg
+	}
+	return models.EcosystemPackagist, vendor + "/" + pkg
+}
+
+// isExactComposerVersion reports whether a composer.json constraint names a
+// single version. Ranges, wildcards, stability flags and branches don't.
+func isExactComposerVersion(constraint string) bool {
+	constraint = strings.TrimPrefix(strings.TrimPrefix(constraint, "=="), "v")
+	if constraint == "" || strings.ContainsAny(constraint, "^~<>=!*|@ ,") {
+		return false
+	}
+	return constraint[0] >= '0' && constraint[0] <= '9' && !strings.HasSuffix(constraint, "-dev")
+}
@@ -1,7 +1,11 @@
 package parsers
 
 import (
+	"bytes"
+	"encoding/json"
 	"regexp"
+	"slices"
+	"sort"
 	"strings"
 
 	"github.com/BurntSushi/toml"
@@ -9,7 +13,11 @@ import (
 )
 
 // PythonRequirementsParser parses requirements.txt files
-type PythonRequirementsParser struct{}
+type PythonRequirementsParser struct {
+	// Patterns are extra glob patterns for requirements files, matched
+	// against the end of a slash-separated path, e.g. "requirements/*.txt"
+	Patterns []string
+}
 
 // CanParse returns true for requirements.txt files
 func (p *PythonRequirementsParser) CanParse(filename string) bool {
@@ -20,6 +28,16 @@ func (p *PythonRequirementsParser) CanParse(filename string) bool {
 		filename == "requirements-test.txt"
 }
 
+// CanParsePath returns true for files matching one of Patterns
+func (p *PythonRequirementsParser) CanParsePath(file string) bool {
+	for _, pattern := range p.Patterns {
+		if MatchPathSuffix(pattern, file) {
+			return true
+		}
+	}
+	return false
+}
+
 // versionPattern matches package version specifiers like ==1.2.3, >=1.2.3, ~=1.2.3
 var versionPattern = regexp.MustCompile(`^([a-zA-Z0-9_-]+)\s*([<>=!~]+)\s*([\d.]+.*)$`)
 
@@ -31,8 +49,21 @@ func (p *PythonRequirementsParser) Parse(filepath string, content []byte) ([]mod
 	var deps []models.Dependency
 	lines := strings.Split(string(content), "\n")
 
-	for lineNum, line := range lines {
-		line = strings.TrimSpace(line)
+	for lineNum, raw := range lines {
+		line := strings.TrimSpace(raw)
+
+		// Projects installed from a local path have no PyPI release
+		if name, path := localRequirement(line); name != "" {
+			deps = append(deps, models.Dependency{
+				Name:       name,
+				Local:      path,
+				Ecosystem:  models.EcosystemPyPI,
+				SourceFile: filepath,
+				Line:       lineNum + 1,
+				Snippet:    strings.TrimRight(raw, "\r"),
+			})
+			continue
+		}
 
 		// Skip empty lines, comments, and options
 		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, "-") {
@@ -53,14 +84,16 @@ func (p *PythonRequirementsParser) Parse(filepath string, content []byte) ([]mod
 			}
 		}
 
-		name, version := parseVersionSpec(line)
+		name, op, version := parseVersionSpec(line)
 		if name != "" {
 			deps = append(deps, models.Dependency{
 				Name:       strings.ToLower(name), // PyPI is case-insensitive
 				Version:    version,
+				Unpinned:   !isExactPin(op, version),
 				Ecosystem:  models.EcosystemPyPI,
 				SourceFile: filepath,
 				Line:       lineNum + 1,
+				Snippet:    strings.TrimRight(raw, "\r"),
 			})
 		}
 	}
@@ -68,22 +101,98 @@ func (p *PythonRequirementsParser) Parse(filepath string, content []byte) ([]mod
 	return deps, nil
 }
 
-func parseVersionSpec(line string) (name string, version string) {
+// localPEP508 matches a direct reference to a local path, e.g.
+// "mylib @ file:///src/mylib" or "mylib[extra] @ file:../mylib"
+var localPEP508 = regexp.MustCompile(`^([A-Za-z0-9_.-]+)\s*(?:\[[^\]]*\])?\s*@\s*(file:[^\s;]+)`)
+
+// eggFragment matches the #egg=name fragment naming the project of a path or
+// URL requirement
+var eggFragment = regexp.MustCompile(`#egg=([A-Za-z0-9_.-]+)`)
+
+// localRequirement returns the name and path of a requirements.txt line that
+// installs a project from a local directory or archive: "-e ./mylib",
+// "../mylib", "file:mylib#egg=mylib" or "mylib @ file:../mylib". Without an
+// explicit name, the project is named after the last path element. It
+// returns an empty name for any other line.
+func localRequirement(line string) (name, path string) {
+	if idx := strings.Index(line, " #"); idx > 0 {
+		line = strings.TrimSpace(line[:idx])
+	}
+	if m := localPEP508.FindStringSubmatch(line); m != nil {
+		return strings.ToLower(m[1]), m[2]
+	}
+
+	for _, flag := range []string{"-e", "--editable"} {
+		if rest, ok := strings.CutPrefix(line, flag); ok && (rest == "" || rest[0] == ' ' || rest[0] == '=') {
+			line = strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(rest), "="))
+			break
+		}
+	}
+	if !isLocalPath(line) {
+		return "", ""
+	}
+
+	path = line
+	if m := eggFragment.FindStringSubmatch(line); m != nil {
+		name = m[1]
+		path = line[:strings.Index(line, "#")]
+	} else {
+		name = pythonProjectFromPath(path)
+	}
+	return strings.ToLower(name), path
+}
+
+// isLocalPath reports whether a requirement is a filesystem path or a file:
+// URL rather than a project name or remote URL
+func isLocalPath(s string) bool {
+	for _, prefix := range []string{"file:", "./", "../", "/", "~/", ".\\", "..\\"} {
+		if strings.HasPrefix(s, prefix) {
+			return true
+		}
+	}
+	return s == "."
+}
+
+// pythonProjectFromPath returns the project name implied by a local path: the
+// directory name, or the name part of a wheel or sdist file name
+func pythonProjectFromPath(p string) string {
+	p = strings.TrimPrefix(p, "file:")
+	p = strings.TrimRight(strings.ReplaceAll(p, "\\", "/"), "/")
+	base := p[strings.LastIndex(p, "/")+1:]
+	for _, ext := range []string{".whl", ".tar.gz", ".zip"} {
+		if name, ok := strings.CutSuffix(base, ext); ok {
+			base, _, _ = strings.Cut(name, "-")
+			break
+		}
+	}
+	if base == "" || base == "." || base == ".." {
+		return ""
+	}
+	return base
+}
+
+func parseVersionSpec(line string) (name, op, version string) {
 	// Try exact/pinned version patterns
 	if matches := versionPattern.FindStringSubmatch(line); matches != nil {
-		name = matches[1]
-		version = matches[3]
-		return
+		return matches[1], matches[2], matches[3]
 	}
 
 	// Try simple package name (no version)
 	if matches := simplePattern.FindStringSubmatch(line); matches != nil {
-		name = matches[1]
-		version = ""
-		return
+		return matches[1], "", ""
 	}
 
-	return "", ""
+	return "", "", ""
+}
+
+// isExactPin reports whether a PEP 440 specifier pins a single version.
+// Anything else (ranges, compatible releases, wildcards, extra clauses) can
+// resolve to more than one version.
+func isExactPin(op, version string) bool {
+	if op != "==" && op != "===" {
+		return false
+	}
+	return version != "" && !strings.ContainsAny(version, "*,<>!~ ")
 }
 
 // PythonPyProjectParser parses pyproject.toml files
@@ -119,11 +228,21 @@ func (p *PythonPyProjectParser) Parse(filepath string, content []byte) ([]models
 
 	// Parse PEP 621 dependencies (project.dependencies)
 	for _, dep := range proj.Project.Dependencies {
-		name, version := parsePEP508(dep)
+		if m := localPEP508.FindStringSubmatch(strings.TrimSpace(dep)); m != nil {
+			deps = append(deps, models.Dependency{
+				Name:       strings.ToLower(m[1]),
+				Local:      m[2],
+				Ecosystem:  models.EcosystemPyPI,
+				SourceFile: filepath,
+			})
+			continue
+		}
+		name, op, version := parsePEP508(dep)
 		if name != "" {
 			deps = append(deps, models.Dependency{
 				Name:       strings.ToLower(name),
 				Version:    version,
+				Unpinned:   !isExactPin(op, version),
 				Ecosystem:  models.EcosystemPyPI,
 				SourceFile: filepath,
 			})
@@ -135,10 +254,22 @@ func (p *PythonPyProjectParser) Parse(filepath string, content []byte) ([]models
 		if name == "python" {
 			continue
 		}
-		version := extractPoetryVersion(val)
+		if v, ok := val.(map[string]interface{}); ok {
+			if path, _ := v["path"].(string); path != "" {
+				deps = append(deps, models.Dependency{
+					Name:       strings.ToLower(name),
+					Local:      path,
+					Ecosystem:  models.EcosystemPyPI,
+					SourceFile: filepath,
+				})
+				continue
+			}
+		}
+		version, pinned := extractPoetryVersion(val)
 		deps = append(deps, models.Dependency{
 			Name:       strings.ToLower(name),
 			Version:    version,
+			Unpinned:   !pinned,
 			Ecosystem:  models.EcosystemPyPI,
 			SourceFile: filepath,
 		})
@@ -148,7 +279,7 @@ func (p *PythonPyProjectParser) Parse(filepath string, content []byte) ([]models
 }
 
 // parsePEP508 parses a PEP 508 dependency specification
-func parsePEP508(spec string) (name string, version string) {
+func parsePEP508(spec string) (name, op, version string) {
 	// Simple parsing for common patterns
 	// e.g., "requests>=2.28.0", "flask[async]>=2.0", "django==4.2"
 
@@ -168,29 +299,325 @@ func parsePEP508(spec string) (name string, version string) {
 	spec = strings.TrimSpace(spec)
 
 	if matches := versionPattern.FindStringSubmatch(spec); matches != nil {
-		return matches[1], matches[3]
+		return matches[1], matches[2], matches[3]
 	}
 
 	if matches := simplePattern.FindStringSubmatch(spec); matches != nil {
-		return matches[1], ""
+		return matches[1], "", ""
 	}
 
-	return "", ""
+	return "", "", ""
 }
 
-func extractPoetryVersion(val interface{}) string {
+// extractPoetryVersion returns the version from a Poetry constraint and
+// whether it pins an exact version (a bare "1.2.3" or "==1.2.3")
+func extractPoetryVersion(val interface{}) (string, bool) {
+	var constraint string
 	switch v := val.(type) {
 	case string:
-		// Remove ^ or ~ prefixes, keep the version
-		v = strings.TrimPrefix(v, "^")
-		v = strings.TrimPrefix(v, "~")
-		return v
+		constraint = v
 	case map[string]interface{}:
-		if ver, ok := v["version"].(string); ok {
-			ver = strings.TrimPrefix(ver, "^")
-			ver = strings.TrimPrefix(ver, "~")
-			return ver
+		constraint, _ = v["version"].(string)
+	}
+
+	constraint = strings.TrimSpace(constraint)
+	pinned := constraint != "" && !strings.ContainsAny(constraint, "^~<>!*, ")
+
+	// Remove ^ or ~ prefixes, keep the version
+	version := strings.TrimPrefix(constraint, "^")
+	version = strings.TrimPrefix(version, "~")
+	version = strings.TrimPrefix(version, "==")
+	return version, pinned
+}
+
+// PythonPipfileParser parses Pipfile manifests. Without Pipfile.lock only the
+// declared constraints are known, so anything short of an exact pin is
+// reported as unpinned.
+type PythonPipfileParser struct{}
+
+// CanParse returns true for Pipfile files
+func (p *PythonPipfileParser) CanParse(filename string) bool {
+	return filename == "Pipfile"
+}
+
+// pipfile represents the package tables of a Pipfile
+type pipfile struct {
+	Packages    map[string]interface{} `toml:"packages"`
+	DevPackages map[string]interface{} `toml:"dev-packages"`
+}
+
+// Parse extracts dependencies from the [packages] and [dev-packages] tables
+// of a Pipfile, marking those in [dev-packages] as dev dependencies. Environment markers are not evaluated, since the platform the
+// project is installed on isn't known. Path and local file dependencies are
+// reported as local; VCS and remote file dependencies are skipped, as they
+// aren't resolved from PyPI.
+func (p *PythonPipfileParser) Parse(filepath string, content []byte) ([]models.Dependency, error) {
+	var pf pipfile
+	if err := toml.Unmarshal(content, &pf); err != nil {
+		return nil, err
+	}
+	lines := strings.Split(string(content), "\n")
+	locations := pipfileLocations(lines)
+
+	var deps []models.Dependency
+	for _, table := range []struct {
+		name     string
+		packages map[string]interface{}
+	}{{"packages", pf.Packages}, {"dev-packages", pf.DevPackages}} {
+		var tableDeps []models.Dependency
+		for name, val := range table.packages {
+			dep := models.Dependency{
+				Name:       strings.ToLower(name),
+				Ecosystem:  models.EcosystemPyPI,
+				SourceFile: filepath,
+				Dev:        table.name == "dev-packages",
+			}
+			if local := pipfileLocal(val); local != "" {
+				dep.Local = local
+			} else {
+				constraint, ok := pipfileConstraint(val)
+				if !ok {
+					continue
+				}
+				op, version := splitSpecifier(constraint)
+				dep.Version = version
+				dep.Unpinned = !isExactPin(op, version)
+			}
+			if line, ok := locations[table.name+"."+strings.ToLower(name)]; ok {
+				dep.Line = line
+				dep.Snippet = strings.TrimRight(lines[line-1], "\r")
+			}
+			tableDeps = append(tableDeps, dep)
 		}
+		// Map order is random; keep the file's order
+		sort.Slice(tableDeps, func(i, j int) bool {
+			if tableDeps[i].Line != tableDeps[j].Line {
+				return tableDeps[i].Line < tableDeps[j].Line
+			}
+			return tableDeps[i].Name < tableDeps[j].Name
+		})
+		deps = append(deps, tableDeps...)
+	}
+	return deps, nil
+}
+
+// pipfileConstraint returns the version specifier of a Pipfile entry, either
+// a string ("*", "==1.2.3") or a table with a version key. It returns false
+// for entries installed from VCS, a path or a file.
+func pipfileConstraint(val interface{}) (string, bool) {
+	var constraint string
+	switch v := val.(type) {
+	case string:
+		constraint = v
+	case map[string]interface{}:
+		for _, key := range []string{"git", "hg", "svn", "bzr", "path", "file"} {
+			if _, ok := v[key]; ok {
+				return "", false
+			}
+		}
+		constraint, _ = v["version"].(string)
+	default:
+		return "", false
+	}
+
+	constraint = strings.TrimSpace(constraint)
+	if constraint == "*" {
+		constraint = ""
+	}
+	return constraint, true
+}
+
+// pipfileLocal returns the path of a Pipfile entry installed from a local
+// directory or file, or "" for any other entry
+func pipfileLocal(val interface{}) string {
+	v, ok := val.(map[string]interface{})
+	if !ok {
+		return ""
+	}
+	if path, _ := v["path"].(string); path != "" {
+		return path
+	}
+	if file, _ := v["file"].(string); file != "" && (!strings.Contains(file, "://") || strings.HasPrefix(file, "file:")) {
+		return file
 	}
 	return ""
 }
+
+// splitSpecifier splits a specifier such as ">=2.0,<3" into its leading
+// operator and the rest
+func splitSpecifier(spec string) (op, version string) {
+	i := strings.IndexFunc(spec, func(r rune) bool {
+		return !strings.ContainsRune("<>=!~ ", r)
+	})
+	if i < 0 {
+		return strings.TrimSpace(spec), ""
+	}
+	return strings.TrimSpace(spec[:i]), strings.TrimSpace(spec[i:])
+}
+
+// pipfileLocations maps "<table>.<lowercased package>" to the 1-based line
+// of the package's key in the [packages] and [dev-packages] tables
+func pipfileLocations(lines []string) map[string]int {
+	locations := make(map[string]int)
+	table := ""
+	for i, raw := range lines {
+		line := strings.TrimSpace(raw)
+		if strings.HasPrefix(line, "[") {
+			table = strings.Trim(line, "[] ")
+			continue
+		}
+		if table != "packages" && table != "dev-packages" {
+			continue
+		}
+		key, _, ok := strings.Cut(line, "=")
+		if !ok || strings.HasPrefix(line, "#") {
+			continue
+		}
+		key = strings.ToLower(strings.Trim(strings.TrimSpace(key), `"'`))
+		if _, seen := locations[table+"."+key]; !seen {
+			locations[table+"."+key] = i + 1
+		}
+	}
+	return locations
+}
+
+// PythonPipfileLockParser parses Pipfile.lock files, which pin every package
+// Pipenv resolved to an exact version
+type PythonPipfileLockParser struct{}
+
+// CanParse returns true for Pipfile.lock files
+func (p *PythonPipfileLockParser) CanParse(filename string) bool {
+	return filename == "Pipfile.lock"
+}
+
+// pipfileLock represents the package sections of a Pipfile.lock
+type pipfileLock struct {
+	Default map[string]map[string]interface{} `json:"default"`
+	Develop map[string]map[string]interface{} `json:"develop"`
+}
+
+// Parse extracts the resolved packages from the default and develop sections
+// of a Pipfile.lock, marking those only in develop as dev dependencies.
+// Packages listed in both sections at the same version are reported once. Path and local file packages are reported as local; VCS and
+// remote file packages are skipped, as they aren't resolved from PyPI.
+func (p *PythonPipfileLockParser) Parse(filepath string, content []byte) ([]models.Dependency, error) {
+	var lock pipfileLock
+	if err := json.Unmarshal(content, &lock); err != nil {
+		return nil, err
+	}
+	lines := strings.Split(string(content), "\n")
+
+	var deps []models.Dependency
+	seen := make(map[string]bool)
+	for _, section := range []struct {
+		name     string
+		packages map[string]map[string]interface{}
+	}{{"default", lock.Default}, {"develop", lock.Develop}} {
+		start := bytes.Index(content, []byte(`"`+section.name+`"`))
+
+		names := make([]string, 0, len(section.packages))
+		for name := range section.packages {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		for _, name := range names {
+			entry := section.packages[name]
+			dep := models.Dependency{
+				Name:       strings.ToLower(name),
+				Ecosystem:  models.EcosystemPyPI,
+				SourceFile: filepath,
+				Dev:        section.name == "develop",
+			}
+			if local := pipfileLocal(entry); local != "" {
+				dep.Local = local
+			} else {
+				version, _ := entry["version"].(string)
+				if version == "" {
+					continue // VCS or remote file
+				}
+				op, v := splitSpecifier(version)
+				dep.Version = v
+				dep.Unpinned = !isExactPin(op, v)
+			}
+			key := dep.Name + "@" + dep.Version + dep.Local
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+
+			if start >= 0 {
+				if i := bytes.Index(content[start:], []byte(`"`+name+`": {`)); i >= 0 {
+					dep.Line = lineAt(content, start+i)
+					dep.Snippet = strings.TrimSpace(lines[dep.Line-1])
+				}
+			}
+			deps = append(deps, dep)
+		}
+	}
+	return deps, nil
+}
+
+// PythonPoetryLockParser parses poetry.lock files, which pin every package
+// Poetry resolved, direct and transitive, to an exact version
+type PythonPoetryLockParser struct{}
+
+// CanParse returns true for poetry.lock files
+func (p *PythonPoetryLockParser) CanParse(filename string) bool {
+	return filename == "poetry.lock"
+}
+
+// poetryLock represents the packages of a poetry.lock
+type poetryLock struct {
+	Packages []struct {
+		Name     string   `toml:"name"`
+		Version  string   `toml:"version"`
+		Category string   `toml:"category"` // Lock format 1.x: "main" or "dev"
+		Groups   []string `toml:"groups"`   // Lock format 2.1+
+		Source   struct {
+			Type string `toml:"type"`
+			URL  string `toml:"url"`
+		} `toml:"source"`
+	} `toml:"package"`
+}
+
+// Parse extracts the locked packages from a poetry.lock. Packages only in
+// non-main dependency groups (or the dev category of older lock files) are
+// marked as dev dependencies; lock format 2.0 records neither, so its
+// packages aren't. Directory and local file packages are reported as local;
+// git and URL packages are skipped, as they aren't resolved from PyPI.
+func (p *PythonPoetryLockParser) Parse(filepath string, content []byte) ([]models.Dependency, error) {
+	var lock poetryLock
+	if err := toml.Unmarshal(content, &lock); err != nil {
+		return nil, err
+	}
+	lines := strings.Split(string(content), "\n")
+	nameLines := packageNameLines(lines)
+
+	var deps []models.Dependency
+	for i, pkg := range lock.Packages {
+		dep := models.Dependency{
+			Name:       strings.ToLower(pkg.Name),
+			Version:    pkg.Version,
+			Ecosystem:  models.EcosystemPyPI,
+			SourceFile: filepath,
+			Dev:        pkg.Category == "dev" || len(pkg.Groups) > 0 && !slices.Contains(pkg.Groups, "main"),
+		}
+		switch pkg.Source.Type {
+		case "directory", "file":
+			if strings.Contains(pkg.Source.URL, "://") {
+				continue
+			}
+			dep.Version = ""
+			dep.Local = pkg.Source.URL
+		case "git", "url":
+			continue
+		}
+		if i < len(nameLines) {
+			dep.Line = nameLines[i]
+			dep.Snippet = strings.TrimSpace(lines[dep.Line-1])
+		}
+		deps = append(deps, dep)
+	}
+	return deps, nil
+}
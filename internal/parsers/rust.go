@@ -0,0 +1,262 @@
+package parsers
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/ethanolivertroy/kev-check-demo/internal/models"
+)
+
+// crates.io index URLs, as Cargo.lock records them in package sources
+var cratesIOSources = []string{
+	"registry+https://github.com/rust-lang/crates.io-index",
+	"sparse+https://index.crates.io/",
+}
+
+// CargoLockParser parses Cargo.lock files, which pin every crate Cargo
+// resolved, direct and transitive, to an exact version
+type CargoLockParser struct{}
+
+// CanParse returns true for Cargo.lock files
+func (p *CargoLockParser) CanParse(filename string) bool {
+	return filename == "Cargo.lock"
+}
+
+// cargoLock represents the packages of a Cargo.lock
+type cargoLock struct {
+	Packages []struct {
+		Name    string `toml:"name"`
+		Version string `toml:"version"`
+		Source  string `toml:"source"`
+	} `toml:"package"`
+}
+
+// Parse extracts the crates.io packages from a Cargo.lock. Packages without a
+// source are the workspace's own crates and path dependencies, whose paths
+// the lockfile doesn't record; they're skipped along with git and
+// alternative registry packages.
+func (p *CargoLockParser) Parse(filepath string, content []byte) ([]models.Dependency, error) {
+	var lock cargoLock
+	if err := toml.Unmarshal(content, &lock); err != nil {
+		return nil, err
+	}
+	lines := strings.Split(string(content), "\n")
+	nameLines := packageNameLines(lines)
+
+	var deps []models.Dependency
+	for i, pkg := range lock.Packages {
+		if !isCratesIOSource(pkg.Source) {
+			continue
+		}
+		dep := models.Dependency{
+			Name:       pkg.Name,
+			Version:    pkg.Version,
+			Ecosystem:  models.EcosystemCratesIO,
+			SourceFile: filepath,
+		}
+		if i < len(nameLines) {
+			dep.Line = nameLines[i]
+			dep.Snippet = strings.TrimSpace(lines[dep.Line-1])
+		}
+		deps = append(deps, dep)
+	}
+	return deps, nil
+}
+
+// isCratesIOSource reports whether a Cargo.lock package source is crates.io
+func isCratesIOSource(source string) bool {
+	for _, s := range cratesIOSources {
+		if strings.TrimSuffix(source, "/") == strings.TrimSuffix(s, "/") {
+			return true
+		}
+	}
+	return false
+}
+
+// CargoTomlParser parses Cargo.toml files (direct dependencies only)
+type CargoTomlParser struct{}
+
+// CanParse returns true for Cargo.toml files
+func (p *CargoTomlParser) CanParse(filename string) bool {
+	return filename == "Cargo.toml"
+}
+
+// cargoDependencies are the dependency tables of a manifest or target
+type cargoDependencies struct {
+	Dependencies      map[string]interface{} `toml:"dependencies"`
+	DevDependencies   map[string]interface{} `toml:"dev-dependencies"`
+	BuildDependencies map[string]interface{} `toml:"build-dependencies"`
+}
+
+// cargoManifest is the subset of Cargo.toml we read
+type cargoManifest struct {
+	cargoDependencies
+	Target    map[string]cargoDependencies `toml:"target"`
+	Workspace struct {
+		Dependencies map[string]interface{} `toml:"dependencies"`
+	} `toml:"workspace"`
+}
+
+// Parse extracts dependencies from the [dependencies], [dev-dependencies] and
+// [build-dependencies] tables of a Cargo.toml, including platform-specific
+// [target.*] tables and [workspace.dependencies]. Cargo treats a bare
+// version such as "1.2.3" as a compatible range (^1.2.3), so only "=1.2.3"
+// is an exact pin. Path dependencies are local; git, alternative registry
+// and workspace-inherited dependencies are skipped.
+func (p *CargoTomlParser) Parse(filepath string, content []byte) ([]models.Dependency, error) {
+	var manifest cargoManifest
+	if err := toml.Unmarshal(content, &manifest); err != nil {
+		return nil, err
+	}
+	lines := strings.Split(string(content), "\n")
+	keyLines := cargoDependencyLines(lines)
+
+	type table struct {
+		kind string
+		deps map[string]interface{}
+	}
+	tables := []table{
+		{"dependencies", manifest.Dependencies},
+		{"build-dependencies", manifest.BuildDependencies},
+	}
+	targets := make([]string, 0, len(manifest.Target))
+	for target := range manifest.Target {
+		targets = append(targets, target)
+	}
+	sort.Strings(targets)
+	for _, target := range targets {
+		t := manifest.Target[target]
+		tables = append(tables, table{"dependencies", t.Dependencies}, table{"build-dependencies", t.BuildDependencies})
+	}
+	tables = append(tables, table{"dev-dependencies", manifest.DevDependencies})
+	for _, target := range targets {
+		tables = append(tables, table{"dev-dependencies", manifest.Target[target].DevDependencies})
+	}
+	tables = append(tables, table{"dependencies", manifest.Workspace.Dependencies})
+
+	// A crate listed in several tables is reported once, from the first
+	// non-dev table that lists it
+	seen := make(map[string]bool)
+	var deps []models.Dependency
+	for _, t := range tables {
+		keys := make([]string, 0, len(t.deps))
+		for key := range t.deps {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		for _, key := range keys {
+			dep, ok := cargoDependency(key, t.deps[key])
+			if !ok || seen[dep.Name+"\x00"+dep.Local] {
+				continue
+			}
+			seen[dep.Name+"\x00"+dep.Local] = true
+			dep.SourceFile = filepath
+			dep.Dev = t.kind == "dev-dependencies"
+			if line, ok := keyLines[t.kind+"\x00"+key]; ok {
+				dep.Line = line
+				dep.Snippet = strings.TrimSpace(lines[line-1])
+			}
+			deps = append(deps, dep)
+		}
+	}
+	return deps, nil
+}
+
+// cargoDependency returns the dependency a Cargo.toml entry declares, or false
+// for entries that aren't resolved from crates.io
+func cargoDependency(key string, val interface{}) (models.Dependency, bool) {
+	dep := models.Dependency{Name: key, Ecosystem: models.EcosystemCratesIO}
+	var req string
+	switch v := val.(type) {
+	case string:
+		req = v
+	case map[string]interface{}:
+		if inherited, _ := v["workspace"].(bool); inherited {
+			return dep, false // Declared in [workspace.dependencies]
+		}
+		if _, git := v["git"]; git {
+			return dep, false
+		}
+		if _, registry := v["registry"]; registry {
+			return dep, false
+		}
+		if pkg, _ := v["package"].(string); pkg != "" {
+			dep.Name = pkg // Renamed dependency
+		}
+		if path, _ := v["path"].(string); path != "" {
+			// A path dependency may also name a version for publishing, but
+			// builds use the local source
+			dep.Local = path
+			return dep, true
+		}
+		req, _ = v["version"].(string)
+	default:
+		return dep, false
+	}
+
+	if version, ok := cargoExactVersion(req); ok {
+		dep.Version = version
+	} else {
+		dep.Unpinned = true
+	}
+	return dep, true
+}
+
+// cargoExactVersion returns the version a Cargo requirement pins exactly
+// ("=1.2.3"). Bare versions are caret requirements and, like ranges,
+// wildcards and partial versions, match more than one release.
+func cargoExactVersion(req string) (string, bool) {
+	version, ok := strings.CutPrefix(strings.TrimSpace(req), "=")
+	version = strings.TrimSpace(version)
+	if !ok || version == "" || strings.ContainsAny(version, " ,*<>=^~") || strings.Count(version, ".") < 2 {
+		return "", false
+	}
+	return version, version[0] >= '0' && version[0] <= '9'
+}
+
+// cargoDependencyLines maps each dependency key in a Cargo.toml to the
+// 1-based line declaring it, keyed by table kind ("dependencies",
+// "dev-dependencies" or "build-dependencies") and key. Entries are declared
+// either as keys of a dependency table or as [dependencies.<name>] tables; a
+// key declared in several tables of the same kind keeps its first line.
+func cargoDependencyLines(lines []string) map[string]int {
+	found := make(map[string]int)
+	record := func(kind, key string, line int) {
+		key = strings.Trim(strings.TrimSpace(key), `"'`)
+		if _, ok := found[kind+"\x00"+key]; !ok && key != "" {
+			found[kind+"\x00"+key] = line
+		}
+	}
+
+	kind := ""
+	for i, raw := range lines {
+		line := strings.TrimSpace(raw)
+		if strings.HasPrefix(line, "[") {
+			header := strings.TrimSpace(strings.Trim(line, "[]"))
+			kind = ""
+			for _, k := range []string{"dependencies", "dev-dependencies", "build-dependencies"} {
+				if header == k || strings.HasSuffix(header, "."+k) {
+					kind = k
+					break
+				}
+				if idx := strings.LastIndex(header, k+"."); idx >= 0 && (idx == 0 || header[idx-1] == '.') {
+					record(k, header[idx+len(k)+1:], i+1)
+					break
+				}
+			}
+			continue
+		}
+		if kind == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if key, _, ok := strings.Cut(line, "="); ok {
+			if name, _, dotted := strings.Cut(key, "."); dotted && !strings.HasPrefix(strings.TrimSpace(key), `"`) {
+				key = name // serde.version = "1"
+			}
+			record(kind, key, i+1)
+		}
+	}
+	return found
+}
@@ -0,0 +1,146 @@
+package parsers
+
+import (
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/ethanolivertroy/kev-check-demo/internal/models"
+)
+
+// jsLibraries maps the file name stems of common browser libraries, as
+// they're vendored, to their npm package names
+var jsLibraries = map[string]string{
+	"angular":             "angular",
+	"backbone":            "backbone",
+	"bootstrap":           "bootstrap",
+	"d3":                  "d3",
+	"dompurify":           "dompurify",
+	"purify":              "dompurify",
+	"ember":               "ember-source",
+	"handlebars":          "handlebars",
+	"jquery":              "jquery",
+	"jquery-ui":           "jquery-ui",
+	"jquery.ui":           "jquery-ui",
+	"jquery-migrate":      "jquery-migrate",
+	"knockout":            "knockout",
+	"lodash":              "lodash",
+	"moment":              "moment",
+	"moment-with-locales": "moment",
+	"mustache":            "mustache",
+	"pdf":                 "pdfjs-dist",
+	"react":               "react",
+	"react-dom":           "react-dom",
+	"underscore":          "underscore",
+	"vue":                 "vue",
+}
+
+// jsSignature recognizes a library's source
+type jsSignature struct {
+	// banner matches the library's license banner or another string only
+	// its source contains, capturing the version if it's there
+	banner *regexp.Regexp
+	// version matches a library-specific version declaration, for banners
+	// without one
+	version *regexp.Regexp
+}
+
+// jsSignatures identify each library's source. A file named after a library
+// is only reported if its banner matches, so unrelated files that share the
+// name aren't.
+var jsSignatures = map[string]jsSignature{
+	"angular":        {banner: regexp.MustCompile(`AngularJS v(\d+\.\d+\.\d+)`)},
+	"backbone":       {banner: regexp.MustCompile(`Backbone\.js (\d+\.\d+\.\d+)`)},
+	"bootstrap":      {banner: regexp.MustCompile(`Bootstrap v(\d+\.\d+\.\d+)`)},
+	"d3":             {banner: regexp.MustCompile(`https://d3js\.org v(\d+\.\d+\.\d+)`)},
+	"dompurify":      {banner: regexp.MustCompile(`DOMPurify (\d+\.\d+\.\d+)`)},
+	"ember-source":   {banner: regexp.MustCompile(`(?s)Ember - JavaScript Application Framework.{0,512}?@version\s+(\d+\.\d+\.\d+)`)},
+	"handlebars":     {banner: regexp.MustCompile(`(?i)handlebars v(\d+\.\d+\.\d+)`)},
+	"jquery":         {banner: regexp.MustCompile(`jQuery (?:JavaScript Library )?v(\d+\.\d+\.\d+)`)},
+	"jquery-ui":      {banner: regexp.MustCompile(`jQuery UI - v(\d+\.\d+\.\d+)`)},
+	"jquery-migrate": {banner: regexp.MustCompile(`jQuery Migrate (?:- )?v(\d+\.\d+\.\d+)`)},
+	"knockout":       {banner: regexp.MustCompile(`Knockout JavaScript library v(\d+\.\d+\.\d+)`)},
+	// lodash 4 banners carry no version; minified builds have no other
+	// trace of it, so they fall back to the file name
+	"lodash": {
+		banner:  regexp.MustCompile(`(?i)@license[\s*]+lodash(?: (\d+\.\d+\.\d+))?`),
+		version: regexp.MustCompile(`var VERSION = '(\d+\.\d+\.\d+)'`),
+	},
+	"moment":     {banner: regexp.MustCompile(`//! moment\.js\s+//! version : (\d+\.\d+\.\d+)`)},
+	"mustache":   {banner: regexp.MustCompile(`name: ['"]mustache\.js['"],\s*version: ['"](\d+\.\d+\.\d+)`)},
+	"pdfjs-dist": {banner: regexp.MustCompile(`pdfjsVersion = ['"](\d+\.\d+\.\d+)`)},
+	// React 18 banners carry no version; development builds declare it
+	"react": {
+		banner:  regexp.MustCompile(`@license React(?: v(\d+\.\d+\.\d+))?`),
+		version: regexp.MustCompile(`var ReactVersion = '(\d+\.\d+\.\d+)'`),
+	},
+	"react-dom": {
+		banner:  regexp.MustCompile(`@license React(?: v(\d+\.\d+\.\d+))?`),
+		version: regexp.MustCompile(`var ReactVersion = '(\d+\.\d+\.\d+)'`),
+	},
+	"underscore": {banner: regexp.MustCompile(`Underscore\.js (\d+\.\d+\.\d+)`)},
+	"vue":        {banner: regexp.MustCompile(`(?i)vue(?:\.js)? v(\d+\.\d+\.\d+)`)},
+}
+
+// jsLibraryFile matches library file names such as jquery.min.js,
+// jquery-3.3.1.min.js, lodash.4.17.20.js and react-dom.production.min.js
+var jsLibraryFile = regexp.MustCompile(`^(.+?)(?:[-._@]v?(\d+\.\d+(?:\.\d+)?))?((?:[.-](?:min|slim|bundle|umd|esm|prod|production|development|global|full|custom))*)\.js$`)
+
+// jsLibraryFromFilename returns the npm package of a vendored library file
+// and the version in its name, if any. The name is "" if the file isn't a
+// recognized library.
+func jsLibraryFromFilename(file string) (name, version string) {
+	m := jsLibraryFile.FindStringSubmatch(strings.ToLower(path.Base(file)))
+	if m == nil {
+		return "", ""
+	}
+	name, ok := jsLibraries[m[1]]
+	if !ok {
+		return "", ""
+	}
+	return name, m[2]
+}
+
+// VendoredJSParser fingerprints copies of common browser libraries, such as
+// jQuery, lodash and moment, checked into a project without a package
+// manager
+type VendoredJSParser struct{}
+
+// CanParse returns true for JavaScript files named after a known library.
+// Parse still requires the library's banner in the content.
+func (p *VendoredJSParser) CanParse(filename string) bool {
+	name, _ := jsLibraryFromFilename(filename)
+	return name != ""
+}
+
+// Parse reports the library if its banner matches. The version comes from
+// the banner, a library-specific declaration or else the file name; a
+// library whose version can't be found is reported as unpinned.
+func (p *VendoredJSParser) Parse(filepath string, content []byte) ([]models.Dependency, error) {
+	name, version := jsLibraryFromFilename(filepath)
+	if name == "" {
+		return nil, nil
+	}
+	sig := jsSignatures[name]
+	m := sig.banner.FindSubmatchIndex(content)
+	if m == nil {
+		return nil, nil
+	}
+
+	dep := models.Dependency{
+		Name:       name,
+		Version:    version,
+		Ecosystem:  models.EcosystemNpm,
+		SourceFile: filepath,
+		Line:       lineAt(content, m[0]),
+	}
+	if m[2] < 0 && sig.version != nil {
+		m = sig.version.FindSubmatchIndex(content)
+	}
+	if m != nil && m[2] >= 0 {
+		dep.Version = string(content[m[2]:m[3]])
+		dep.Line = lineAt(content, m[2])
+	}
+	dep.Unpinned = dep.Version == ""
+	return []models.Dependency{dep}, nil
+}
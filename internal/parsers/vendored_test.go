@@ -0,0 +1,75 @@
+package parsers
+
+import (
+	"testing"
+)
+
+func TestVendoredJSParser(t *testing.T) {
+	tests := []struct {
+		file    string
+		content string
+		name    string // Empty if nothing is reported
+		version string
+		line    int
+	}{
+		// Version from the banner, over the file name
+		{"static/jquery.min.js", "/*! jQuery v3.4.1 | (c) JS Foundation */\n!function(e,t){}", "jquery", "3.4.1", 1},
+		{"js/jquery-1.12.4.min.js", "/*! jQuery v3.5.0 | (c) JS Foundation */", "jquery", "3.5.0", 1},
+		{"vendor/moment.js", "//! moment.js\n//! version : 2.29.1\n;(function (global, factory) {", "moment", "2.29.1", 2},
+		{"lib/angular.min.js", "/*\n AngularJS v1.8.2\n (c) 2010-2020 Google LLC.\n*/", "angular", "1.8.2", 2},
+		{"lib/ember.js", "/*!\n * @overview  Ember - JavaScript Application Framework\n * @version   3.28.0\n */", "ember-source", "3.28.0", 3},
+		{"assets/mustache.js", "var mustache = {\n  name: 'mustache.js',\n  version: '4.2.0',\n};", "mustache", "4.2.0", 3},
+
+		// Banner without a version: a library-specific declaration, else
+		// the file name, else unpinned
+		{"js/lodash.js", "/**\n * @license\n * Lodash <https://lodash.com/>\n */\n  var VERSION = '4.17.21';", "lodash", "4.17.21", 5},
+		{"js/lodash-4.17.15.min.js", "/** @license Lodash <https://lodash.com/> */\n;(function(){var n=\"4.17.15\"})", "lodash", "4.17.15", 1},
+		{"js/lodash.min.js", "/** @license Lodash <https://lodash.com/> */\n;(function(){var n=\"4.17.15\"})", "lodash", "", 1},
+		{"js/react-dom.production.min.js", "/** @license React\n * react-dom.production.min.js */", "react-dom", "", 1},
+		{"js/react.development.js", "/** @license React\n * react.development.js */\nvar ReactVersion = '18.2.0';", "react", "18.2.0", 3},
+
+		// Named after a library, but not its source
+		{"src/moment.js", "export const config = { version: '1.0.0' };", "", "", 0},
+		{"src/lodash.js", "// local helpers\nexport const pick = (o, k) => o[k]; const v = \"1.2.3\";", "", "", 0},
+		{"static/vue.js", "new Vue({ el: '#app' })", "", "", 0},
+
+		// Not a library file
+		{"src/app.js", "/*! jQuery v3.4.1 */", "", "", 0},
+	}
+
+	p := &VendoredJSParser{}
+	for _, tt := range tests {
+		t.Run(tt.file, func(t *testing.T) {
+			if !p.CanParse(tt.file) {
+				if tt.name != "" {
+					t.Fatalf("CanParse(%q) = false", tt.file)
+				}
+				return
+			}
+			deps, err := p.Parse(tt.file, []byte(tt.content))
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+			if tt.name == "" {
+				if len(deps) != 0 {
+					t.Errorf("Parse() = %+v, want nothing", deps)
+				}
+				return
+			}
+			if len(deps) != 1 {
+				t.Fatalf("Parse() returned %d dependencies, want 1", len(deps))
+			}
+			d := deps[0]
+			if d.Name != tt.name || d.Version != tt.version || d.Line != tt.line || d.Unpinned != (tt.version == "") {
+				t.Errorf("Parse() = %s@%s line %d unpinned %t, want %s@%s line %d", d.Name, d.Version, d.Line, d.Unpinned, tt.name, tt.version, tt.line)
+			}
+		})
+	}
+
+	// Every library has a signature
+	for stem, name := range jsLibraries {
+		if jsSignatures[name].banner == nil {
+			t.Errorf("no signature for %s (%s)", name, stem)
+		}
+	}
+}
@@ -0,0 +1,169 @@
+package policy
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/ethanolivertroy/kev-check-demo/internal/models"
+)
+
+// Condition decides whether a KEV finding should fail the build
+type Condition interface {
+	Matches(f models.Finding, kev models.KEVInfo) bool
+	String() string
+}
+
+// openForPattern matches "open>30d" style age conditions
+var openForPattern = regexp.MustCompile(`^open\s*(>=|>)\s*(\d+)d$`)
+
+// severityPattern matches "severity>=high" style severity conditions
+var severityPattern = regexp.MustCompile(`^severity\s*>=\s*(\w+)$`)
+
+// ParseFailOn parses --fail-on expressions into conditions
+func ParseFailOn(exprs []string) ([]Condition, error) {
+	var conds []Condition
+	for _, expr := range exprs {
+		expr = strings.TrimSpace(strings.ToLower(expr))
+
+		if m := openForPattern.FindStringSubmatch(expr); m != nil {
+			days, _ := strconv.Atoi(m[2])
+			if m[1] == ">" {
+				days++
+			}
+			conds = append(conds, openFor{minDays: days, expr: expr})
+			continue
+		}
+
+		if m := severityPattern.FindStringSubmatch(expr); m != nil {
+			min, err := ParseSeverity(m[1])
+			if err != nil {
+				return nil, fmt.Errorf("invalid --fail-on condition %q: %w", expr, err)
+			}
+			conds = append(conds, severityAtLeast{min: min})
+			continue
+		}
+
+		switch expr {
+		case "sla-breach":
+			conds = append(conds, slaBreach{})
+			continue
+		case "no-fix":
+			conds = append(conds, fixState{noFix: true})
+			continue
+		case "fixable":
+			conds = append(conds, fixState{noFix: false})
+			continue
+		}
+
+		return nil, fmt.Errorf("invalid --fail-on condition %q", expr)
+	}
+	return conds, nil
+}
+
+// ShouldFail reports whether any unaccepted KEV matches a condition. With no
+// conditions, any unaccepted KEV fails. Potential findings only fail with
+// potential set.
+func ShouldFail(findings []models.Finding, conds []Condition, potential bool) bool {
+	return len(Failing(findings, conds, potential)) > 0
+}
+
+// Failing returns the findings with at least one unaccepted KEV matching a
+// condition, or any unaccepted KEV if there are no conditions. Potential
+// findings, whose unpinned version may not be affected, are left out unless
+// potential is set.
+func Failing(findings []models.Finding, conds []Condition, potential bool) []models.Finding {
+	var failing []models.Finding
+	for _, f := range findings {
+		if f.Potential() && !potential {
+			continue
+		}
+		if failsOn(f, conds) {
+			failing = append(failing, f)
+		}
+	}
+	return failing
+}
+
+func failsOn(f models.Finding, conds []Condition) bool {
+	for _, kev := range f.KEVs {
+		if kev.Accepted != nil {
+			continue
+		}
+		if len(conds) == 0 {
+			return true
+		}
+		for _, c := range conds {
+			if c.Matches(f, kev) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// AllUnfixable reports whether every finding has no fixed version available
+func AllUnfixable(findings []models.Finding) bool {
+	for _, f := range findings {
+		if f.Effort != models.EffortNoFix {
+			return false
+		}
+	}
+	return len(findings) > 0
+}
+
+// openFor matches KEVs first seen at least minDays ago
+type openFor struct {
+	minDays int
+	expr    string
+}
+
+func (c openFor) Matches(_ models.Finding, kev models.KEVInfo) bool {
+	return !kev.FirstSeen.IsZero() && kev.DaysOpen() >= c.minDays
+}
+
+func (c openFor) String() string {
+	return c.expr
+}
+
+// slaBreach matches KEVs past their internal remediation deadline
+type slaBreach struct{}
+
+func (slaBreach) Matches(_ models.Finding, kev models.KEVInfo) bool {
+	return kev.SLABreached()
+}
+
+func (slaBreach) String() string {
+	return "sla-breach"
+}
+
+// fixState matches findings with (noFix) or without a fixed version. Findings
+// whose fix data couldn't be fetched count as fixable.
+type fixState struct {
+	noFix bool
+}
+
+func (c fixState) Matches(f models.Finding, _ models.KEVInfo) bool {
+	return (f.Effort == models.EffortNoFix) == c.noFix
+}
+
+func (c fixState) String() string {
+	if c.noFix {
+		return "no-fix"
+	}
+	return "fixable"
+}
+
+// severityAtLeast matches KEVs whose normalized severity is min or higher
+type severityAtLeast struct {
+	min models.Severity
+}
+
+func (c severityAtLeast) Matches(_ models.Finding, kev models.KEVInfo) bool {
+	return kev.Severity.AtLeast(c.min)
+}
+
+func (c severityAtLeast) String() string {
+	return "severity>=" + string(c.min)
+}
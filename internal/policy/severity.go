@@ -0,0 +1,105 @@
+package policy
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/ethanolivertroy/kev-check-demo/internal/models"
+)
+
+// SeverityMapping normalizes CVSS, EPSS and ransomware use into a single
+// severity. A KEV is critical if it meets any critical criterion, otherwise
+// high if it meets any high criterion, otherwise medium. KEVs with neither a
+// CVSS nor an EPSS score get Unscored. A zero threshold disables it.
+type SeverityMapping struct {
+	CriticalRansomware bool            `toml:"critical_ransomware"`
+	CriticalCVSS       float64         `toml:"critical_cvss"` // CVSS base score, 0-10
+	CriticalEPSS       float64         `toml:"critical_epss"` // EPSS probability, 0-1
+	HighCVSS           float64         `toml:"high_cvss"`
+	HighEPSS           float64         `toml:"high_epss"`
+	Unscored           models.Severity `toml:"unscored"`
+}
+
+// DefaultSeverityMapping treats ransomware use, CVSS 9.0+ and EPSS 50%+ as
+// critical, and CVSS 7.0+ and EPSS 10%+ as high. Unscored KEVs are high:
+// they are still known to be exploited.
+func DefaultSeverityMapping() SeverityMapping {
+	return SeverityMapping{
+		CriticalRansomware: true,
+		CriticalCVSS:       9.0,
+		CriticalEPSS:       0.5,
+		HighCVSS:           7.0,
+		HighEPSS:           0.1,
+		Unscored:           models.SeverityHigh,
+	}
+}
+
+// LoadSeverityMapping reads the [severity] table of a severity config file.
+// Settings it leaves out keep their defaults.
+func LoadSeverityMapping(path string) (SeverityMapping, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return SeverityMapping{}, fmt.Errorf("failed to read severity config: %w", err)
+	}
+
+	m := DefaultSeverityMapping()
+	file := struct {
+		Severity *SeverityMapping `toml:"severity"`
+	}{&m}
+	if err := toml.Unmarshal(data, &file); err != nil {
+		return SeverityMapping{}, fmt.Errorf("failed to parse severity config: %w", err)
+	}
+
+	for _, cvss := range []float64{m.CriticalCVSS, m.HighCVSS} {
+		if cvss < 0 || cvss > 10 {
+			return SeverityMapping{}, fmt.Errorf("severity CVSS thresholds must be 0-10")
+		}
+	}
+	for _, epss := range []float64{m.CriticalEPSS, m.HighEPSS} {
+		if epss < 0 || epss > 1 {
+			return SeverityMapping{}, fmt.Errorf("severity EPSS thresholds must be 0-1")
+		}
+	}
+	if m.Unscored, err = ParseSeverity(string(m.Unscored)); err != nil {
+		return SeverityMapping{}, fmt.Errorf("severity unscored: %w", err)
+	}
+	return m, nil
+}
+
+// ParseSeverity parses a severity name, ignoring case
+func ParseSeverity(s string) (models.Severity, error) {
+	sev := models.Severity(strings.ToLower(strings.TrimSpace(s)))
+	if sev.Rank() == 0 {
+		return "", fmt.Errorf("unknown severity %q (want critical, high or medium)", s)
+	}
+	return sev, nil
+}
+
+// Classify returns the KEV's normalized severity
+func (m SeverityMapping) Classify(kev models.KEVInfo) models.Severity {
+	cvss := kev.CVSSVector != ""
+	epss := kev.EPSSScore > 0
+	switch {
+	case m.CriticalRansomware && kev.RansomwareUse,
+		cvss && m.CriticalCVSS > 0 && kev.CVSSScore >= m.CriticalCVSS,
+		epss && m.CriticalEPSS > 0 && kev.EPSSScore >= m.CriticalEPSS:
+		return models.SeverityCritical
+	case cvss && m.HighCVSS > 0 && kev.CVSSScore >= m.HighCVSS,
+		epss && m.HighEPSS > 0 && kev.EPSSScore >= m.HighEPSS:
+		return models.SeverityHigh
+	case !cvss && !epss:
+		return m.Unscored
+	}
+	return models.SeverityMedium
+}
+
+// Apply stamps every KEV in the findings with its severity
+func (m SeverityMapping) Apply(findings []models.Finding) {
+	for i := range findings {
+		for j := range findings[i].KEVs {
+			findings[i].KEVs[j].Severity = m.Classify(findings[i].KEVs[j])
+		}
+	}
+}
@@ -0,0 +1,51 @@
+package policy
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ethanolivertroy/kev-check-demo/internal/models"
+)
+
+// SLA holds internal remediation windows, independent of CISA due dates
+type SLA struct {
+	Ransomware time.Duration
+	Default    time.Duration
+}
+
+// Deadline returns when the KEV must be remediated, or zero if the finding
+// has no first-seen time
+func (s SLA) Deadline(kev models.KEVInfo) time.Time {
+	if kev.FirstSeen.IsZero() {
+		return time.Time{}
+	}
+	window := s.Default
+	if kev.RansomwareUse {
+		window = s.Ransomware
+	}
+	if window <= 0 {
+		return time.Time{}
+	}
+	return kev.FirstSeen.Add(window)
+}
+
+// Apply stamps every KEV in the findings with its SLA deadline
+func (s SLA) Apply(findings []models.Finding) {
+	for i := range findings {
+		for j := range findings[i].KEVs {
+			findings[i].KEVs[j].SLADeadline = s.Deadline(findings[i].KEVs[j])
+		}
+	}
+}
+
+// ParseDuration parses a Go duration, additionally accepting whole days ("7d")
+func ParseDuration(s string) (time.Duration, error) {
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err == nil {
+			return time.Duration(n) * 24 * time.Hour, nil
+		}
+	}
+	return time.ParseDuration(s)
+}
@@ -0,0 +1,165 @@
+package queue
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// dirPollInterval is how often an empty directory queue is re-read
+const dirPollInterval = time.Second
+
+// DefaultVisibilityTimeout is how long a directory queue job may stay
+// claimed before it is handed to another worker
+const DefaultVisibilityTimeout = time.Hour
+
+// dirQueue keeps each job as a file in <dir>/pending. Workers claim a job by
+// renaming it into <dir>/claimed, which is atomic on a shared filesystem, so
+// exactly one worker wins. A claimed job's modification time is its claim
+// time; jobs claimed longer than the visibility timeout, such as those of a
+// worker that crashed, are moved back to pending. Invalid jobs are moved to
+// <dir>/rejected.
+type dirQueue struct {
+	pending    string
+	claimed    string
+	rejected   string
+	visibility time.Duration
+}
+
+func openDir(dir string, visibility time.Duration) (*dirQueue, error) {
+	if visibility <= 0 {
+		visibility = DefaultVisibilityTimeout
+	}
+	q := &dirQueue{
+		pending:    filepath.Join(dir, "pending"),
+		claimed:    filepath.Join(dir, "claimed"),
+		rejected:   filepath.Join(dir, "rejected"),
+		visibility: visibility,
+	}
+	for _, d := range []string{q.pending, q.claimed, q.rejected} {
+		if err := os.MkdirAll(d, 0755); err != nil {
+			return nil, fmt.Errorf("failed to create queue directory: %w", err)
+		}
+	}
+	return q, nil
+}
+
+func (q *dirQueue) Push(ctx context.Context, job Job) error {
+	data, err := json.Marshal(job)
+	if err != nil {
+		return err
+	}
+	// Write under a dot name first so workers never see a partial job
+	tmp := filepath.Join(q.pending, "."+job.ID+".tmp")
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("failed to queue job: %w", err)
+	}
+	if err := os.Rename(tmp, filepath.Join(q.pending, job.ID+".json")); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("failed to queue job: %w", err)
+	}
+	return nil
+}
+
+func (q *dirQueue) Receive(ctx context.Context, wait time.Duration) (*Job, error) {
+	deadline := time.Now().Add(wait)
+	for {
+		job, err := q.claim()
+		if job != nil || err != nil {
+			return job, err
+		}
+		if !time.Now().Before(deadline) {
+			return nil, ErrEmpty
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(dirPollInterval):
+		}
+	}
+}
+
+// claim moves the oldest pending job into claimed, or returns nil if there
+// are none left to claim. Expired claims are released first.
+func (q *dirQueue) claim() (*Job, error) {
+	if err := q.release(); err != nil {
+		return nil, err
+	}
+	entries, err := os.ReadDir(q.pending)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read queue: %w", err)
+	}
+	var names []string
+	for _, e := range entries {
+		if !e.IsDir() && strings.HasSuffix(e.Name(), ".json") {
+			names = append(names, e.Name())
+		}
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		// Stamp the claim time before the rename, so the job never sits in
+		// claimed with its queue time
+		pending, claimed := filepath.Join(q.pending, name), filepath.Join(q.claimed, name)
+		now := time.Now()
+		if err := os.Chtimes(pending, now, now); err != nil {
+			continue // Another worker claimed it first
+		}
+		if err := os.Rename(pending, claimed); err != nil {
+			continue
+		}
+		data, err := os.ReadFile(claimed)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read job: %w", err)
+		}
+		var job Job
+		err = json.Unmarshal(data, &job)
+		if err == nil {
+			err = job.Validate()
+		}
+		if err != nil {
+			os.Rename(claimed, filepath.Join(q.rejected, name))
+			return nil, fmt.Errorf("%w %s (moved to %s): %v", ErrInvalidJob, name, q.rejected, err)
+		}
+		job.raw = name
+		return &job, nil
+	}
+	return nil, nil
+}
+
+// release moves jobs claimed longer than the visibility timeout back to
+// pending
+func (q *dirQueue) release() error {
+	entries, err := os.ReadDir(q.claimed)
+	if err != nil {
+		return fmt.Errorf("failed to read queue: %w", err)
+	}
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasSuffix(e.Name(), ".json") {
+			continue
+		}
+		info, err := e.Info()
+		if err != nil || time.Since(info.ModTime()) < q.visibility {
+			continue // Acknowledged meanwhile, or still being processed
+		}
+		// Fails harmlessly if another worker released it first
+		os.Rename(filepath.Join(q.claimed, e.Name()), filepath.Join(q.pending, e.Name()))
+	}
+	return nil
+}
+
+func (q *dirQueue) Ack(ctx context.Context, job *Job) error {
+	if err := os.Remove(filepath.Join(q.claimed, job.raw)); err != nil {
+		return fmt.Errorf("failed to acknowledge job %s: %w", job.ID, err)
+	}
+	return nil
+}
+
+func (q *dirQueue) Close() error {
+	return nil
+}
@@ -0,0 +1,102 @@
+// Package queue distributes scan jobs to worker instances. A Queue is a
+// reliable work queue: a received job stays claimed until it is acknowledged,
+// so jobs held by a worker that dies can be recovered.
+package queue
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// ErrEmpty is returned by Receive when no job arrived before the wait elapsed
+var ErrEmpty = errors.New("queue is empty")
+
+// ErrInvalidJob is returned by Receive for a job that doesn't unmarshal or
+// fails Validate. The job is moved aside rather than handed to a worker.
+var ErrInvalidJob = errors.New("invalid job")
+
+// Job describes one scan. Exactly one of Paths, Ref (with Paths) or
+// Inventory is used, as in a CLI scan.
+type Job struct {
+	ID        string    `json:"id"`
+	Paths     []string  `json:"paths,omitempty"`
+	Ref       string    `json:"ref,omitempty"`
+	Inventory string    `json:"inventory,omitempty"`
+	QueuedAt  time.Time `json:"queued_at"`
+
+	raw string // Payload as received, used to acknowledge
+}
+
+// Validate checks that the job describes exactly one kind of scan, has an
+// ID usable as a file name and a ref that can't be taken for a git option
+func (j Job) Validate() error {
+	if j.Inventory != "" && (len(j.Paths) > 0 || j.Ref != "") {
+		return fmt.Errorf("an inventory cannot be combined with paths or a ref")
+	}
+	if j.Inventory == "" && len(j.Paths) == 0 {
+		return fmt.Errorf("a path or inventory is required")
+	}
+	if j.ID == "" || strings.ContainsAny(j.ID, `/\`) || strings.HasPrefix(j.ID, ".") {
+		return fmt.Errorf("invalid job ID %q: must be non-empty, not start with a dot and not contain path separators", j.ID)
+	}
+	if strings.HasPrefix(j.Ref, "-") {
+		return fmt.Errorf("invalid git ref %q", j.Ref)
+	}
+	return nil
+}
+
+// Queue is implemented by each queue backend. NATS JetStream or SQS backends
+// only need to provide these four operations.
+type Queue interface {
+	// Push adds a job to the queue
+	Push(ctx context.Context, job Job) error
+	// Receive claims the next job, waiting up to wait for one to arrive. It
+	// returns ErrEmpty if none did, and ErrInvalidJob if the job it took
+	// was invalid.
+	Receive(ctx context.Context, wait time.Duration) (*Job, error)
+	// Ack removes a claimed job from the queue once it has been processed
+	Ack(ctx context.Context, job *Job) error
+	// Close releases the connection to the queue
+	Close() error
+}
+
+// Open returns the queue at target: a directory path (or dir:// URL) shared
+// between workers, or a redis:// URL. A dir:// URL may set how long a job
+// stays claimed before another worker may take it, as in ?visibility=2h.
+func Open(target string) (Queue, error) {
+	if !strings.Contains(target, "://") {
+		return openDir(target, DefaultVisibilityTimeout)
+	}
+
+	u, err := url.Parse(target)
+	if err != nil {
+		return nil, fmt.Errorf("invalid queue URL: %w", err)
+	}
+	switch u.Scheme {
+	case "dir", "file":
+		visibility := DefaultVisibilityTimeout
+		if v := u.Query().Get("visibility"); v != "" {
+			if visibility, err = time.ParseDuration(v); err != nil || visibility <= 0 {
+				return nil, fmt.Errorf("invalid queue visibility timeout %q", v)
+			}
+		}
+		return openDir(u.Path, visibility)
+	case "redis":
+		return openRedis(u)
+	default:
+		return nil, fmt.Errorf("unsupported queue scheme %q (supported: dir, redis)", u.Scheme)
+	}
+}
+
+// NewJobID returns a unique, time-ordered job ID
+func NewJobID() string {
+	b := make([]byte, 4)
+	rand.Read(b)
+	return time.Now().UTC().Format("20060102T150405") + "-" + hex.EncodeToString(b)
+}
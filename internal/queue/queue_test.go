@@ -0,0 +1,92 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestJobValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		job     Job
+		wantErr string
+	}{
+		{"paths", Job{ID: "20260101T000000-abcd", Paths: []string{"."}}, ""},
+		{"ref", Job{ID: "job-1", Paths: []string{"."}, Ref: "origin/main"}, ""},
+		{"inventory", Job{ID: "job-1", Inventory: "sbom.json"}, ""},
+		{"nothing to scan", Job{ID: "job-1"}, "path or inventory"},
+		{"inventory with paths", Job{ID: "job-1", Inventory: "sbom.json", Paths: []string{"."}}, "cannot be combined"},
+		{"inventory with ref", Job{ID: "job-1", Inventory: "sbom.json", Ref: "main"}, "cannot be combined"},
+		{"empty ID", Job{Paths: []string{"."}}, "invalid job ID"},
+		{"ID with slash", Job{ID: "a/b", Paths: []string{"."}}, "invalid job ID"},
+		{"ID with backslash", Job{ID: `a\b`, Paths: []string{"."}}, "invalid job ID"},
+		{"ID escaping the queue", Job{ID: "../pending/x", Paths: []string{"."}}, "invalid job ID"},
+		{"hidden ID", Job{ID: ".job", Paths: []string{"."}}, "invalid job ID"},
+		{"ref taken for an option", Job{ID: "job-1", Paths: []string{"."}, Ref: "--output=/tmp/x"}, "invalid git ref"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.job.Validate()
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("Validate() error = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Validate() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestDirQueueReceive(t *testing.T) {
+	tests := []struct {
+		name         string
+		payload      string
+		wantErr      error
+		wantRejected bool
+	}{
+		{"valid", `{"id": "job-1", "paths": ["."]}`, nil, false},
+		{"not JSON", `{"id": "job-1", "pa`, ErrInvalidJob, true},
+		{"invalid ID", `{"id": "../job-1", "paths": ["."]}`, ErrInvalidJob, true},
+		{"option as ref", `{"id": "job-1", "paths": ["."], "ref": "-c"}`, ErrInvalidJob, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			q, err := openDir(dir, DefaultVisibilityTimeout)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(filepath.Join(q.pending, "job-1.json"), []byte(tt.payload), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			job, err := q.Receive(context.Background(), 0)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Receive() error = %v, want %v", err, tt.wantErr)
+			}
+			_, statErr := os.Stat(filepath.Join(q.rejected, "job-1.json"))
+			if rejected := statErr == nil; rejected != tt.wantRejected {
+				t.Errorf("job rejected = %v, want %v", rejected, tt.wantRejected)
+			}
+			if err != nil {
+				return
+			}
+
+			if err := q.Ack(context.Background(), job); err != nil {
+				t.Errorf("Ack() error = %v", err)
+			}
+			if _, err := q.Receive(context.Background(), 0); !errors.Is(err, ErrEmpty) {
+				t.Errorf("Receive() after Ack error = %v, want %v", err, ErrEmpty)
+			}
+		})
+	}
+}
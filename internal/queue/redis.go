@@ -0,0 +1,228 @@
+package queue
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// defaultRedisKey is the list jobs are pushed to unless ?key= is given
+const defaultRedisKey = "kev-checker:jobs"
+
+// redisQueue is a reliable Redis list queue: BLMOVE atomically moves a job
+// into a processing list, and LREM removes it from there once acknowledged.
+// Jobs left in <key>:processing by a dead worker can be moved back by hand.
+// Invalid jobs are moved to <key>:rejected. Requires Redis 6.2 or later.
+//
+// The connection is dropped on any I/O error or timeout, since a reply may
+// still be in flight, and redialed by the next command.
+type redisQueue struct {
+	url        *url.URL
+	conn       net.Conn // nil until dialed
+	r          *bufio.Reader
+	key        string
+	processing string
+	rejected   string
+}
+
+// redisError is an error reply from the server
+type redisError string
+
+func (e redisError) Error() string { return "redis: " + string(e) }
+
+func openRedis(u *url.URL) (*redisQueue, error) {
+	key := u.Query().Get("key")
+	if key == "" {
+		key = defaultRedisKey
+	}
+	q := &redisQueue{url: u, key: key, processing: key + ":processing", rejected: key + ":rejected"}
+	if err := q.dial(); err != nil {
+		return nil, err
+	}
+	return q, nil
+}
+
+// dial connects to the server, authenticating and selecting the database
+// given in the URL
+func (q *redisQueue) dial() error {
+	host := q.url.Host
+	if q.url.Port() == "" {
+		host = net.JoinHostPort(q.url.Hostname(), "6379")
+	}
+	conn, err := net.DialTimeout("tcp", host, 10*time.Second)
+	if err != nil {
+		return fmt.Errorf("failed to connect to Redis: %w", err)
+	}
+	q.conn, q.r = conn, bufio.NewReader(conn)
+
+	if pass, ok := q.url.User.Password(); ok {
+		args := []string{"AUTH", pass}
+		if user := q.url.User.Username(); user != "" {
+			args = []string{"AUTH", user, pass}
+		}
+		if _, err := q.roundTrip(context.Background(), 0, args...); err != nil {
+			q.drop()
+			return err
+		}
+	}
+	if db := strings.Trim(q.url.Path, "/"); db != "" {
+		if _, err := q.roundTrip(context.Background(), 0, "SELECT", db); err != nil {
+			q.drop()
+			return err
+		}
+	}
+	return nil
+}
+
+// drop closes the connection, if any, so the next command redials
+func (q *redisQueue) drop() {
+	if q.conn != nil {
+		q.conn.Close()
+		q.conn, q.r = nil, nil
+	}
+}
+
+func (q *redisQueue) Push(ctx context.Context, job Job) error {
+	data, err := json.Marshal(job)
+	if err != nil {
+		return err
+	}
+	_, err = q.do(ctx, 0, "LPUSH", q.key, string(data))
+	return err
+}
+
+func (q *redisQueue) Receive(ctx context.Context, wait time.Duration) (*Job, error) {
+	// BLMOVE takes 0 as "block forever", so never pass less than a millisecond
+	timeout := strconv.FormatFloat(max(wait.Seconds(), 0.001), 'f', 3, 64)
+	reply, err := q.do(ctx, wait, "BLMOVE", q.key, q.processing, "RIGHT", "LEFT", timeout)
+	if err != nil {
+		return nil, err
+	}
+	payload, ok := reply.(string)
+	if !ok {
+		return nil, ErrEmpty
+	}
+
+	var job Job
+	err = json.Unmarshal([]byte(payload), &job)
+	if err == nil {
+		err = job.Validate()
+	}
+	if err != nil {
+		if _, rerr := q.do(ctx, 0, "LPUSH", q.rejected, payload); rerr != nil {
+			return nil, rerr
+		}
+		if _, rerr := q.do(ctx, 0, "LREM", q.processing, "1", payload); rerr != nil {
+			return nil, rerr
+		}
+		return nil, fmt.Errorf("%w (moved to %s): %v", ErrInvalidJob, q.rejected, err)
+	}
+	job.raw = payload
+	return &job, nil
+}
+
+func (q *redisQueue) Ack(ctx context.Context, job *Job) error {
+	if _, err := q.do(ctx, 0, "LREM", q.processing, "1", job.raw); err != nil {
+		return fmt.Errorf("failed to acknowledge job %s: %w", job.ID, err)
+	}
+	return nil
+}
+
+func (q *redisQueue) Close() error {
+	q.drop()
+	return nil
+}
+
+// do sends a command and reads its reply, dialing first if the connection
+// was dropped. block is how long the server may legitimately take before
+// replying.
+func (q *redisQueue) do(ctx context.Context, block time.Duration, args ...string) (any, error) {
+	if q.conn == nil {
+		if err := q.dial(); err != nil {
+			return nil, err
+		}
+	}
+	return q.roundTrip(ctx, block, args...)
+}
+
+// roundTrip sends a command on the current connection and reads its reply.
+// Errors other than error replies drop the connection.
+func (q *redisQueue) roundTrip(ctx context.Context, block time.Duration, args ...string) (any, error) {
+	deadline := time.Now().Add(block + 30*time.Second)
+	if d, ok := ctx.Deadline(); ok && d.Before(deadline) {
+		deadline = d
+	}
+	q.conn.SetDeadline(deadline)
+
+	var cmd strings.Builder
+	fmt.Fprintf(&cmd, "*%d\r\n", len(args))
+	for _, a := range args {
+		fmt.Fprintf(&cmd, "$%d\r\n%s\r\n", len(a), a)
+	}
+	if _, err := io.WriteString(q.conn, cmd.String()); err != nil {
+		q.drop()
+		return nil, fmt.Errorf("redis: %w", err)
+	}
+
+	reply, err := q.readReply()
+	var rerr redisError
+	if err != nil && !errors.As(err, &rerr) {
+		q.drop()
+		return nil, fmt.Errorf("redis: %w", err)
+	}
+	return reply, err
+}
+
+// readReply parses one RESP reply. Bulk strings are returned as string, nil
+// bulk strings and arrays as nil, integers as int64 and arrays as []any.
+func (q *redisQueue) readReply() (any, error) {
+	line, err := q.r.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	line = strings.TrimSuffix(line, "\r\n")
+	if line == "" {
+		return nil, fmt.Errorf("empty reply")
+	}
+
+	switch line[0] {
+	case '+':
+		return line[1:], nil
+	case '-':
+		return nil, redisError(line[1:])
+	case ':':
+		return strconv.ParseInt(line[1:], 10, 64)
+	case '$':
+		n, err := strconv.Atoi(line[1:])
+		if err != nil || n < 0 {
+			return nil, err
+		}
+		buf := make([]byte, n+2)
+		if _, err := io.ReadFull(q.r, buf); err != nil {
+			return nil, err
+		}
+		return string(buf[:n]), nil
+	case '*':
+		n, err := strconv.Atoi(line[1:])
+		if err != nil || n < 0 {
+			return nil, err
+		}
+		items := make([]any, n)
+		for i := range items {
+			if items[i], err = q.readReply(); err != nil {
+				return nil, err
+			}
+		}
+		return items, nil
+	default:
+		return nil, fmt.Errorf("unexpected reply %q", line)
+	}
+}
@@ -0,0 +1,195 @@
+package reachability
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/ethanolivertroy/kev-check-demo/internal/clients"
+	"github.com/ethanolivertroy/kev-check-demo/internal/models"
+)
+
+// GoAnalyzer determines whether vulnerable Go symbols from the vuln DB are
+// referenced by a module's own source. It works at the import/selector level
+// rather than building a call graph: a referenced symbol may never be called,
+// and use through another dependency is not detected. Results are only
+// annotations, never grounds to drop a finding.
+type GoAnalyzer struct {
+	modules map[string]*moduleUsage
+}
+
+// moduleUsage records which imported packages and symbols a module references
+type moduleUsage struct {
+	imported  map[string]bool            // import path -> imported anywhere
+	dotImport map[string]bool            // import path -> dot-imported
+	symbols   map[string]map[string]bool // import path -> referenced identifiers
+	methods   map[string]map[string]bool // import path -> selector names in files importing it
+}
+
+// NewGoAnalyzer creates a new Go reachability analyzer
+func NewGoAnalyzer() *GoAnalyzer {
+	return &GoAnalyzer{modules: make(map[string]*moduleUsage)}
+}
+
+// Analyze reports whether any of the vulnerable imports are referenced by the
+// module rooted at moduleDir
+func (a *GoAnalyzer) Analyze(moduleDir string, imports []clients.OSVImport) models.Reachability {
+	if len(imports) == 0 {
+		return models.ReachabilityUnknown
+	}
+
+	usage, err := a.usage(moduleDir)
+	if err != nil {
+		return models.ReachabilityUnknown
+	}
+
+	for _, imp := range imports {
+		if !usage.imported[imp.Path] {
+			continue
+		}
+		if len(imp.Symbols) == 0 || usage.dotImport[imp.Path] {
+			return models.ReachabilityReferenced
+		}
+		for _, sym := range imp.Symbols {
+			if usage.references(imp.Path, sym) {
+				return models.ReachabilityReferenced
+			}
+		}
+	}
+
+	return models.ReachabilityUnreferenced
+}
+
+// references reports whether a symbol ("Func" or "Type.Method") is used
+func (u *moduleUsage) references(path, symbol string) bool {
+	if typ, method, ok := strings.Cut(symbol, "."); ok {
+		return u.symbols[path][typ] || u.methods[path][method]
+	}
+	return u.symbols[path][symbol]
+}
+
+// usage returns the cached usage for a module, scanning it on first use
+func (a *GoAnalyzer) usage(moduleDir string) (*moduleUsage, error) {
+	if u, ok := a.modules[moduleDir]; ok {
+		return u, nil
+	}
+
+	u := &moduleUsage{
+		imported:  make(map[string]bool),
+		dotImport: make(map[string]bool),
+		symbols:   make(map[string]map[string]bool),
+		methods:   make(map[string]map[string]bool),
+	}
+
+	fset := token.NewFileSet()
+	err := filepath.WalkDir(moduleDir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if d.IsDir() {
+			name := d.Name()
+			if p != moduleDir {
+				// Skip vendored code, test fixtures, hidden dirs and nested modules
+				if name == "vendor" || name == "testdata" ||
+					strings.HasPrefix(name, ".") || strings.HasPrefix(name, "_") {
+					return filepath.SkipDir
+				}
+				if _, err := os.Stat(filepath.Join(p, "go.mod")); err == nil {
+					return filepath.SkipDir
+				}
+			}
+			return nil
+		}
+
+		if !strings.HasSuffix(p, ".go") || strings.HasSuffix(p, "_test.go") {
+			return nil
+		}
+
+		file, err := parser.ParseFile(fset, p, nil, parser.SkipObjectResolution)
+		if err != nil {
+			return nil // Ignore unparsable files
+		}
+		u.addFile(file)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	a.modules[moduleDir] = u
+	return u, nil
+}
+
+// addFile records the imports and selector expressions of a parsed file
+func (u *moduleUsage) addFile(file *ast.File) {
+	locals := make(map[string]string) // local package name -> import path
+	var paths []string
+
+	for _, imp := range file.Imports {
+		path, err := strconv.Unquote(imp.Path.Value)
+		if err != nil {
+			continue
+		}
+		u.imported[path] = true
+		paths = append(paths, path)
+
+		name := defaultPackageName(path)
+		if imp.Name != nil {
+			name = imp.Name.Name
+		}
+		switch name {
+		case ".":
+			u.dotImport[path] = true
+		case "_":
+		default:
+			locals[name] = path
+		}
+	}
+
+	ast.Inspect(file, func(n ast.Node) bool {
+		sel, ok := n.(*ast.SelectorExpr)
+		if !ok {
+			return true
+		}
+		// A method is matched by name in files importing its package, as
+		// the receiver's type isn't known without type checking
+		for _, path := range paths {
+			if u.methods[path] == nil {
+				u.methods[path] = make(map[string]bool)
+			}
+			u.methods[path][sel.Sel.Name] = true
+		}
+		if ident, ok := sel.X.(*ast.Ident); ok {
+			if path, ok := locals[ident.Name]; ok {
+				if u.symbols[path] == nil {
+					u.symbols[path] = make(map[string]bool)
+				}
+				u.symbols[path][sel.Sel.Name] = true
+			}
+		}
+		return true
+	})
+}
+
+// defaultPackageName guesses the package name from an import path,
+// handling major version suffixes (/v2) and gopkg.in style (.v3) paths
+func defaultPackageName(path string) string {
+	parts := strings.Split(path, "/")
+	name := parts[len(parts)-1]
+	if len(parts) > 1 && len(name) > 1 && name[0] == 'v' {
+		if _, err := strconv.Atoi(name[1:]); err == nil {
+			name = parts[len(parts)-2]
+		}
+	}
+	if idx := strings.Index(name, ".v"); idx > 0 {
+		name = name[:idx]
+	}
+	name = strings.TrimPrefix(name, "go-")
+	return strings.ReplaceAll(name, "-", "_")
+}
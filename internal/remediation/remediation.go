@@ -0,0 +1,83 @@
+package remediation
+
+import (
+	"strings"
+
+	"github.com/ethanolivertroy/kev-check-demo/internal/clients"
+	"github.com/ethanolivertroy/kev-check-demo/internal/models"
+	"github.com/ethanolivertroy/kev-check-demo/internal/versions"
+)
+
+// FixVersion returns the lowest version of dep that is fixed in every one of
+// the given advisories. ok is false if any advisory has no fixed version at or
+// above the dependency's current version.
+func FixVersion(dep models.Dependency, records []*clients.OSVRecord) (fix string, ok bool) {
+	scheme := dep.Ecosystem.Info().Versions
+	for _, rec := range records {
+		v, found := recordFix(dep, rec)
+		if !found {
+			return "", false
+		}
+		if fix == "" || versions.Compare(scheme, v, fix) > 0 {
+			fix = v
+		}
+	}
+	return fix, fix != ""
+}
+
+// Classify decides the remediation effort for a finding's dependency
+func Classify(dep models.Dependency, fixAvailable bool) models.Effort {
+	switch {
+	case !fixAvailable:
+		return models.EffortNoFix
+	case dep.Transitive:
+		return models.EffortTransitiveOverride
+	default:
+		return models.EffortDirectBump
+	}
+}
+
+// recordFix returns the lowest fixed version in the advisory that is newer
+// than dep's version. Unpinned dependencies take the highest fix, since any
+// installed version may be behind it.
+func recordFix(dep models.Dependency, rec *clients.OSVRecord) (string, bool) {
+	current := strings.TrimPrefix(dep.Version, "v")
+	var best string
+	for _, a := range rec.Affected {
+		if !strings.EqualFold(a.Package.Ecosystem, dep.Ecosystem.OSVName()) {
+			continue
+		}
+		if models.NormalizeName(dep.Ecosystem, a.Package.Name) != models.NormalizeName(dep.Ecosystem, dep.Name) {
+			continue
+		}
+		for _, r := range a.Ranges {
+			if r.Type == "GIT" {
+				continue
+			}
+			// SEMVER ranges are semver regardless of ecosystem
+			scheme := dep.Ecosystem.Info().Versions
+			if r.Type == "SEMVER" {
+				scheme = models.VersionSemver
+			}
+			for _, e := range r.Events {
+				fixed := strings.TrimPrefix(e.Fixed, "v")
+				if fixed == "" {
+					continue
+				}
+				if dep.Unpinned || current == "" {
+					if best == "" || versions.Compare(scheme, fixed, best) > 0 {
+						best = fixed
+					}
+					continue
+				}
+				if versions.Compare(scheme, fixed, current) <= 0 {
+					continue
+				}
+				if best == "" || versions.Compare(scheme, fixed, best) < 0 {
+					best = fixed
+				}
+			}
+		}
+	}
+	return best, best != ""
+}
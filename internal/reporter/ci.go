@@ -0,0 +1,128 @@
+package reporter
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/ethanolivertroy/kev-check-demo/internal/models"
+)
+
+// AzureDevOpsReporter outputs findings as Azure Pipelines logging commands
+type AzureDevOpsReporter struct{}
+
+// Report generates one ##vso[task.logissue] command per KEV finding
+func (r *AzureDevOpsReporter) Report(findings []models.Finding) ([]byte, error) {
+	var sb strings.Builder
+
+	for _, f := range findings {
+		for _, kev := range f.KEVs {
+			issueType := "error"
+			if kev.Accepted != nil || severityOf(kev) == models.SeverityMedium {
+				issueType = "warning"
+			}
+
+			props := []string{
+				"type=" + issueType,
+				"sourcepath=" + escapeVSOProperty(f.Dependency.SourceFile),
+			}
+			if f.Dependency.Line > 0 {
+				props = append(props, fmt.Sprintf("linenumber=%d", f.Dependency.Line))
+			}
+			props = append(props, "code="+escapeVSOProperty(kev.CVEID))
+
+			sb.WriteString(fmt.Sprintf("##vso[task.logissue %s]%s\n",
+				strings.Join(props, ";"), escapeVSOData(ciMessage(f, kev))))
+		}
+	}
+
+	if len(findings) > 0 {
+		sb.WriteString("##vso[task.complete result=SucceededWithIssues;]KEV vulnerabilities found\n")
+	}
+
+	return []byte(sb.String()), nil
+}
+
+// TeamCityReporter outputs findings as TeamCity inspection service messages
+type TeamCityReporter struct{}
+
+// Report generates inspectionType and inspection service messages per KEV finding
+func (r *TeamCityReporter) Report(findings []models.Finding) ([]byte, error) {
+	var sb strings.Builder
+	declared := make(map[string]bool)
+
+	for _, f := range findings {
+		for _, kev := range f.KEVs {
+			if !declared[kev.CVEID] {
+				declared[kev.CVEID] = true
+				sb.WriteString(fmt.Sprintf("##teamcity[inspectionType id='%s' name='%s' category='CISA KEV' description='%s']\n",
+					escapeTeamCity(kev.CVEID), escapeTeamCity(kev.VulnerabilityName), escapeTeamCity(kev.ShortDescription)))
+			}
+
+			line := ""
+			if f.Dependency.Line > 0 {
+				line = fmt.Sprintf(" line='%d'", f.Dependency.Line)
+			}
+			severity := "ERROR"
+			switch {
+			case kev.Accepted != nil:
+				severity = "INFO"
+			case severityOf(kev) == models.SeverityMedium:
+				severity = "WARNING"
+			}
+			sb.WriteString(fmt.Sprintf("##teamcity[inspection typeId='%s' message='%s' file='%s'%s SEVERITY='%s']\n",
+				escapeTeamCity(kev.CVEID), escapeTeamCity(ciMessage(f, kev)),
+				escapeTeamCity(f.Dependency.SourceFile), line, severity))
+		}
+	}
+
+	if len(findings) > 0 {
+		sb.WriteString(fmt.Sprintf("##teamcity[buildProblem description='%s' identity='kev-checker']\n",
+			escapeTeamCity(fmt.Sprintf("KEV vulnerabilities found in %d dependencies", len(findings)))))
+	}
+
+	return []byte(sb.String()), nil
+}
+
+// ciMessage builds the single-finding message shared by CI service-message formats
+func ciMessage(f models.Finding, kev models.KEVInfo) string {
+	msg := fmt.Sprintf("Dependency %s has known exploited vulnerability %s: %s",
+		f.Dependency.String(), kev.CVEID, kev.VulnerabilityName)
+	if kev.EPSSScore > 0 {
+		msg += fmt.Sprintf(" (EPSS: %.1f%%)", kev.EPSSScore*100)
+	}
+	if kev.RansomwareUse {
+		msg += " [Known ransomware usage]"
+	}
+	if kev.Accepted != nil {
+		msg += fmt.Sprintf(" [Risk accepted: %s]", kev.Accepted.Reason)
+	}
+	return msg
+}
+
+// escapeVSOData escapes an Azure Pipelines logging command message
+func escapeVSOData(s string) string {
+	s = strings.ReplaceAll(s, "%", "%AZP25")
+	s = strings.ReplaceAll(s, "\r", "%0D")
+	s = strings.ReplaceAll(s, "\n", "%0A")
+	return s
+}
+
+// escapeVSOProperty escapes an Azure Pipelines logging command property value
+func escapeVSOProperty(s string) string {
+	s = escapeVSOData(s)
+	s = strings.ReplaceAll(s, ";", "%3B")
+	s = strings.ReplaceAll(s, "]", "%5D")
+	return s
+}
+
+// escapeTeamCity escapes a TeamCity service message attribute value
+func escapeTeamCity(s string) string {
+	return strings.NewReplacer(
+		"|", "||",
+		"'", "|'",
+		"\n", "|n",
+		"\r", "|r",
+		"[", "|[",
+		"]", "|]",
+	).Replace(s)
+}
@@ -0,0 +1,73 @@
+package reporter
+
+import (
+	"sort"
+
+	"github.com/ethanolivertroy/kev-check-demo/internal/models"
+)
+
+// cweNames gives short names for the weakness classes most common in KEV
+var cweNames = map[string]string{
+	"CWE-20":  "improper input validation",
+	"CWE-22":  "path traversal",
+	"CWE-77":  "command injection",
+	"CWE-78":  "OS command injection",
+	"CWE-79":  "cross-site scripting",
+	"CWE-89":  "SQL injection",
+	"CWE-94":  "code injection",
+	"CWE-119": "memory corruption",
+	"CWE-120": "buffer overflow",
+	"CWE-125": "out-of-bounds read",
+	"CWE-190": "integer overflow",
+	"CWE-200": "information exposure",
+	"CWE-269": "privilege management",
+	"CWE-287": "improper authentication",
+	"CWE-288": "authentication bypass",
+	"CWE-306": "missing authentication",
+	"CWE-352": "cross-site request forgery",
+	"CWE-400": "resource exhaustion",
+	"CWE-416": "use after free",
+	"CWE-434": "unrestricted file upload",
+	"CWE-476": "null pointer dereference",
+	"CWE-502": "deserialization",
+	"CWE-611": "XML external entities",
+	"CWE-787": "out-of-bounds write",
+	"CWE-798": "hard-coded credentials",
+	"CWE-843": "type confusion",
+	"CWE-862": "missing authorization",
+	"CWE-863": "incorrect authorization",
+	"CWE-917": "expression language injection",
+	"CWE-918": "server-side request forgery",
+}
+
+// cweCount is the number of KEVs with one weakness class
+type cweCount struct {
+	ID    string
+	Name  string
+	Count int
+}
+
+// cweBreakdown counts KEVs by CWE, most common first. KEVs without a CWE
+// aren't counted; KEVs with several count once for each.
+func cweBreakdown(findings []models.Finding) []cweCount {
+	counts := make(map[string]int)
+	for _, f := range findings {
+		for _, kev := range f.KEVs {
+			for _, cwe := range kev.CWEs {
+				counts[cwe]++
+			}
+		}
+	}
+
+	breakdown := make([]cweCount, 0, len(counts))
+	for id, n := range counts {
+		breakdown = append(breakdown, cweCount{ID: id, Name: cweNames[id], Count: n})
+	}
+	sort.Slice(breakdown, func(i, j int) bool {
+		if breakdown[i].Count != breakdown[j].Count {
+			return breakdown[i].Count > breakdown[j].Count
+		}
+		return breakdown[i].ID < breakdown[j].ID
+	})
+	return breakdown
+}
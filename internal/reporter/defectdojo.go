@@ -0,0 +1,123 @@
+package reporter
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ethanolivertroy/kev-check-demo/internal/models"
+)
+
+// DefectDojoReporter outputs findings in DefectDojo's Generic Findings Import format
+type DefectDojoReporter struct {
+	noTimestamps bool
+}
+
+// OmitTimestamps implements TimestampOmitter, leaving out the finding date so
+// DefectDojo uses the import date
+func (r *DefectDojoReporter) OmitTimestamps() { r.noTimestamps = true }
+
+type ddReport struct {
+	Findings []ddFinding `json:"findings"`
+}
+
+type ddFinding struct {
+	Title            string   `json:"title"`
+	Description      string   `json:"description"`
+	Severity         string   `json:"severity"`
+	Mitigation       string   `json:"mitigation"`
+	Impact           string   `json:"impact,omitempty"`
+	References       string   `json:"references"`
+	CVE              string   `json:"cve"`
+	CWE              int      `json:"cwe,omitempty"`
+	Date             string   `json:"date,omitempty"`
+	FilePath         string   `json:"file_path"`
+	Line             int      `json:"line,omitempty"`
+	ComponentName    string   `json:"component_name"`
+	ComponentVersion string   `json:"component_version"`
+	VulnIDFromTool   string   `json:"vuln_id_from_tool"`
+	UniqueIDFromTool string   `json:"unique_id_from_tool"`
+	EPSSScore        float64  `json:"epss_score,omitempty"`
+	EPSSPercentile   float64  `json:"epss_percentile,omitempty"`
+	KnownExploited   bool     `json:"known_exploited"`
+	RansomwareUsed   bool     `json:"ransomware_used"`
+	KEVDate          string   `json:"kev_date,omitempty"`
+	RiskAccepted     bool     `json:"risk_accepted"`
+	Active           bool     `json:"active"`
+	Verified         bool     `json:"verified"`
+	StaticFinding    bool     `json:"static_finding"`
+	DynamicFinding   bool     `json:"dynamic_finding"`
+	Tags             []string `json:"tags"`
+}
+
+// Report generates DefectDojo generic import JSON for the given findings
+func (r *DefectDojoReporter) Report(findings []models.Finding) ([]byte, error) {
+	report := ddReport{Findings: make([]ddFinding, 0, len(findings))}
+	today := time.Now().Format("2006-01-02")
+	if r.noTimestamps {
+		today = ""
+	}
+
+	for _, f := range findings {
+		for _, kev := range f.KEVs {
+			severity := severityTitle(kev)
+			tags := []string{"kev", "cisa", strings.ToLower(string(f.Dependency.Ecosystem))}
+			if kev.RansomwareUse {
+				tags = append(tags, "ransomware")
+			}
+
+			desc := fmt.Sprintf("%s\n\n**Package:** %s\n**Vendor/Product:** %s - %s\n**Due Date:** %s",
+				kev.ShortDescription, f.Dependency.String(), kev.VendorProject, kev.Product,
+				kev.DueDate.Format("2006-01-02"))
+			if len(f.Owners) > 0 {
+				desc += "\n**Owner:** " + strings.Join(f.Owners, ", ")
+				for _, owner := range f.Owners {
+					tags = append(tags, "owner:"+strings.TrimPrefix(owner, "@"))
+				}
+			}
+
+			report.Findings = append(report.Findings, ddFinding{
+				Title:            fmt.Sprintf("%s in %s", kev.CVEID, f.Dependency.String()),
+				Description:      desc,
+				Severity:         severity,
+				Mitigation:       kev.RequiredAction,
+				Impact:           kev.VulnerabilityName,
+				References:       fmt.Sprintf("https://nvd.nist.gov/vuln/detail/%s", kev.CVEID),
+				CVE:              kev.CVEID,
+				CWE:              firstCWE(kev.CWEs),
+				Date:             today,
+				FilePath:         f.Dependency.SourceFile,
+				Line:             f.Dependency.Line,
+				ComponentName:    f.Dependency.Name,
+				ComponentVersion: f.Dependency.Version,
+				VulnIDFromTool:   kev.CVEID,
+				UniqueIDFromTool: f.Fingerprint(kev.CVEID),
+				EPSSScore:        kev.EPSSScore,
+				EPSSPercentile:   kev.EPSSPercentile,
+				KnownExploited:   true,
+				RansomwareUsed:   kev.RansomwareUse,
+				KEVDate:          kev.DateAdded.Format("2006-01-02"),
+				RiskAccepted:     kev.Accepted != nil,
+				Active:           true,
+				Verified:         false,
+				StaticFinding:    true,
+				DynamicFinding:   false,
+				Tags:             tags,
+			})
+		}
+	}
+
+	return json.MarshalIndent(report, "", "  ")
+}
+
+// firstCWE returns the numeric ID of the first "CWE-n" entry, or 0
+func firstCWE(cwes []string) int {
+	for _, cwe := range cwes {
+		if n, err := strconv.Atoi(strings.TrimPrefix(cwe, "CWE-")); err == nil {
+			return n
+		}
+	}
+	return 0
+}
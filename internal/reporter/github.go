@@ -0,0 +1,67 @@
+package reporter
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/ethanolivertroy/kev-check-demo/internal/models"
+)
+
+// GitHubActionsReporter outputs findings as GitHub Actions workflow commands
+type GitHubActionsReporter struct{}
+
+// Report generates one workflow command per KEV finding (::notice if risk accepted)
+func (r *GitHubActionsReporter) Report(findings []models.Finding) ([]byte, error) {
+	var sb strings.Builder
+
+	for _, f := range findings {
+		for _, kev := range f.KEVs {
+			props := []string{"file=" + escapeGHProperty(f.Dependency.SourceFile)}
+			if f.Dependency.Line > 0 {
+				props = append(props, fmt.Sprintf("line=%d", f.Dependency.Line))
+			}
+			props = append(props, "title="+escapeGHProperty(fmt.Sprintf("KEV %s in %s", kev.CVEID, f.Dependency.String())))
+
+			msg := fmt.Sprintf("%s: %s", kev.CVEID, kev.VulnerabilityName)
+			if kev.EPSSScore > 0 {
+				msg += fmt.Sprintf(" (EPSS: %.1f%%)", kev.EPSSScore*100)
+			}
+			if kev.RansomwareUse {
+				msg += " [Known ransomware usage]"
+			}
+			if kev.RequiredAction != "" {
+				msg += "\nRequired Action: " + kev.RequiredAction
+			}
+			msg += "\nDue Date: " + kev.DueDate.Format("2006-01-02")
+
+			level := "error"
+			if severityOf(kev) == models.SeverityMedium {
+				level = "warning"
+			}
+			if kev.Accepted != nil {
+				level = "notice"
+				msg += fmt.Sprintf("\nRisk accepted (%s): %s", kev.Accepted.Reason, kev.Accepted.Justification)
+			}
+
+			sb.WriteString(fmt.Sprintf("::%s %s::%s\n", level, strings.Join(props, ","), escapeGHData(msg)))
+		}
+	}
+
+	return []byte(sb.String()), nil
+}
+
+// escapeGHData escapes a workflow command message
+func escapeGHData(s string) string {
+	s = strings.ReplaceAll(s, "%", "%25")
+	s = strings.ReplaceAll(s, "\r", "%0D")
+	s = strings.ReplaceAll(s, "\n", "%0A")
+	return s
+}
+
+// escapeGHProperty escapes a workflow command property value
+func escapeGHProperty(s string) string {
+	s = escapeGHData(s)
+	s = strings.ReplaceAll(s, ":", "%3A")
+	s = strings.ReplaceAll(s, ",", "%2C")
+	return s
+}
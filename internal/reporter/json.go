@@ -2,41 +2,127 @@ package reporter
 
 import (
 	"encoding/json"
+	"time"
 
 	"github.com/ethanolivertroy/kev-check-demo/internal/models"
 )
 
 // JSONReporter outputs findings in JSON format
-type JSONReporter struct{}
+type JSONReporter struct {
+	// Omitted is the number of findings dropped by --max-findings, reported
+	// in the truncated marker
+	Omitted int
+	// Violations are deny-listed dependencies, reported alongside findings
+	Violations []models.Violation
+	// Errors are manifests that could not be parsed, whose dependencies are
+	// missing from the findings
+	Errors []models.ParseError
+	// Local are dependencies built from the project's own source, which
+	// weren't checked
+	Local []models.Dependency
+}
 
 // jsonOutput represents the JSON output structure
 type jsonOutput struct {
-	Summary  jsonSummary   `json:"summary"`
-	Findings []jsonFinding `json:"findings"`
+	SchemaVersion string          `json:"schema_version"`
+	Summary       jsonSummary     `json:"summary"`
+	Findings      []jsonFinding   `json:"findings"`
+	Truncated     *jsonTruncated  `json:"truncated,omitempty"`
+	Violations    []jsonViolation `json:"violations,omitempty"`
+	Errors        []jsonError     `json:"errors,omitempty"`
+	Local         []jsonLocal     `json:"local_components,omitempty"`
 }
 
-type jsonSummary struct {
-	TotalFindings      int `json:"total_findings"`
-	TotalKEVs          int `json:"total_kevs"`
-	RansomwareRelated  int `json:"ransomware_related"`
-	AffectedPackages   int `json:"affected_packages"`
+type jsonLocal struct {
+	Name       string `json:"name"`
+	Ecosystem  string `json:"ecosystem"`
+	Local      string `json:"local"`
+	SourceFile string `json:"source_file"`
+	Line       int    `json:"line,omitempty"`
 }
 
-type jsonFinding struct {
+type jsonError struct {
+	SourceFile string `json:"source_file"`
+	Error      string `json:"error"`
+	Panic      bool   `json:"panic"`
+}
+
+type jsonViolation struct {
 	Package    jsonPackage `json:"package"`
 	SourceFile string      `json:"source_file"`
 	Line       int         `json:"line,omitempty"`
-	KEVs       []jsonKEV   `json:"kevs"`
+	Rule       string      `json:"rule"`
+	Reason     string      `json:"reason"`
+}
+
+type jsonTruncated struct {
+	ScannedFindings int    `json:"scanned_findings"`
+	OmittedFindings int    `json:"omitted_findings"`
+	Note            string `json:"note"`
+}
+
+type jsonSummary struct {
+	TotalFindings     int `json:"total_findings"`
+	TotalKEVs         int `json:"total_kevs"`
+	RansomwareRelated int `json:"ransomware_related"`
+	AffectedPackages  int `json:"affected_packages"`
+	RiskAccepted      int `json:"risk_accepted"`
+	SLABreaches       int `json:"sla_breaches"`
+	Potential         int `json:"potential"`
+	NoFix             int `json:"no_fix"`
+
+	BySeverity map[string]int `json:"by_severity,omitempty"`
+	ByCWE      []jsonCWECount `json:"by_cwe,omitempty"`
+}
+
+type jsonCWECount struct {
+	CWE   string `json:"cwe"`
+	Name  string `json:"name,omitempty"`
+	Count int    `json:"count"`
+}
+
+type jsonFinding struct {
+	Package     jsonPackage      `json:"package"`
+	SourceFile  string           `json:"source_file"`
+	Line        int              `json:"line,omitempty"`
+	Snippet     string           `json:"snippet,omitempty"`
+	AlsoIn      []jsonLocation   `json:"also_in,omitempty"`
+	Confidence  string           `json:"confidence"`
+	Remediation *jsonRemediation `json:"remediation,omitempty"`
+	Owners      []string         `json:"owners,omitempty"`
+	KEVs        []jsonKEV        `json:"kevs"`
+	Health      *jsonHealth      `json:"package_health,omitempty"`
+}
+
+type jsonLocation struct {
+	SourceFile string `json:"source_file"`
+	Line       int    `json:"line,omitempty"`
+}
+
+type jsonRemediation struct {
+	Effort     string `json:"effort"`
+	FixVersion string `json:"fix_version,omitempty"`
+}
+
+type jsonHealth struct {
+	LatestVersion    string `json:"latest_version,omitempty"`
+	LastRelease      string `json:"last_release,omitempty"`
+	Deprecated       bool   `json:"deprecated"`
+	DeprecatedReason string `json:"deprecated_reason,omitempty"`
+	Unmaintained     bool   `json:"unmaintained"`
 }
 
 type jsonPackage struct {
 	Name      string `json:"name"`
 	Version   string `json:"version"`
 	Ecosystem string `json:"ecosystem"`
+	Dev       bool   `json:"dev,omitempty"`
 }
 
 type jsonKEV struct {
 	CVEID             string   `json:"cve_id"`
+	Fingerprint       string   `json:"fingerprint"`
+	Catalog           string   `json:"catalog"`
 	VendorProject     string   `json:"vendor_project"`
 	Product           string   `json:"product"`
 	VulnerabilityName string   `json:"vulnerability_name"`
@@ -45,14 +131,69 @@ type jsonKEV struct {
 	DueDate           string   `json:"due_date"`
 	RequiredAction    string   `json:"required_action"`
 	RansomwareUse     bool     `json:"ransomware_use"`
+	Severity          string   `json:"severity"`
 	CWEs              []string `json:"cwes,omitempty"`
 	EPSSScore         float64  `json:"epss_score,omitempty"`
 	EPSSPercentile    float64  `json:"epss_percentile,omitempty"`
+	CVSSScore         float64  `json:"cvss_score,omitempty"`
+	CVSSVector        string   `json:"cvss_vector,omitempty"`
+	Reachability      string   `json:"reachability,omitempty"`
+	FirstSeen         string   `json:"first_seen,omitempty"`
+	DaysOpen          int      `json:"days_open,omitempty"`
+	SLADeadline       string   `json:"sla_deadline,omitempty"`
+	SLABreached       bool     `json:"sla_breached"`
+
+	RiskAccepted *jsonRiskAcceptance `json:"risk_accepted,omitempty"`
+}
+
+type jsonRiskAcceptance struct {
+	Reason        string `json:"reason"`
+	Justification string `json:"justification"`
+	ApprovedBy    string `json:"approved_by,omitempty"`
+	ApprovedOn    string `json:"approved_on,omitempty"`
 }
 
 // Report generates JSON output for the given findings
 func (r *JSONReporter) Report(findings []models.Finding) ([]byte, error) {
+	output := newJSONOutput(findings, r.Omitted)
+	for _, v := range r.Violations {
+		output.Violations = append(output.Violations, jsonViolation{
+			Package: jsonPackage{
+				Name:      v.Dependency.Name,
+				Version:   v.Dependency.Version,
+				Ecosystem: string(v.Dependency.Ecosystem),
+				Dev:       v.Dependency.Dev,
+			},
+			SourceFile: v.Dependency.SourceFile,
+			Line:       v.Dependency.Line,
+			Rule:       v.Rule,
+			Reason:     v.Reason,
+		})
+	}
+	for _, e := range r.Errors {
+		output.Errors = append(output.Errors, jsonError{
+			SourceFile: e.File,
+			Error:      e.Error,
+			Panic:      e.Panic,
+		})
+	}
+	for _, dep := range r.Local {
+		output.Local = append(output.Local, jsonLocal{
+			Name:       dep.Name,
+			Ecosystem:  string(dep.Ecosystem),
+			Local:      dep.Local,
+			SourceFile: dep.SourceFile,
+			Line:       dep.Line,
+		})
+	}
+	return json.MarshalIndent(output, "", "  ")
+}
+
+// newJSONOutput builds the JSON report document, which template packs also
+// render from
+func newJSONOutput(findings []models.Finding, omitted int) jsonOutput {
 	output := jsonOutput{
+		SchemaVersion: JSONSchemaVersion,
 		Summary: jsonSummary{
 			TotalFindings:    len(findings),
 			AffectedPackages: len(findings),
@@ -61,42 +202,125 @@ func (r *JSONReporter) Report(findings []models.Finding) ([]byte, error) {
 	}
 
 	for _, f := range findings {
-		jf := jsonFinding{
-			Package: jsonPackage{
-				Name:      f.Dependency.Name,
-				Version:   f.Dependency.Version,
-				Ecosystem: string(f.Dependency.Ecosystem),
-			},
-			SourceFile: f.Dependency.SourceFile,
-			Line:       f.Dependency.Line,
-			KEVs:       make([]jsonKEV, 0, len(f.KEVs)),
+		if f.Potential() {
+			output.Summary.Potential++
+		}
+		if f.Effort == models.EffortNoFix {
+			output.Summary.NoFix++
 		}
-
 		for _, kev := range f.KEVs {
 			output.Summary.TotalKEVs++
 			if kev.RansomwareUse {
 				output.Summary.RansomwareRelated++
 			}
-
-			jk := jsonKEV{
-				CVEID:             kev.CVEID,
-				VendorProject:     kev.VendorProject,
-				Product:           kev.Product,
-				VulnerabilityName: kev.VulnerabilityName,
-				Description:       kev.ShortDescription,
-				DateAdded:         kev.DateAdded.Format("2006-01-02"),
-				DueDate:           kev.DueDate.Format("2006-01-02"),
-				RequiredAction:    kev.RequiredAction,
-				RansomwareUse:     kev.RansomwareUse,
-				CWEs:              kev.CWEs,
-				EPSSScore:         kev.EPSSScore,
-				EPSSPercentile:    kev.EPSSPercentile,
+			if kev.SLABreached() {
+				output.Summary.SLABreaches++
 			}
-			jf.KEVs = append(jf.KEVs, jk)
+			if kev.Accepted != nil {
+				output.Summary.RiskAccepted++
+			}
+			if output.Summary.BySeverity == nil {
+				output.Summary.BySeverity = make(map[string]int)
+			}
+			output.Summary.BySeverity[string(severityOf(kev))]++
 		}
+		output.Findings = append(output.Findings, newJSONFinding(f))
+	}
 
-		output.Findings = append(output.Findings, jf)
+	for _, c := range cweBreakdown(findings) {
+		output.Summary.ByCWE = append(output.Summary.ByCWE, jsonCWECount{CWE: c.ID, Name: c.Name, Count: c.Count})
 	}
 
-	return json.MarshalIndent(output, "", "  ")
+	if omitted > 0 {
+		output.Truncated = &jsonTruncated{
+			ScannedFindings: len(findings) + omitted,
+			OmittedFindings: omitted,
+			Note:            truncationNote,
+		}
+	}
+
+	return output
+}
+
+// newJSONFinding converts a finding to its JSON report form
+func newJSONFinding(f models.Finding) jsonFinding {
+	jf := jsonFinding{
+		Package: jsonPackage{
+			Name:      f.Dependency.Name,
+			Version:   f.Dependency.Version,
+			Ecosystem: string(f.Dependency.Ecosystem),
+			Dev:       f.Dependency.Dev,
+		},
+		SourceFile: f.Dependency.SourceFile,
+		Line:       f.Dependency.Line,
+		Snippet:    f.Dependency.Snippet,
+		Confidence: string(f.Confidence),
+		Owners:     f.Owners,
+		KEVs:       make([]jsonKEV, 0, len(f.KEVs)),
+	}
+
+	for _, loc := range f.Dependency.Duplicates {
+		jf.AlsoIn = append(jf.AlsoIn, jsonLocation{SourceFile: loc.File, Line: loc.Line})
+	}
+
+	if f.Effort != "" {
+		jf.Remediation = &jsonRemediation{
+			Effort:     string(f.Effort),
+			FixVersion: f.FixVersion,
+		}
+	}
+
+	if f.Health != nil {
+		jf.Health = &jsonHealth{
+			LatestVersion:    f.Health.LatestVersion,
+			Deprecated:       f.Health.Deprecated,
+			DeprecatedReason: f.Health.DeprecatedReason,
+			Unmaintained:     f.Health.Unmaintained(),
+		}
+		if !f.Health.LastRelease.IsZero() {
+			jf.Health.LastRelease = f.Health.LastRelease.Format("2006-01-02")
+		}
+	}
+
+	for _, kev := range f.KEVs {
+		jk := jsonKEV{
+			CVEID:             kev.CVEID,
+			Fingerprint:       f.Fingerprint(kev.CVEID),
+			VendorProject:     kev.VendorProject,
+			Product:           kev.Product,
+			VulnerabilityName: kev.VulnerabilityName,
+			Description:       kev.ShortDescription,
+			Catalog:           kev.Catalog,
+			DateAdded:         kev.DateAdded.Format("2006-01-02"),
+			DueDate:           kev.DueDate.Format("2006-01-02"),
+			RequiredAction:    kev.RequiredAction,
+			RansomwareUse:     kev.RansomwareUse,
+			Severity:          string(severityOf(kev)),
+			CWEs:              kev.CWEs,
+			EPSSScore:         kev.EPSSScore,
+			EPSSPercentile:    kev.EPSSPercentile,
+			CVSSScore:         kev.CVSSScore,
+			CVSSVector:        kev.CVSSVector,
+			Reachability:      string(kev.Reachability),
+		}
+		if !kev.FirstSeen.IsZero() {
+			jk.FirstSeen = kev.FirstSeen.Format("2006-01-02")
+			jk.DaysOpen = kev.DaysOpen()
+		}
+		if !kev.SLADeadline.IsZero() {
+			jk.SLADeadline = kev.SLADeadline.Format(time.RFC3339)
+			jk.SLABreached = kev.SLABreached()
+		}
+		if kev.Accepted != nil {
+			jk.RiskAccepted = &jsonRiskAcceptance{
+				Reason:        kev.Accepted.Reason,
+				Justification: kev.Accepted.Justification,
+				ApprovedBy:    kev.Accepted.ApprovedBy,
+				ApprovedOn:    kev.Accepted.ApprovedOn,
+			}
+		}
+		jf.KEVs = append(jf.KEVs, jk)
+	}
+
+	return jf
 }
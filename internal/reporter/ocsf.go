@@ -0,0 +1,202 @@
+package reporter
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/ethanolivertroy/kev-check-demo/internal/models"
+)
+
+// OCSFReporter outputs findings as OCSF Vulnerability Finding events
+type OCSFReporter struct {
+	noTimestamps bool
+}
+
+// OmitTimestamps implements TimestampOmitter, leaving out the event time
+func (r *OCSFReporter) OmitTimestamps() { r.noTimestamps = true }
+
+// OCSF class and enum values (schema 1.1.0)
+const (
+	ocsfVersion          = "1.1.0"
+	ocsfCategoryFindings = 2
+	ocsfClassVulnFinding = 2002
+	ocsfActivityCreate   = 1
+	ocsfSeverityMedium   = 3
+	ocsfSeverityHigh     = 4
+	ocsfSeverityCritical = 5
+	ocsfStatusNew        = 1
+	ocsfStatusSuppressed = 3
+)
+
+// OCSF structures
+type ocsfEvent struct {
+	ActivityID      int                 `json:"activity_id"`
+	ActivityName    string              `json:"activity_name"`
+	CategoryUID     int                 `json:"category_uid"`
+	CategoryName    string              `json:"category_name"`
+	ClassUID        int                 `json:"class_uid"`
+	ClassName       string              `json:"class_name"`
+	TypeUID         int                 `json:"type_uid"`
+	SeverityID      int                 `json:"severity_id"`
+	Severity        string              `json:"severity"`
+	StatusID        int                 `json:"status_id"`
+	Status          string              `json:"status"`
+	StatusDetail    string              `json:"status_detail,omitempty"`
+	Time            int64               `json:"time,omitzero"`
+	Message         string              `json:"message"`
+	Metadata        ocsfMetadata        `json:"metadata"`
+	FindingInfo     ocsfFindingInfo     `json:"finding_info"`
+	Vulnerabilities []ocsfVulnerability `json:"vulnerabilities"`
+}
+
+type ocsfMetadata struct {
+	Version string      `json:"version"`
+	Product ocsfProduct `json:"product"`
+}
+
+type ocsfProduct struct {
+	Name       string `json:"name"`
+	VendorName string `json:"vendor_name"`
+	Version    string `json:"version"`
+}
+
+type ocsfFindingInfo struct {
+	UID       string   `json:"uid"`
+	Title     string   `json:"title"`
+	Desc      string   `json:"desc,omitempty"`
+	Types     []string `json:"types"`
+	SrcURL    string   `json:"src_url,omitempty"`
+	FirstSeen int64    `json:"first_seen_time,omitempty"`
+}
+
+type ocsfVulnerability struct {
+	Title              string          `json:"title"`
+	Desc               string          `json:"desc,omitempty"`
+	Severity           string          `json:"severity"`
+	CVE                ocsfCVE         `json:"cve"`
+	CWE                *ocsfCWE        `json:"cwe,omitempty"`
+	AffectedPackages   []ocsfPackage   `json:"affected_packages"`
+	IsExploitAvailable bool            `json:"is_exploit_available"`
+	Remediation        ocsfRemediation `json:"remediation"`
+	References         []string        `json:"references,omitempty"`
+	VendorName         string          `json:"vendor_name,omitempty"`
+}
+
+type ocsfCVE struct {
+	UID  string    `json:"uid"`
+	Desc string    `json:"desc,omitempty"`
+	EPSS *ocsfEPSS `json:"epss,omitempty"`
+}
+
+type ocsfEPSS struct {
+	Score      string  `json:"score"`
+	Percentile float64 `json:"percentile"`
+}
+
+type ocsfCWE struct {
+	UID string `json:"uid"`
+}
+
+type ocsfPackage struct {
+	Name           string `json:"name"`
+	Version        string `json:"version"`
+	PackageManager string `json:"package_manager"`
+	Path           string `json:"path,omitempty"`
+}
+
+type ocsfRemediation struct {
+	Desc string `json:"desc"`
+}
+
+// Report generates a JSON array of OCSF events for the given findings
+func (r *OCSFReporter) Report(findings []models.Finding) ([]byte, error) {
+	now := time.Now().UnixMilli()
+	if r.noTimestamps {
+		now = 0
+	}
+	events := make([]ocsfEvent, 0, len(findings))
+
+	for _, f := range findings {
+		for _, kev := range f.KEVs {
+			severity := severityTitle(kev)
+			severityID := map[models.Severity]int{
+				models.SeverityCritical: ocsfSeverityCritical,
+				models.SeverityHigh:     ocsfSeverityHigh,
+				models.SeverityMedium:   ocsfSeverityMedium,
+			}[severityOf(kev)]
+
+			vuln := ocsfVulnerability{
+				Title:    kev.VulnerabilityName,
+				Desc:     kev.ShortDescription,
+				Severity: severity,
+				CVE: ocsfCVE{
+					UID:  kev.CVEID,
+					Desc: kev.ShortDescription,
+				},
+				AffectedPackages: []ocsfPackage{{
+					Name:           f.Dependency.Name,
+					Version:        f.Dependency.Version,
+					PackageManager: string(f.Dependency.Ecosystem),
+					Path:           f.Dependency.SourceFile,
+				}},
+				IsExploitAvailable: true,
+				Remediation:        ocsfRemediation{Desc: kev.RequiredAction},
+				References:         []string{fmt.Sprintf("https://nvd.nist.gov/vuln/detail/%s", kev.CVEID)},
+				VendorName:         kev.VendorProject,
+			}
+			if kev.EPSSScore > 0 {
+				vuln.CVE.EPSS = &ocsfEPSS{
+					Score:      fmt.Sprintf("%.5f", kev.EPSSScore),
+					Percentile: kev.EPSSPercentile,
+				}
+			}
+			if len(kev.CWEs) > 0 {
+				vuln.CWE = &ocsfCWE{UID: kev.CWEs[0]}
+			}
+
+			// Accepted risks are reported as suppressed findings
+			statusID, status, statusDetail := ocsfStatusNew, "New", ""
+			if kev.Accepted != nil {
+				statusID, status = ocsfStatusSuppressed, "Suppressed"
+				statusDetail = fmt.Sprintf("Risk accepted (%s): %s", kev.Accepted.Reason, kev.Accepted.Justification)
+			}
+
+			events = append(events, ocsfEvent{
+				ActivityID:   ocsfActivityCreate,
+				ActivityName: "Create",
+				CategoryUID:  ocsfCategoryFindings,
+				CategoryName: "Findings",
+				ClassUID:     ocsfClassVulnFinding,
+				ClassName:    "Vulnerability Finding",
+				TypeUID:      ocsfClassVulnFinding*100 + ocsfActivityCreate,
+				SeverityID:   severityID,
+				Severity:     severity,
+				StatusID:     statusID,
+				Status:       status,
+				StatusDetail: statusDetail,
+				Time:         now,
+				Message: fmt.Sprintf("Dependency %s has known exploited vulnerability %s",
+					f.Dependency.String(), kev.CVEID),
+				Metadata: ocsfMetadata{
+					Version: ocsfVersion,
+					Product: ocsfProduct{
+						Name:       "kev-checker",
+						VendorName: "ethanolivertroy",
+						Version:    "1.0.0",
+					},
+				},
+				FindingInfo: ocsfFindingInfo{
+					UID:    f.Fingerprint(kev.CVEID),
+					Title:  fmt.Sprintf("KEV: %s - %s", kev.CVEID, kev.VulnerabilityName),
+					Desc:   kev.ShortDescription,
+					Types:  []string{"CISA KEV"},
+					SrcURL: "https://www.cisa.gov/known-exploited-vulnerabilities-catalog",
+				},
+				Vulnerabilities: []ocsfVulnerability{vuln},
+			})
+		}
+	}
+
+	return json.MarshalIndent(events, "", "  ")
+}
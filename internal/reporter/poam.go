@@ -0,0 +1,138 @@
+package reporter
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"time"
+
+	"github.com/ethanolivertroy/kev-check-demo/internal/models"
+)
+
+// POAMReporter outputs findings as CSV rows matching the FedRAMP POA&M template
+type POAMReporter struct {
+	noTimestamps bool
+}
+
+// OmitTimestamps implements TimestampOmitter, leaving the detection and
+// status dates empty
+func (r *POAMReporter) OmitTimestamps() { r.noTimestamps = true }
+
+// poamColumns are the open POA&M items columns from the FedRAMP template
+var poamColumns = []string{
+	"POAM ID",
+	"Controls",
+	"Weakness Name",
+	"Weakness Description",
+	"Weakness Detector Source",
+	"Weakness Source Identifier",
+	"Asset Identifier",
+	"Point of Contact",
+	"Resources Required",
+	"Overall Remediation Plan",
+	"Original Detection Date",
+	"Scheduled Completion Date",
+	"Planned Milestones",
+	"Milestone Changes",
+	"Status Date",
+	"Vendor Dependency",
+	"Last Vendor Check-in Date",
+	"Vendor Dependent Product Name",
+	"Original Risk Rating",
+	"Adjusted Risk Rating",
+	"Risk Adjustment",
+	"False Positive",
+	"Operational Requirement",
+	"Deviation Rationale",
+	"Supporting Documents",
+	"Comments",
+	"Auto-Approve",
+}
+
+// Report generates POA&M CSV output for the given findings
+func (r *POAMReporter) Report(findings []models.Finding) ([]byte, error) {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+
+	if err := w.Write(poamColumns); err != nil {
+		return nil, err
+	}
+
+	today := time.Now().Format("01/02/2006")
+	if r.noTimestamps {
+		today = ""
+	}
+	id := 0
+
+	for _, f := range findings {
+		for _, kev := range f.KEVs {
+			id++
+
+			risk := severityTitle(kev)
+
+			completion := ""
+			if !kev.DueDate.IsZero() {
+				completion = kev.DueDate.Format("01/02/2006")
+			}
+
+			comments := fmt.Sprintf("CISA KEV added %s", kev.DateAdded.Format("2006-01-02"))
+			if kev.EPSSScore > 0 {
+				comments += fmt.Sprintf("; EPSS %.1f%% (percentile %.1f%%)", kev.EPSSScore*100, kev.EPSSPercentile*100)
+			}
+			if kev.RansomwareUse {
+				comments += "; known ransomware usage"
+			}
+
+			// Accepted risks are operational requirements, with the
+			// acceptance as their deviation rationale
+			operational, rationale := "No", ""
+			if kev.Accepted != nil {
+				operational = "Yes"
+				rationale = fmt.Sprintf("Risk accepted (%s): %s", kev.Accepted.Reason, kev.Accepted.Justification)
+				if kev.Accepted.ApprovedBy != "" {
+					rationale += fmt.Sprintf("; approved by %s on %s", kev.Accepted.ApprovedBy, kev.Accepted.ApprovedOn)
+				}
+			}
+
+			row := []string{
+				fmt.Sprintf("KEV-%04d", id),
+				"RA-5, SI-2",
+				fmt.Sprintf("%s: %s", kev.CVEID, kev.VulnerabilityName),
+				kev.ShortDescription,
+				"kev-checker (CISA KEV / OSV)",
+				kev.CVEID,
+				fmt.Sprintf("%s (%s)", f.Dependency.String(), f.Dependency.SourceFile),
+				"",
+				"",
+				kev.RequiredAction,
+				today,
+				completion,
+				fmt.Sprintf("Upgrade %s to a version not affected by %s", f.Dependency.Name, kev.CVEID),
+				"",
+				today,
+				"No",
+				"",
+				"",
+				risk,
+				"",
+				"No",
+				"No",
+				operational,
+				rationale,
+				fmt.Sprintf("https://nvd.nist.gov/vuln/detail/%s", kev.CVEID),
+				comments,
+				"No",
+			}
+			if err := w.Write(row); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
@@ -1,6 +1,13 @@
 package reporter
 
-import "github.com/ethanolivertroy/kev-check-demo/internal/models"
+import (
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ethanolivertroy/kev-check-demo/internal/models"
+)
 
 // Reporter is the interface for output formatters
 type Reporter interface {
@@ -8,14 +15,87 @@ type Reporter interface {
 	Report(findings []models.Finding) ([]byte, error)
 }
 
-// Get returns a reporter for the specified format
+// TimestampOmitter is implemented by reporters whose output includes the time
+// of the run, or due dates relative to it
+type TimestampOmitter interface {
+	// OmitTimestamps leaves them out, so the same findings always produce
+	// the same report
+	OmitTimestamps()
+}
+
+// Reproducible returns copies of findings without first-seen times and SLA
+// deadlines, which depend on the history store and the time of the run
+func Reproducible(findings []models.Finding) []models.Finding {
+	out := make([]models.Finding, len(findings))
+	for i, f := range findings {
+		f.KEVs = slices.Clone(f.KEVs)
+		for j := range f.KEVs {
+			f.KEVs[j].FirstSeen = time.Time{}
+			f.KEVs[j].SLADeadline = time.Time{}
+		}
+		out[i] = f
+	}
+	return out
+}
+
+// Factory creates a fresh reporter, so per-run options set on one instance
+// don't leak into the next
+type Factory func() Reporter
+
+var (
+	registryMu sync.RWMutex
+	registry   = map[string]Factory{}
+)
+
+func init() {
+	Register("terminal", func() Reporter { return &TerminalReporter{} })
+	Register("json", func() Reporter { return &JSONReporter{} })
+	Register("ndjson", func() Reporter { return &NDJSONReporter{} })
+	Register("sarif", func() Reporter { return &SARIFReporter{} })
+	Register("poam", func() Reporter { return &POAMReporter{} })
+	Register("ocsf", func() Reporter { return &OCSFReporter{} })
+	Register("defectdojo", func() Reporter { return &DefectDojoReporter{} })
+	Register("github-actions", func() Reporter { return &GitHubActionsReporter{} })
+	Register("azure-devops", func() Reporter { return &AzureDevOpsReporter{} })
+	Register("teamcity", func() Reporter { return &TeamCityReporter{} })
+}
+
+// Register makes a reporter available under a format name, replacing any
+// reporter already registered under that name
+func Register(format string, f Factory) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	registry[format] = f
+}
+
+// Lookup returns a reporter for the format and whether one is registered
+func Lookup(format string) (Reporter, bool) {
+	registryMu.RLock()
+	f, ok := registry[format]
+	registryMu.RUnlock()
+	if !ok {
+		return nil, false
+	}
+	return f(), true
+}
+
+// Get returns a reporter for the specified format, falling back to terminal
+// output for unknown formats
 func Get(format string) Reporter {
-	switch format {
-	case "json":
-		return &JSONReporter{}
-	case "sarif":
-		return &SARIFReporter{}
-	default:
-		return &TerminalReporter{}
+	if r, ok := Lookup(format); ok {
+		return r
+	}
+	return &TerminalReporter{}
+}
+
+// Formats returns the registered format names, sorted
+func Formats() []string {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
 	}
+	sort.Strings(names)
+	return names
 }
@@ -8,7 +8,20 @@ import (
 )
 
 // SARIFReporter outputs findings in SARIF format for GitHub Code Scanning
-type SARIFReporter struct{}
+type SARIFReporter struct {
+	Severity *SeverityPolicy  // Optional, defaults to DefaultSeverityPolicy
+	Run      *SARIFRunDetails // Optional CI metadata for the run
+}
+
+// SARIFRunDetails identifies the CI run that produced a report, filling in
+// automationDetails and versionControlProvenance
+type SARIFRunDetails struct {
+	AutomationID  string // "category/run", e.g. "kev-checker/build/1234"
+	Description   string
+	RepositoryURI string
+	RevisionID    string
+	Branch        string
+}
 
 // SARIF structures
 type sarifReport struct {
@@ -18,8 +31,21 @@ type sarifReport struct {
 }
 
 type sarifRun struct {
-	Tool    sarifTool     `json:"tool"`
-	Results []sarifResult `json:"results"`
+	Tool                     sarifTool         `json:"tool"`
+	AutomationDetails        *sarifAutomation  `json:"automationDetails,omitempty"`
+	VersionControlProvenance []sarifProvenance `json:"versionControlProvenance,omitempty"`
+	Results                  []sarifResult     `json:"results"`
+}
+
+type sarifAutomation struct {
+	ID          string     `json:"id"`
+	Description *sarifText `json:"description,omitempty"`
+}
+
+type sarifProvenance struct {
+	RepositoryURI string `json:"repositoryUri"`
+	RevisionID    string `json:"revisionId,omitempty"`
+	Branch        string `json:"branch,omitempty"`
 }
 
 type sarifTool struct {
@@ -34,14 +60,14 @@ type sarifDriver struct {
 }
 
 type sarifRule struct {
-	ID               string           `json:"id"`
-	Name             string           `json:"name"`
-	ShortDescription sarifText        `json:"shortDescription"`
-	FullDescription  sarifText        `json:"fullDescription"`
-	Help             sarifText        `json:"help"`
-	HelpURI          string           `json:"helpUri"`
-	DefaultConfig    sarifRuleConfig  `json:"defaultConfiguration"`
-	Properties       sarifProperties  `json:"properties"`
+	ID               string          `json:"id"`
+	Name             string          `json:"name"`
+	ShortDescription sarifText       `json:"shortDescription"`
+	FullDescription  sarifText       `json:"fullDescription"`
+	Help             sarifText       `json:"help"`
+	HelpURI          string          `json:"helpUri"`
+	DefaultConfig    sarifRuleConfig `json:"defaultConfiguration"`
+	Properties       sarifProperties `json:"properties"`
 }
 
 type sarifText struct {
@@ -53,17 +79,32 @@ type sarifRuleConfig struct {
 }
 
 type sarifProperties struct {
-	Tags           []string `json:"tags"`
-	SecuritySeverity string `json:"security-severity,omitempty"`
+	Tags             []string `json:"tags"`
+	SecuritySeverity string   `json:"security-severity,omitempty"`
 }
 
 type sarifResult struct {
-	RuleID              string              `json:"ruleId"`
-	RuleIndex           int                 `json:"ruleIndex"`
-	Level               string              `json:"level"`
-	Message             sarifText           `json:"message"`
-	Locations           []sarifLocation     `json:"locations"`
-	PartialFingerprints map[string]string   `json:"partialFingerprints"`
+	RuleID              string             `json:"ruleId"`
+	RuleIndex           int                `json:"ruleIndex"`
+	Level               string             `json:"level"`
+	Message             sarifText          `json:"message"`
+	Locations           []sarifLocation    `json:"locations"`
+	PartialFingerprints map[string]string  `json:"partialFingerprints"`
+	Suppressions        []sarifSuppression `json:"suppressions,omitempty"`
+
+	Properties *sarifResultProperties `json:"properties,omitempty"`
+}
+
+type sarifResultProperties struct {
+	CVSSScore  float64  `json:"cvssScore,omitempty"`
+	CVSSVector string   `json:"cvssVector,omitempty"`
+	Owners     []string `json:"owners,omitempty"`
+}
+
+type sarifSuppression struct {
+	Kind          string `json:"kind"`
+	Status        string `json:"status"`
+	Justification string `json:"justification"`
 }
 
 type sarifLocation struct {
@@ -80,11 +121,16 @@ type sarifArtifact struct {
 }
 
 type sarifRegion struct {
-	StartLine int `json:"startLine,omitempty"`
+	StartLine int        `json:"startLine,omitempty"`
+	Snippet   *sarifText `json:"snippet,omitempty"`
 }
 
 // Report generates SARIF output for the given findings
 func (r *SARIFReporter) Report(findings []models.Finding) ([]byte, error) {
+	if r.Severity == nil {
+		r.Severity = DefaultSeverityPolicy()
+	}
+
 	rules, ruleIndexMap := r.buildRules(findings)
 
 	report := sarifReport{
@@ -103,6 +149,23 @@ func (r *SARIFReporter) Report(findings []models.Finding) ([]byte, error) {
 		}},
 	}
 
+	if r.Run != nil {
+		run := &report.Runs[0]
+		if r.Run.AutomationID != "" {
+			run.AutomationDetails = &sarifAutomation{ID: r.Run.AutomationID}
+			if r.Run.Description != "" {
+				run.AutomationDetails.Description = &sarifText{Text: r.Run.Description}
+			}
+		}
+		if r.Run.RepositoryURI != "" {
+			run.VersionControlProvenance = []sarifProvenance{{
+				RepositoryURI: r.Run.RepositoryURI,
+				RevisionID:    r.Run.RevisionID,
+				Branch:        r.Run.Branch,
+			}}
+		}
+	}
+
 	return json.MarshalIndent(report, "", "  ")
 }
 
@@ -116,12 +179,10 @@ func (r *SARIFReporter) buildRules(findings []models.Finding) ([]sarifRule, map[
 				continue
 			}
 
-			level := "error"
-			severity := "8.0" // High severity for all KEVs
+			level, severity := r.Severity.Evaluate(kev)
 			tags := []string{"security", "vulnerability", "kev", "cisa"}
 
 			if kev.RansomwareUse {
-				severity = "9.5" // Critical for ransomware
 				tags = append(tags, "ransomware")
 			}
 
@@ -188,19 +249,42 @@ func (r *SARIFReporter) buildResults(findings []models.Finding, ruleIndexMap map
 				location.PhysicalLocation.Region = sarifRegion{
 					StartLine: f.Dependency.Line,
 				}
+				if f.Dependency.Snippet != "" {
+					location.PhysicalLocation.Region.Snippet = &sarifText{Text: f.Dependency.Snippet}
+				}
 			}
 
-			results = append(results, sarifResult{
+			level, _ := r.Severity.Evaluate(kev)
+			fingerprint := f.Fingerprint(kev.CVEID)
+
+			result := sarifResult{
 				RuleID:    kev.CVEID,
 				RuleIndex: ruleIndexMap[kev.CVEID],
-				Level:     "error",
+				Level:     level,
 				Message:   sarifText{Text: msg},
 				Locations: []sarifLocation{location},
 				PartialFingerprints: map[string]string{
-					"primaryLocationLineHash": fmt.Sprintf("%s:%s:%s",
-						f.Dependency.Name, f.Dependency.Version, kev.CVEID),
+					"primaryLocationLineHash": fingerprint,
+					"kevFingerprint/v1":       fingerprint,
 				},
-			})
+			}
+			if kev.CVSSVector != "" || len(f.Owners) > 0 {
+				result.Properties = &sarifResultProperties{
+					CVSSScore:  kev.CVSSScore,
+					CVSSVector: kev.CVSSVector,
+					Owners:     f.Owners,
+				}
+			}
+
+			if kev.Accepted != nil {
+				result.Suppressions = []sarifSuppression{{
+					Kind:          "external",
+					Status:        "accepted",
+					Justification: fmt.Sprintf("%s: %s", kev.Accepted.Reason, kev.Accepted.Justification),
+				}}
+			}
+
+			results = append(results, result)
 		}
 	}
 
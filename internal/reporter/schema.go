@@ -0,0 +1,24 @@
+package reporter
+
+import (
+	_ "embed"
+	"fmt"
+)
+
+// JSONSchemaVersion is the version of the JSON report format. Bump the minor
+// version when adding fields and keep schema/report.schema.json in sync.
+// Fields are never renamed, retyped or removed without a major version bump.
+const JSONSchemaVersion = "1.12"
+
+//go:embed schema/report.schema.json
+var jsonReportSchema []byte
+
+// Schema returns the published JSON Schema for a report format
+func Schema(format string) ([]byte, error) {
+	switch format {
+	case "json":
+		return jsonReportSchema, nil
+	default:
+		return nil, fmt.Errorf("no schema published for format %q", format)
+	}
+}
@@ -0,0 +1,131 @@
+package reporter
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"github.com/ethanolivertroy/kev-check-demo/internal/models"
+)
+
+// Severity rule conditions
+const (
+	ConditionRansomware = "ransomware"
+	ConditionOverdue    = "overdue"
+	ConditionReferenced = "referenced"
+	ConditionDefault    = "default"
+
+	// Normalized severities, as set by the severity mapping
+	ConditionCritical = "critical"
+	ConditionHigh     = "high"
+	ConditionMedium   = "medium"
+)
+
+// SeverityRule maps findings matching a condition to a SARIF level and
+// security-severity score
+type SeverityRule struct {
+	When             string  `toml:"when"`
+	Level            string  `toml:"level"`
+	SecuritySeverity float64 `toml:"security_severity"`
+}
+
+// SeverityPolicy is an ordered list of rules; the first matching rule wins
+type SeverityPolicy struct {
+	Rules []SeverityRule `toml:"rule"`
+	// Location is the timezone due dates are compared in (nil means UTC)
+	Location *time.Location `toml:"-"`
+}
+
+// DefaultSeverityPolicy maps each normalized severity to a SARIF level and
+// security-severity score
+func DefaultSeverityPolicy() *SeverityPolicy {
+	return &SeverityPolicy{
+		Rules: []SeverityRule{
+			{When: ConditionCritical, Level: "error", SecuritySeverity: 9.5},
+			{When: ConditionHigh, Level: "error", SecuritySeverity: 8.0},
+			{When: ConditionMedium, Level: "warning", SecuritySeverity: 6.0},
+			{When: ConditionDefault, Level: "error", SecuritySeverity: 8.0},
+		},
+	}
+}
+
+// LoadSeverityPolicy reads the SARIF rules of a TOML severity config file.
+// A file without rules, e.g. one that only sets the severity mapping, gets
+// the default rules.
+func LoadSeverityPolicy(path string) (*SeverityPolicy, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read severity config: %w", err)
+	}
+
+	var policy SeverityPolicy
+	if err := toml.Unmarshal(data, &policy); err != nil {
+		return nil, fmt.Errorf("failed to parse severity config: %w", err)
+	}
+
+	for i, rule := range policy.Rules {
+		switch rule.When {
+		case ConditionRansomware, ConditionOverdue, ConditionReferenced, ConditionDefault,
+			ConditionCritical, ConditionHigh, ConditionMedium:
+		default:
+			return nil, fmt.Errorf("severity rule %d: unknown condition %q", i+1, rule.When)
+		}
+		switch rule.Level {
+		case "error", "warning", "note", "none":
+		default:
+			return nil, fmt.Errorf("severity rule %d: invalid level %q", i+1, rule.Level)
+		}
+		if rule.SecuritySeverity < 0 || rule.SecuritySeverity > 10 {
+			return nil, fmt.Errorf("severity rule %d: security_severity must be 0-10", i+1)
+		}
+	}
+	if len(policy.Rules) == 0 {
+		policy.Rules = DefaultSeverityPolicy().Rules
+	}
+
+	return &policy, nil
+}
+
+// Evaluate returns the SARIF level and security-severity string for a KEV
+func (p *SeverityPolicy) Evaluate(kev models.KEVInfo) (string, string) {
+	for _, rule := range p.Rules {
+		if matchesCondition(rule.When, kev, p.Location) {
+			return rule.Level, strconv.FormatFloat(rule.SecuritySeverity, 'f', 1, 64)
+		}
+	}
+	return "error", "8.0"
+}
+
+// severityOf returns the KEV's normalized severity. KEVs the severity
+// mapping hasn't classified are high, as every KEV is known exploited.
+func severityOf(kev models.KEVInfo) models.Severity {
+	if kev.Severity == "" {
+		return models.SeverityHigh
+	}
+	return kev.Severity
+}
+
+// severityTitle returns the KEV's severity capitalized, e.g. "Critical"
+func severityTitle(kev models.KEVInfo) string {
+	s := string(severityOf(kev))
+	return strings.ToUpper(s[:1]) + s[1:]
+}
+
+func matchesCondition(cond string, kev models.KEVInfo, loc *time.Location) bool {
+	switch cond {
+	case ConditionRansomware:
+		return kev.RansomwareUse
+	case ConditionOverdue:
+		return kev.Overdue(time.Now(), loc)
+	case ConditionReferenced:
+		return kev.Reachability == models.ReachabilityReferenced
+	case ConditionCritical, ConditionHigh, ConditionMedium:
+		return string(kev.Severity) == cond
+	case ConditionDefault:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,81 @@
+package reporter
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/ethanolivertroy/kev-check-demo/internal/models"
+)
+
+// truncationNote tells readers of a capped report where to find the rest
+const truncationNote = "report truncated by --max-findings; use --format ndjson for every finding"
+
+// StreamReporter is implemented by formats that can write findings one at a
+// time instead of building the whole report in memory
+type StreamReporter interface {
+	Reporter
+	// Begin writes anything that precedes the first finding
+	Begin(w io.Writer) error
+	// WriteFinding writes a single finding
+	WriteFinding(w io.Writer, f models.Finding) error
+	// End writes anything that follows the last finding
+	End(w io.Writer) error
+}
+
+// Stream writes findings through a StreamReporter, buffering writes to w
+func Stream(sr StreamReporter, w io.Writer, findings []models.Finding) error {
+	bw := bufio.NewWriter(w)
+	if err := sr.Begin(bw); err != nil {
+		return err
+	}
+	for _, f := range findings {
+		if err := sr.WriteFinding(bw, f); err != nil {
+			return err
+		}
+	}
+	if err := sr.End(bw); err != nil {
+		return err
+	}
+	return bw.Flush()
+}
+
+// Truncate keeps the first max findings and returns how many were dropped.
+// A max of 0 or less keeps everything.
+func Truncate(findings []models.Finding, max int) ([]models.Finding, int) {
+	if max <= 0 || len(findings) <= max {
+		return findings, 0
+	}
+	return findings[:max], len(findings) - max
+}
+
+// NDJSONReporter outputs one JSON report finding per line. It is never
+// truncated, so it carries the full data when other formats are capped.
+type NDJSONReporter struct{}
+
+// Report generates NDJSON output for the given findings
+func (r *NDJSONReporter) Report(findings []models.Finding) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := Stream(r, &buf, findings); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Begin writes nothing; NDJSON has no header
+func (r *NDJSONReporter) Begin(w io.Writer) error { return nil }
+
+// WriteFinding writes the finding as a single JSON line
+func (r *NDJSONReporter) WriteFinding(w io.Writer, f models.Finding) error {
+	line, err := json.Marshal(newJSONFinding(f))
+	if err != nil {
+		return fmt.Errorf("failed to encode finding: %w", err)
+	}
+	_, err = w.Write(append(line, '\n'))
+	return err
+}
+
+// End writes nothing; NDJSON has no trailer
+func (r *NDJSONReporter) End(w io.Writer) error { return nil }
@@ -0,0 +1,142 @@
+package reporter
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/ethanolivertroy/kev-check-demo/internal/models"
+)
+
+// templateExt marks report templates in a pack directory
+const templateExt = ".tmpl"
+
+// TemplateReporter renders findings through a text/template from a report
+// pack. Templates see the same fields as the JSON report, in Go field names.
+type TemplateReporter struct {
+	tmpl *template.Template
+	// Omitted is the number of findings dropped by --max-findings
+	Omitted int
+	// Location is the timezone the due function compares dates in (nil means UTC)
+	Location *time.Location
+
+	noTimestamps bool
+}
+
+// OmitTimestamps implements TimestampOmitter, leaving GeneratedAt zero and
+// the due function empty
+func (r *TemplateReporter) OmitTimestamps() { r.noTimestamps = true }
+
+// templateData is the value templates are executed with
+type templateData struct {
+	SchemaVersion string
+	GeneratedAt   time.Time
+	Summary       jsonSummary
+	Findings      []jsonFinding
+	Truncated     *jsonTruncated
+}
+
+// templateFuncs are available to every template in a pack
+var templateFuncs = template.FuncMap{
+	"join":  strings.Join,
+	"upper": strings.ToUpper,
+	"lower": strings.ToLower,
+	"json": func(v any) (string, error) {
+		b, err := json.Marshal(v)
+		return string(b), err
+	},
+	// due renders a YYYY-MM-DD due date relative to today; bound per report
+	"due": func(string) string { return "" },
+}
+
+// Report renders the template for the given findings
+func (r *TemplateReporter) Report(findings []models.Finding) ([]byte, error) {
+	out := newJSONOutput(findings, r.Omitted)
+	data := templateData{
+		SchemaVersion: out.SchemaVersion,
+		GeneratedAt:   time.Now().UTC(),
+		Summary:       out.Summary,
+		Findings:      out.Findings,
+		Truncated:     out.Truncated,
+	}
+
+	if r.noTimestamps {
+		data.GeneratedAt = time.Time{}
+	}
+
+	tmpl, err := r.tmpl.Clone()
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	tmpl.Funcs(template.FuncMap{
+		"due": func(date string) string {
+			t, err := time.Parse("2006-01-02", date)
+			if err != nil || r.noTimestamps {
+				return ""
+			}
+			days, _ := models.KEVInfo{DueDate: t}.DueIn(now, r.Location)
+			return RelativeDue(days)
+		},
+	})
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return nil, fmt.Errorf("failed to render %s: %w", r.tmpl.Name(), err)
+	}
+	return buf.Bytes(), nil
+}
+
+// LoadTemplatePack registers each <name>.tmpl file in dir as a report format
+// called <name>. Files starting with "_" are partials: they aren't formats
+// themselves but can be included from any template in the pack with
+// {{template "_header.tmpl" .}}. Returns the registered format names.
+func LoadTemplatePack(dir string) ([]string, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*"+templateExt))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read report pack: %w", err)
+	}
+
+	var partials, formats []string
+	for _, path := range paths {
+		if strings.HasPrefix(filepath.Base(path), "_") {
+			partials = append(partials, path)
+		} else {
+			formats = append(formats, path)
+		}
+	}
+	if len(formats) == 0 {
+		return nil, fmt.Errorf("no %s templates in report pack %s", templateExt, dir)
+	}
+
+	var names []string
+	for _, path := range formats {
+		base := filepath.Base(path)
+		name := strings.TrimSuffix(base, templateExt)
+
+		src, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read report template: %w", err)
+		}
+		tmpl, err := template.New(base).Funcs(templateFuncs).Parse(string(src))
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse report template %s: %w", base, err)
+		}
+		if len(partials) > 0 {
+			if tmpl, err = tmpl.ParseFiles(partials...); err != nil {
+				return nil, fmt.Errorf("failed to parse report pack partials: %w", err)
+			}
+		}
+
+		Register(name, func() Reporter { return &TemplateReporter{tmpl: tmpl} })
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names, nil
+}
@@ -2,90 +2,436 @@ package reporter
 
 import (
 	"fmt"
+	"path/filepath"
+	"sort"
 	"strings"
+	"time"
 
 	"github.com/ethanolivertroy/kev-check-demo/internal/models"
 )
 
+// Terminal layouts for TerminalReporter.GroupBy
+const (
+	GroupByNone    = ""
+	GroupByFile    = "file"
+	GroupByProject = "project"
+)
+
+// Default truncation limits for terminal output, in characters
+const (
+	DefaultDescriptionWidth = 200
+	DefaultActionWidth      = 100
+)
+
+// maxTerminalCWEs caps the weakness classes listed in the terminal summary
+const maxTerminalCWEs = 5
+
 // TerminalReporter outputs findings in a human-readable terminal format
-type TerminalReporter struct{}
+type TerminalReporter struct {
+	// GroupBy lists findings under each manifest file or project directory
+	// with per-group counts instead of one flat list
+	GroupBy string
+	// Omitted is the number of findings dropped by --max-findings
+	Omitted int
+	// FullText disables truncation of descriptions and required actions
+	FullText bool
+	// DescriptionWidth and ActionWidth cap the description and required
+	// action text; zero uses the defaults
+	DescriptionWidth int
+	ActionWidth      int
+	// Width wraps long text to this many columns; zero disables wrapping
+	Width int
+	// Location is the timezone dates are shown and compared in (nil means UTC)
+	Location *time.Location
+	// Violations are deny-listed dependencies, listed in their own section
+	Violations []models.Violation
+	// Local are dependencies built from the project's own source, listed
+	// after the findings as a coverage gap
+	Local []models.Dependency
+
+	noTimestamps bool
+}
+
+// OmitTimestamps implements TimestampOmitter, leaving out how long until or
+// since each KEV is due
+func (r *TerminalReporter) OmitTimestamps() { r.noTimestamps = true }
 
 // Report generates terminal output for the given findings
 func (r *TerminalReporter) Report(findings []models.Finding) ([]byte, error) {
+	var sb strings.Builder
+	r.writeViolations(&sb)
+
 	if len(findings) == 0 {
-		return []byte("No KEV vulnerabilities found in dependencies.\n"), nil
+		sb.WriteString("No KEV vulnerabilities found in dependencies.\n")
+		r.writeLocal(&sb)
+		return []byte(sb.String()), nil
 	}
 
-	var sb strings.Builder
-
 	// Summary
 	totalKEVs := 0
 	ransomwareCount := 0
+	acceptedCount := 0
+	potentialCount := 0
+	noFixCount := 0
+	bySeverity := make(map[models.Severity]int)
 	for _, f := range findings {
 		totalKEVs += len(f.KEVs)
+		if f.Potential() {
+			potentialCount++
+		}
+		if f.Effort == models.EffortNoFix {
+			noFixCount++
+		}
 		for _, kev := range f.KEVs {
 			if kev.RansomwareUse {
 				ransomwareCount++
 			}
+			if kev.Accepted != nil {
+				acceptedCount++
+			}
+			bySeverity[severityOf(kev)]++
 		}
 	}
 
 	sb.WriteString(fmt.Sprintf("\n⚠️  KEV VULNERABILITIES FOUND\n"))
 	sb.WriteString(strings.Repeat("=", 60) + "\n\n")
 	sb.WriteString(fmt.Sprintf("Found %d KEV vulnerabilities in %d dependencies\n", totalKEVs, len(findings)))
+	var severities []string
+	for _, sev := range models.Severities {
+		if n := bySeverity[sev]; n > 0 {
+			severities = append(severities, fmt.Sprintf("%d %s", n, sev))
+		}
+	}
+	sb.WriteString("Severity: " + strings.Join(severities, ", ") + "\n")
 	if ransomwareCount > 0 {
 		sb.WriteString(fmt.Sprintf("🚨 %d vulnerabilities known to be used in ransomware campaigns\n", ransomwareCount))
 	}
+	if acceptedCount > 0 {
+		sb.WriteString(fmt.Sprintf("✅ %d vulnerabilities risk accepted via exclusions\n", acceptedCount))
+	}
+	if potentialCount > 0 {
+		sb.WriteString(fmt.Sprintf("❔ %d dependencies are unpinned or matched by product name; their findings are potential and version-unconfirmed\n", potentialCount))
+	}
+	if noFixCount > 0 {
+		sb.WriteString(fmt.Sprintf("🚫 %d dependencies have no fixed version; mitigate per the required action or discontinue use\n", noFixCount))
+	}
+	if cwes := cweBreakdown(findings); len(cwes) > 0 {
+		sb.WriteString("🧩 Weaknesses: " + cweSummary(cwes, maxTerminalCWEs) + "\n")
+	}
 	sb.WriteString("\n")
 
 	// Details
-	for _, f := range findings {
-		sb.WriteString(fmt.Sprintf("📦 %s\n", f.Dependency.String()))
-		sb.WriteString(fmt.Sprintf("   Source: %s", f.Dependency.SourceFile))
-		if f.Dependency.Line > 0 {
-			sb.WriteString(fmt.Sprintf(":%d", f.Dependency.Line))
+	switch r.GroupBy {
+	case GroupByFile, GroupByProject:
+		for _, g := range groupFindings(findings, r.GroupBy) {
+			kevs := 0
+			for _, f := range g.findings {
+				kevs += len(f.KEVs)
+			}
+			sb.WriteString(fmt.Sprintf("📁 %s (%d KEVs in %d dependencies)\n", g.key, kevs, len(g.findings)))
+			sb.WriteString(strings.Repeat("=", 60) + "\n\n")
+			for _, f := range g.findings {
+				r.writeFinding(&sb, f)
+			}
+			sb.WriteString("\n")
+		}
+	default:
+		for _, f := range findings {
+			r.writeFinding(&sb, f)
+		}
+	}
+
+	if r.Omitted > 0 {
+		sb.WriteString(fmt.Sprintf("\n… %d more findings not shown (%s)\n", r.Omitted, truncationNote))
+	}
+
+	r.writeLocal(&sb)
+
+	sb.WriteString("\nFor more information, visit: https://www.cisa.gov/known-exploited-vulnerabilities-catalog\n")
+
+	return []byte(sb.String()), nil
+}
+
+// cweSummary lists the most common weakness classes, e.g.
+// "4 deserialization (CWE-502), 3 path traversal (CWE-22)"
+func cweSummary(cwes []cweCount, max int) string {
+	var parts []string
+	for i, c := range cwes {
+		if i == max {
+			parts = append(parts, fmt.Sprintf("%d more", len(cwes)-max))
+			break
+		}
+		if c.Name != "" {
+			parts = append(parts, fmt.Sprintf("%d %s (%s)", c.Count, c.Name, c.ID))
+		} else {
+			parts = append(parts, fmt.Sprintf("%d %s", c.Count, c.ID))
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+// writeViolations writes the deny-listed dependencies ahead of the findings
+func (r *TerminalReporter) writeViolations(sb *strings.Builder) {
+	if len(r.Violations) == 0 {
+		return
+	}
+	sb.WriteString(fmt.Sprintf("\n⛔ DENIED DEPENDENCIES (%d)\n", len(r.Violations)))
+	sb.WriteString(strings.Repeat("=", 60) + "\n\n")
+	for _, v := range r.Violations {
+		sb.WriteString(fmt.Sprintf("📦 %s\n", v.Dependency.String()))
+		sb.WriteString(fmt.Sprintf("   Source: %s", v.Dependency.SourceFile))
+		if v.Dependency.Line > 0 {
+			sb.WriteString(fmt.Sprintf(":%d", v.Dependency.Line))
 		}
 		sb.WriteString("\n")
+		sb.WriteString(fmt.Sprintf("   Rule: %s\n", v.Rule))
+		sb.WriteString(fmt.Sprintf("   Reason: %s\n\n", v.Reason))
+	}
+}
 
-		for _, kev := range f.KEVs {
-			sb.WriteString(fmt.Sprintf("\n   🔴 %s\n", kev.CVEID))
-			sb.WriteString(fmt.Sprintf("      %s - %s\n", kev.VendorProject, kev.Product))
-			sb.WriteString(fmt.Sprintf("      %s\n", kev.VulnerabilityName))
-
-			if kev.ShortDescription != "" {
-				// Truncate long descriptions
-				desc := kev.ShortDescription
-				if len(desc) > 200 {
-					desc = desc[:197] + "..."
-				}
-				sb.WriteString(fmt.Sprintf("      %s\n", desc))
+// writeLocal writes the local dependencies that weren't checked, so gaps in
+// coverage are visible
+func (r *TerminalReporter) writeLocal(sb *strings.Builder) {
+	if len(r.Local) == 0 {
+		return
+	}
+	sb.WriteString(fmt.Sprintf("\n🏠 LOCAL COMPONENTS NOT CHECKED (%d)\n", len(r.Local)))
+	sb.WriteString(strings.Repeat("=", 60) + "\n")
+	sb.WriteString("Built from the project's own source, with no upstream package to check; scan their source directly if it's outside the scanned paths.\n\n")
+	for _, dep := range r.Local {
+		sb.WriteString(fmt.Sprintf("🏠 %s (%s) → %s\n", dep.Name, dep.Ecosystem, dep.Local))
+		sb.WriteString(fmt.Sprintf("   Source: %s", dep.SourceFile))
+		if dep.Line > 0 {
+			sb.WriteString(fmt.Sprintf(":%d", dep.Line))
+		}
+		sb.WriteString("\n")
+	}
+}
+
+// writeFinding writes one dependency and its KEVs
+func (r *TerminalReporter) writeFinding(sb *strings.Builder, f models.Finding) {
+	label := f.Dependency.String()
+	if f.Dependency.Dev {
+		label += " (dev)"
+	}
+	if f.Potential() {
+		sb.WriteString(fmt.Sprintf("📦 %s (potential / version-unconfirmed)\n", label))
+	} else {
+		sb.WriteString(fmt.Sprintf("📦 %s\n", label))
+	}
+	sb.WriteString(fmt.Sprintf("   Source: %s", f.Dependency.SourceFile))
+	if f.Dependency.Line > 0 {
+		sb.WriteString(fmt.Sprintf(":%d", f.Dependency.Line))
+	}
+	sb.WriteString("\n")
+	if len(f.Dependency.Duplicates) > 0 {
+		also := make([]string, len(f.Dependency.Duplicates))
+		for i, loc := range f.Dependency.Duplicates {
+			also[i] = loc.File
+			if loc.Line > 0 {
+				also[i] += fmt.Sprintf(":%d", loc.Line)
 			}
+		}
+		sb.WriteString(fmt.Sprintf("   Also in: %s\n", strings.Join(also, ", ")))
+	}
 
-			sb.WriteString(fmt.Sprintf("      Added: %s | Due: %s\n",
-				kev.DateAdded.Format("2006-01-02"),
-				kev.DueDate.Format("2006-01-02")))
+	if len(f.Owners) > 0 {
+		sb.WriteString(fmt.Sprintf("   Owner: %s\n", strings.Join(f.Owners, ", ")))
+	}
+
+	switch f.Effort {
+	case models.EffortDirectBump:
+		sb.WriteString(fmt.Sprintf("   Remediation: upgrade to %s\n", f.FixVersion))
+	case models.EffortTransitiveOverride:
+		sb.WriteString(fmt.Sprintf("   Remediation: transitive - override to %s or upgrade the parent dependency\n", f.FixVersion))
+	case models.EffortNoFix:
+		sb.WriteString("   Remediation: 🚫 no fixed version available - apply mitigations per the required action or discontinue use\n")
+	}
 
-			if kev.EPSSScore > 0 {
-				sb.WriteString(fmt.Sprintf("      EPSS: %.1f%% (percentile: %.1f%%)\n",
-					kev.EPSSScore*100, kev.EPSSPercentile*100))
+	if f.Health != nil {
+		if !f.Health.LastRelease.IsZero() {
+			sb.WriteString(fmt.Sprintf("   Last release: %s", f.Health.LastRelease.Format("2006-01-02")))
+			if f.Health.LatestVersion != "" {
+				sb.WriteString(fmt.Sprintf(" (latest %s)", f.Health.LatestVersion))
+			}
+			sb.WriteString("\n")
+		}
+		if f.Health.Deprecated {
+			sb.WriteString("   ⚠️  Deprecated")
+			if f.Health.DeprecatedReason != "" {
+				sb.WriteString(": " + f.Health.DeprecatedReason)
 			}
+			sb.WriteString(" - consider replacing rather than upgrading\n")
+		} else if f.Health.Unmaintained() {
+			sb.WriteString("   ⚠️  No release in over 2 years - consider replacing rather than upgrading\n")
+		}
+	}
 
-			if kev.RansomwareUse {
-				sb.WriteString("      ⚠️  Known ransomware usage\n")
+	for _, kev := range f.KEVs {
+		if kev.Catalog != "" && kev.Catalog != "CISA" {
+			sb.WriteString(fmt.Sprintf("\n   🔴 %s [%s]\n", kev.CVEID, kev.Catalog))
+		} else {
+			sb.WriteString(fmt.Sprintf("\n   🔴 %s\n", kev.CVEID))
+		}
+		sb.WriteString(fmt.Sprintf("      %s - %s\n", kev.VendorProject, kev.Product))
+		sb.WriteString(fmt.Sprintf("      %s\n", kev.VulnerabilityName))
+
+		if kev.ShortDescription != "" {
+			desc := r.truncate(kev.ShortDescription, r.DescriptionWidth, DefaultDescriptionWidth)
+			sb.WriteString(r.wrap("      ", desc))
+		}
+
+		sb.WriteString(fmt.Sprintf("      Added: %s | Due: %s",
+			kev.DateAdded.Format("2006-01-02"),
+			kev.DueDate.Format("2006-01-02")))
+		if days, ok := kev.DueIn(time.Now(), r.Location); ok && !r.noTimestamps {
+			sb.WriteString(" (" + RelativeDue(days) + ")")
+		}
+		sb.WriteString("\n")
+
+		if !kev.FirstSeen.IsZero() {
+			sb.WriteString(fmt.Sprintf("      First seen: %s (%d days open)\n",
+				kev.FirstSeen.Format("2006-01-02"), kev.DaysOpen()))
+		}
+
+		if !kev.SLADeadline.IsZero() {
+			status := "within SLA"
+			if kev.SLABreached() {
+				status = "🚨 SLA BREACH"
 			}
+			sb.WriteString(fmt.Sprintf("      SLA: %s (%s)\n", kev.SLADeadline.In(r.location()).Format("2006-01-02 15:04 MST"), status))
+		}
+
+		sb.WriteString(fmt.Sprintf("      Severity: %s\n", severityOf(kev)))
+
+		if kev.EPSSScore > 0 {
+			sb.WriteString(fmt.Sprintf("      EPSS: %.1f%% (percentile: %.1f%%)\n",
+				kev.EPSSScore*100, kev.EPSSPercentile*100))
+		}
+
+		if kev.CVSSVector != "" {
+			sb.WriteString(fmt.Sprintf("      CVSS: %.1f (%s)\n", kev.CVSSScore, kev.CVSSVector))
+		}
+
+		if kev.RansomwareUse {
+			sb.WriteString("      ⚠️  Known ransomware usage\n")
+		}
+
+		if kev.Reachability != "" {
+			sb.WriteString(fmt.Sprintf("      Reachability: %s\n", kev.Reachability))
+		}
+
+		if kev.RequiredAction != "" {
+			action := r.truncate(kev.RequiredAction, r.ActionWidth, DefaultActionWidth)
+			sb.WriteString(r.wrap("      ", "Required Action: "+action))
+		}
 
-			if kev.RequiredAction != "" {
-				action := kev.RequiredAction
-				if len(action) > 100 {
-					action = action[:97] + "..."
-				}
-				sb.WriteString(fmt.Sprintf("      Required Action: %s\n", action))
+		if kev.Accepted != nil {
+			sb.WriteString(fmt.Sprintf("      ✅ Risk accepted (%s): %s\n", kev.Accepted.Reason, kev.Accepted.Justification))
+			if kev.Accepted.ApprovedBy != "" {
+				sb.WriteString(fmt.Sprintf("         Approved by %s on %s\n", kev.Accepted.ApprovedBy, kev.Accepted.ApprovedOn))
 			}
 		}
-		sb.WriteString("\n" + strings.Repeat("-", 60) + "\n")
 	}
+	sb.WriteString("\n" + strings.Repeat("-", 60) + "\n")
+}
 
-	sb.WriteString("\nFor more information, visit: https://www.cisa.gov/known-exploited-vulnerabilities-catalog\n")
+// location returns the configured timezone, defaulting to UTC
+func (r *TerminalReporter) location() *time.Location {
+	if r.Location == nil {
+		return time.UTC
+	}
+	return r.Location
+}
 
-	return []byte(sb.String()), nil
+// RelativeDue renders a DueIn day count, e.g. "due in 3 days" or
+// "overdue by 12 days"
+func RelativeDue(days int) string {
+	switch {
+	case days == 0:
+		return "due today"
+	case days == 1:
+		return "due tomorrow"
+	case days > 1:
+		return fmt.Sprintf("due in %d days", days)
+	case days == -1:
+		return "overdue by 1 day"
+	default:
+		return fmt.Sprintf("overdue by %d days", -days)
+	}
+}
+
+// truncate shortens text to limit characters (or def if limit is zero),
+// unless FullText is set
+func (r *TerminalReporter) truncate(text string, limit, def int) string {
+	if r.FullText {
+		return text
+	}
+	if limit <= 0 {
+		limit = def
+	}
+	runes := []rune(text)
+	if len(runes) <= limit {
+		return text
+	}
+	if limit <= 3 {
+		return string(runes[:limit])
+	}
+	return string(runes[:limit-3]) + "..."
+}
+
+// wrap writes text after indent, word-wrapped to Width with continuation
+// lines aligned under the first
+func (r *TerminalReporter) wrap(indent, text string) string {
+	avail := r.Width - len(indent)
+	if r.Width <= 0 || avail < 20 {
+		return indent + text + "\n"
+	}
+
+	var sb strings.Builder
+	line := 0
+	for i, word := range strings.Fields(text) {
+		n := len([]rune(word))
+		if i > 0 && line+1+n > avail {
+			sb.WriteString("\n")
+			line = 0
+		}
+		if line == 0 {
+			sb.WriteString(indent)
+		} else {
+			sb.WriteString(" ")
+			line++
+		}
+		sb.WriteString(word)
+		line += n
+	}
+	sb.WriteString("\n")
+	return sb.String()
+}
+
+type findingGroup struct {
+	key      string
+	findings []models.Finding
+}
+
+// groupFindings buckets findings by manifest file or by its directory,
+// sorted by path
+func groupFindings(findings []models.Finding, groupBy string) []findingGroup {
+	index := make(map[string]int)
+	var groups []findingGroup
+	for _, f := range findings {
+		key := f.Dependency.SourceFile
+		if groupBy == GroupByProject {
+			key = filepath.Dir(key)
+		}
+		i, ok := index[key]
+		if !ok {
+			i = len(groups)
+			index[key] = i
+			groups = append(groups, findingGroup{key: key})
+		}
+		groups[i].findings = append(groups[i].findings, f)
+	}
+	sort.Slice(groups, func(a, b int) bool { return groups[a].key < groups[b].key })
+	return groups
 }
@@ -0,0 +1,98 @@
+package scanner
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/ethanolivertroy/kev-check-demo/internal/models"
+	"github.com/ethanolivertroy/kev-check-demo/internal/parsers"
+)
+
+// budgetStep narrows the dependencies checked when they exceed the query
+// budget
+type budgetStep struct {
+	skipped string // What the step leaves unchecked, for warnings
+	keep    func(dep models.Dependency) bool
+}
+
+// budgetSteps returns the steps applied in turn until the registry
+// dependencies fit the query budget: the selected manifests, then production
+// dependencies, then those declared directly
+func (s *Scanner) budgetSteps() []budgetStep {
+	var steps []budgetStep
+	if len(s.config.BudgetManifests) > 0 {
+		steps = append(steps, budgetStep{
+			skipped: "dependencies outside --budget-manifests",
+			keep: func(dep models.Dependency) bool {
+				for _, pattern := range s.config.BudgetManifests {
+					for _, file := range dep.Files() {
+						if parsers.MatchPathSuffix(pattern, filepath.ToSlash(file)) {
+							return true
+						}
+					}
+				}
+				return false
+			},
+		})
+	}
+	return append(steps,
+		budgetStep{skipped: "dev dependencies", keep: func(dep models.Dependency) bool { return !dep.Dev }},
+		budgetStep{skipped: "transitive dependencies", keep: func(dep models.Dependency) bool { return !dep.Transitive }},
+	)
+}
+
+// withinBudget returns the dependencies to check under Config.QueryBudget.
+// Steps stop as soon as the registry dependencies fit; each one that skips
+// dependencies raises a warning, and the skipped dependencies are listed by
+// OverBudget. Local dependencies aren't queried and are always kept. It fails
+// if the dependencies still don't fit after every step.
+func (s *Scanner) withinBudget(deps []models.Dependency) ([]models.Dependency, error) {
+	budget := s.config.QueryBudget
+	queried, _ := upstream(deps)
+	if budget <= 0 || len(queried) <= budget {
+		return deps, nil
+	}
+
+	total, remaining := len(queried), len(queried)
+	for _, step := range s.budgetSteps() {
+		var kept []models.Dependency
+		skipped := 0
+		for _, dep := range deps {
+			if dep.Local != "" || step.keep(dep) {
+				kept = append(kept, dep)
+				continue
+			}
+			s.overBudget = append(s.overBudget, dep)
+			skipped++
+		}
+		deps = kept
+		if skipped > 0 {
+			remaining -= skipped
+			s.warnings = append(s.warnings, models.Warning{
+				Kind:    models.WarningLimit,
+				Message: fmt.Sprintf("query budget: %s not checked (%d skipped; %d of %d registry dependencies checked, --query-budget %d)", step.skipped, skipped, remaining, total, budget),
+			})
+		}
+		if remaining <= budget {
+			return deps, nil
+		}
+	}
+	return nil, fmt.Errorf("%d registry dependencies exceed the query budget of %d even after leaving out dev and transitive ones; raise --query-budget or select manifests with --budget-manifests", remaining, budget)
+}
+
+// OverBudget returns the dependencies the query budget left unchecked
+func (s *Scanner) OverBudget() []models.Dependency {
+	return s.overBudget
+}
+
+// overBudgetFiles returns the manifests with dependencies the query budget
+// left unchecked, whose results are incomplete
+func (s *Scanner) overBudgetFiles() map[string]bool {
+	files := make(map[string]bool)
+	for _, dep := range s.overBudget {
+		for _, file := range dep.Files() {
+			files[file] = true
+		}
+	}
+	return files
+}
@@ -0,0 +1,11 @@
+//go:build !linux && !darwin
+
+package scanner
+
+import "io/fs"
+
+// deviceID is not implemented on this platform; --one-file-system has no
+// effect
+func deviceID(info fs.FileInfo) (uint64, bool) {
+	return 0, false
+}
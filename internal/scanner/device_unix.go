@@ -0,0 +1,17 @@
+//go:build linux || darwin
+
+package scanner
+
+import (
+	"io/fs"
+	"syscall"
+)
+
+// deviceID returns the ID of the filesystem holding the file
+func deviceID(info fs.FileInfo) (uint64, bool) {
+	st, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return 0, false
+	}
+	return uint64(st.Dev), true
+}
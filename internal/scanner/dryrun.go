@@ -0,0 +1,176 @@
+package scanner
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/ethanolivertroy/kev-check-demo/internal/clients"
+	"github.com/ethanolivertroy/kev-check-demo/internal/models"
+)
+
+// Plan lists the requests a scan would send, built by a dry run from the
+// discovered dependencies without network access
+type Plan struct {
+	Dependencies int `json:"dependencies"`
+	Local        int `json:"local"`                 // Local dependencies, which aren't queried
+	Reused       int `json:"reused"`                // Dependencies answered from stored results (--incremental)
+	OverBudget   int `json:"over_budget,omitempty"` // Dependencies left unchecked by --query-budget
+
+	// Requests are sent whatever the scan finds
+	Requests []PlannedRequest `json:"requests"`
+
+	// Enrichment requests are sent per KEV match, so how many isn't known
+	// until the scan runs
+	Enrichment []PlannedCall `json:"enrichment"`
+
+	Notes []string `json:"notes,omitempty"`
+}
+
+// PlannedRequest is a request the scan would send and what it's for, e.g.
+// "KEV catalog" or a vulnerability source's name
+type PlannedRequest struct {
+	Purpose string `json:"purpose"`
+	clients.Request
+}
+
+// PlannedCall is an endpoint called for each KEV match or finding
+type PlannedCall struct {
+	Enricher  string `json:"enricher"`
+	Method    string `json:"method"`
+	URL       string `json:"url"`
+	Per       string `json:"per"` // What one request is sent for
+	BatchSize int    `json:"batch_size,omitempty"`
+}
+
+// newPlan returns an empty plan for a dry run, or nil
+func newPlan(config *models.Config) *Plan {
+	if !config.DryRun {
+		return nil
+	}
+	return &Plan{}
+}
+
+// advisories plans the advisory locations served over HTTP(S) instead of
+// fetching them, returning the ones that are read locally
+func (p *Plan) advisories(locations []string) []string {
+	if p == nil {
+		return locations
+	}
+	var local []string
+	for _, loc := range locations {
+		if strings.HasPrefix(loc, "http://") || strings.HasPrefix(loc, "https://") {
+			p.Requests = append(p.Requests, PlannedRequest{
+				Purpose: "advisories",
+				Request: clients.Request{Method: http.MethodGet, URL: clients.RedactURL(loc)},
+			})
+			continue
+		}
+		local = append(local, loc)
+	}
+	return local
+}
+
+// planManifest records a manifest URL that would be fetched. Its dependencies
+// aren't known without fetching it.
+func (s *Scanner) planManifest(rawURL string) {
+	s.discoverMu.Lock()
+	defer s.discoverMu.Unlock()
+	s.plan.Requests = append(s.plan.Requests, PlannedRequest{
+		Purpose: "manifest",
+		Request: clients.Request{Method: http.MethodGet, URL: clients.RedactURL(rawURL)},
+	})
+	s.plan.Notes = append(s.plan.Notes, fmt.Sprintf("dependencies in %s aren't known until it is fetched and aren't counted in the planned queries", clients.RedactURL(rawURL)))
+}
+
+// Plan discovers dependencies and returns the requests a scan would send.
+// It needs a scanner created with Config.DryRun and makes no network requests:
+// manifest and advisory URLs are listed rather than fetched.
+func (s *Scanner) Plan() (*Plan, error) {
+	if s.plan == nil {
+		return nil, fmt.Errorf("scanner not configured for a dry run")
+	}
+	deps, err := s.discoverDependencies()
+	if err != nil {
+		return nil, fmt.Errorf("failed to discover dependencies: %w", err)
+	}
+	s.deps = deps
+
+	plan := s.plan
+	plan.Dependencies = len(deps)
+	warned := len(s.warnings)
+	if deps, err = s.withinBudget(deps); err != nil {
+		return nil, err
+	}
+	plan.OverBudget = len(s.overBudget)
+	for _, w := range s.warnings[warned:] {
+		plan.Notes = append(plan.Notes, w.Message)
+	}
+	for _, req := range s.kevClient.PlanFetch() {
+		plan.Requests = append(plan.Requests, PlannedRequest{Purpose: "KEV catalog", Request: req})
+	}
+
+	// Dependencies from unchanged manifests reuse stored results, as in
+	// queryVulnerabilities
+	stale := deps
+	if s.history != nil && !s.fromGit() {
+		_, staleIdx, _ := s.reuseStored(deps)
+		stale = make([]models.Dependency, len(staleIdx))
+		for j, i := range staleIdx {
+			stale[j] = deps[i]
+		}
+	}
+	plan.Reused = len(deps) - len(stale)
+	queried, _ := upstream(stale)
+	for _, dep := range deps {
+		if dep.Local != "" {
+			plan.Local++
+		}
+	}
+
+	if len(queried) > 0 {
+		for _, src := range s.sources {
+			planner, ok := src.(clients.Planner)
+			if !ok {
+				continue // Queried in memory, e.g. internal advisories
+			}
+			requests, err := planner.PlanByPackage(queried)
+			if err != nil {
+				return nil, fmt.Errorf("failed to plan %s queries: %w", src.Name(), err)
+			}
+			for _, req := range requests {
+				plan.Requests = append(plan.Requests, PlannedRequest{Purpose: src.Name(), Request: req})
+			}
+		}
+	}
+
+	plan.Enrichment = s.planEnrichment()
+	return plan, nil
+}
+
+// planEnrichment lists the endpoints the enabled enrichers and withdrawn
+// advisory checks call. Full OSV advisories are fetched once per scan and
+// shared between them; without OSV as a source none are fetched.
+func (s *Scanner) planEnrichment() []PlannedCall {
+	const perAdvisory = "OSV advisory matched to a KEV, fetched once per scan"
+	osv := slices.Contains(s.sources, clients.VulnSource(s.osvClient))
+
+	var calls []PlannedCall
+	if osv {
+		calls = append(calls, PlannedCall{Enricher: "withdrawn check", Method: http.MethodGet, URL: clients.OSVVulnEndpoint, Per: perAdvisory})
+	}
+	for _, e := range s.enrichers {
+		switch e.Name() {
+		case "epss":
+			calls = append(calls, PlannedCall{Enricher: "epss", Method: http.MethodGet, URL: clients.EPSSEndpoint, Per: "batch of matched CVEs without fresh cached scores", BatchSize: clients.EPSSBatchSize})
+		case "cvss", "remediation":
+			if osv {
+				calls = append(calls, PlannedCall{Enricher: e.Name(), Method: http.MethodGet, URL: clients.OSVVulnEndpoint, Per: perAdvisory})
+			}
+		case "freshness":
+			calls = append(calls, PlannedCall{Enricher: "freshness", Method: http.MethodGet, URL: clients.DepsDevEndpoint, Per: "dependency with a KEV finding"})
+		}
+	}
+	return calls
+}
@@ -0,0 +1,133 @@
+package scanner
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/ethanolivertroy/kev-check-demo/internal/clients"
+	"github.com/ethanolivertroy/kev-check-demo/internal/models"
+)
+
+// Enricher adds data to findings after they are matched to KEVs, e.g. EPSS
+// scores or CVSS vectors. Enrichers run in order, so each sees the data added
+// by the ones before it.
+type Enricher interface {
+	// Name identifies the enricher, e.g. to disable it with --no-enrich
+	Name() string
+
+	// Enrich annotates findings in place. An error leaves findings with
+	// whatever was added before it and is reported as a warning.
+	Enrich(findings []models.Finding) error
+}
+
+// Enrichers lists the built-in enrichers in the order they run. Freshness
+// and reachability also need --freshness and --reachability.
+var Enrichers = []string{"epss", "cvss", "remediation", "freshness", "reachability"}
+
+// enricherFunc adapts a function to the Enricher interface
+type enricherFunc struct {
+	name string
+	fn   func(findings []models.Finding) error
+}
+
+func (e enricherFunc) Name() string { return e.name }
+
+func (e enricherFunc) Enrich(findings []models.Finding) error { return e.fn(findings) }
+
+// newEnrichers builds the enrichment pipeline for the scanner's
+// configuration, leaving out the enrichers named in NoEnrich
+func (s *Scanner) newEnrichers() ([]Enricher, error) {
+	disabled := make(map[string]bool)
+	for _, name := range s.config.NoEnrich {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if !slices.Contains(Enrichers, name) {
+			return nil, fmt.Errorf("unknown enricher %q (available: %s)", name, strings.Join(Enrichers, ", "))
+		}
+		disabled[name] = true
+	}
+
+	all := []Enricher{
+		&epssEnricher{client: s.epssClient},
+		enricherFunc{"cvss", func(f []models.Finding) error { s.enrichCVSS(f); return nil }},
+		enricherFunc{"remediation", func(f []models.Finding) error { s.assessRemediation(f); return nil }},
+	}
+	if s.config.Freshness {
+		all = append(all, enricherFunc{"freshness", func(f []models.Finding) error { s.enrichHealth(f); return nil }})
+	}
+	if s.config.Reachability {
+		all = append(all, enricherFunc{"reachability", func(f []models.Finding) error { s.analyzeReachability(f); return nil }})
+	}
+
+	var enrichers []Enricher
+	for _, e := range all {
+		if !disabled[e.Name()] {
+			enrichers = append(enrichers, e)
+		}
+	}
+	return enrichers, nil
+}
+
+// enrich runs the enrichment pipeline over the findings. Enrichment is
+// best-effort: a failing enricher is reported as a warning and the rest
+// still run.
+func (s *Scanner) enrich(findings []models.Finding) {
+	if len(findings) == 0 {
+		return
+	}
+	for _, e := range s.enrichers {
+		if err := e.Enrich(findings); err != nil {
+			s.warnings = append(s.warnings, models.Warning{
+				Kind:    models.WarningDegraded,
+				Message: fmt.Sprintf("%s enrichment failed: %v", e.Name(), err),
+			})
+		}
+	}
+}
+
+// epssEnricher sets the EPSS score and percentile on each KEV
+type epssEnricher struct {
+	client *clients.EPSSClient
+}
+
+func (e *epssEnricher) Name() string { return "epss" }
+
+func (e *epssEnricher) Enrich(findings []models.Finding) error {
+	var cveIDs []string
+	for _, f := range findings {
+		for _, kev := range f.KEVs {
+			cveIDs = append(cveIDs, kev.CVEID)
+		}
+	}
+	if len(cveIDs) == 0 {
+		return nil
+	}
+	// Findings come in no particular order; keep the request URL stable
+	slices.Sort(cveIDs)
+	cveIDs = slices.Compact(cveIDs)
+
+	scores, err := e.client.FetchScores(cveIDs)
+	if err != nil {
+		return err
+	}
+	for i := range findings {
+		for j := range findings[i].KEVs {
+			if score, ok := scores[findings[i].KEVs[j].CVEID]; ok {
+				findings[i].KEVs[j].EPSSScore = score.Score
+				findings[i].KEVs[j].EPSSPercentile = score.Percentile
+				findings[i].KEVs[j].EPSSScored = true
+			}
+		}
+	}
+	return nil
+}
+
+// enrichHealth sets package freshness and deprecation status from deps.dev.
+// Packages deps.dev can't describe are left without health data.
+func (s *Scanner) enrichHealth(findings []models.Finding) {
+	for i := range findings {
+		if health, err := s.depsClient.FetchPackageHealth(findings[i].Dependency); err == nil {
+			findings[i].Health = health
+		}
+	}
+}
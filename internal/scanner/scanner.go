@@ -1,16 +1,39 @@
 package scanner
 
 import (
+	"cmp"
 	"context"
 	"fmt"
-	"io/fs"
+	"maps"
 	"os"
+	"path"
 	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
 
+	"github.com/ethanolivertroy/kev-check-demo/internal/advisories"
+	"github.com/ethanolivertroy/kev-check-demo/internal/archive"
+	"github.com/ethanolivertroy/kev-check-demo/internal/baseline"
 	"github.com/ethanolivertroy/kev-check-demo/internal/cache"
 	"github.com/ethanolivertroy/kev-check-demo/internal/clients"
+	"github.com/ethanolivertroy/kev-check-demo/internal/cvss"
+	"github.com/ethanolivertroy/kev-check-demo/internal/denylist"
+	"github.com/ethanolivertroy/kev-check-demo/internal/exclusions"
+	"github.com/ethanolivertroy/kev-check-demo/internal/exposure"
+	"github.com/ethanolivertroy/kev-check-demo/internal/git"
+	"github.com/ethanolivertroy/kev-check-demo/internal/history"
+	"github.com/ethanolivertroy/kev-check-demo/internal/inventory"
 	"github.com/ethanolivertroy/kev-check-demo/internal/models"
+	"github.com/ethanolivertroy/kev-check-demo/internal/osvmirror"
+	"github.com/ethanolivertroy/kev-check-demo/internal/owners"
 	"github.com/ethanolivertroy/kev-check-demo/internal/parsers"
+	"github.com/ethanolivertroy/kev-check-demo/internal/policy"
+	"github.com/ethanolivertroy/kev-check-demo/internal/reachability"
+	"github.com/ethanolivertroy/kev-check-demo/internal/remediation"
+	"github.com/ethanolivertroy/kev-check-demo/internal/typosquat"
 )
 
 // Scanner orchestrates the vulnerability scanning process
@@ -19,7 +42,35 @@ type Scanner struct {
 	parsers    []parsers.Parser
 	kevClient  *clients.KEVClient
 	osvClient  *clients.OSVClient
+	osvMirror  *osvmirror.Mirror // OSV records cached by cache warm, or nil
+	sources    []clients.VulnSource
+	advisories *advisories.Feed
+	kevOverlay map[string]models.KEVInfo
 	epssClient *clients.EPSSClient
+	depsClient *clients.DepsDevClient
+	enrichers  []Enricher
+	severity   policy.SeverityMapping
+	exclusions *exclusions.List
+	baseline   *baseline.Baseline
+	denylist   *denylist.List
+	owners     *owners.Map
+	history    *history.Store
+	osvRecords map[string]*clients.OSVRecord
+	deps       []models.Dependency
+	warnings   []models.Warning
+	violations []models.Violation
+	failures   []models.SourceFailure
+	mirrored   bool                  // OSV results came from the cached mirror
+	suppressed []suppressedKEV       // KEVs matched but not reported
+	lifecycle  map[history.State]int // Findings per lifecycle state in the last scan
+	plan       *Plan                 // Requests planned by a dry run, or nil
+	overBudget []models.Dependency   // Dependencies the query budget left unchecked
+
+	// discoverMu guards state updated while paths are discovered concurrently
+	discoverMu  sync.Mutex
+	parseErrors []models.ParseError
+	manifests   int  // Manifests parsed, counted against MaxFiles
+	fileLimit   bool // MaxFiles was reached
 }
 
 // New creates a new Scanner with the given configuration
@@ -35,13 +86,127 @@ func New(config *models.Config) (*Scanner, error) {
 		}
 	}
 
-	return &Scanner{
+	var excl *exclusions.List
+	if config.ExclusionsFile != "" {
+		excl, err = exclusions.Load(config.ExclusionsFile, config.RequireSignoff)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var base *baseline.Baseline
+	if config.BaselineFile != "" {
+		base, err = baseline.Load(config.BaselineFile)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var deny *denylist.List
+	if config.DenyListFile != "" {
+		deny, err = denylist.Load(config.DenyListFile)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var own *owners.Map
+	if config.OwnersFile != "" {
+		own, err = owners.Load(config.OwnersFile)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	plan := newPlan(config)
+
+	var hist *history.Store
+	if config.DryRun && history.IsDatabaseURL(config.HistoryFile) {
+		plan.Notes = append(plan.Notes, "history database not opened; stored results for --incremental aren't counted")
+	} else if !config.NoHistory {
+		hist, err = openHistory(config)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open history store (--no-history scans without it): %w", err)
+		}
+	}
+
+	osv := clients.NewOSVClient(config.Timeout)
+	sources, err := clients.NewVulnSources(config.Sources, osv, config.Timeout)
+	if err != nil {
+		return nil, err
+	}
+
+	var feed *advisories.Feed
+	if locations := plan.advisories(config.Advisories); len(locations) > 0 {
+		feed, err = advisories.Load(locations)
+		if err != nil {
+			return nil, err
+		}
+		sources = append(sources, feed)
+	}
+
+	var mirror *osvmirror.Mirror
+	if c != nil {
+		// Non-fatal: without the mirror, OSV outages degrade the scan
+		mirror, _ = osvmirror.Load(c)
+	}
+
+	severity := policy.DefaultSeverityMapping()
+	if config.SeverityFile != "" {
+		severity, err = policy.LoadSeverityMapping(config.SeverityFile)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var overlay map[string]models.KEVInfo
+	if config.KEVOverlayFile != "" {
+		overlay, err = clients.LoadKEVOverlay(config.KEVOverlayFile)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	s := &Scanner{
 		config:     config,
-		parsers:    parsers.GetAllParsers(),
+		parsers:    parsers.NewParsers(config.RequirementsPatterns),
 		kevClient:  clients.NewKEVClient(c),
-		osvClient:  clients.NewOSVClient(),
-		epssClient: clients.NewEPSSClient(),
-	}, nil
+		osvClient:  osv,
+		osvMirror:  mirror,
+		sources:    sources,
+		advisories: feed,
+		kevOverlay: overlay,
+		epssClient: clients.NewEPSSClient(c),
+		depsClient: clients.NewDepsDevClient(),
+		exclusions: excl,
+		baseline:   base,
+		denylist:   deny,
+		owners:     own,
+		history:    hist,
+		severity:   severity,
+		plan:       plan,
+	}
+	if s.enrichers, err = s.newEnrichers(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// openHistory opens the configured history store, or the default location.
+// Without a home directory for the default, scans run without history.
+func openHistory(config *models.Config) (*history.Store, error) {
+	path := config.HistoryFile
+	if path == "" {
+		var err error
+		path, err = history.DefaultPath("kev-checker")
+		if err != nil {
+			if config.Incremental {
+				return nil, err
+			}
+			return nil, nil
+		}
+	}
+	return history.Open(path)
 }
 
 // Scan performs the full vulnerability scan
@@ -51,41 +216,66 @@ func (s *Scanner) Scan(ctx context.Context) ([]models.Finding, error) {
 	if err != nil {
 		return nil, fmt.Errorf("failed to discover dependencies: %w", err)
 	}
+	s.deps = deps
 
 	if len(deps) == 0 {
 		return nil, nil
 	}
 
+	if s.config.Typosquat {
+		// Local dependencies aren't fetched from a registry
+		registry, _ := upstream(deps)
+		s.warnings = append(s.warnings, typosquat.Check(registry)...)
+	}
+	for _, dep := range deps {
+		if dep.Replacement != "" {
+			s.warnings = append(s.warnings, models.Warning{
+				Kind:       models.WarningReplaced,
+				Dependency: dep,
+				Message:    fmt.Sprintf("replaced by %s; checked as the original module, so fixes or flaws specific to the replacement aren't known", dep.Replacement),
+			})
+		}
+	}
+
+	// Step 1a: Narrow the dependencies checked to the query budget
+	if deps, err = s.withinBudget(deps); err != nil {
+		return nil, err
+	}
+
 	// Step 2: Fetch KEV catalog (cached)
-	kevCatalog, err := s.kevClient.FetchKEVCatalog()
+	kevCatalog, err := s.fetchCatalog()
 	if err != nil {
-		return nil, fmt.Errorf("failed to fetch KEV catalog: %w", err)
+		return nil, err
 	}
 
-	// Step 3: Query OSV for CVEs affecting dependencies
-	cvesByDep, err := s.osvClient.QueryBatch(deps)
+	// Step 3: Query the vulnerability sources for CVEs affecting dependencies
+	cvesByDep, err := s.queryVulnerabilities(deps)
 	if err != nil {
-		return nil, fmt.Errorf("failed to query OSV: %w", err)
+		return nil, fmt.Errorf("failed to query vulnerability sources: %w", err)
+	}
+	if len(s.failures) > 0 {
+		// Pinned CVE references from stored results are still matched below
+		s.warnings = append(s.warnings, models.Warning{
+			Kind:    models.WarningDegraded,
+			Message: fmt.Sprintf("vulnerability source unavailable (%s); results may be incomplete or based on previously stored data", strings.Join(s.failedSources(), ", ")),
+		})
 	}
 
-	// Step 4: Cross-reference with KEV and build findings
-	var findings []models.Finding
-	var allKEVCVEs []string
-
-	for depIdx, cves := range cvesByDep {
-		dep := deps[depIdx]
-		finding := models.Finding{
-			Dependency: dep,
-			CVEs:       cves,
+	// Step 3a: Match plugins without version-level advisories to the KEV
+	// entries naming them
+	for i, dep := range deps {
+		if cves := platformKEVs(dep, cvesByDep[i], kevCatalog); len(cves) > 0 {
+			cvesByDep[i] = cves
 		}
+	}
 
-		// Check each CVE against KEV catalog
-		for _, cve := range cves {
-			if kevInfo, isKEV := kevCatalog[cve.ID]; isKEV {
-				finding.KEVs = append(finding.KEVs, kevInfo)
-				allKEVCVEs = append(allKEVCVEs, cve.ID)
-			}
-		}
+	// Step 4: Cross-reference with KEV and build findings, in discovery order
+	// so reports are stable
+	var findings []models.Finding
+
+	depIndices := slices.Sorted(maps.Keys(cvesByDep))
+	for _, depIdx := range depIndices {
+		finding := s.matchKEVs(deps[depIdx], cvesByDep[depIdx], kevCatalog)
 
 		// Only include findings that have KEV matches
 		if len(finding.KEVs) > 0 {
@@ -93,28 +283,35 @@ func (s *Scanner) Scan(ctx context.Context) ([]models.Finding, error) {
 		}
 	}
 
-	// Step 5: Enrich with EPSS scores
-	if len(allKEVCVEs) > 0 {
-		epssScores, _ := s.epssClient.FetchScores(allKEVCVEs)
-		for i := range findings {
-			for j := range findings[i].KEVs {
-				if score, ok := epssScores[findings[i].KEVs[j].CVEID]; ok {
-					findings[i].KEVs[j].EPSSScore = score.Score
-					findings[i].KEVs[j].EPSSPercentile = score.Percentile
-				}
-			}
-		}
-	}
+	// Step 4a: Check dependencies against the deny list, before threshold
+	// filtering so vendor rules see every KEV
+	s.violations = s.denylist.Check(s.deps, findings)
 
-	// Step 6: Filter by EPSS threshold if configured
-	if s.config.EPSSThreshold > 0 {
+	// Step 5: Run the enrichment pipeline (EPSS, CVSS, remediation effort,
+	// and freshness and reachability if enabled)
+	s.enrich(findings)
+
+	// Step 5a: Normalize severity from the enriched scores
+	s.severity.Apply(findings)
+
+	// Step 6: Filter by EPSS score/percentile and CVSS thresholds if
+	// configured. KEVs without EPSS or CVSS data are kept, so a skipped or
+	// failed enrichment can't hide them.
+	if s.config.EPSSThreshold > 0 || s.config.EPSSPercentile > 0 || s.config.MinCVSS > 0 {
 		var filtered []models.Finding
+		epssFiltered, unscored := s.config.EPSSThreshold > 0 || s.config.EPSSPercentile > 0, 0
 		for _, f := range findings {
 			var filteredKEVs []models.KEVInfo
 			for _, kev := range f.KEVs {
-				if kev.EPSSScore >= s.config.EPSSThreshold {
-					filteredKEVs = append(filteredKEVs, kev)
+				if epssFiltered && !kev.EPSSScored {
+					unscored++
 				}
+				if kev.EPSSScored && (kev.EPSSScore < s.config.EPSSThreshold || kev.EPSSPercentile < s.config.EPSSPercentile) ||
+					kev.CVSSVector != "" && kev.CVSSScore < s.config.MinCVSS {
+					s.suppress(f, kev.CVEID)
+					continue
+				}
+				filteredKEVs = append(filteredKEVs, kev)
 			}
 			if len(filteredKEVs) > 0 {
 				f.KEVs = filteredKEVs
@@ -122,76 +319,1261 @@ func (s *Scanner) Scan(ctx context.Context) ([]models.Finding, error) {
 			}
 		}
 		findings = filtered
+		if unscored > 0 {
+			s.warnings = append(s.warnings, models.Warning{
+				Kind:    models.WarningDegraded,
+				Message: fmt.Sprintf("%d KEVs have no EPSS data and are reported regardless of the EPSS thresholds", unscored),
+			})
+		}
+	}
+
+	// Step 6a: Route findings to the teams owning their manifests
+	if s.owners != nil {
+		for i := range findings {
+			findings[i].Owners = s.owners.Owners(findings[i].Dependency.SourceFile)
+		}
 	}
 
+	// Step 7: Record first-seen times and lifecycle states in the history
+	// store, resolving findings no longer present
+	if s.history != nil {
+		// Non-fatal: ages are still reported for this run
+		_ = s.trackFindings(findings, true)
+	}
+
+	// Step 8: Compute internal SLA deadlines from first-seen
+	policy.SLA{Ransomware: s.config.SLARansomware, Default: s.config.SLADefault}.Apply(findings)
+
 	return findings, nil
 }
 
-// discoverDependencies walks the configured paths and parses dependency files
-func (s *Scanner) discoverDependencies() ([]models.Dependency, error) {
-	var allDeps []models.Dependency
+// Exposure discovers dependencies and returns the KEV vendors/products that
+// appear in them by name, without querying vulnerability sources
+func (s *Scanner) Exposure() ([]exposure.Product, error) {
+	deps, err := s.discoverDependencies()
+	if err != nil {
+		return nil, fmt.Errorf("failed to discover dependencies: %w", err)
+	}
+	s.deps = deps
 
-	for _, path := range s.config.Paths {
-		info, err := os.Stat(path)
+	kevCatalog, err := s.fetchCatalog()
+	if err != nil {
+		return nil, err
+	}
+	return exposure.Match(deps, kevCatalog), nil
+}
+
+// Discover parses dependency files without querying any data source, for
+// exporting an inventory
+func (s *Scanner) Discover() ([]models.Dependency, error) {
+	deps, err := s.discoverDependencies()
+	if err != nil {
+		return nil, fmt.Errorf("failed to discover dependencies: %w", err)
+	}
+	s.deps = deps
+	return deps, nil
+}
+
+// fetchCatalog fetches the KEV catalog and merges the organizational overlay
+// and internal advisories into it
+func (s *Scanner) fetchCatalog() (map[string]models.KEVInfo, error) {
+	kevCatalog, err := s.kevClient.FetchKEVCatalog()
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch KEV catalog: %w", err)
+	}
+	if err := s.checkCatalogAge(); err != nil {
+		return nil, err
+	}
+	if s.kevOverlay != nil {
+		clients.MergeKEVOverlay(kevCatalog, s.kevOverlay)
+	}
+	if s.advisories != nil {
+		// Internal advisories are known-exploited by definition; CISA entries
+		// win when both cover the same CVE
+		for id, kev := range s.advisories.Catalog() {
+			if _, exists := kevCatalog[id]; !exists {
+				kevCatalog[id] = kev
+			}
+		}
+	}
+	return kevCatalog, nil
+}
+
+// matchKEVs builds a finding for the dependency from the CVEs that are in the
+// KEV catalog, skipping withdrawn advisories
+func (s *Scanner) matchKEVs(dep models.Dependency, cves []models.CVEInfo, kevCatalog map[string]models.KEVInfo) models.Finding {
+	finding := models.Finding{
+		Dependency: dep,
+		CVEs:       cves,
+		Confidence: models.ConfidenceConfirmed,
+	}
+	if dep.Unpinned {
+		finding.Confidence = models.ConfidencePotential
+	}
+
+	// KEVs matched only by product name can't confirm the version
+	byProduct := true
+	seenKEV := make(map[string]bool)
+	for _, cve := range cves {
+		if seenKEV[cve.ID] {
+			continue
+		}
+		if kevInfo, isKEV := kevCatalog[cve.ID]; isKEV {
+			seenKEV[cve.ID] = true
+			if s.withdrawn(cve) {
+				s.warnings = append(s.warnings, models.Warning{
+					Kind:       models.WarningWithdrawn,
+					Dependency: dep,
+					Message:    fmt.Sprintf("OSV advisories for %s have been withdrawn; not reported", cve.ID),
+				})
+				continue
+			}
+			if s.ignored(dep, cve.ID) {
+				s.suppress(finding, cve.ID)
+				continue
+			}
+			kevInfo.Accepted = s.exclusions.Match(dep, cve.ID)
+			if kevInfo.Accepted == nil {
+				kevInfo.Accepted = s.baseline.Match(s.recorded(finding), cve.ID)
+			}
+			finding.KEVs = append(finding.KEVs, kevInfo)
+			byProduct = byProduct && cve.Source == exposure.ProductSource
+		}
+	}
+	if len(finding.KEVs) > 0 && byProduct {
+		finding.Confidence = models.ConfidencePotential
+	}
+	return finding
+}
+
+// platformKEVs adds the KEVs matched by product name to a dependency's CVEs
+// when its ecosystem has no version-level advisories, e.g. WordPress plugins
+func platformKEVs(dep models.Dependency, cves []models.CVEInfo, kevCatalog map[string]models.KEVInfo) []models.CVEInfo {
+	platform := dep.Ecosystem.Info().KEVPlatform
+	if platform == "" || dep.Local != "" {
+		return cves
+	}
+	for _, cve := range exposure.PlatformMatches(dep, platform, kevCatalog) {
+		if !slices.ContainsFunc(cves, func(c models.CVEInfo) bool { return c.ID == cve.ID }) {
+			cves = append(cves, cve)
+		}
+	}
+	return cves
+}
+
+// Recheck re-evaluates the dependency inventories recorded in the history
+// store against the latest KEV catalog, without walking or re-querying any
+// manifests. It returns only KEVs never seen in a previous scan or recheck,
+// and records them as seen.
+func (s *Scanner) Recheck() ([]models.Finding, error) {
+	if s.history == nil {
+		return nil, fmt.Errorf("recheck requires the history store")
+	}
+
+	kevCatalog, err := s.fetchCatalog()
+	if err != nil {
+		return nil, err
+	}
+
+	manifests := s.history.Manifests()
+	paths := make([]string, 0, len(manifests))
+	for path := range manifests {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	var findings []models.Finding
+	for _, path := range paths {
+		for _, d := range manifests[path].Dependencies {
+			s.deps = append(s.deps, d.Dependency)
+			finding := s.matchKEVs(d.Dependency, platformKEVs(d.Dependency, d.CVEs, kevCatalog), kevCatalog)
+
+			var newKEVs []models.KEVInfo
+			for _, kev := range finding.KEVs {
+				if !s.history.Seen(s.recorded(finding).Fingerprint(kev.CVEID)) {
+					newKEVs = append(newKEVs, kev)
+				}
+			}
+			if len(newKEVs) > 0 {
+				finding.KEVs = newKEVs
+				findings = append(findings, finding)
+			}
+		}
+	}
+	s.severity.Apply(findings)
+
+	if s.owners != nil {
+		for i := range findings {
+			findings[i].Owners = s.owners.Owners(findings[i].Dependency.SourceFile)
+		}
+	}
+	if err := s.trackFindings(findings, false); err != nil {
+		return nil, fmt.Errorf("failed to save history store: %w", err)
+	}
+	policy.SLA{Ransomware: s.config.SLARansomware, Default: s.config.SLADefault}.Apply(findings)
+
+	return findings, nil
+}
+
+// suppressedKEV is a KEV matched to a dependency but left out of the report
+type suppressedKEV struct {
+	fingerprint string
+	observation history.Observation
+}
+
+// suppress records that a finding's KEV was matched but isn't reported
+func (s *Scanner) suppress(f models.Finding, cveID string) {
+	f = s.recorded(f)
+	s.suppressed = append(s.suppressed, suppressedKEV{
+		fingerprint: f.Fingerprint(cveID),
+		observation: history.Observed(f, cveID, history.StateSuppressed),
+	})
+}
+
+// trackFindings stamps each KEV with the time its fingerprint was first seen
+// and records the lifecycle state of every matched KEV. With resolve, open
+// findings in the scanned manifests that this scan no longer matched are
+// marked resolved.
+func (s *Scanner) trackFindings(findings []models.Finding, resolve bool) error {
+	now := time.Now()
+	s.lifecycle = make(map[history.State]int)
+
+	var fingerprints []string
+	for _, f := range findings {
+		for _, kev := range f.KEVs {
+			fingerprints = append(fingerprints, s.recorded(f).Fingerprint(kev.CVEID))
+		}
+	}
+	for _, k := range s.suppressed {
+		fingerprints = append(fingerprints, k.fingerprint)
+	}
+	s.history.Prefetch(nil, fingerprints)
+
+	for i := range findings {
+		for j := range findings[i].KEVs {
+			kev := &findings[i].KEVs[j]
+			var state history.State
+			if kev.Accepted != nil {
+				state = history.StateAccepted
+			}
+			f := s.recorded(findings[i])
+			rec := s.history.Observe(f.Fingerprint(kev.CVEID), history.Observed(f, kev.CVEID, state), now)
+			kev.FirstSeen = rec.FirstSeen
+			s.lifecycle[rec.State]++
+		}
+	}
+	for _, k := range s.suppressed {
+		s.history.Observe(k.fingerprint, k.observation, now)
+		s.lifecycle[history.StateSuppressed]++
+	}
+	if resolve {
+		if manifests, roots, ok := s.resolvable(); ok {
+			s.lifecycle[history.StateResolved] = s.history.Resolve(manifests, roots, now)
+		}
+	}
+	return s.history.Save()
+}
+
+// resolvable returns the manifests the last scan covered completely, and the
+// paths under which deleted manifests count as covered. It returns false
+// when absent findings can't be told apart from ones the scan missed: for
+// refs, archives and inventories, or when a vulnerability source failed.
+func (s *Scanner) resolvable() (manifests, roots []string, ok bool) {
+	if s.fromGit() || s.config.Archive != "" || s.config.Inventory != "" || len(s.failures) > 0 {
+		return nil, nil, false
+	}
+	// Manifests checked in part by the query budget aren't covered
+	seen := s.overBudgetFiles()
+	for _, dep := range s.deps {
+		for _, file := range dep.Files() {
+			if !seen[file] && !strings.Contains(file, "://") {
+				seen[file] = true
+				manifests = append(manifests, s.recordedPath(file))
+			}
+		}
+	}
+	// Limits, ecosystem filters and the query budget leave manifests
+	// unparsed or unchecked, which mustn't count as deleted
+	if s.fileLimit || len(s.config.Ecosystems) > 0 || len(s.overBudget) > 0 {
+		return manifests, nil, true
+	}
+	for _, p := range s.config.Paths {
+		if !strings.Contains(p, "://") {
+			roots = append(roots, s.recordedPath(p))
+		}
+	}
+	return manifests, roots, true
+}
+
+// recordedPath returns the name the history store knows a file by. Files in
+// a clone of Config.CloneOf are named after the repository, as in
+// "https://host/repo.git!/go.mod", so that their findings keep the same
+// fingerprints however many times the repository is cloned.
+func (s *Scanner) recordedPath(file string) string {
+	if s.config.CloneOf == "" || len(s.config.Paths) == 0 {
+		return file
+	}
+	rel, err := filepath.Rel(s.config.Paths[0], file)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		return file
+	}
+	if rel == "." {
+		return s.config.CloneOf + "!"
+	}
+	return s.config.CloneOf + "!/" + filepath.ToSlash(rel)
+}
+
+// recorded returns a finding with its manifest named as the history store
+// records it
+func (s *Scanner) recorded(f models.Finding) models.Finding {
+	f.Dependency.SourceFile = s.recordedPath(f.Dependency.SourceFile)
+	return f
+}
+
+// Lifecycle returns the number of KEV findings in each lifecycle state after
+// the last scan, including those it resolved. It is empty without the
+// history store.
+func (s *Scanner) Lifecycle() map[history.State]int {
+	return s.lifecycle
+}
+
+// queryVulnerabilities queries OSV for the dependencies and records the
+// results per manifest in the history store. In incremental mode,
+// dependencies from manifests whose content hash matches the history store
+// reuse the stored results and only changed manifests are re-queried.
+//
+// If a source is unavailable the scan degrades instead of failing: the last
+// results stored for each manifest (if any) fill in for it, merged with the
+// sources that answered, and the failure is recorded in SourceFailures.
+// Manifests with nothing stored to fall back on are reported in a warning.
+func (s *Scanner) queryVulnerabilities(deps []models.Dependency) (map[int][]models.CVEInfo, error) {
+	// Manifest hashes come from the working tree, so they don't apply to refs
+	// or the index
+	if s.history == nil || s.fromGit() {
+		results, err := s.querySources(deps)
 		if err != nil {
-			return nil, fmt.Errorf("failed to stat path %s: %w", path, err)
+			s.warnUnchecked(deps, nil)
+			return make(map[int][]models.CVEInfo), nil
 		}
+		return results, nil
+	}
 
-		if !info.IsDir() {
-			// Single file
-			deps, err := s.parseFile(path)
-			if err != nil {
-				return nil, err
+	results, staleIdx, hashes := s.reuseStored(deps)
+	if len(staleIdx) == 0 {
+		return results, nil
+	}
+
+	stale := make([]models.Dependency, len(staleIdx))
+	for j, i := range staleIdx {
+		stale[j] = deps[i]
+	}
+
+	failed := len(s.failures)
+	staleResults, err := s.querySources(stale)
+	if len(s.failures) > failed {
+		// Degraded mode: what was stored for these manifests stands in for
+		// the failed sources
+		fallback := make(map[int][]models.CVEInfo)
+		stored := make(map[string]bool)
+		for _, i := range staleIdx {
+			if rec, ok := s.history.Manifest(s.recordedPath(deps[i].SourceFile)); ok {
+				s.applyStored(deps, []int{i}, rec, fallback)
+				stored[deps[i].SourceFile] = true
 			}
-			allDeps = append(allDeps, deps...)
+		}
+		if err != nil {
+			s.warnUnchecked(stale, stored)
+			maps.Copy(results, fallback)
+			return results, nil
+		}
+		fresh := make(map[int][]models.CVEInfo, len(staleResults))
+		for j, cves := range staleResults {
+			fresh[staleIdx[j]] = cves
+		}
+		maps.Copy(results, clients.MergeCVEs(fresh, fallback))
+		staleResults = nil
+	}
+
+	// Record fresh results for queried manifests. Manifests the query
+	// budget checked in part aren't recorded, nor is anything when a source
+	// failed or OSV was answered from the mirror, or later runs would reuse
+	// their incomplete or stale results.
+	records := make(map[string]*history.ManifestRecord)
+	partial := s.overBudgetFiles()
+	for j, i := range staleIdx {
+		if cves := staleResults[j]; len(cves) > 0 {
+			results[i] = cves
+		}
+
+		file := deps[i].SourceFile
+		hash, ok := hashes[file]
+		if !ok || partial[file] || len(s.failures) > 0 || s.mirrored {
 			continue
 		}
+		if records[file] == nil {
+			records[file] = &history.ManifestRecord{Hash: hash, ScannedAt: time.Now()}
+		}
+		dep := deps[i]
+		dep.SourceFile = s.recordedPath(dep.SourceFile)
+		records[file].Dependencies = append(records[file].Dependencies, history.DependencyRecord{
+			Dependency: dep,
+			CVEs:       staleResults[j],
+		})
+	}
+	for file, rec := range records {
+		s.history.SetManifest(s.recordedPath(file), *rec)
+	}
 
-		// Directory walk
-		err = filepath.WalkDir(path, func(p string, d fs.DirEntry, err error) error {
-			if err != nil {
-				return err
+	if err := s.history.Save(); err != nil && s.config.Incremental {
+		return nil, fmt.Errorf("failed to save history store: %w", err)
+	}
+
+	return results, nil
+}
+
+// reuseStored hashes the dependencies' manifests and, in incremental mode,
+// returns the stored results of those unchanged since the last run. The
+// indices of dependencies still to be queried are returned with the hash of
+// each manifest that could be read.
+func (s *Scanner) reuseStored(deps []models.Dependency) (results map[int][]models.CVEInfo, staleIdx []int, hashes map[string]string) {
+	results = make(map[int][]models.CVEInfo)
+	byFile := make(map[string][]int)
+	for i, dep := range deps {
+		byFile[dep.SourceFile] = append(byFile[dep.SourceFile], i)
+	}
+
+	files := make([]string, 0, len(byFile))
+	for file := range byFile {
+		files = append(files, s.recordedPath(file))
+	}
+	s.history.Prefetch(files, nil)
+
+	hashes = make(map[string]string)
+	for file, indices := range byFile {
+		hash, err := history.HashFile(file)
+		if err != nil {
+			staleIdx = append(staleIdx, indices...)
+			continue
+		}
+		hashes[file] = hash
+
+		rec, ok := s.history.Manifest(s.recordedPath(file))
+		if !s.config.Incremental || !ok || rec.Hash != hash || s.expired(rec) {
+			staleIdx = append(staleIdx, indices...)
+			continue
+		}
+		s.applyStored(deps, indices, rec, results)
+	}
+	return results, staleIdx, hashes
+}
+
+// expired reports whether a manifest's stored results are older than
+// Config.IncrementalTTL, so new advisories for unchanged dependencies are
+// picked up
+func (s *Scanner) expired(rec history.ManifestRecord) bool {
+	return s.config.IncrementalTTL > 0 && time.Since(rec.ScannedAt) > s.config.IncrementalTTL
+}
+
+// upstream returns the dependencies fetched from a registry and their indices
+// in deps, leaving out local ones
+func upstream(deps []models.Dependency) (queried []models.Dependency, index []int) {
+	for i, dep := range deps {
+		if dep.Local == "" {
+			queried = append(queried, dep)
+			index = append(index, i)
+		}
+	}
+	return queried, index
+}
+
+// querySources queries every configured vulnerability source and merges the
+// results with per-dependency CVE dedup. Local dependencies have no upstream
+// package and are left out. Failing sources are recorded in SourceFailures;
+// an error is returned only if every source failed.
+func (s *Scanner) querySources(deps []models.Dependency) (map[int][]models.CVEInfo, error) {
+	queried, index := upstream(deps) // index is the index in deps of each queried dependency
+	if len(queried) == 0 {
+		return make(map[int][]models.CVEInfo), nil
+	}
+
+	var resultSets []map[int][]models.CVEInfo
+	var lastErr error
+
+	for _, src := range s.sources {
+		results, err := src.QueryByPackage(queried)
+		mirrored := false
+		if err != nil && src == clients.VulnSource(s.osvClient) && s.osvMirror != nil {
+			results, err = s.queryMirror(queried, err)
+			mirrored, s.mirrored = true, true
+		}
+		if err != nil {
+			s.recordFailure(src.Name(), err)
+			lastErr = err
+			if !mirrored {
+				continue
+			}
+			// The mirror's matches for the ecosystems it covers still count
+		}
+		resultSets = append(resultSets, results)
+	}
+
+	if len(resultSets) == 0 {
+		return nil, fmt.Errorf("every vulnerability source failed: %w", lastErr)
+	}
+	results := make(map[int][]models.CVEInfo)
+	for j, cves := range clients.MergeCVEs(resultSets...) {
+		results[index[j]] = cves
+	}
+	return results, nil
+}
+
+// queryMirror answers an OSV query from the cached mirror after the API
+// failed with apiErr. If the mirror lacks an ecosystem of the dependencies,
+// its matches are still returned but the OSV failure stands.
+func (s *Scanner) queryMirror(deps []models.Dependency, apiErr error) (map[int][]models.CVEInfo, error) {
+	s.warnings = append(s.warnings, models.Warning{
+		Kind:    models.WarningDegraded,
+		Message: fmt.Sprintf("OSV API unavailable; matched against OSV records cached on %s", s.osvMirror.Updated().Format("2006-01-02")),
+	})
+	results := s.osvMirror.QueryByPackage(deps)
+	for _, dep := range deps {
+		if !s.osvMirror.Covers(dep) {
+			return results, fmt.Errorf("%w (no cached OSV records for %s)", apiErr, dep.Ecosystem)
+		}
+	}
+	return results, nil
+}
+
+// applyStored copies stored CVEs for the given dependency indices into results
+func (s *Scanner) applyStored(deps []models.Dependency, indices []int, rec history.ManifestRecord, results map[int][]models.CVEInfo) {
+	stored := make(map[string][]models.CVEInfo, len(rec.Dependencies))
+	for _, d := range rec.Dependencies {
+		stored[dependencyKey(d.Dependency)] = d.CVEs
+	}
+	for _, i := range indices {
+		if cves := stored[dependencyKey(deps[i])]; len(cves) > 0 {
+			results[i] = cves
+		}
+	}
+}
+
+// warnUnchecked warns that every vulnerability source failed for manifests
+// of deps not in stored, so none of their KEVs could be matched
+func (s *Scanner) warnUnchecked(deps []models.Dependency, stored map[string]bool) {
+	queried, _ := upstream(deps)
+	files := make(map[string]bool)
+	for _, dep := range queried {
+		if !stored[dep.SourceFile] {
+			files[dep.SourceFile] = true
+		}
+	}
+	if len(files) == 0 {
+		return
+	}
+	s.warnings = append(s.warnings, models.Warning{
+		Kind:    models.WarningDegraded,
+		Message: fmt.Sprintf("every vulnerability source failed and %d manifests have no stored results to fall back on; their KEVs could not be checked", len(files)),
+	})
+}
+
+// failedSources returns the names of the sources that failed, once each
+func (s *Scanner) failedSources() []string {
+	var names []string
+	for _, f := range s.failures {
+		if !slices.Contains(names, f.Source) {
+			names = append(names, f.Source)
+		}
+	}
+	return names
+}
+
+// recordFailure notes that a data source failed and results are partial
+func (s *Scanner) recordFailure(source string, err error) {
+	s.failures = append(s.failures, models.SourceFailure{Source: source, Error: err.Error()})
+}
+
+// SourceFailures returns data sources that failed during the last scan. A
+// non-empty result means findings may be incomplete.
+func (s *Scanner) SourceFailures() []models.SourceFailure {
+	return s.failures
+}
+
+// dependencyKey identifies a dependency within a manifest
+func dependencyKey(dep models.Dependency) string {
+	return string(dep.Ecosystem) + "/" + dep.Name + "@" + dep.Version
+}
+
+// Dependencies returns the dependencies discovered during the last scan
+func (s *Scanner) Dependencies() []models.Dependency {
+	return s.deps
+}
+
+// CatalogInfo returns release metadata for the KEV catalog used in the last scan
+func (s *Scanner) CatalogInfo() clients.KEVCatalogInfo {
+	return s.kevClient.Info()
+}
+
+// SourceNames returns the names of the configured vulnerability sources
+func (s *Scanner) SourceNames() []string {
+	names := make([]string, 0, len(s.sources))
+	for _, src := range s.sources {
+		names = append(names, src.Name())
+	}
+	return names
+}
+
+// History returns the history store, or nil if history is disabled
+func (s *Scanner) History() *history.Store {
+	return s.history
+}
+
+// Warnings returns non-finding warnings raised during the last scan
+func (s *Scanner) Warnings() []models.Warning {
+	return s.warnings
+}
+
+// Violations returns dependencies banned by the deny list in the last scan
+func (s *Scanner) Violations() []models.Violation {
+	return s.violations
+}
+
+// analyzeReachability annotates Go KEVs with whether the vulnerable symbols
+// listed in the OSV record are referenced by the module's source
+func (s *Scanner) analyzeReachability(findings []models.Finding) {
+	analyzer := reachability.NewGoAnalyzer()
+
+	for i := range findings {
+		dep := findings[i].Dependency
+		if dep.Ecosystem != models.EcosystemGo {
+			continue
+		}
+		moduleDir := filepath.Dir(dep.SourceFile)
+
+		for j := range findings[i].KEVs {
+			kev := &findings[i].KEVs[j]
+			result := models.ReachabilityUnknown
+
+			for _, cve := range findings[i].CVEs {
+				if cve.ID != kev.CVEID || cve.AdvisoryID == "" {
+					continue
+				}
+
+				record := s.osvRecord(cve.AdvisoryID)
+				if record == nil {
+					continue
+				}
+
+				var imports []clients.OSVImport
+				for _, affected := range record.Affected {
+					if affected.Package.Name == dep.Name {
+						imports = append(imports, affected.EcosystemSpecific.Imports...)
+					}
+				}
+
+				switch analyzer.Analyze(moduleDir, imports) {
+				case models.ReachabilityReferenced:
+					result = models.ReachabilityReferenced
+				case models.ReachabilityUnreferenced:
+					if result == models.ReachabilityUnknown {
+						result = models.ReachabilityUnreferenced
+					}
+				}
+				if result == models.ReachabilityReferenced {
+					break
+				}
 			}
 
-			// Skip common non-source directories
-			if d.IsDir() {
-				name := d.Name()
-				if name == "node_modules" || name == ".git" || name == "vendor" ||
-					name == "__pycache__" || name == ".venv" || name == "venv" {
-					return filepath.SkipDir
+			kev.Reachability = result
+		}
+	}
+}
+
+// staleCatalogAge is how old a KEV catalog release can be before a warning
+// is raised. CISA usually publishes several times a week.
+const staleCatalogAge = 7 * 24 * time.Hour
+
+// checkCatalogAge warns when the KEV catalog in use is stale and fails the
+// scan if it is older than MaxCatalogAge, or its age is unknown while
+// MaxCatalogAge is set
+func (s *Scanner) checkCatalogAge() error {
+	released := s.kevClient.Info().Released()
+	if released.IsZero() {
+		if s.config.MaxCatalogAge > 0 {
+			return fmt.Errorf("KEV catalog release date is unknown, so --max-catalog-age can't be checked")
+		}
+		return nil
+	}
+	age := time.Since(released)
+
+	if s.config.MaxCatalogAge > 0 && age > s.config.MaxCatalogAge {
+		return fmt.Errorf("KEV catalog released %s is %d days old, exceeding --max-catalog-age",
+			released.Format("2006-01-02"), int(age.Hours()/24))
+	}
+	if age > staleCatalogAge {
+		s.warnings = append(s.warnings, models.Warning{
+			Kind: models.WarningStaleData,
+			Message: fmt.Sprintf("KEV catalog released %s is %d days old; results may miss recently added KEVs",
+				released.Format("2006-01-02"), int(age.Hours()/24)),
+		})
+	}
+	return nil
+}
+
+// enrichCVSS sets the CVSS vector and base score on each KEV from the OSV
+// advisory it was matched through. Among several vectors, v3.1 is preferred
+// over v3.0, then the higher base score.
+func (s *Scanner) enrichCVSS(findings []models.Finding) {
+	for i := range findings {
+		for j := range findings[i].KEVs {
+			kev := &findings[i].KEVs[j]
+			for _, cve := range findings[i].CVEs {
+				if cve.ID != kev.CVEID || cve.Source != "OSV" || cve.AdvisoryID == "" {
+					continue
+				}
+				record := s.osvRecord(cve.AdvisoryID)
+				if record == nil {
+					continue
+				}
+				for _, sev := range record.Severity {
+					if sev.Type != "CVSS_V3" {
+						continue
+					}
+					score, err := cvss.BaseScore(sev.Score)
+					if err != nil {
+						continue
+					}
+					version, current := cvss.MinorVersion(sev.Score), cvss.MinorVersion(kev.CVSSVector)
+					if kev.CVSSVector == "" || version > current || version == current && score > kev.CVSSScore {
+						kev.CVSSVector = sev.Score
+						kev.CVSSScore = score
+					}
 				}
-				return nil
 			}
+		}
+	}
+}
 
-			deps, err := s.parseFile(p)
-			if err != nil {
-				// Log but don't fail on individual file parse errors
-				return nil
+// assessRemediation sets the fix version and remediation effort on each
+// finding from the advisories its KEVs were matched through. Findings are
+// left unclassified if any advisory couldn't be fetched.
+func (s *Scanner) assessRemediation(findings []models.Finding) {
+	for i := range findings {
+		var records []*clients.OSVRecord
+		complete := true
+		for _, kev := range findings[i].KEVs {
+			record := s.advisoryFor(findings[i].CVEs, kev.CVEID)
+			if record == nil {
+				complete = false
+				break
 			}
-			allDeps = append(allDeps, deps...)
-			return nil
-		})
+			records = append(records, record)
+		}
+		if !complete {
+			continue
+		}
+		fix, ok := remediation.FixVersion(findings[i].Dependency, records)
+		findings[i].FixVersion = fix
+		findings[i].Effort = remediation.Classify(findings[i].Dependency, ok)
+	}
+}
+
+// advisoryFor returns the full advisory a KEV was matched through, from OSV
+// or the internal advisory feed
+func (s *Scanner) advisoryFor(cves []models.CVEInfo, cveID string) *clients.OSVRecord {
+	for _, cve := range cves {
+		if cve.ID != cveID {
+			continue
+		}
+		for _, ref := range cve.Advisories() {
+			if ref.ID == "" {
+				continue
+			}
+			switch {
+			case ref.Source == "OSV":
+				if record := s.osvRecord(ref.ID); record != nil && record.Withdrawn == nil {
+					return record
+				}
+			case s.advisories != nil && ref.Source == advisories.CatalogName:
+				if record := s.advisories.Record(ref.ID); record != nil {
+					return record
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// osvRecord fetches a full OSV record once per scan. Returns nil if the
+// record couldn't be fetched.
+func (s *Scanner) osvRecord(id string) *clients.OSVRecord {
+	if s.osvRecords == nil {
+		s.osvRecords = make(map[string]*clients.OSVRecord)
+	}
+	record, ok := s.osvRecords[id]
+	if !ok {
+		var err error
+		record, err = s.osvClient.GetVuln(id)
+		if err != nil && s.osvMirror != nil {
+			record = s.osvMirror.Record(id)
+		}
+		s.osvRecords[id] = record
+	}
+	return record
+}
+
+// withdrawn reports whether every advisory behind a KEV match has been
+// retracted. Batch results don't always carry the withdrawn field, so KEV
+// matches are confirmed against the full OSV records. Advisories from other
+// sources, or whose record can't be fetched, keep the match.
+func (s *Scanner) withdrawn(cve models.CVEInfo) bool {
+	for _, ref := range cve.Advisories() {
+		if ref.Source != "OSV" || ref.ID == "" {
+			return false
+		}
+		record := s.osvRecord(ref.ID)
+		if record == nil || record.Withdrawn == nil {
+			return false
+		}
+	}
+	return true
+}
+
+// discoverDependencies returns the dependencies being scanned, limited to the
+// configured ecosystems, with duplicates merged where the ecosystem asks for it
+func (s *Scanner) discoverDependencies() ([]models.Dependency, error) {
+	deps, err := s.discoverAll()
+	if err != nil {
+		return nil, err
+	}
+	deps = mergeDuplicates(deps)
+	if len(s.config.Ecosystems) == 0 {
+		return deps, nil
+	}
+
+	var kept []models.Dependency
+	for _, dep := range deps {
+		if slices.Contains(s.config.Ecosystems, dep.Ecosystem) {
+			kept = append(kept, dep)
+		}
+	}
+	return kept, nil
+}
+
+// mergeDuplicates merges the declarations of a package in ecosystems with
+// MergeDuplicates into the first, keeping the other locations. A merged
+// dependency is dev or transitive only if every declaration is. Local
+// dependencies are left alone, as each names its own source.
+func mergeDuplicates(deps []models.Dependency) []models.Dependency {
+	merged := make([]models.Dependency, 0, len(deps))
+	index := make(map[string]int)
+	for _, dep := range deps {
+		info := dep.Ecosystem.Info()
+		if !info.MergeDuplicates || dep.Local != "" {
+			merged = append(merged, dep)
+			continue
+		}
+		key := fmt.Sprintf("%s|%s|%s|%t", dep.Ecosystem, models.NormalizeName(dep.Ecosystem, dep.Name), dep.Version, dep.Unpinned)
+		i, ok := index[key]
+		if !ok {
+			index[key] = len(merged)
+			merged = append(merged, dep)
+			continue
+		}
+		m := &merged[i]
+		m.Duplicates = append(m.Duplicates, models.SourceLocation{File: dep.SourceFile, Line: dep.Line})
+		m.Dev = m.Dev && dep.Dev
+		m.Transitive = m.Transitive && dep.Transitive
+	}
+	return merged
+}
+
+// ignored reports whether the config ignores the CVE or the dependency
+func (s *Scanner) ignored(dep models.Dependency, cveID string) bool {
+	for _, ig := range s.config.Ignore {
+		if strings.EqualFold(ig, cveID) || models.NormalizeName(dep.Ecosystem, ig) == models.NormalizeName(dep.Ecosystem, dep.Name) {
+			return true
+		}
+	}
+	return false
+}
+
+// discoverAll returns the dependencies in the inventory, git ref, archive or
+// paths being scanned
+func (s *Scanner) discoverAll() ([]models.Dependency, error) {
+	if s.config.Inventory != "" {
+		return inventory.Load(s.config.Inventory)
+	}
+	if s.fromGit() {
+		return s.discoverAtRef()
+	}
+	if s.config.Archive != "" {
+		return s.discoverInArchive()
+	}
+
+	// Paths are discovered concurrently, then merged in argument order
+	results := make([][]models.Dependency, len(s.config.Paths))
+	errs := make([]error, len(s.config.Paths))
+	workers := s.config.MaxConcurrent
+	if workers <= 0 {
+		workers = 1
+	}
+	sem := make(chan struct{}, workers)
+	var wg sync.WaitGroup
+	for i, path := range s.config.Paths {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+			results[i], errs[i] = s.discoverPath(path)
+		}()
+	}
+	wg.Wait()
+
+	var allDeps []models.Dependency
+	for i, deps := range results {
+		if errs[i] != nil {
+			return nil, errs[i]
+		}
+		allDeps = append(allDeps, deps...)
+	}
+	return allDeps, nil
+}
+
+// discoverPath parses one path argument: a manifest URL, a single file, or a
+// directory tree
+func (s *Scanner) discoverPath(path string) ([]models.Dependency, error) {
+	var deps []models.Dependency
+	var err error
+
+	if clients.IsManifestURL(path) && s.plan != nil {
+		s.planManifest(path)
+		return nil, nil
+	}
+	if clients.IsManifestURL(path) {
+		deps, err = s.parseURL(path)
+	} else {
+		deps, err = s.walkPath(path)
+	}
+	if err != nil {
+		return nil, err
+	}
+	for i := range deps {
+		deps[i].ScanPath = path
+	}
+	return deps, nil
+}
+
+// parseURL fetches and parses a manifest served over HTTPS. Dependencies
+// reference the URL, without credentials, as their source file.
+func (s *Scanner) parseURL(rawURL string) ([]models.Dependency, error) {
+	name, err := clients.ManifestURLName(rawURL)
+	if err != nil {
+		return nil, err
+	}
+	parser := s.parserFor(name)
+	if parser == nil {
+		return nil, fmt.Errorf("no parser for manifest %q at %s", name, clients.RedactURL(rawURL))
+	}
+
+	content, err := clients.FetchManifest(rawURL)
+	if err != nil {
+		return nil, err
+	}
+	return s.parse(parser, clients.RedactURL(rawURL), content), nil
+}
+
+// discoverInArchive parses dependency files read in memory from the
+// configured archive. The archive is listed first so manifests can defer to
+// their lockfiles, then read one entry at a time under the walker limits.
+func (s *Scanner) discoverInArchive() ([]models.Dependency, error) {
+	entries, err := archive.List(s.config.Archive)
+	if err != nil {
+		return nil, err
+	}
+	names := make(map[string]bool, len(entries))
+	for _, e := range entries {
+		names[path.Clean(e.Name)] = true
+	}
+
+	var allDeps []models.Dependency
+	depthLimited := false
+	want := func(e archive.Entry) bool {
+		if s.inSkippedDir(e.Name) || s.parserFor(e.Name) == nil {
+			return false
+		}
+		if s.superseded(path.Base(e.Name), func(lockfile string) bool { return names[siblingPath(e.Name, lockfile)] }) {
+			return false
+		}
+		file := s.config.Archive + "!/" + e.Name
+		if s.config.MaxDepth > 0 && strings.Count(path.Clean(e.Name), "/") > s.config.MaxDepth {
+			if !depthLimited {
+				depthLimited = true
+				s.warnLimit(fmt.Sprintf("directories deeper than --max-depth %d in %s were not scanned", s.config.MaxDepth, s.config.Archive))
+			}
+			return false
+		}
+		if s.config.MaxFileSize > 0 && e.Size > s.config.MaxFileSize {
+			s.warnLimit(fmt.Sprintf("%s was not scanned: it is %s, over --max-file-size %s", file, formatSize(e.Size), formatSize(s.config.MaxFileSize)))
+			return false
+		}
+		// Once --max-files is reached the remaining headers are still
+		// listed, but no more entries are read
+		return s.countManifest()
+	}
+	err = archive.Walk(s.config.Archive, want, func(e archive.Entry, content []byte, err error) error {
+		file := s.config.Archive + "!/" + e.Name
 		if err != nil {
-			return nil, err
+			s.recordParseError(file, err.Error(), false)
+			return nil
 		}
+		deps := s.parse(s.parserFor(e.Name), file, content)
+		for i := range deps {
+			deps[i].ScanPath = s.config.Archive
+		}
+		allDeps = append(allDeps, deps...)
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
 
 	return allDeps, nil
 }
 
-// parseFile attempts to parse a file with any matching parser
-func (s *Scanner) parseFile(path string) ([]models.Dependency, error) {
-	filename := filepath.Base(path)
+// fromGit reports whether manifests are read from git, at a ref or from the
+// index, rather than from the working tree
+func (s *Scanner) fromGit() bool {
+	return s.config.GitRef != "" || s.config.Staged
+}
+
+// discoverAtRef parses dependency files from the git object store at the
+// configured ref, or from the index when scanning staged files, without
+// checking them out
+func (s *Scanner) discoverAtRef() ([]models.Dependency, error) {
+	var allDeps []models.Dependency
+
+	for _, path := range s.config.Paths {
+		top, err := git.TopLevel(path)
+		if err != nil {
+			// path may be a file rather than a directory
+			top, err = git.TopLevel(filepath.Dir(path))
+			if err != nil {
+				return nil, fmt.Errorf("%s is not in a git repository: %w", path, err)
+			}
+		}
+
+		abs, err := filepath.Abs(path)
+		if err != nil {
+			return nil, err
+		}
+		pathspec, err := filepath.Rel(top, abs)
+		if err != nil {
+			return nil, err
+		}
+
+		var files []string
+		if s.config.Staged {
+			files, err = git.IndexFiles(top, filepath.ToSlash(pathspec))
+		} else {
+			files, err = git.ListFiles(top, s.config.GitRef, filepath.ToSlash(pathspec))
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to list files at %s: %w", cmp.Or(s.config.GitRef, "the index"), err)
+		}
+
+		listed := make(map[string]bool, len(files))
+		for _, file := range files {
+			listed[file] = true
+		}
+
+		for _, file := range files {
+			if s.inSkippedDir(file) {
+				continue
+			}
+			if s.superseded(filepath.Base(file), func(lockfile string) bool { return listed[siblingPath(file, lockfile)] }) {
+				continue
+			}
+			parser := s.parserFor(file)
+			if parser == nil {
+				continue
+			}
 
-	for _, parser := range s.parsers {
-		if parser.CanParse(filename) {
-			content, err := os.ReadFile(path)
+			content, err := git.ReadFile(top, s.config.GitRef, file)
 			if err != nil {
 				return nil, err
 			}
-			return parser.Parse(path, content)
+			deps := s.parse(parser, file, content)
+			for i := range deps {
+				deps[i].ScanPath = path
+			}
+			allDeps = append(allDeps, deps...)
+		}
+	}
+
+	return allDeps, nil
+}
+
+// skipDir returns true for directories excluded from walks by SkipDirs
+func (s *Scanner) skipDir(name string) bool {
+	if s.config.ScanVendored && slices.Contains(models.VendoredDirs, name) {
+		return false
+	}
+	for _, pattern := range s.config.SkipDirs {
+		if ok, _ := path.Match(pattern, name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// inSkippedDir returns true if any directory component of a slash-separated
+// path should be skipped
+func (s *Scanner) inSkippedDir(file string) bool {
+	parts := strings.Split(file, "/")
+	for _, part := range parts[:len(parts)-1] {
+		if s.skipDir(part) {
+			return true
+		}
+	}
+	return false
+}
+
+// superseded reports whether a manifest should be skipped in favor of a
+// lockfile next to it; exists reports whether the directory holds a file
+func (s *Scanner) superseded(manifest string, exists func(name string) bool) bool {
+	if s.config.AllManifests {
+		return false
+	}
+	for _, lockfile := range parsers.Lockfiles(manifest) {
+		if exists(lockfile) {
+			return true
+		}
+	}
+	return false
+}
+
+// siblingPath returns the slash-separated path of name in file's directory
+func siblingPath(file, name string) string {
+	return path.Join(path.Dir(file), name)
+}
+
+// parserFor returns the first parser that can handle the file, or nil
+func (s *Scanner) parserFor(file string) parsers.Parser {
+	return parsers.Find(s.parsers, file)
+}
+
+// parseFile attempts to parse a file with any matching parser. Read and
+// parse failures are recorded as parse errors.
+func (s *Scanner) parseFile(path string) []models.Dependency {
+	parser := s.parserFor(path)
+	if parser == nil {
+		return nil // No matching parser
+	}
+
+	if s.config.MaxFileSize > 0 {
+		if info, err := os.Stat(path); err == nil && info.Size() > s.config.MaxFileSize {
+			s.warnLimit(fmt.Sprintf("%s was not scanned: it is %s, over --max-file-size %s", path, formatSize(info.Size()), formatSize(s.config.MaxFileSize)))
+			return nil
+		}
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		s.recordParseError(path, err.Error(), false)
+		return nil
+	}
+	return s.parse(parser, path, content)
+}
+
+// countManifest counts a manifest found by the walker against MaxFiles,
+// returning false once the limit has been reached
+func (s *Scanner) countManifest() bool {
+	s.discoverMu.Lock()
+	defer s.discoverMu.Unlock()
+	if s.config.MaxFiles > 0 && s.manifests >= s.config.MaxFiles {
+		if !s.fileLimit {
+			s.fileLimit = true
+			s.warnings = append(s.warnings, models.Warning{
+				Kind:    models.WarningLimit,
+				Message: fmt.Sprintf("stopped after %d manifests (--max-files); remaining manifests were not scanned", s.config.MaxFiles),
+			})
 		}
+		return false
 	}
+	s.manifests++
+	return true
+}
+
+// warnLimit records that a walker limit skipped part of the scan
+func (s *Scanner) warnLimit(message string) {
+	s.discoverMu.Lock()
+	defer s.discoverMu.Unlock()
+	s.warnings = append(s.warnings, models.Warning{Kind: models.WarningLimit, Message: message})
+}
+
+// warnSkipped records that the walk options left part of a tree unscanned
+func (s *Scanner) warnSkipped(message string) {
+	s.discoverMu.Lock()
+	defer s.discoverMu.Unlock()
+	s.warnings = append(s.warnings, models.Warning{Kind: models.WarningSkipped, Message: message})
+}
+
+// walkDepth returns how many directories below root dir is
+func walkDepth(root, dir string) int {
+	rel, err := filepath.Rel(root, dir)
+	if err != nil || rel == "." {
+		return 0
+	}
+	return strings.Count(rel, string(filepath.Separator)) + 1
+}
+
+// formatSize renders a byte count in the largest fitting unit
+func formatSize(n int64) string {
+	switch {
+	case n >= 1<<30:
+		return fmt.Sprintf("%.4gGB", float64(n)/(1<<30))
+	case n >= 1<<20:
+		return fmt.Sprintf("%.4gMB", float64(n)/(1<<20))
+	case n >= 1<<10:
+		return fmt.Sprintf("%.4gKB", float64(n)/(1<<10))
+	default:
+		return fmt.Sprintf("%dB", n)
+	}
+}
+
+// parse runs a parser on one manifest. An error or panic in the parser is
+// recorded as a parse error and the manifest contributes no dependencies, so
+// one malformed file doesn't fail the scan.
+func (s *Scanner) parse(parser parsers.Parser, file string, content []byte) (deps []models.Dependency) {
+	defer func() {
+		if r := recover(); r != nil {
+			s.recordParseError(file, fmt.Sprintf("parser panic: %v", r), true)
+			deps = nil
+		}
+	}()
+
+	deps, err := parser.Parse(file, content)
+	if err != nil {
+		s.recordParseError(file, err.Error(), false)
+		return nil
+	}
+	return deps
+}
+
+func (s *Scanner) recordParseError(file, message string, panicked bool) {
+	s.discoverMu.Lock()
+	defer s.discoverMu.Unlock()
+	s.parseErrors = append(s.parseErrors, models.ParseError{File: file, Error: message, Panic: panicked})
+}
 
-	return nil, nil // No matching parser
+// ParseErrors returns the manifests that could not be read or parsed during
+// the last scan, sorted by file
+func (s *Scanner) ParseErrors() []models.ParseError {
+	s.discoverMu.Lock()
+	defer s.discoverMu.Unlock()
+	sort.Slice(s.parseErrors, func(i, j int) bool {
+		return s.parseErrors[i].File < s.parseErrors[j].File
+	})
+	return s.parseErrors
 }
@@ -0,0 +1,222 @@
+package scanner
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/ethanolivertroy/kev-check-demo/internal/models"
+)
+
+// walker walks the directory tree of one path argument
+type walker struct {
+	s    *Scanner
+	root string
+
+	device    uint64 // Filesystem of root, for OneFileSystem
+	hasDevice bool
+
+	// Real paths of the trees walked so far: root, then each followed
+	// symlinked directory. Links into one of them are duplicates or loops.
+	walked []string
+
+	unfollowed   int // Symlinks skipped without FollowSymlinks
+	depthLimited bool
+	stopped      bool // MaxFiles reached
+	deps         []models.Dependency
+}
+
+// walkPath parses a file, or every dependency file under a directory
+func (s *Scanner) walkPath(path string) ([]models.Dependency, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to stat path %s: %w", path, err)
+	}
+
+	if !info.IsDir() {
+		// Single file
+		return s.parseFile(path), nil
+	}
+
+	real, err := filepath.EvalSymlinks(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve path %s: %w", path, err)
+	}
+	w := &walker{s: s, root: path, walked: []string{real}}
+	w.device, w.hasDevice = deviceID(info)
+
+	if err := w.walk(path); err != nil {
+		return nil, err
+	}
+	if w.unfollowed > 0 {
+		s.warnSkipped(fmt.Sprintf("%d symbolic link(s) to directories or manifests under %s were not followed (see --follow-symlinks)", w.unfollowed, path))
+	}
+	return w.deps, nil
+}
+
+// walk walks the tree at dir, which is root or a followed symlink under it
+func (w *walker) walk(dir string) error {
+	// A trailing separator makes WalkDir resolve dir when it is a symlink
+	// instead of reporting the link itself
+	start := dir + string(filepath.Separator)
+
+	return filepath.WalkDir(start, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if p == start && dir == w.root {
+				return err
+			}
+			// Unreadable entries, e.g. on a network share, leave the scan
+			// incomplete rather than aborting it
+			w.s.recordParseError(filepath.Clean(p), err.Error(), false)
+			if d != nil && d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if p == start {
+			return nil
+		}
+
+		if d.Type()&fs.ModeSymlink != 0 {
+			return w.symlink(p)
+		}
+
+		if d.IsDir() {
+			if !w.enter(p, d.Name(), d.Info) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		return w.file(p)
+	})
+}
+
+// enter reports whether the directory at p should be walked
+func (w *walker) enter(p, name string, stat func() (fs.FileInfo, error)) bool {
+	if w.s.skipDir(name) {
+		return false
+	}
+
+	cfg := w.s.config
+	if cfg.MaxDepth > 0 && walkDepth(w.root, p) > cfg.MaxDepth {
+		if !w.depthLimited {
+			w.depthLimited = true
+			w.s.warnLimit(fmt.Sprintf("directories deeper than --max-depth %d under %s were not scanned", cfg.MaxDepth, w.root))
+		}
+		return false
+	}
+
+	if cfg.OneFileSystem && w.hasDevice {
+		info, err := stat()
+		if err != nil {
+			return true // WalkDir reports the error when reading the directory
+		}
+		if dev, ok := deviceID(info); ok && dev != w.device {
+			w.s.warnSkipped(fmt.Sprintf("%s is on a different filesystem than %s and was not scanned (--one-file-system)", p, w.root))
+			return false
+		}
+	}
+	return true
+}
+
+// file parses the manifest at p, if it is one
+func (w *walker) file(p string) error {
+	if w.s.parserFor(p) == nil || w.s.superseded(filepath.Base(p), w.sibling(p)) {
+		return nil
+	}
+	if !w.s.countManifest() {
+		w.stopped = true
+		return filepath.SkipAll
+	}
+	w.deps = append(w.deps, w.s.parseFile(p)...)
+	return nil
+}
+
+// sibling returns a function reporting whether the walk will parse a file of
+// the given name in p's directory
+func (w *walker) sibling(p string) func(name string) bool {
+	return func(name string) bool {
+		info, err := os.Lstat(filepath.Join(filepath.Dir(p), name))
+		if err != nil {
+			return false
+		}
+		if info.Mode()&fs.ModeSymlink != 0 {
+			if !w.s.config.FollowSymlinks {
+				return false
+			}
+			info, err = os.Stat(filepath.Join(filepath.Dir(p), name))
+			if err != nil {
+				return false
+			}
+		}
+		return info.Mode().IsRegular()
+	}
+}
+
+// symlink handles a symbolic link found by the walk. Links are only followed
+// with FollowSymlinks; dangling links are ignored.
+func (w *walker) symlink(p string) error {
+	info, err := os.Stat(p)
+	if err != nil {
+		return nil
+	}
+	isManifest := w.s.parserFor(p) != nil
+	if !info.IsDir() && !isManifest {
+		return nil
+	}
+	if !w.s.config.FollowSymlinks {
+		w.unfollowed++
+		return nil
+	}
+
+	real, err := filepath.EvalSymlinks(p)
+	if err != nil {
+		return nil
+	}
+
+	if !info.IsDir() {
+		// A manifest linked from inside the tree is parsed where it lives
+		if w.inWalked(real) && w.s.parserFor(real) != nil {
+			return nil
+		}
+		return w.file(p)
+	}
+
+	// Links back to an ancestor would loop, and links into a tree already
+	// walked would scan it twice
+	linkDir, err := filepath.EvalSymlinks(filepath.Dir(p))
+	if err != nil || w.inWalked(real) || within(linkDir, real) {
+		return nil
+	}
+	if !w.enter(p, filepath.Base(p), func() (fs.FileInfo, error) { return info, nil }) {
+		return nil
+	}
+
+	w.walked = append(w.walked, real)
+	if err := w.walk(p); err != nil {
+		return err
+	}
+	if w.stopped {
+		return filepath.SkipAll
+	}
+	return nil
+}
+
+// inWalked reports whether real lies in a tree already walked
+func (w *walker) inWalked(real string) bool {
+	for _, dir := range w.walked {
+		if within(real, dir) {
+			return true
+		}
+	}
+	return false
+}
+
+// within reports whether path is dir or below it
+func within(path, dir string) bool {
+	rel, err := filepath.Rel(dir, path)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
@@ -0,0 +1,284 @@
+package server
+
+import (
+	"encoding/json"
+	"html/template"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ethanolivertroy/kev-check-demo/internal/queue"
+)
+
+// reportInfo describes one report in the reports directory
+type reportInfo struct {
+	ID       string        `json:"id"`
+	Status   string        `json:"status"` // "ok" or "failed"
+	Error    string        `json:"error,omitempty"`
+	Modified time.Time     `json:"modified"`
+	Summary  *reportCounts `json:"summary,omitempty"`
+}
+
+// viewReport is the subset of the JSON report format the viewer displays
+type viewReport struct {
+	Summary  reportCounts  `json:"summary"`
+	Findings []viewFinding `json:"findings"`
+}
+
+type reportCounts struct {
+	TotalFindings     int `json:"total_findings"`
+	TotalKEVs         int `json:"total_kevs"`
+	RansomwareRelated int `json:"ransomware_related"`
+	AffectedPackages  int `json:"affected_packages"`
+	RiskAccepted      int `json:"risk_accepted"`
+	SLABreaches       int `json:"sla_breaches"`
+}
+
+type viewFinding struct {
+	Package struct {
+		Name      string `json:"name"`
+		Version   string `json:"version"`
+		Ecosystem string `json:"ecosystem"`
+	} `json:"package"`
+	SourceFile string    `json:"source_file"`
+	Line       int       `json:"line"`
+	Owners     []string  `json:"owners"`
+	KEVs       []viewKEV `json:"kevs"`
+}
+
+type viewKEV struct {
+	CVEID             string `json:"cve_id"`
+	VendorProject     string `json:"vendor_project"`
+	Product           string `json:"product"`
+	VulnerabilityName string `json:"vulnerability_name"`
+	DueDate           string `json:"due_date"`
+	RequiredAction    string `json:"required_action"`
+	RansomwareUse     bool   `json:"ransomware_use"`
+	DaysOpen          int    `json:"days_open"`
+	SLABreached       bool   `json:"sla_breached"`
+	RiskAccepted      *struct {
+		Reason string `json:"reason"`
+	} `json:"risk_accepted"`
+}
+
+// reportFilter narrows the findings shown on a report page
+type reportFilter struct {
+	Query      string // Substring of package, CVE, vendor, product or file
+	Ecosystem  string
+	Owner      string
+	Ransomware bool
+}
+
+// listReports returns the reports in the reports directory, newest first.
+// Failed queue jobs (<id>.error.json) are included with their error.
+func (s *Server) listReports() ([]reportInfo, error) {
+	entries, err := os.ReadDir(s.config.ReportsDir)
+	if err != nil {
+		return nil, err
+	}
+
+	var reports []reportInfo
+	for _, e := range entries {
+		name := e.Name()
+		if e.IsDir() || strings.HasPrefix(name, ".") || !strings.HasSuffix(name, ".json") {
+			continue
+		}
+		info, err := e.Info()
+		if err != nil {
+			continue
+		}
+
+		if id, ok := strings.CutSuffix(name, ".error.json"); ok {
+			r := reportInfo{ID: id, Status: "failed", Modified: info.ModTime()}
+			var failed struct {
+				Error string `json:"error"`
+			}
+			if data, err := os.ReadFile(filepath.Join(s.config.ReportsDir, name)); err == nil && json.Unmarshal(data, &failed) == nil {
+				r.Error = failed.Error
+			}
+			reports = append(reports, r)
+			continue
+		}
+
+		id := strings.TrimSuffix(name, ".json")
+		report, err := s.loadReport(id)
+		if err != nil {
+			continue
+		}
+		reports = append(reports, reportInfo{ID: id, Status: "ok", Modified: info.ModTime(), Summary: &report.Summary})
+	}
+
+	sort.Slice(reports, func(i, j int) bool {
+		return reports[i].Modified.After(reports[j].Modified)
+	})
+	return reports, nil
+}
+
+// loadReport reads a JSON report by ID
+func (s *Server) loadReport(id string) (*viewReport, error) {
+	if id == "" || strings.ContainsAny(id, `/\`) || strings.HasPrefix(id, ".") {
+		return nil, os.ErrNotExist
+	}
+	data, err := os.ReadFile(filepath.Join(s.config.ReportsDir, id+".json"))
+	if err != nil {
+		return nil, err
+	}
+	var report viewReport
+	if err := json.Unmarshal(data, &report); err != nil {
+		return nil, err
+	}
+	return &report, nil
+}
+
+func (s *Server) handleReportList(w http.ResponseWriter, r *http.Request) {
+	reports, err := s.listReports()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to read reports")
+		return
+	}
+	if reports == nil {
+		reports = []reportInfo{}
+	}
+	writeJSON(w, http.StatusOK, reports)
+}
+
+func (s *Server) handleReportJSON(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	if _, err := s.loadReport(id); err != nil {
+		writeError(w, http.StatusNotFound, "report not found")
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	http.ServeFile(w, r, filepath.Join(s.config.ReportsDir, id+".json"))
+}
+
+func (s *Server) handleReportIndex(w http.ResponseWriter, r *http.Request) {
+	reports, err := s.listReports()
+	if err != nil {
+		http.Error(w, "failed to read reports", http.StatusInternalServerError)
+		return
+	}
+	renderHTML(w, indexTemplate, reports)
+}
+
+func (s *Server) handleReportPage(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	report, err := s.loadReport(id)
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	q := r.URL.Query()
+	filter := reportFilter{
+		Query:      strings.TrimSpace(q.Get("q")),
+		Ecosystem:  q.Get("ecosystem"),
+		Owner:      q.Get("owner"),
+		Ransomware: q.Get("ransomware") != "",
+	}
+
+	ecosystems := make(map[string]bool)
+	owners := make(map[string]bool)
+	for _, f := range report.Findings {
+		ecosystems[f.Package.Ecosystem] = true
+		for _, o := range f.Owners {
+			owners[o] = true
+		}
+	}
+
+	renderHTML(w, reportTemplate, map[string]any{
+		"ID":         id,
+		"Summary":    report.Summary,
+		"Findings":   filter.apply(report.Findings),
+		"Total":      len(report.Findings),
+		"Filter":     filter,
+		"Ecosystems": sortedKeys(ecosystems),
+		"Owners":     sortedKeys(owners),
+	})
+}
+
+// apply returns the findings matching the filter, keeping only matching KEVs
+func (f reportFilter) apply(findings []viewFinding) []viewFinding {
+	query := strings.ToLower(f.Query)
+	var out []viewFinding
+	for _, finding := range findings {
+		if f.Ecosystem != "" && finding.Package.Ecosystem != f.Ecosystem {
+			continue
+		}
+		if f.Owner != "" && !contains(finding.Owners, f.Owner) {
+			continue
+		}
+
+		pkgText := strings.ToLower(finding.Package.Name + " " + finding.SourceFile)
+		var kevs []viewKEV
+		for _, kev := range finding.KEVs {
+			if f.Ransomware && !kev.RansomwareUse {
+				continue
+			}
+			kevText := strings.ToLower(kev.CVEID + " " + kev.VendorProject + " " + kev.Product)
+			if query != "" && !strings.Contains(pkgText, query) && !strings.Contains(kevText, query) {
+				continue
+			}
+			kevs = append(kevs, kev)
+		}
+		if len(kevs) > 0 {
+			finding.KEVs = kevs
+			out = append(out, finding)
+		}
+	}
+	return out
+}
+
+// handleQueueScan answers POST /v1/scans by queueing a scan job, taking the
+// same fields as queue push. The server always assigns the job ID, so
+// clients can't overwrite queued jobs.
+func (s *Server) handleQueueScan(w http.ResponseWriter, r *http.Request) {
+	var job queue.Job
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<20)).Decode(&job); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid job: "+err.Error())
+		return
+	}
+	job.ID = queue.NewJobID()
+	job.QueuedAt = time.Now().UTC()
+	if err := job.Validate(); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	s.queueMu.Lock()
+	err := s.config.Queue.Push(r.Context(), job)
+	s.queueMu.Unlock()
+	if err != nil {
+		writeError(w, http.StatusBadGateway, "failed to queue job")
+		return
+	}
+	writeJSON(w, http.StatusAccepted, map[string]string{"id": job.ID})
+}
+
+func renderHTML(w http.ResponseWriter, tmpl *template.Template, data any) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := tmpl.Execute(w, data); err != nil {
+		http.Error(w, "failed to render page", http.StatusInternalServerError)
+	}
+}
+
+func sortedKeys(m map[string]bool) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
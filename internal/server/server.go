@@ -0,0 +1,251 @@
+// Package server exposes the history store and scan reports over HTTP, so
+// services can ask which inventoried projects are affected by a
+// vulnerability, and people can browse results, without running a scan.
+package server
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethanolivertroy/kev-check-demo/internal/clients"
+	"github.com/ethanolivertroy/kev-check-demo/internal/history"
+	"github.com/ethanolivertroy/kev-check-demo/internal/queue"
+)
+
+var cvePattern = regexp.MustCompile(`^CVE-\d{4}-\d{4,}$`)
+
+// Role is the access level granted by a token
+type Role int
+
+const (
+	RoleNone     Role = iota
+	RoleReadOnly      // May view reports and query exposure
+	RoleAdmin         // May also queue scans
+)
+
+// Config configures a Server
+type Config struct {
+	HistoryPath    string      // History store file path or postgres:// URL
+	AdminToken     string      // Grants RoleAdmin
+	ReadOnlyTokens []string    // Each grants RoleReadOnly
+	ReportsDir     string      // JSON reports to browse, e.g. queue work --results (optional)
+	Queue          queue.Queue // Queue for POST /v1/scans (optional)
+}
+
+// Server answers queries against the scan history store and serves the
+// report viewer. The store and reports are re-read on every request, so
+// results from scans that ran since the server started are included.
+type Server struct {
+	config Config
+
+	kevMu     sync.Mutex // KEVClient isn't safe for concurrent use
+	kevClient *clients.KEVClient
+	queueMu   sync.Mutex // Nor are queue connections
+}
+
+// New creates a server. If no tokens are configured, every request is
+// granted RoleReadOnly; RoleAdmin always requires the admin token.
+func New(config Config, kevClient *clients.KEVClient) *Server {
+	return &Server{config: config, kevClient: kevClient}
+}
+
+// Handler returns the HTTP routes
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /healthz", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	mux.Handle("GET /v1/exposure", s.authorize(RoleReadOnly, http.HandlerFunc(s.handleExposure)))
+	if s.config.ReportsDir != "" {
+		mux.Handle("GET /v1/reports", s.authorize(RoleReadOnly, http.HandlerFunc(s.handleReportList)))
+		mux.Handle("GET /v1/reports/{id}", s.authorize(RoleReadOnly, http.HandlerFunc(s.handleReportJSON)))
+		mux.Handle("GET /reports", s.authorize(RoleReadOnly, http.HandlerFunc(s.handleReportIndex)))
+		mux.Handle("GET /reports/{id}", s.authorize(RoleReadOnly, http.HandlerFunc(s.handleReportPage)))
+		mux.Handle("GET /{$}", http.RedirectHandler("/reports", http.StatusFound))
+	}
+	if s.config.Queue != nil {
+		mux.Handle("POST /v1/scans", s.authorize(RoleAdmin, http.HandlerFunc(s.handleQueueScan)))
+	}
+	return mux
+}
+
+// role returns the access level of the request's token, sent as a bearer
+// token or, for browsers, as the password of HTTP basic auth
+func (s *Server) role(r *http.Request) Role {
+	open := s.config.AdminToken == "" && len(s.config.ReadOnlyTokens) == 0
+
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok {
+		_, token, ok = r.BasicAuth()
+	}
+	if !ok || token == "" {
+		if open {
+			return RoleReadOnly
+		}
+		return RoleNone
+	}
+
+	if s.config.AdminToken != "" && subtle.ConstantTimeCompare([]byte(token), []byte(s.config.AdminToken)) == 1 {
+		return RoleAdmin
+	}
+	for _, t := range s.config.ReadOnlyTokens {
+		if subtle.ConstantTimeCompare([]byte(token), []byte(t)) == 1 {
+			return RoleReadOnly
+		}
+	}
+	if open {
+		return RoleReadOnly
+	}
+	return RoleNone
+}
+
+// authorize rejects requests whose token doesn't grant at least min
+func (s *Server) authorize(min Role, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch role := s.role(r); {
+		case role == RoleNone:
+			// Basic lets browsers prompt for the token on the report pages
+			w.Header().Set("WWW-Authenticate", `Basic realm="kev-checker"`)
+			w.Header().Add("WWW-Authenticate", `Bearer realm="kev-checker"`)
+			writeError(w, http.StatusUnauthorized, "missing or invalid token")
+		case role < min:
+			writeError(w, http.StatusForbidden, "token is read-only")
+		default:
+			next.ServeHTTP(w, r)
+		}
+	})
+}
+
+type exposureResponse struct {
+	CVE               string            `json:"cve"`
+	KEV               *exposureKEV      `json:"kev"`
+	Affected          []affectedPackage `json:"affected"`
+	Projects          []string          `json:"projects"`
+	ManifestsSearched int               `json:"manifests_searched"`
+}
+
+type exposureKEV struct {
+	VendorProject     string `json:"vendor_project"`
+	Product           string `json:"product"`
+	VulnerabilityName string `json:"vulnerability_name"`
+	DateAdded         string `json:"date_added"`
+	DueDate           string `json:"due_date"`
+	RansomwareUse     bool   `json:"ransomware_use"`
+}
+
+type affectedPackage struct {
+	Project    string    `json:"project"`
+	Manifest   string    `json:"manifest"`
+	Line       int       `json:"line,omitempty"`
+	Name       string    `json:"name"`
+	Version    string    `json:"version"`
+	Ecosystem  string    `json:"ecosystem"`
+	Purl       string    `json:"purl,omitempty"`
+	AdvisoryID string    `json:"advisory_id,omitempty"`
+	ScannedAt  time.Time `json:"scanned_at"`
+}
+
+// handleExposure answers GET /v1/exposure?cve=CVE-... with every stored
+// dependency the CVE was reported for, and the KEV entry if it has one
+func (s *Server) handleExposure(w http.ResponseWriter, r *http.Request) {
+	cve := strings.ToUpper(strings.TrimSpace(r.URL.Query().Get("cve")))
+	if !cvePattern.MatchString(cve) {
+		writeError(w, http.StatusBadRequest, "query parameter cve must be a CVE ID, e.g. CVE-2021-44228")
+		return
+	}
+
+	store, err := history.Open(s.config.HistoryPath)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to read history store")
+		return
+	}
+	manifests, searched, err := store.ManifestsWithCVE(cve)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to read history store")
+		return
+	}
+
+	resp := exposureResponse{CVE: cve, Affected: []affectedPackage{}, Projects: []string{}, ManifestsSearched: searched}
+	projects := make(map[string]bool)
+	for path, rec := range manifests {
+		for _, d := range rec.Dependencies {
+			for _, c := range d.CVEs {
+				if c.ID != cve {
+					continue
+				}
+				dep := d.Dependency
+				project := dep.ScanPath
+				if project == "" {
+					project = path
+				}
+				resp.Affected = append(resp.Affected, affectedPackage{
+					Project:    project,
+					Manifest:   path,
+					Line:       dep.Line,
+					Name:       dep.Name,
+					Version:    dep.Version,
+					Ecosystem:  string(dep.Ecosystem),
+					Purl:       dep.Purl(),
+					AdvisoryID: c.AdvisoryID,
+					ScannedAt:  rec.ScannedAt,
+				})
+				projects[project] = true
+				break
+			}
+		}
+	}
+	sort.Slice(resp.Affected, func(i, j int) bool {
+		a, b := resp.Affected[i], resp.Affected[j]
+		if a.Project != b.Project {
+			return a.Project < b.Project
+		}
+		if a.Manifest != b.Manifest {
+			return a.Manifest < b.Manifest
+		}
+		return a.Name < b.Name
+	})
+	for p := range projects {
+		resp.Projects = append(resp.Projects, p)
+	}
+	sort.Strings(resp.Projects)
+
+	// The KEV entry is informational; answer without it if the catalog is
+	// unavailable
+	if s.kevClient != nil {
+		s.kevMu.Lock()
+		catalog, err := s.kevClient.FetchKEVCatalog()
+		s.kevMu.Unlock()
+		if err == nil {
+			if kev, ok := catalog[cve]; ok {
+				resp.KEV = &exposureKEV{
+					VendorProject:     kev.VendorProject,
+					Product:           kev.Product,
+					VulnerabilityName: kev.VulnerabilityName,
+					DateAdded:         kev.DateAdded.Format("2006-01-02"),
+					DueDate:           kev.DueDate.Format("2006-01-02"),
+					RansomwareUse:     kev.RansomwareUse,
+				}
+			}
+		}
+	}
+
+	writeJSON(w, http.StatusOK, resp)
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	enc.Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, msg string) {
+	writeJSON(w, status, map[string]string{"error": msg})
+}
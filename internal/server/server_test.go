@@ -0,0 +1,110 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ethanolivertroy/kev-check-demo/internal/queue"
+)
+
+func TestAuthorize(t *testing.T) {
+	tokens := Config{AdminToken: "admin-secret", ReadOnlyTokens: []string{"viewer-1", "viewer-2"}}
+
+	tests := []struct {
+		name   string
+		config Config
+		method string
+		path   string
+		header string // Authorization header
+		want   int
+	}{
+		{"no token", tokens, "GET", "/v1/reports", "", http.StatusUnauthorized},
+		{"wrong token", tokens, "GET", "/v1/reports", "Bearer nope", http.StatusUnauthorized},
+		{"read-only token", tokens, "GET", "/v1/reports", "Bearer viewer-2", http.StatusOK},
+		{"admin token reads", tokens, "GET", "/v1/reports", "Bearer admin-secret", http.StatusOK},
+		{"basic auth password", tokens, "GET", "/v1/reports", basicAuth("viewer-1"), http.StatusOK},
+		{"read-only token queues", tokens, "POST", "/v1/scans", "Bearer viewer-1", http.StatusForbidden},
+		{"admin token queues", tokens, "POST", "/v1/scans", "Bearer admin-secret", http.StatusAccepted},
+		{"token prefix", tokens, "GET", "/v1/reports", "Bearer viewer", http.StatusUnauthorized},
+		{"open server reads", Config{}, "GET", "/v1/reports", "", http.StatusOK},
+		{"open server queues", Config{}, "POST", "/v1/scans", "", http.StatusForbidden},
+		{"open server ignores tokens", Config{}, "POST", "/v1/scans", "Bearer anything", http.StatusForbidden},
+		{"read-only tokens only", Config{ReadOnlyTokens: []string{"viewer-1"}}, "POST", "/v1/scans", "Bearer viewer-1", http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q, err := queue.Open(t.TempDir())
+			if err != nil {
+				t.Fatal(err)
+			}
+			config := tt.config
+			config.ReportsDir = t.TempDir()
+			config.Queue = q
+
+			req := httptest.NewRequest(tt.method, tt.path, strings.NewReader(`{"paths": ["."]}`))
+			if tt.header != "" {
+				req.Header.Set("Authorization", tt.header)
+			}
+			rec := httptest.NewRecorder()
+			New(config, nil).Handler().ServeHTTP(rec, req)
+			if rec.Code != tt.want {
+				t.Errorf("%s %s = %d, want %d", tt.method, tt.path, rec.Code, tt.want)
+			}
+		})
+	}
+}
+
+func basicAuth(password string) string {
+	req := httptest.NewRequest("GET", "/", nil)
+	req.SetBasicAuth("kev-checker", password)
+	return req.Header.Get("Authorization")
+}
+
+func TestLoadReport(t *testing.T) {
+	root := t.TempDir()
+	dir := filepath.Join(root, "reports")
+	files := map[string]string{
+		filepath.Join(dir, "scan-1.json"):  `{"summary": {"total_kevs": 2}}`,
+		filepath.Join(dir, ".scan-1.json"): `{"summary": {"total_kevs": 2}}`,
+		filepath.Join(root, "secret.json"): `{"summary": {"total_kevs": 2}}`,
+	}
+	for path, content := range files {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	s := New(Config{ReportsDir: dir}, nil)
+
+	tests := []struct {
+		id   string
+		want bool
+	}{
+		{"scan-1", true},
+		{"missing", false},
+		{"", false},
+		{".scan-1", false},
+		{"../secret", false},
+		{`..\secret`, false},
+		{"sub/scan-1", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.id, func(t *testing.T) {
+			report, err := s.loadReport(tt.id)
+			if got := err == nil; got != tt.want {
+				t.Fatalf("loadReport(%q) error = %v, want found %v", tt.id, err, tt.want)
+			}
+			if tt.want && report.Summary.TotalKEVs != 2 {
+				t.Errorf("loadReport(%q) total KEVs = %d, want 2", tt.id, report.Summary.TotalKEVs)
+			}
+		})
+	}
+}
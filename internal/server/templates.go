@@ -0,0 +1,67 @@
+package server
+
+import "html/template"
+
+const pageStyle = `<style>
+body { font-family: system-ui, sans-serif; margin: 2em; color: #222; }
+table { border-collapse: collapse; width: 100%; }
+th, td { text-align: left; padding: .4em .6em; border-bottom: 1px solid #ddd; vertical-align: top; }
+th { background: #f4f4f4; }
+.failed, .ransomware, .breach { color: #b00; font-weight: 600; }
+.muted { color: #777; }
+form { margin: 1em 0; }
+form * { margin-right: .5em; }
+</style>`
+
+var indexTemplate = template.Must(template.New("index").Parse(`<!DOCTYPE html>
+<html><head><meta charset="utf-8"><title>kev-checker reports</title>` + pageStyle + `</head>
+<body>
+<h1>Scan reports</h1>
+{{if not .}}<p class="muted">No reports yet.</p>{{else}}
+<table>
+<tr><th>Scan</th><th>Finished</th><th>Findings</th><th>KEVs</th><th>Ransomware</th><th>SLA breaches</th></tr>
+{{range .}}<tr>
+{{if eq .Status "failed"}}<td>{{.ID}}</td><td>{{.Modified.Format "2006-01-02 15:04 MST"}}</td><td colspan="4" class="failed">Failed: {{.Error}}</td>
+{{else}}<td><a href="/reports/{{.ID}}">{{.ID}}</a></td><td>{{.Modified.Format "2006-01-02 15:04 MST"}}</td>
+<td>{{.Summary.TotalFindings}}</td><td>{{.Summary.TotalKEVs}}</td>
+<td{{if .Summary.RansomwareRelated}} class="ransomware"{{end}}>{{.Summary.RansomwareRelated}}</td>
+<td{{if .Summary.SLABreaches}} class="breach"{{end}}>{{.Summary.SLABreaches}}</td>{{end}}
+</tr>{{end}}
+</table>{{end}}
+</body></html>
+`))
+
+var reportTemplate = template.Must(template.New("report").Parse(`<!DOCTYPE html>
+<html><head><meta charset="utf-8"><title>{{.ID}} - kev-checker</title>` + pageStyle + `</head>
+<body>
+<p><a href="/reports">&larr; All reports</a></p>
+<h1>{{.ID}}</h1>
+<p>{{.Summary.TotalKEVs}} KEVs in {{.Summary.AffectedPackages}} packages &middot;
+{{.Summary.RansomwareRelated}} ransomware-related &middot; {{.Summary.SLABreaches}} SLA breaches &middot;
+{{.Summary.RiskAccepted}} risk-accepted &middot; <a href="/v1/reports/{{.ID}}">JSON</a></p>
+
+<form method="get">
+<input type="search" name="q" value="{{.Filter.Query}}" placeholder="Package, CVE, vendor, file">
+<select name="ecosystem"><option value="">All ecosystems</option>
+{{range .Ecosystems}}<option{{if eq . $.Filter.Ecosystem}} selected{{end}}>{{.}}</option>{{end}}</select>
+{{if .Owners}}<select name="owner"><option value="">All owners</option>
+{{range .Owners}}<option{{if eq . $.Filter.Owner}} selected{{end}}>{{.}}</option>{{end}}</select>{{end}}
+<label><input type="checkbox" name="ransomware" value="1"{{if .Filter.Ransomware}} checked{{end}}> Ransomware only</label>
+<button type="submit">Filter</button>
+</form>
+
+<p class="muted">Showing {{len .Findings}} of {{.Total}} affected packages.</p>
+<table>
+<tr><th>Package</th><th>Source</th><th>CVE</th><th>Vulnerability</th><th>Due</th><th>Open</th><th>Required action</th></tr>
+{{range .Findings}}{{$f := .}}{{range $i, $k := .KEVs}}<tr>
+{{if eq $i 0}}<td rowspan="{{len $f.KEVs}}"><strong>{{$f.Package.Name}}@{{$f.Package.Version}}</strong><br><span class="muted">{{$f.Package.Ecosystem}}</span></td>
+<td rowspan="{{len $f.KEVs}}">{{$f.SourceFile}}{{if $f.Line}}:{{$f.Line}}{{end}}{{range $f.Owners}}<br><span class="muted">{{.}}</span>{{end}}</td>{{end}}
+<td>{{$k.CVEID}}{{if $k.RansomwareUse}}<br><span class="ransomware">ransomware</span>{{end}}{{if $k.RiskAccepted}}<br><span class="muted">accepted: {{$k.RiskAccepted.Reason}}</span>{{end}}</td>
+<td>{{$k.VendorProject}} {{$k.Product}}<br><span class="muted">{{$k.VulnerabilityName}}</span></td>
+<td>{{$k.DueDate}}</td>
+<td{{if $k.SLABreached}} class="breach"{{end}}>{{if $k.DaysOpen}}{{$k.DaysOpen}}d{{end}}</td>
+<td>{{$k.RequiredAction}}</td>
+</tr>{{end}}{{end}}
+</table>
+</body></html>
+`))
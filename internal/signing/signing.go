@@ -0,0 +1,189 @@
+package signing
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// Keyless selects Sigstore keyless signing through the cosign CLI
+const Keyless = "keyless"
+
+// SignatureExt and BundleExt are appended to the report path for the
+// detached signature and the cosign bundle
+const (
+	SignatureExt = ".sig"
+	BundleExt    = ".sigstore.json"
+)
+
+// Sign writes a detached, base64-encoded signature for the file at path to
+// path+".sig" using an unencrypted PEM private key (ECDSA, Ed25519 or RSA).
+// ECDSA signatures are over the SHA-256 digest, matching
+// `cosign verify-blob --key`.
+func Sign(path, keyPath string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read report: %w", err)
+	}
+	key, err := loadPrivateKey(keyPath)
+	if err != nil {
+		return "", err
+	}
+
+	var sig []byte
+	digest := sha256.Sum256(data)
+	switch k := key.(type) {
+	case *ecdsa.PrivateKey:
+		sig, err = ecdsa.SignASN1(rand.Reader, k, digest[:])
+	case ed25519.PrivateKey:
+		sig = ed25519.Sign(k, data)
+	case *rsa.PrivateKey:
+		sig, err = rsa.SignPKCS1v15(rand.Reader, k, crypto.SHA256, digest[:])
+	default:
+		return "", fmt.Errorf("unsupported signing key type %T", key)
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to sign report: %w", err)
+	}
+
+	sigPath := path + SignatureExt
+	if err := os.WriteFile(sigPath, []byte(base64.StdEncoding.EncodeToString(sig)+"\n"), 0644); err != nil {
+		return "", fmt.Errorf("failed to write signature: %w", err)
+	}
+	return sigPath, nil
+}
+
+// Verify checks a detached signature written by Sign against a PEM public key
+func Verify(path, sigPath, pubKeyPath string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read report: %w", err)
+	}
+	encoded, err := os.ReadFile(sigPath)
+	if err != nil {
+		return fmt.Errorf("failed to read signature: %w", err)
+	}
+	sig, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(encoded)))
+	if err != nil {
+		return fmt.Errorf("failed to decode signature: %w", err)
+	}
+	key, err := loadPublicKey(pubKeyPath)
+	if err != nil {
+		return err
+	}
+
+	digest := sha256.Sum256(data)
+	ok := false
+	switch k := key.(type) {
+	case *ecdsa.PublicKey:
+		ok = ecdsa.VerifyASN1(k, digest[:], sig)
+	case ed25519.PublicKey:
+		ok = ed25519.Verify(k, data, sig)
+	case *rsa.PublicKey:
+		ok = rsa.VerifyPKCS1v15(k, crypto.SHA256, digest[:], sig) == nil
+	default:
+		return fmt.Errorf("unsupported public key type %T", key)
+	}
+	if !ok {
+		return errors.New("signature does not match report; it was modified or signed with a different key")
+	}
+	return nil
+}
+
+// SignKeyless signs the file with Sigstore keyless signing, writing a cosign
+// bundle to path+".sigstore.json". Requires cosign on PATH and an OIDC
+// identity (ambient in most CI systems, otherwise via browser).
+func SignKeyless(path string) (string, error) {
+	bundle := path + BundleExt
+	if err := cosign("sign-blob", "--yes", "--bundle", bundle, path); err != nil {
+		return "", err
+	}
+	return bundle, nil
+}
+
+// VerifyKeyless checks a cosign bundle against the expected signer identity
+// and OIDC issuer
+func VerifyKeyless(path, bundle, identity, issuer string) error {
+	return cosign("verify-blob", "--bundle", bundle,
+		"--certificate-identity", identity,
+		"--certificate-oidc-issuer", issuer,
+		path)
+}
+
+// cosign runs the cosign CLI, surfacing its stderr on failure
+func cosign(args ...string) error {
+	cmd := exec.Command("cosign", args...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if errors.Is(err, exec.ErrNotFound) {
+			return errors.New("keyless signing requires cosign on PATH")
+		}
+		return fmt.Errorf("cosign %s: %s", args[0], strings.TrimSpace(stderr.String()))
+	}
+	return nil
+}
+
+func loadPrivateKey(path string) (crypto.Signer, error) {
+	block, err := readPEM(path)
+	if err != nil {
+		return nil, err
+	}
+	if strings.Contains(block.Type, "ENCRYPTED") {
+		return nil, fmt.Errorf("%s is an encrypted key; export an unencrypted PKCS#8 key or use --sign keyless", path)
+	}
+
+	var key any
+	switch block.Type {
+	case "EC PRIVATE KEY":
+		key, err = x509.ParseECPrivateKey(block.Bytes)
+	case "RSA PRIVATE KEY":
+		key, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	default:
+		key, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse signing key: %w", err)
+	}
+	signer, ok := key.(crypto.Signer)
+	if !ok {
+		return nil, fmt.Errorf("unsupported signing key type %T", key)
+	}
+	return signer, nil
+}
+
+func loadPublicKey(path string) (any, error) {
+	block, err := readPEM(path)
+	if err != nil {
+		return nil, err
+	}
+	key, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse public key: %w", err)
+	}
+	return key, nil
+}
+
+func readPEM(path string) (*pem.Block, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read key: %w", err)
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("%s is not a PEM-encoded key", path)
+	}
+	return block, nil
+}
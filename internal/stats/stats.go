@@ -0,0 +1,122 @@
+package stats
+
+import (
+	"sort"
+
+	"github.com/ethanolivertroy/kev-check-demo/internal/models"
+)
+
+// EPSS bands used to bucket KEVs by exploit probability
+const (
+	BandUnscored = "unscored"
+	BandLow      = "<1%"
+	BandModerate = "1-10%"
+	BandHigh     = "10-50%"
+	BandCritical = ">=50%"
+)
+
+// bandOrder lists EPSS bands from lowest to highest
+var bandOrder = []string{BandUnscored, BandLow, BandModerate, BandHigh, BandCritical}
+
+// Stats is an anonymized aggregate of a scan. It deliberately carries no
+// repository, path, package or version names so it can be shared widely.
+type Stats struct {
+	Dependencies      int      `json:"dependencies"`
+	AffectedPackages  int      `json:"affected_packages"`
+	TotalKEVs         int      `json:"total_kevs"`
+	UniqueCVEs        int      `json:"unique_cves"`
+	RansomwareRelated int      `json:"ransomware_related"`
+	RiskAccepted      int      `json:"risk_accepted"`
+	ByEcosystem       []Bucket `json:"by_ecosystem"`
+	ByVendor          []Bucket `json:"by_vendor"`
+	ByCWE             []Bucket `json:"by_cwe"`
+	ByEPSSBand        []Bucket `json:"by_epss_band"`
+}
+
+// Bucket is a count of KEVs for one aggregation key
+type Bucket struct {
+	Key   string `json:"key"`
+	Count int    `json:"count"`
+}
+
+// Aggregate computes anonymized statistics from scan results
+func Aggregate(deps []models.Dependency, findings []models.Finding) Stats {
+	s := Stats{
+		Dependencies:     len(deps),
+		AffectedPackages: len(findings),
+	}
+
+	ecosystems := make(map[string]int)
+	vendors := make(map[string]int)
+	cwes := make(map[string]int)
+	bands := make(map[string]int)
+	unique := make(map[string]bool)
+
+	for _, f := range findings {
+		for _, kev := range f.KEVs {
+			s.TotalKEVs++
+			unique[kev.CVEID] = true
+			if kev.RansomwareUse {
+				s.RansomwareRelated++
+			}
+			if kev.Accepted != nil {
+				s.RiskAccepted++
+			}
+
+			ecosystems[string(f.Dependency.Ecosystem)]++
+			vendor := kev.VendorProject
+			if vendor == "" {
+				vendor = "unknown"
+			}
+			vendors[vendor]++
+			if len(kev.CWEs) == 0 {
+				cwes["unknown"]++
+			}
+			for _, cwe := range kev.CWEs {
+				cwes[cwe]++
+			}
+			bands[EPSSBand(kev)]++
+		}
+	}
+	s.UniqueCVEs = len(unique)
+
+	s.ByEcosystem = SortedBuckets(ecosystems)
+	s.ByVendor = SortedBuckets(vendors)
+	s.ByCWE = SortedBuckets(cwes)
+	for _, band := range bandOrder {
+		s.ByEPSSBand = append(s.ByEPSSBand, Bucket{Key: band, Count: bands[band]})
+	}
+
+	return s
+}
+
+// EPSSBand returns the EPSS band for a KEV
+func EPSSBand(kev models.KEVInfo) string {
+	switch {
+	case kev.EPSSScore <= 0:
+		return BandUnscored
+	case kev.EPSSScore < 0.01:
+		return BandLow
+	case kev.EPSSScore < 0.10:
+		return BandModerate
+	case kev.EPSSScore < 0.50:
+		return BandHigh
+	default:
+		return BandCritical
+	}
+}
+
+// SortedBuckets returns the counts as buckets ordered by count descending, then key
+func SortedBuckets(counts map[string]int) []Bucket {
+	buckets := make([]Bucket, 0, len(counts))
+	for k, c := range counts {
+		buckets = append(buckets, Bucket{Key: k, Count: c})
+	}
+	sort.Slice(buckets, func(i, j int) bool {
+		if buckets[i].Count != buckets[j].Count {
+			return buckets[i].Count > buckets[j].Count
+		}
+		return buckets[i].Key < buckets[j].Key
+	})
+	return buckets
+}
@@ -0,0 +1,205 @@
+package summary
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/ethanolivertroy/kev-check-demo/internal/clients"
+	"github.com/ethanolivertroy/kev-check-demo/internal/history"
+	"github.com/ethanolivertroy/kev-check-demo/internal/models"
+)
+
+// Exit reasons recorded alongside the exit code
+const (
+	ReasonClean       = "clean"
+	ReasonKEVsFound   = "kevs_found"
+	ReasonNoFix       = "kevs_no_fix"
+	ReasonViolations  = "policy_violation"
+	ReasonPartialData = "partial_data"
+	ReasonError       = "error"
+)
+
+// Summary is a machine-readable record of a scan run, written separately from
+// the findings report for dashboards that only need metrics
+type Summary struct {
+	StartedAt       time.Time        `json:"started_at,omitzero"`
+	DurationSeconds float64          `json:"duration_seconds,omitzero"`
+	ExitCode        int              `json:"exit_code"`
+	ExitReason      string           `json:"exit_reason"`
+	Error           string           `json:"error,omitempty"`
+	Dependencies    DependencyCounts `json:"dependencies"`
+	Findings        FindingCounts    `json:"findings"`
+	DataSources     DataSources      `json:"data_sources"`
+	// Lifecycle counts KEV findings by lifecycle state in the history store:
+	// new, recurring, accepted and suppressed as of this run, and resolved
+	// by it
+	Lifecycle map[history.State]int `json:"lifecycle,omitempty"`
+	// ParseErrors lists manifests whose dependencies are missing
+	ParseErrors []models.ParseError `json:"parse_errors,omitempty"`
+
+	noTimestamps bool
+}
+
+// DependencyCounts summarizes the scanned dependencies
+type DependencyCounts struct {
+	Total       int            `json:"total"`
+	Manifests   int            `json:"manifests"`
+	ByEcosystem map[string]int `json:"by_ecosystem"`
+	// Local counts dependencies built from the project's own source, which
+	// aren't checked
+	Local int `json:"local,omitempty"`
+	// OverBudget counts registry dependencies left unchecked by the query
+	// budget
+	OverBudget int `json:"over_budget,omitempty"`
+}
+
+// FindingCounts summarizes the findings
+type FindingCounts struct {
+	AffectedPackages  int `json:"affected_packages"`
+	TotalKEVs         int `json:"total_kevs"`
+	RansomwareRelated int `json:"ransomware_related"`
+	RiskAccepted      int `json:"risk_accepted"`
+	SLABreaches       int `json:"sla_breaches"`
+	Potential         int `json:"potential"`
+	NoFix             int `json:"no_fix"`
+	Violations        int `json:"violations"`
+	// BySeverity counts KEVs by normalized severity
+	BySeverity map[models.Severity]int `json:"by_severity,omitempty"`
+}
+
+// DataSources records which data was used and whether any source failed
+type DataSources struct {
+	KEVCatalogVersion  string                 `json:"kev_catalog_version,omitempty"`
+	KEVCatalogReleased string                 `json:"kev_catalog_released,omitempty"`
+	KEVCatalogCount    int                    `json:"kev_catalog_count,omitempty"`
+	Sources            []string               `json:"sources"`
+	Failures           []models.SourceFailure `json:"failures,omitempty"`
+}
+
+// New starts a summary for a run beginning now
+func New() *Summary {
+	return &Summary{
+		StartedAt:    time.Now().UTC(),
+		Dependencies: DependencyCounts{ByEcosystem: map[string]int{}},
+	}
+}
+
+// SetDependencies records dependency counts per ecosystem and manifest
+func (s *Summary) SetDependencies(deps []models.Dependency) {
+	manifests := make(map[string]bool)
+	for _, dep := range deps {
+		s.Dependencies.ByEcosystem[string(dep.Ecosystem)]++
+		for _, file := range dep.Files() {
+			manifests[file] = true
+		}
+		if dep.Local != "" {
+			s.Dependencies.Local++
+		}
+	}
+	s.Dependencies.Total = len(deps)
+	s.Dependencies.Manifests = len(manifests)
+}
+
+// SetOverBudget records the dependencies the query budget left unchecked
+func (s *Summary) SetOverBudget(deps []models.Dependency) {
+	s.Dependencies.OverBudget = len(deps)
+}
+
+// SetFindings records finding counts
+func (s *Summary) SetFindings(findings []models.Finding) {
+	s.Findings = FindingCounts{AffectedPackages: len(findings)}
+	for _, f := range findings {
+		if f.Potential() {
+			s.Findings.Potential++
+		}
+		if f.Effort == models.EffortNoFix {
+			s.Findings.NoFix++
+		}
+		for _, kev := range f.KEVs {
+			s.Findings.TotalKEVs++
+			if kev.RansomwareUse {
+				s.Findings.RansomwareRelated++
+			}
+			if kev.Accepted != nil {
+				s.Findings.RiskAccepted++
+			}
+			if kev.SLABreached() {
+				s.Findings.SLABreaches++
+			}
+			if kev.Severity != "" {
+				if s.Findings.BySeverity == nil {
+					s.Findings.BySeverity = make(map[models.Severity]int)
+				}
+				s.Findings.BySeverity[kev.Severity]++
+			}
+		}
+	}
+}
+
+// SetLifecycle records the number of findings in each lifecycle state
+func (s *Summary) SetLifecycle(counts map[history.State]int) {
+	if len(counts) == 0 {
+		return
+	}
+	s.Lifecycle = make(map[history.State]int, len(history.States))
+	for _, state := range history.States {
+		s.Lifecycle[state] = counts[state]
+	}
+}
+
+// SetViolations records the number of deny-listed dependencies
+func (s *Summary) SetViolations(violations []models.Violation) {
+	s.Findings.Violations = len(violations)
+}
+
+// SetParseErrors records manifests that could not be read or parsed
+func (s *Summary) SetParseErrors(errs []models.ParseError) {
+	s.ParseErrors = errs
+}
+
+// SetDataSources records the KEV catalog release and vulnerability sources
+func (s *Summary) SetDataSources(info clients.KEVCatalogInfo, sources []string, failures []models.SourceFailure) {
+	sorted := append([]string(nil), sources...)
+	sort.Strings(sorted)
+	s.DataSources = DataSources{
+		KEVCatalogVersion:  info.Version,
+		KEVCatalogReleased: info.DateReleased,
+		KEVCatalogCount:    info.Count,
+		Sources:            sorted,
+		Failures:           failures,
+	}
+}
+
+// OmitTimestamps leaves out the start time and duration, so the same run
+// always produces the same summary
+func (s *Summary) OmitTimestamps() {
+	s.noTimestamps = true
+	s.StartedAt = time.Time{}
+}
+
+// Finish records the exit code and reason and the run duration
+func (s *Summary) Finish(code int, reason string, err error) {
+	s.ExitCode = code
+	s.ExitReason = reason
+	if err != nil {
+		s.Error = err.Error()
+	}
+	if !s.noTimestamps {
+		s.DurationSeconds = time.Since(s.StartedAt).Seconds()
+	}
+}
+
+// Write saves the summary as indented JSON
+func (s *Summary) Write(path string) error {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write summary file: %w", err)
+	}
+	return nil
+}
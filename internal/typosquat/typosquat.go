@@ -0,0 +1,174 @@
+package typosquat
+
+import (
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/ethanolivertroy/kev-check-demo/internal/models"
+)
+
+// popular is a curated list of widely used packages (including ones with
+// KEV history) that are common typosquatting targets
+var popular = map[models.Ecosystem][]string{
+	models.EcosystemPyPI: {
+		"requests", "django", "flask", "urllib3", "numpy", "pandas", "pillow",
+		"setuptools", "cryptography", "pyyaml", "jinja2", "boto3", "botocore",
+		"certifi", "idna", "six", "python-dateutil", "colorama", "beautifulsoup4",
+		"sqlalchemy", "paramiko", "pycryptodome", "tensorflow", "torch",
+		"scikit-learn", "matplotlib", "pytest", "fastapi", "uvicorn", "aiohttp",
+	},
+	models.EcosystemNpm: {
+		"lodash", "express", "react", "react-dom", "axios", "moment", "chalk",
+		"commander", "debug", "request", "jquery", "vue", "webpack", "typescript",
+		"eslint", "babel-core", "underscore", "async", "uuid", "colors",
+		"cross-env", "dotenv", "mongoose", "socket.io", "electron", "node-fetch",
+		"minimist", "yargs", "body-parser", "jsonwebtoken",
+	},
+	models.EcosystemGo: {
+		"github.com/sirupsen/logrus", "github.com/spf13/cobra", "github.com/gin-gonic/gin",
+		"github.com/gorilla/mux", "github.com/stretchr/testify", "github.com/pkg/errors",
+		"github.com/golang-jwt/jwt", "github.com/google/uuid", "golang.org/x/crypto",
+		"golang.org/x/net", "google.golang.org/grpc", "gopkg.in/yaml.v3",
+	},
+}
+
+// legitimate are established packages within edit distance of a popular one
+// that the distance rules can't tell apart from a typosquat
+var legitimate = map[models.Ecosystem][]string{
+	models.EcosystemPyPI: {"pyaml"},
+	models.EcosystemNpm:  {"preact", "tslint"},
+	models.EcosystemGo:   {"golang.org/x/text"},
+}
+
+// goMajorVersion matches the major version suffix of a Go module path, as in
+// github.com/golang-jwt/jwt/v5 or gopkg.in/yaml.v2
+var goMajorVersion = regexp.MustCompile(`(/v[0-9]+|\.v[0-9]+)$`)
+
+// Check returns a warning for each dependency whose name is a near miss of a
+// popular package in the same ecosystem
+func Check(deps []models.Dependency) []models.Warning {
+	var warnings []models.Warning
+	seen := make(map[string]bool)
+
+	for _, dep := range deps {
+		key := string(dep.Ecosystem) + "/" + dep.Name
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+
+		if target, ok := lookalike(dep.Ecosystem, dep.Name); ok {
+			warnings = append(warnings, models.Warning{
+				Kind:       models.WarningTyposquat,
+				Dependency: dep,
+				Message:    "name resembles popular package " + target + "; possible typosquat",
+			})
+		}
+	}
+
+	return warnings
+}
+
+// lookalike returns the popular package the name is suspiciously close to
+func lookalike(eco models.Ecosystem, name string) (string, bool) {
+	candidates := popular[eco]
+	lower := strings.ToLower(name)
+
+	if slices.Contains(candidates, lower) || slices.Contains(legitimate[eco], lower) {
+		return "", false
+	}
+	if eco == models.EcosystemGo {
+		// Other major versions of a popular module are the same project
+		for _, target := range candidates {
+			if goMajorVersion.ReplaceAllString(lower, "") == goMajorVersion.ReplaceAllString(target, "") {
+				return "", false
+			}
+		}
+	}
+
+	norm := normalize(lower)
+	for _, target := range candidates {
+		tnorm := normalize(target)
+		if norm == tnorm {
+			// Separator-only differences are legitimate on PyPI
+			if eco == models.EcosystemPyPI {
+				continue
+			}
+			return target, true
+		}
+		if distance(norm, tnorm) <= maxDistance(tnorm) && !extends(norm, tnorm) {
+			return target, true
+		}
+	}
+
+	return "", false
+}
+
+// extends reports whether name only differs from target at its end: a suffix
+// added (torchx, pycryptodomex), the last letter changed (colord) or dropped
+// (boto). Such names are usually separate projects, while typosquats change
+// the middle of a name or repeat a letter (expresss, expres).
+func extends(name, target string) bool {
+	p := 0
+	for p < len(name) && p < len(target) && name[p] == target[p] {
+		p++
+	}
+	last := target[len(target)-1]
+	switch {
+	case p == len(target):
+		return name[p] != last
+	case p == len(name) && len(name) == len(target)-1:
+		return len(target) < 2 || target[len(target)-2] != last
+	case p == len(target)-1 && len(name) == len(target):
+		return true
+	}
+	return false
+}
+
+// maxDistance scales the allowed edit distance with name length so short
+// names don't match everything
+func maxDistance(name string) int {
+	switch {
+	case len(name) < 5:
+		return 0
+	case len(name) < 10:
+		return 1
+	default:
+		return 2
+	}
+}
+
+// normalize strips separators that typosquatters commonly add or drop
+func normalize(name string) string {
+	return strings.NewReplacer("-", "", "_", "", ".", "").Replace(name)
+}
+
+// distance computes the optimal string alignment distance (Levenshtein plus
+// adjacent transpositions) between a and b
+func distance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	d := make([][]int, len(ra)+1)
+	for i := range d {
+		d[i] = make([]int, len(rb)+1)
+		d[i][0] = i
+	}
+	for j := range d[0] {
+		d[0][j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			d[i][j] = min(d[i-1][j]+1, d[i][j-1]+1, d[i-1][j-1]+cost)
+			if i > 1 && j > 1 && ra[i-1] == rb[j-2] && ra[i-2] == rb[j-1] {
+				d[i][j] = min(d[i][j], d[i-2][j-2]+1)
+			}
+		}
+	}
+
+	return d[len(ra)][len(rb)]
+}
@@ -0,0 +1,63 @@
+package typosquat
+
+import (
+	"testing"
+
+	"github.com/ethanolivertroy/kev-check-demo/internal/models"
+)
+
+func TestLookalike(t *testing.T) {
+	tests := []struct {
+		eco  models.Ecosystem
+		name string
+		want string // Empty if the name isn't reported
+	}{
+		// Typosquats: swapped, dropped, repeated or separated letters
+		{models.EcosystemPyPI, "reqeusts", "requests"},
+		{models.EcosystemPyPI, "djnago", "django"},
+		{models.EcosystemPyPI, "urlib3", "urllib3"},
+		{models.EcosystemPyPI, "cryptograpy", "cryptography"},
+		{models.EcosystemNpm, "lodahs", "lodash"},
+		{models.EcosystemNpm, "expresss", "express"},
+		{models.EcosystemNpm, "expres", "express"},
+		{models.EcosystemNpm, "crossenv", "cross-env"},
+		{models.EcosystemNpm, "cross_env", "cross-env"},
+		{models.EcosystemNpm, "axois", "axios"},
+		{models.EcosystemGo, "github.com/sirupsen/logurs", "github.com/sirupsen/logrus"},
+
+		// Popular packages themselves
+		{models.EcosystemPyPI, "Requests", ""},
+		{models.EcosystemNpm, "express", ""},
+
+		// Separate projects that extend or shorten a popular name
+		{models.EcosystemPyPI, "pycryptodomex", ""},
+		{models.EcosystemPyPI, "torchx", ""},
+		{models.EcosystemPyPI, "boto", ""},
+		{models.EcosystemPyPI, "jinja", ""},
+		{models.EcosystemNpm, "colord", ""},
+		{models.EcosystemNpm, "color", ""},
+		{models.EcosystemNpm, "expresso", ""},
+
+		// Unrelated or listed as legitimate
+		{models.EcosystemPyPI, "numba", ""},
+		{models.EcosystemPyPI, "pyaml", ""},
+		{models.EcosystemNpm, "preact", ""},
+
+		// Separator variants are the same project on PyPI
+		{models.EcosystemPyPI, "python_dateutil", ""},
+		{models.EcosystemPyPI, "scikit_learn", ""},
+
+		// Other major versions of a popular Go module
+		{models.EcosystemGo, "github.com/golang-jwt/jwt/v5", ""},
+		{models.EcosystemGo, "gopkg.in/yaml.v2", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.eco)+"/"+tt.name, func(t *testing.T) {
+			got, ok := lookalike(tt.eco, tt.name)
+			if got != tt.want || ok != (tt.want != "") {
+				t.Errorf("lookalike(%s, %q) = %q, %t, want %q", tt.eco, tt.name, got, ok, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,217 @@
+package upload
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+)
+
+const (
+	gcsUploadURL = "https://storage.googleapis.com/upload/storage/v1/b/%s/o?uploadType=media&name=%s"
+	gcsScope     = "https://www.googleapis.com/auth/devstorage.read_write"
+)
+
+var (
+	gcsMetadataURL = "http://metadata.google.internal/computeMetadata/v1/instance/service-accounts/default/token"
+	gcpTokenURL    = "https://oauth2.googleapis.com/token"
+)
+
+// uploadGCS uploads an object to Google Cloud Storage
+func uploadGCS(client *http.Client, bucket, key string, data []byte, contentType string) error {
+	token, err := gcpAccessToken(client)
+	if err != nil {
+		return err
+	}
+
+	u := fmt.Sprintf(gcsUploadURL, url.PathEscape(bucket), url.QueryEscape(key))
+	req, err := http.NewRequest(http.MethodPost, u, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", contentType)
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to upload to GCS: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("GCS returned status %d: %s", resp.StatusCode, strings.TrimSpace(string(msg)))
+	}
+	return nil
+}
+
+// gcpAccessToken resolves an OAuth access token as Application Default
+// Credentials do: GOOGLE_OAUTH_ACCESS_TOKEN, the credentials file in
+// GOOGLE_APPLICATION_CREDENTIALS or gcloud's well-known location, then the
+// GCE metadata server
+func gcpAccessToken(client *http.Client) (string, error) {
+	if token := os.Getenv("GOOGLE_OAUTH_ACCESS_TOKEN"); token != "" {
+		return token, nil
+	}
+
+	if path := os.Getenv("GOOGLE_APPLICATION_CREDENTIALS"); path != "" {
+		return credentialsFileToken(client, path)
+	}
+	if path := gcloudCredentialsPath(); path != "" {
+		if _, err := os.Stat(path); err == nil {
+			return credentialsFileToken(client, path)
+		}
+	}
+
+	req, _ := http.NewRequest(http.MethodGet, gcsMetadataURL, nil)
+	req.Header.Set("Metadata-Flavor", "Google")
+	c := &http.Client{Timeout: metadataTimeout, Transport: client.Transport}
+	body, err := readBody(c, req)
+	if err != nil {
+		return "", fmt.Errorf("no GCP credentials found (set GOOGLE_APPLICATION_CREDENTIALS, run gcloud auth application-default login or set GOOGLE_OAUTH_ACCESS_TOKEN)")
+	}
+	return parseTokenResponse(body)
+}
+
+// gcloudCredentialsPath returns where gcloud auth application-default login
+// stores credentials
+func gcloudCredentialsPath() string {
+	if dir := os.Getenv("CLOUDSDK_CONFIG"); dir != "" {
+		return filepath.Join(dir, "application_default_credentials.json")
+	}
+	if runtime.GOOS == "windows" {
+		if dir := os.Getenv("APPDATA"); dir != "" {
+			return filepath.Join(dir, "gcloud", "application_default_credentials.json")
+		}
+		return ""
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".config", "gcloud", "application_default_credentials.json")
+}
+
+// gcpCredentials is a credentials file: a service account key or the user
+// credentials written by gcloud
+type gcpCredentials struct {
+	Type         string `json:"type"`
+	ClientEmail  string `json:"client_email"`
+	PrivateKey   string `json:"private_key"`
+	TokenURI     string `json:"token_uri"`
+	ClientID     string `json:"client_id"`
+	ClientSecret string `json:"client_secret"`
+	RefreshToken string `json:"refresh_token"`
+}
+
+// credentialsFileToken obtains an access token with the credentials file at
+// path
+func credentialsFileToken(client *http.Client, path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read GCP credentials: %w", err)
+	}
+
+	var creds gcpCredentials
+	if err := json.Unmarshal(data, &creds); err != nil {
+		return "", fmt.Errorf("failed to parse GCP credentials: %w", err)
+	}
+	if creds.TokenURI == "" {
+		creds.TokenURI = gcpTokenURL
+	}
+
+	switch creds.Type {
+	case "service_account":
+		return serviceAccountToken(client, creds)
+	case "authorized_user":
+		return requestToken(client, creds.TokenURI, url.Values{
+			"grant_type":    {"refresh_token"},
+			"client_id":     {creds.ClientID},
+			"client_secret": {creds.ClientSecret},
+			"refresh_token": {creds.RefreshToken},
+		})
+	default:
+		return "", fmt.Errorf("unsupported GCP credentials type %q in %s (expected service_account or authorized_user)", creds.Type, path)
+	}
+}
+
+// serviceAccountToken exchanges a signed JWT for an access token
+func serviceAccountToken(client *http.Client, sa gcpCredentials) (string, error) {
+	block, _ := pem.Decode([]byte(sa.PrivateKey))
+	if block == nil {
+		return "", fmt.Errorf("invalid private key in GCP credentials")
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return "", fmt.Errorf("invalid private key in GCP credentials: %w", err)
+	}
+	key, ok := parsed.(*rsa.PrivateKey)
+	if !ok {
+		return "", fmt.Errorf("GCP credentials key is not RSA")
+	}
+
+	now := time.Now()
+	header := base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"RS256","typ":"JWT"}`))
+	claims, _ := json.Marshal(map[string]interface{}{
+		"iss":   sa.ClientEmail,
+		"scope": gcsScope,
+		"aud":   sa.TokenURI,
+		"iat":   now.Unix(),
+		"exp":   now.Add(time.Hour).Unix(),
+	})
+	unsigned := header + "." + base64.RawURLEncoding.EncodeToString(claims)
+
+	digest := sha256.Sum256([]byte(unsigned))
+	sig, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+	if err != nil {
+		return "", err
+	}
+	assertion := unsigned + "." + base64.RawURLEncoding.EncodeToString(sig)
+
+	return requestToken(client, sa.TokenURI, url.Values{
+		"grant_type": {"urn:ietf:params:oauth:grant-type:jwt-bearer"},
+		"assertion":  {assertion},
+	})
+}
+
+// requestToken posts a grant to an OAuth token endpoint
+func requestToken(client *http.Client, tokenURI string, grant url.Values) (string, error) {
+	resp, err := client.PostForm(tokenURI, grant)
+	if err != nil {
+		return "", fmt.Errorf("failed to fetch GCP access token: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("GCP token endpoint returned status %d", resp.StatusCode)
+	}
+	return parseTokenResponse(body)
+}
+
+func parseTokenResponse(body []byte) (string, error) {
+	var tok struct {
+		AccessToken string `json:"access_token"`
+	}
+	if err := json.Unmarshal(body, &tok); err != nil || tok.AccessToken == "" {
+		return "", fmt.Errorf("invalid token response")
+	}
+	return tok.AccessToken, nil
+}
@@ -0,0 +1,397 @@
+package upload
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// awsCredentials are static or temporary AWS credentials
+type awsCredentials struct {
+	AccessKeyID     string
+	SecretAccessKey string
+	SessionToken    string
+}
+
+// uploadS3 PUTs an object to S3 signed with SigV4
+func uploadS3(client *http.Client, bucket, key string, data []byte, contentType string) error {
+	region := awsRegion()
+	creds, err := awsCredentialChain(client, region)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodPut, s3URL(bucket, region, key), bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", contentType)
+	payloadHash := sha256Hex(data)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+	signSigV4(req, creds, region, "s3", payloadHash, time.Now().UTC())
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to upload to S3: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("S3 returned status %d: %s", resp.StatusCode, strings.TrimSpace(string(msg)))
+	}
+	return nil
+}
+
+// awsRegion returns the configured region, defaulting to us-east-1
+func awsRegion() string {
+	if region := os.Getenv("AWS_REGION"); region != "" {
+		return region
+	}
+	if region := os.Getenv("AWS_DEFAULT_REGION"); region != "" {
+		return region
+	}
+	return "us-east-1"
+}
+
+// s3URL returns the URL of an object. Bucket names with dots use path-style
+// addressing, since the bucket's virtual host doesn't match the S3 wildcard
+// certificate.
+func s3URL(bucket, region, key string) string {
+	if strings.Contains(bucket, ".") {
+		return fmt.Sprintf("https://s3.%s.amazonaws.com/%s/%s", region, bucket, encodeS3Key(key))
+	}
+	return fmt.Sprintf("https://%s.s3.%s.amazonaws.com/%s", bucket, region, encodeS3Key(key))
+}
+
+// encodeS3Key URI-encodes each segment of an object key
+func encodeS3Key(key string) string {
+	segments := strings.Split(key, "/")
+	for i, s := range segments {
+		segments[i] = strings.ReplaceAll(url.PathEscape(s), "+", "%2B")
+	}
+	return strings.Join(segments, "/")
+}
+
+// signSigV4 adds AWS Signature Version 4 headers to req, signing every
+// header already set on it. payloadHash is the hex SHA-256 of the body.
+func signSigV4(req *http.Request, creds awsCredentials, region, service, payloadHash string, now time.Time) {
+	amzDate := now.Format("20060102T150405Z")
+	date := now.Format("20060102")
+
+	req.Header.Set("Host", req.URL.Host)
+	req.Header.Set("X-Amz-Date", amzDate)
+	if creds.SessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", creds.SessionToken)
+	}
+
+	var names []string
+	for name := range req.Header {
+		names = append(names, strings.ToLower(name))
+	}
+	sort.Strings(names)
+
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + strings.TrimSpace(req.Header.Get(name)) + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		canonicalQuery(req.URL.Query()),
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope := fmt.Sprintf("%s/%s/%s/aws4_request", date, region, service)
+	stringToSign := strings.Join([]string{
+		"AWS4-HMAC-SHA256",
+		amzDate,
+		scope,
+		sha256Hex([]byte(canonicalRequest)),
+	}, "\n")
+
+	signingKey := hmacSHA256([]byte("AWS4"+creds.SecretAccessKey), date)
+	signingKey = hmacSHA256(signingKey, region)
+	signingKey = hmacSHA256(signingKey, service)
+	signingKey = hmacSHA256(signingKey, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(signingKey, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		creds.AccessKeyID, scope, signedHeaders, signature))
+}
+
+// canonicalQuery encodes query parameters sorted by name, then value, as
+// SigV4 requires
+func canonicalQuery(query url.Values) string {
+	var params []string
+	for name, values := range query {
+		for _, v := range values {
+			params = append(params, awsEscape(name)+"="+awsEscape(v))
+		}
+	}
+	sort.Strings(params)
+	return strings.Join(params, "&")
+}
+
+// awsEscape percent-encodes everything but unreserved characters
+func awsEscape(s string) string {
+	return strings.ReplaceAll(url.QueryEscape(s), "+", "%20")
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+// awsCredentialChain resolves credentials in the order of the AWS SDKs:
+// environment variables, the shared credentials file, a web identity token
+// (EKS IAM roles for service accounts), the ECS or EKS Pod Identity container
+// endpoint, then the EC2 instance metadata service
+func awsCredentialChain(client *http.Client, region string) (awsCredentials, error) {
+	if id := os.Getenv("AWS_ACCESS_KEY_ID"); id != "" {
+		return awsCredentials{
+			AccessKeyID:     id,
+			SecretAccessKey: os.Getenv("AWS_SECRET_ACCESS_KEY"),
+			SessionToken:    os.Getenv("AWS_SESSION_TOKEN"),
+		}, nil
+	}
+
+	if creds, ok := awsSharedCredentials(); ok {
+		return creds, nil
+	}
+
+	if os.Getenv("AWS_WEB_IDENTITY_TOKEN_FILE") != "" && os.Getenv("AWS_ROLE_ARN") != "" {
+		return awsWebIdentityCredentials(client, region)
+	}
+
+	if os.Getenv("AWS_CONTAINER_CREDENTIALS_RELATIVE_URI") != "" || os.Getenv("AWS_CONTAINER_CREDENTIALS_FULL_URI") != "" {
+		return awsContainerCredentials(client)
+	}
+
+	if creds, err := awsInstanceCredentials(client); err == nil {
+		return creds, nil
+	}
+
+	return awsCredentials{}, fmt.Errorf("no AWS credentials found (set AWS_ACCESS_KEY_ID or configure ~/.aws/credentials)")
+}
+
+// awsSharedCredentials reads the profile from the shared credentials file
+func awsSharedCredentials() (awsCredentials, bool) {
+	path := os.Getenv("AWS_SHARED_CREDENTIALS_FILE")
+	if path == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return awsCredentials{}, false
+		}
+		path = filepath.Join(home, ".aws", "credentials")
+	}
+	profile := os.Getenv("AWS_PROFILE")
+	if profile == "" {
+		profile = "default"
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return awsCredentials{}, false
+	}
+	defer f.Close()
+
+	var creds awsCredentials
+	inProfile := false
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			inProfile = strings.TrimSpace(line[1:len(line)-1]) == profile
+			continue
+		}
+		if !inProfile {
+			continue
+		}
+		k, v, ok := strings.Cut(line, "=")
+		if !ok {
+			continue
+		}
+		switch strings.TrimSpace(k) {
+		case "aws_access_key_id":
+			creds.AccessKeyID = strings.TrimSpace(v)
+		case "aws_secret_access_key":
+			creds.SecretAccessKey = strings.TrimSpace(v)
+		case "aws_session_token":
+			creds.SessionToken = strings.TrimSpace(v)
+		}
+	}
+
+	return creds, creds.AccessKeyID != "" && creds.SecretAccessKey != ""
+}
+
+var (
+	imdsURL         = "http://169.254.169.254/latest"
+	ecsURL          = "http://169.254.170.2"
+	stsEndpointURL  = "https://sts.%s.amazonaws.com/"
+	metadataTimeout = 2 * time.Second
+)
+
+// awsInstanceCredentials fetches role credentials from IMDSv2
+func awsInstanceCredentials(client *http.Client) (awsCredentials, error) {
+	c := &http.Client{Timeout: metadataTimeout, Transport: client.Transport}
+
+	tokenReq, _ := http.NewRequest(http.MethodPut, imdsURL+"/api/token", nil)
+	tokenReq.Header.Set("X-aws-ec2-metadata-token-ttl-seconds", "300")
+	token, err := readBody(c, tokenReq)
+	if err != nil {
+		return awsCredentials{}, err
+	}
+
+	roleReq, _ := http.NewRequest(http.MethodGet, imdsURL+"/meta-data/iam/security-credentials/", nil)
+	roleReq.Header.Set("X-aws-ec2-metadata-token", string(token))
+	role, err := readBody(c, roleReq)
+	if err != nil {
+		return awsCredentials{}, err
+	}
+
+	credReq, _ := http.NewRequest(http.MethodGet, imdsURL+"/meta-data/iam/security-credentials/"+strings.TrimSpace(string(role)), nil)
+	credReq.Header.Set("X-aws-ec2-metadata-token", string(token))
+	body, err := readBody(c, credReq)
+	if err != nil {
+		return awsCredentials{}, err
+	}
+	return parseRoleCredentials(body)
+}
+
+// awsContainerCredentials fetches task or pod role credentials from the ECS
+// container endpoint (AWS_CONTAINER_CREDENTIALS_RELATIVE_URI) or a full URI
+// such as the EKS Pod Identity agent's, with its authorization token
+func awsContainerCredentials(client *http.Client) (awsCredentials, error) {
+	u := os.Getenv("AWS_CONTAINER_CREDENTIALS_FULL_URI")
+	if rel := os.Getenv("AWS_CONTAINER_CREDENTIALS_RELATIVE_URI"); rel != "" {
+		u = ecsURL + rel
+	}
+	req, err := http.NewRequest(http.MethodGet, u, nil)
+	if err != nil {
+		return awsCredentials{}, fmt.Errorf("invalid container credentials URI: %w", err)
+	}
+
+	token := os.Getenv("AWS_CONTAINER_AUTHORIZATION_TOKEN")
+	if path := os.Getenv("AWS_CONTAINER_AUTHORIZATION_TOKEN_FILE"); path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return awsCredentials{}, fmt.Errorf("failed to read container authorization token: %w", err)
+		}
+		token = strings.TrimSpace(string(data))
+	}
+	if token != "" {
+		req.Header.Set("Authorization", token)
+	}
+
+	c := &http.Client{Timeout: metadataTimeout, Transport: client.Transport}
+	body, err := readBody(c, req)
+	if err != nil {
+		return awsCredentials{}, fmt.Errorf("failed to fetch container credentials: %w", err)
+	}
+	return parseRoleCredentials(body)
+}
+
+// parseRoleCredentials reads the credentials document served by the
+// instance metadata service and container endpoints
+func parseRoleCredentials(body []byte) (awsCredentials, error) {
+	var out struct {
+		AccessKeyID     string `json:"AccessKeyId"`
+		SecretAccessKey string `json:"SecretAccessKey"`
+		Token           string `json:"Token"`
+	}
+	if err := json.Unmarshal(body, &out); err != nil {
+		return awsCredentials{}, fmt.Errorf("invalid credentials response: %w", err)
+	}
+	if out.AccessKeyID == "" || out.SecretAccessKey == "" {
+		return awsCredentials{}, fmt.Errorf("credentials response has no access key")
+	}
+	return awsCredentials{AccessKeyID: out.AccessKeyID, SecretAccessKey: out.SecretAccessKey, SessionToken: out.Token}, nil
+}
+
+// awsWebIdentityCredentials exchanges the token in AWS_WEB_IDENTITY_TOKEN_FILE
+// for temporary credentials of AWS_ROLE_ARN through STS
+func awsWebIdentityCredentials(client *http.Client, region string) (awsCredentials, error) {
+	token, err := os.ReadFile(os.Getenv("AWS_WEB_IDENTITY_TOKEN_FILE"))
+	if err != nil {
+		return awsCredentials{}, fmt.Errorf("failed to read web identity token: %w", err)
+	}
+	session := os.Getenv("AWS_ROLE_SESSION_NAME")
+	if session == "" {
+		session = fmt.Sprintf("kev-checker-%d", time.Now().Unix())
+	}
+
+	resp, err := client.PostForm(fmt.Sprintf(stsEndpointURL, region), url.Values{
+		"Action":           {"AssumeRoleWithWebIdentity"},
+		"Version":          {"2011-06-15"},
+		"RoleArn":          {os.Getenv("AWS_ROLE_ARN")},
+		"RoleSessionName":  {session},
+		"WebIdentityToken": {strings.TrimSpace(string(token))},
+	})
+	if err != nil {
+		return awsCredentials{}, fmt.Errorf("failed to assume role with web identity: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return awsCredentials{}, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return awsCredentials{}, fmt.Errorf("STS returned status %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	var out struct {
+		Credentials struct {
+			AccessKeyID     string `xml:"AccessKeyId"`
+			SecretAccessKey string `xml:"SecretAccessKey"`
+			SessionToken    string `xml:"SessionToken"`
+		} `xml:"AssumeRoleWithWebIdentityResult>Credentials"`
+	}
+	if err := xml.Unmarshal(body, &out); err != nil {
+		return awsCredentials{}, fmt.Errorf("invalid STS response: %w", err)
+	}
+	if out.Credentials.AccessKeyID == "" {
+		return awsCredentials{}, fmt.Errorf("STS response has no credentials")
+	}
+	return awsCredentials{
+		AccessKeyID:     out.Credentials.AccessKeyID,
+		SecretAccessKey: out.Credentials.SecretAccessKey,
+		SessionToken:    out.Credentials.SessionToken,
+	}, nil
+}
+
+func readBody(client *http.Client, req *http.Request) ([]byte, error) {
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("status %d", resp.StatusCode)
+	}
+	return io.ReadAll(resp.Body)
+}
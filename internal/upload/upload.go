@@ -0,0 +1,54 @@
+package upload
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// IsRemote returns true if the output path is an object storage URL
+func IsRemote(path string) bool {
+	return strings.HasPrefix(path, "s3://") || strings.HasPrefix(path, "gs://")
+}
+
+// Upload writes data to an s3:// or gs:// URL using credentials from the
+// environment
+func Upload(target string, data []byte, contentType string) error {
+	u, err := url.Parse(target)
+	if err != nil {
+		return fmt.Errorf("invalid output URL: %w", err)
+	}
+
+	bucket := u.Host
+	key := strings.TrimPrefix(u.Path, "/")
+	if bucket == "" || key == "" {
+		return fmt.Errorf("output URL must include bucket and object path: %s", target)
+	}
+
+	client := &http.Client{Timeout: 120 * time.Second}
+
+	switch u.Scheme {
+	case "s3":
+		return uploadS3(client, bucket, key, data, contentType)
+	case "gs":
+		return uploadGCS(client, bucket, key, data, contentType)
+	default:
+		return fmt.Errorf("unsupported output scheme: %s", u.Scheme)
+	}
+}
+
+// ContentType guesses a content type for a report format
+func ContentType(format string) string {
+	switch format {
+	case "json", "sarif", "ocsf", "defectdojo":
+		return "application/json"
+	case "ndjson":
+		return "application/x-ndjson"
+	case "poam":
+		return "text/csv"
+	default:
+		return "text/plain; charset=utf-8"
+	}
+}
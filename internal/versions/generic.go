@@ -0,0 +1,55 @@
+package versions
+
+import (
+	"strconv"
+	"strings"
+)
+
+// compareGeneric compares dotted versions segment by segment, numerically
+// where both segments are numbers. Pre-release suffixes after "-" sort
+// before the release.
+func compareGeneric(a, b string) int {
+	a, b = strings.TrimPrefix(a, "v"), strings.TrimPrefix(b, "v")
+	aMain, aPre, _ := strings.Cut(a, "-")
+	bMain, bPre, _ := strings.Cut(b, "-")
+
+	if c := compareSegments(strings.Split(aMain, "."), strings.Split(bMain, ".")); c != 0 {
+		return c
+	}
+
+	switch {
+	case aPre == bPre:
+		return 0
+	case aPre == "":
+		return 1
+	case bPre == "":
+		return -1
+	}
+	return compareSegments(strings.Split(aPre, "."), strings.Split(bPre, "."))
+}
+
+// compareSegments compares segment lists, treating missing segments as "0"
+func compareSegments(a, b []string) int {
+	for i := 0; i < len(a) || i < len(b); i++ {
+		as, bs := "0", "0"
+		if i < len(a) && a[i] != "" {
+			as = a[i]
+		}
+		if i < len(b) && b[i] != "" {
+			bs = b[i]
+		}
+
+		an, aErr := strconv.Atoi(as)
+		bn, bErr := strconv.Atoi(bs)
+		if aErr == nil && bErr == nil {
+			if c := cmpInt(an, bn); c != 0 {
+				return c
+			}
+			continue
+		}
+		if c := strings.Compare(as, bs); c != 0 {
+			return c
+		}
+	}
+	return 0
+}
@@ -0,0 +1,145 @@
+package versions
+
+import (
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// mavenQualifiers orders well-known qualifiers as Maven's ComparableVersion
+// does. The empty qualifier is a release ("ga", "final" and "release" are
+// aliases for it). Unknown qualifiers sort after all of these, lexically.
+var mavenQualifiers = map[string]int{
+	"alpha":     0,
+	"beta":      1,
+	"milestone": 2,
+	"rc":        3,
+	"snapshot":  4,
+	"":          5,
+	"sp":        6,
+}
+
+var mavenAliases = map[string]string{
+	"a":       "alpha",
+	"b":       "beta",
+	"m":       "milestone",
+	"cr":      "rc",
+	"ga":      "",
+	"final":   "",
+	"release": "",
+}
+
+type mavenToken struct {
+	numeric bool
+	num     int
+	str     string
+}
+
+// compareMaven compares Maven versions using a simplified form of Maven's
+// ComparableVersion: tokens split on ".", "-" and digit/letter transitions,
+// trailing zeros and release qualifiers ignored, and qualifiers ranked
+// alpha < beta < milestone < rc < snapshot < release < sp.
+func compareMaven(a, b string) int {
+	at, bt := tokenizeMaven(a), tokenizeMaven(b)
+	for i := 0; i < len(at) || i < len(bt); i++ {
+		var x, y *mavenToken
+		if i < len(at) {
+			x = &at[i]
+		}
+		if i < len(bt) {
+			y = &bt[i]
+		}
+		if c := compareMavenTokens(x, y); c != 0 {
+			return c
+		}
+	}
+	return 0
+}
+
+func compareMavenTokens(x, y *mavenToken) int {
+	// A missing token compares as 0 against numbers and as a release
+	// against qualifiers
+	if x == nil {
+		if y.numeric {
+			x = &mavenToken{numeric: true}
+		} else {
+			x = &mavenToken{}
+		}
+	}
+	if y == nil {
+		if x.numeric {
+			y = &mavenToken{numeric: true}
+		} else {
+			y = &mavenToken{}
+		}
+	}
+
+	switch {
+	case x.numeric && y.numeric:
+		return cmpInt(x.num, y.num)
+	case x.numeric:
+		return 1 // Numbers sort after qualifiers (1.0.1 > 1.0-rc1)
+	case y.numeric:
+		return -1
+	}
+
+	xr, xok := mavenQualifiers[x.str]
+	yr, yok := mavenQualifiers[y.str]
+	switch {
+	case xok && yok:
+		return cmpInt(xr, yr)
+	case xok:
+		return -1
+	case yok:
+		return 1
+	}
+	return strings.Compare(x.str, y.str)
+}
+
+func tokenizeMaven(v string) []mavenToken {
+	v = strings.ToLower(strings.TrimSpace(v))
+
+	var raw []string
+	var cur strings.Builder
+	flush := func() {
+		raw = append(raw, cur.String())
+		cur.Reset()
+	}
+	for i, r := range v {
+		if r == '.' || r == '-' || r == '_' {
+			flush()
+			continue
+		}
+		if i > 0 && cur.Len() > 0 {
+			prev := rune(v[i-1])
+			if unicode.IsDigit(prev) != unicode.IsDigit(r) {
+				flush()
+			}
+		}
+		cur.WriteRune(r)
+	}
+	flush()
+
+	tokens := make([]mavenToken, 0, len(raw))
+	for _, s := range raw {
+		if n, err := strconv.Atoi(s); err == nil {
+			tokens = append(tokens, mavenToken{numeric: true, num: n})
+			continue
+		}
+		if alias, ok := mavenAliases[s]; ok {
+			s = alias
+		}
+		tokens = append(tokens, mavenToken{str: s})
+	}
+
+	// Trailing zeros and release qualifiers don't affect ordering
+	for len(tokens) > 0 {
+		last := tokens[len(tokens)-1]
+		if (last.numeric && last.num == 0) || (!last.numeric && last.str == "") {
+			tokens = tokens[:len(tokens)-1]
+			continue
+		}
+		break
+	}
+	return tokens
+}
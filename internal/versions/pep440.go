@@ -0,0 +1,118 @@
+package versions
+
+import (
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// pep440Pattern matches PEP 440 versions, including the alternate spellings
+// the spec normalizes (e.g. "1.0-alpha1", "1.0.post-1", "1.0-1")
+var pep440Pattern = regexp.MustCompile(`^v?(?:(\d+)!)?(\d+(?:\.\d+)*)` +
+	`(?:[-_.]?(a|alpha|b|beta|rc|c|pre|preview)[-_.]?(\d*))?` +
+	`(?:-(\d+)|[-_.]?(post|rev|r)[-_.]?(\d*))?` +
+	`(?:[-_.]?(dev)[-_.]?(\d*))?` +
+	`(?:\+[a-z0-9]+(?:[-_.][a-z0-9]+)*)?$`)
+
+// pep440Key is the sort key for a parsed version. Absent pre-release and dev
+// segments sort after present ones, absent post segments before.
+type pep440Key struct {
+	epoch   int
+	release []int
+	pre     [2]int // phase (a=0, b=1, rc=2), number
+	post    int
+	dev     int
+}
+
+const (
+	keyMin = math.MinInt
+	keyMax = math.MaxInt
+)
+
+// comparePEP440 compares Python package versions per PEP 440. Versions that
+// don't parse fall back to generic comparison.
+func comparePEP440(a, b string) int {
+	ak, aok := parsePEP440(a)
+	bk, bok := parsePEP440(b)
+	if !aok || !bok {
+		return compareGeneric(a, b)
+	}
+
+	if c := cmpInt(ak.epoch, bk.epoch); c != 0 {
+		return c
+	}
+	for i := 0; i < len(ak.release) || i < len(bk.release); i++ {
+		var av, bv int
+		if i < len(ak.release) {
+			av = ak.release[i]
+		}
+		if i < len(bk.release) {
+			bv = bk.release[i]
+		}
+		if c := cmpInt(av, bv); c != 0 {
+			return c
+		}
+	}
+	if c := cmpInt(ak.pre[0], bk.pre[0]); c != 0 {
+		return c
+	}
+	if c := cmpInt(ak.pre[1], bk.pre[1]); c != 0 {
+		return c
+	}
+	if c := cmpInt(ak.post, bk.post); c != 0 {
+		return c
+	}
+	return cmpInt(ak.dev, bk.dev)
+}
+
+func parsePEP440(v string) (pep440Key, bool) {
+	m := pep440Pattern.FindStringSubmatch(strings.ToLower(strings.TrimSpace(v)))
+	if m == nil {
+		return pep440Key{}, false
+	}
+
+	var k pep440Key
+	k.epoch, _ = strconv.Atoi(m[1])
+	for _, part := range strings.Split(m[2], ".") {
+		n, _ := strconv.Atoi(part)
+		k.release = append(k.release, n)
+	}
+
+	hasPre, hasPost, hasDev := m[3] != "", m[5] != "" || m[6] != "", m[8] != ""
+
+	switch {
+	case hasPre:
+		switch m[3] {
+		case "a", "alpha":
+			k.pre[0] = 0
+		case "b", "beta":
+			k.pre[0] = 1
+		default:
+			k.pre[0] = 2
+		}
+		k.pre[1], _ = strconv.Atoi(m[4])
+	case hasDev && !hasPost:
+		// 1.0.dev0 sorts before 1.0a0
+		k.pre = [2]int{keyMin, keyMin}
+	default:
+		k.pre = [2]int{keyMax, keyMax}
+	}
+
+	switch {
+	case m[5] != "":
+		k.post, _ = strconv.Atoi(m[5])
+	case hasPost:
+		k.post, _ = strconv.Atoi(m[7])
+	default:
+		k.post = keyMin
+	}
+
+	if hasDev {
+		k.dev, _ = strconv.Atoi(m[9])
+	} else {
+		k.dev = keyMax
+	}
+
+	return k, true
+}
@@ -0,0 +1,68 @@
+package versions
+
+import (
+	"strconv"
+	"strings"
+)
+
+// compareSemver compares Semantic Versioning 2.0 versions as used by npm,
+// Go modules and Hex. A leading "v" is ignored, missing minor/patch numbers
+// are treated as zero and build metadata is ignored.
+func compareSemver(a, b string) int {
+	aCore, aPre := splitSemver(a)
+	bCore, bPre := splitSemver(b)
+
+	for i := 0; i < 3; i++ {
+		if c := cmpInt(aCore[i], bCore[i]); c != 0 {
+			return c
+		}
+	}
+
+	// A version without pre-release identifiers has higher precedence
+	switch {
+	case aPre == "" && bPre == "":
+		return 0
+	case aPre == "":
+		return 1
+	case bPre == "":
+		return -1
+	}
+	return comparePrerelease(strings.Split(aPre, "."), strings.Split(bPre, "."))
+}
+
+func splitSemver(v string) ([3]int, string) {
+	v = strings.TrimPrefix(strings.TrimSpace(v), "v")
+	v, _, _ = strings.Cut(v, "+")
+	core, pre, _ := strings.Cut(v, "-")
+
+	var nums [3]int
+	for i, part := range strings.SplitN(core, ".", 3) {
+		nums[i], _ = strconv.Atoi(part)
+	}
+	return nums, pre
+}
+
+// comparePrerelease orders dot-separated identifiers: numeric identifiers
+// compare numerically and sort before alphanumeric ones, and a shorter set
+// sorts first when all shared identifiers are equal
+func comparePrerelease(a, b []string) int {
+	for i := 0; i < len(a) && i < len(b); i++ {
+		an, aErr := strconv.Atoi(a[i])
+		bn, bErr := strconv.Atoi(b[i])
+		switch {
+		case aErr == nil && bErr == nil:
+			if c := cmpInt(an, bn); c != 0 {
+				return c
+			}
+		case aErr == nil:
+			return -1
+		case bErr == nil:
+			return 1
+		default:
+			if c := strings.Compare(a[i], b[i]); c != 0 {
+				return c
+			}
+		}
+	}
+	return cmpInt(len(a), len(b))
+}
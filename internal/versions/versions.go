@@ -0,0 +1,37 @@
+package versions
+
+import (
+	"github.com/ethanolivertroy/kev-check-demo/internal/models"
+)
+
+// Compare returns -1, 0 or 1 as a is less than, equal to or greater than b
+// under the given version scheme. Used to match dependencies against
+// advisory ranges locally rather than relying on the OSV API.
+func Compare(scheme models.VersionScheme, a, b string) int {
+	switch scheme {
+	case models.VersionSemver:
+		return compareSemver(a, b)
+	case models.VersionPEP440:
+		return comparePEP440(a, b)
+	case models.VersionMaven:
+		return compareMaven(a, b)
+	default:
+		return compareGeneric(a, b)
+	}
+}
+
+// ForEcosystem compares versions using the ecosystem's registered scheme
+func ForEcosystem(eco models.Ecosystem, a, b string) int {
+	return Compare(eco.Info().Versions, a, b)
+}
+
+// cmpInt compares two integers
+func cmpInt(a, b int) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,98 @@
+package versions
+
+import (
+	"testing"
+
+	"github.com/ethanolivertroy/kev-check-demo/internal/models"
+)
+
+func TestForEcosystem(t *testing.T) {
+	tests := []struct {
+		eco  models.Ecosystem
+		a, b string
+		want int
+	}{
+		// PEP 440: epochs, pre-, post- and dev releases, local labels
+		{models.EcosystemPyPI, "1.10", "1.9", 1},
+		{models.EcosystemPyPI, "1.0", "1.0.0", 0},
+		{models.EcosystemPyPI, "1!1.0", "2.0", 1},
+		{models.EcosystemPyPI, "1!1.0", "1!1.1", -1},
+		{models.EcosystemPyPI, "0!2.0", "2.0", 0},
+		{models.EcosystemPyPI, "1.0.dev0", "1.0a1", -1},
+		{models.EcosystemPyPI, "1.0a1", "1.0a2", -1},
+		{models.EcosystemPyPI, "1.0a2", "1.0b1", -1},
+		{models.EcosystemPyPI, "1.0b1", "1.0rc1", -1},
+		{models.EcosystemPyPI, "1.0rc1", "1.0", -1},
+		{models.EcosystemPyPI, "1.0", "1.0.post1", -1},
+		{models.EcosystemPyPI, "1.0.post1.dev0", "1.0.post1", -1},
+		{models.EcosystemPyPI, "1.0-alpha1", "1.0a1", 0},
+		{models.EcosystemPyPI, "1.0c1", "1.0rc1", 0},
+		{models.EcosystemPyPI, "1.0-1", "1.0.post1", 0},
+		{models.EcosystemPyPI, "1.0+ubuntu.1", "1.0", 0},
+		{models.EcosystemPyPI, "V2.0", "2.0", 0},
+
+		// SemVer 2.0 precedence (npm)
+		{models.EcosystemNpm, "1.0.0-alpha", "1.0.0-alpha.1", -1},
+		{models.EcosystemNpm, "1.0.0-alpha.1", "1.0.0-alpha.beta", -1},
+		{models.EcosystemNpm, "1.0.0-alpha.beta", "1.0.0-beta", -1},
+		{models.EcosystemNpm, "1.0.0-beta.2", "1.0.0-beta.11", -1},
+		{models.EcosystemNpm, "1.0.0-rc.1", "1.0.0", -1},
+		{models.EcosystemNpm, "1.0.0+build.5", "1.0.0", 0},
+		{models.EcosystemNpm, "1.0.0-rc.1+build.5", "1.0.0-rc.1", 0},
+		{models.EcosystemNpm, "2.10.0", "2.9.9", 1},
+		{models.EcosystemNpm, "1", "1.0.0", 0},
+
+		// Go modules: "v" prefix, pseudo-versions, +incompatible
+		{models.EcosystemGo, "v1.2.3", "1.2.3", 0},
+		{models.EcosystemGo, "v1.10.0", "v1.9.0", 1},
+		{models.EcosystemGo, "v1.2.3-pre", "v1.2.3", -1},
+		{models.EcosystemGo, "v0.0.0-20210101000000-abcdef123456", "v0.1.0", -1},
+		{models.EcosystemGo, "v1.2.4-0.20210101000000-abcdef123456", "v1.2.3", 1},
+		{models.EcosystemGo, "v2.0.0+incompatible", "v2.0.0", 0},
+
+		// crates.io, Hex and Ansible Galaxy follow SemVer
+		{models.EcosystemCratesIO, "0.9.10", "0.9.9", 1},
+		{models.EcosystemCratesIO, "1.0.0-alpha.1", "1.0.0", -1},
+		{models.EcosystemCratesIO, "1.0.0+20240101", "1.0.0", 0},
+		{models.EcosystemHex, "1.0.0-rc.0", "1.0.0", -1},
+		{models.EcosystemHex, "1.5.0", "1.14.0", -1},
+		{models.EcosystemAnsible, "2.10.0", "2.9.27", 1},
+		{models.EcosystemAnsible, "3.0.0-beta.1", "3.0.0", -1},
+
+		// Maven qualifiers
+		{models.EcosystemMaven, "2.12.0", "2.9.1", 1},
+		{models.EcosystemMaven, "1.0", "1.0.0", 0},
+		{models.EcosystemMaven, "1.0-alpha-1", "1.0-beta-1", -1},
+		{models.EcosystemMaven, "1.0-beta-1", "1.0-M1", -1},
+		{models.EcosystemMaven, "1.0-M1", "1.0-RC1", -1},
+		{models.EcosystemMaven, "1.0-RC1", "1.0-SNAPSHOT", -1},
+		{models.EcosystemMaven, "1.0-SNAPSHOT", "1.0", -1},
+		{models.EcosystemMaven, "1.0", "1.0-sp1", -1},
+		{models.EcosystemMaven, "1.0-rc1", "1.0.1", -1},
+		{models.EcosystemMaven, "1.0.Final", "1.0", 0},
+		{models.EcosystemMaven, "1.0-ga", "1.0", 0},
+		{models.EcosystemMaven, "1.0a1", "1.0-alpha-1", 0},
+		{models.EcosystemMaven, "1.0-CR2", "1.0-rc2", 0},
+
+		// Generic dotted versions (Packagist, WordPress, Hackage)
+		{models.EcosystemPackagist, "v1.2", "1.2.0", 0},
+		{models.EcosystemPackagist, "5.4.10", "5.4.9", 1},
+		{models.EcosystemPackagist, "2.0.0-beta1", "2.0.0", -1},
+		{models.EcosystemPackagist, "2.0.0-beta1", "2.0.0-beta2", -1},
+		{models.EcosystemWordPress, "6.4", "6.4.1", -1},
+		{models.EcosystemHackage, "0.10.1.0", "0.9.0.0", 1},
+		{models.EcosystemHackage, "1.2", "1.2.0.0", 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.eco)+"/"+tt.a+"_"+tt.b, func(t *testing.T) {
+			if got := ForEcosystem(tt.eco, tt.a, tt.b); got != tt.want {
+				t.Errorf("ForEcosystem(%s, %q, %q) = %d, want %d", tt.eco, tt.a, tt.b, got, tt.want)
+			}
+			// Comparison is antisymmetric
+			if got := ForEcosystem(tt.eco, tt.b, tt.a); got != -tt.want {
+				t.Errorf("ForEcosystem(%s, %q, %q) = %d, want %d", tt.eco, tt.b, tt.a, got, -tt.want)
+			}
+		})
+	}
+}